@@ -19,6 +19,23 @@ type Archive struct {
 	Data []byte // Содержимое архива в zip-формате
 }
 
+// SignatureExt - расширение файла с отсоединенной подписью.
+// Пример: файл с подписью для файла "req.xml" должен называться "req.xml.sig".
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12", раздел "2.1.3 Отправка заявления (загрузка архива по частям)".
+const SignatureExt = ".sig"
+
+// CMSSigner - интерфейс провайдера усиленной квалифицированной электронной подписи (УКЭП) файлов вложений.
+// Провайдер должен возвращать отсоединенную подпись данных в формате CMS (PKCS#7) по ГОСТ Р 34.10-2012
+// с сертификатом подписанта и временем подписания.
+//
+// Реализация на чистом Go: [signature.CMS].
+//
+// [signature.CMS]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/signature#CMS
+type CMSSigner interface {
+	SignDetached(data []byte) ([]byte, error)
+}
+
 // NewArchive - создает архив из файлов вложений.
 // В случае ошибки возвращает [ErrZip].
 func NewArchive(name string, files ...ArchiveFile) (*Archive, error) {
@@ -26,6 +43,32 @@ func NewArchive(name string, files ...ArchiveFile) (*Archive, error) {
 		return nil, ErrNoFiles
 	}
 
+	return newArchive(name, files)
+}
+
+// NewSignedArchive - создает архив из файлов вложений для услуг, требующих подписания заявления УКЭП:
+// к каждому файлу добавляется файл с отсоединенной подписью signer с именем файла и расширением [SignatureExt].
+// Пример: "req.xml" и "req.xml.sig".
+//
+// В случае ошибки возвращает [ErrNoFiles], [ErrZip] или цепочку из [ErrFileSign] и описания ошибки.
+func NewSignedArchive(name string, signer CMSSigner, files ...ArchiveFile) (*Archive, error) {
+	if len(files) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	signed := make([]ArchiveFile, 0, 2*len(files))
+	for _, file := range files {
+		sig, err := signer.SignDetached(file.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': %w", ErrFileSign, file.Filename, err)
+		}
+		signed = append(signed, file, ArchiveFile{Filename: file.Filename + SignatureExt, Data: sig})
+	}
+
+	return newArchive(name, signed)
+}
+
+func newArchive(name string, files []ArchiveFile) (*Archive, error) {
 	var b bytes.Buffer
 	zipWriter := zip.NewWriter(&b)
 	for _, file := range files {
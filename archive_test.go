@@ -3,6 +3,7 @@ package apipgu
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
 	"io"
 	"testing"
 
@@ -43,6 +44,41 @@ func (suite *suiteTestArchive) TestNewArchive() {
 
 }
 
+func (suite *suiteTestArchive) TestNewSignedArchive() {
+	file1 := ArchiveFile{Filename: "req.xml", Data: []byte("<req/>")}
+	file2 := ArchiveFile{Filename: "file2.txt", Data: []byte("This is file 2")}
+
+	suite.Run("success", func() {
+		archive, err := NewSignedArchive("test", testCMSSigner{}, file1, file2)
+		suite.NoError(err)
+		suite.Require().NotNil(archive)
+		suite.Equal("test", archive.Name)
+
+		r, err := zip.NewReader(bytes.NewReader(archive.Data), int64(len(archive.Data)))
+		suite.Require().NoError(err)
+		suite.Require().Len(r.File, 4)
+
+		suite.Equal(file1, suite.unZip(r.File[0]))
+		suite.Equal(ArchiveFile{Filename: "req.xml.sig", Data: []byte("sig:<req/>")}, suite.unZip(r.File[1]))
+		suite.Equal(file2, suite.unZip(r.File[2]))
+		suite.Equal(ArchiveFile{Filename: "file2.txt.sig", Data: []byte("sig:This is file 2")}, suite.unZip(r.File[3]))
+	})
+
+	suite.Run("no files", func() {
+		archive, err := NewSignedArchive("test", testCMSSigner{})
+		suite.ErrorIs(err, ErrNoFiles)
+		suite.Nil(archive)
+	})
+
+	suite.Run("sign error", func() {
+		archive, err := NewSignedArchive("test", testCMSSigner{err: errors.New("test error")}, file1)
+		suite.ErrorIs(err, ErrFileSign)
+		suite.ErrorContains(err, "req.xml")
+		suite.ErrorContains(err, "test error")
+		suite.Nil(archive)
+	})
+}
+
 func (suite *suiteTestArchive) unZip(zipFile *zip.File) ArchiveFile {
 	f, err := zipFile.Open()
 	suite.Require().NoError(err)
@@ -55,3 +91,15 @@ func (suite *suiteTestArchive) unZip(zipFile *zip.File) ArchiveFile {
 		Data:     b.Bytes(),
 	}
 }
+
+// testCMSSigner - провайдер подписи, возвращающий данные с префиксом "sig:".
+type testCMSSigner struct {
+	err error
+}
+
+func (s testCMSSigner) SignDetached(data []byte) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]byte("sig:"), data...), nil
+}
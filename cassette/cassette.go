@@ -0,0 +1,102 @@
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"unicode/utf8"
+)
+
+// Version - версия формата файла кассеты.
+const Version = 1
+
+// Cassette - записанные HTTP-запросы и ответы.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction - HTTP-запрос и ответ на него.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request - записанный HTTP-запрос.
+// Тело запроса хранится в нормализованном виде, см [Recorder].
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   Body        `json:"body"`
+}
+
+// Response - записанный HTTP-ответ.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       Body        `json:"body"`
+}
+
+// Body - тело запроса или ответа.
+// Текст в кодировке UTF-8 хранится в файле кассеты строкой, двоичные данные - в base64.
+type Body []byte
+
+// MarshalJSON - реализация [json.Marshaler].
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON - реализация [json.Unmarshaler].
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var v struct {
+		Base64 []byte `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = v.Base64
+	return nil
+}
+
+// Load - читает кассету из файла path.
+//
+// Возвращает цепочку ошибок из [ErrCassette] и ошибок чтения файла или разбора JSON.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCassette, err)
+	}
+	c := &Cassette{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrCassette, path, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("%w: %s: неподдерживаемая версия %d", ErrCassette, path, c.Version)
+	}
+	return c, nil
+}
+
+// Save - записывает кассету в файл path.
+//
+// Возвращает цепочку ошибок из [ErrCassette] и ошибок записи файла.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCassette, err)
+	}
+	if err = os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("%w: %w", ErrCassette, err)
+	}
+	return nil
+}
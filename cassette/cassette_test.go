@@ -0,0 +1,200 @@
+package cassette
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/epgutest"
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/esiatest"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+const testToken = "test-token"
+
+var testMeta = apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"}
+
+func TestCassette(t *testing.T) {
+	suite.Run(t, new(suiteCassette))
+}
+
+type suiteCassette struct {
+	suite.Suite
+}
+
+// archive - архив заявления с уникальным именем файла.
+func (suite *suiteCassette) archive() *apipgu.Archive {
+	guid, err := utils.GUID()
+	suite.Require().NoError(err)
+	archive, err := apipgu.NewArchive(guid, apipgu.ArchiveFile{Filename: "req_" + guid + ".xml", Data: []byte("<req/>")})
+	suite.Require().NoError(err)
+	return archive
+}
+
+func (suite *suiteCassette) TestRecordReplayEPGU() {
+	server := epgutest.NewServer().WithScenario(testMeta.ServiceCode, epgutest.Scenario{
+		Steps: []epgutest.Step{{StatusId: 3, Title: "Исполнено", Final: true, Files: []epgutest.File{
+			{Name: "resp.pdf", Data: []byte{0xff, 0x00, 0xfe}},
+		}}},
+	})
+	recorder := NewRecorder(nil)
+	client := apipgu.NewClient(server.URL).WithHTTPClient(&http.Client{Transport: recorder}).WithChunkSize(64)
+
+	orderId, err := client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+	suite.Require().NoError(client.OrderPushChunked(testToken, orderId, suite.archive()))
+	suite.Require().NoError(server.Advance(orderId))
+	info, err := client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Require().Len(info.Order.OrderResponseFiles, 1)
+	file, err := client.AttachmentDownload(testToken, info.Order.OrderResponseFiles[0].Link)
+	suite.Require().NoError(err)
+	server.Close()
+
+	path := filepath.Join(suite.T().TempDir(), "epgu.json")
+	suite.Require().NoError(recorder.Save(path))
+	c, err := Load(path)
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(c.Interactions)
+	for _, in := range c.Interactions {
+		suite.Equal([]string{Redacted}, in.Request.Header["Authorization"])
+		suite.NotContains(string(in.Request.Body), "--"+strings.Repeat("-", 10))
+	}
+
+	replayer, err := NewReplayer(path)
+	suite.Require().NoError(err)
+	client = apipgu.NewClient(server.URL).WithHTTPClient(&http.Client{Transport: replayer}).WithChunkSize(64)
+
+	replayedId, err := client.OrderCreate("other-token", testMeta)
+	suite.Require().NoError(err)
+	suite.Equal(orderId, replayedId)
+	suite.Require().NoError(client.OrderPushChunked("other-token", orderId, suite.archive()))
+	replayedInfo, err := client.OrderInfo("other-token", orderId)
+	suite.Require().NoError(err)
+	suite.Equal(info.Order.OrderStatusId, replayedInfo.Order.OrderStatusId)
+	suite.Equal(info.Order.OrderResponseFiles, replayedInfo.Order.OrderResponseFiles)
+	replayedFile, err := client.AttachmentDownload("other-token", info.Order.OrderResponseFiles[0].Link)
+	suite.Require().NoError(err)
+	suite.Equal(file, replayedFile)
+	suite.Zero(replayer.Remaining())
+
+	_, err = client.OrderInfo("other-token", orderId)
+	suite.ErrorIs(err, ErrNoInteraction)
+}
+
+func (suite *suiteCassette) TestRecordReplayEPGUError() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"code":"order_access","message":"Доступ к заявлению запрещен"}`))
+	}))
+	recorder := NewRecorder(nil)
+	client := apipgu.NewClient(server.URL).WithHTTPClient(&http.Client{Transport: recorder})
+	_, recordedErr := client.OrderInfo(testToken, 1230254874)
+	suite.Require().ErrorIs(recordedErr, apipgu.ErrCodeOrderAccess)
+	server.Close()
+
+	c := recorder.Cassette()
+	suite.Require().Len(c.Interactions, 1)
+	suite.Contains(string(c.Interactions[0].Response.Body), `"code":"order_access"`)
+
+	replayer, err := NewReplayerFromCassette(c)
+	suite.Require().NoError(err)
+	client = apipgu.NewClient(server.URL).WithHTTPClient(&http.Client{Transport: replayer})
+	_, replayedErr := client.OrderInfo("other-token", 1230254874)
+	suite.ErrorIs(replayedErr, apipgu.ErrCodeOrderAccess)
+	suite.EqualError(replayedErr, recordedErr.Error())
+}
+
+func (suite *suiteCassette) TestRecordReplayESIA() {
+	const redirectURI = "http://localhost/callback"
+	server := esiatest.NewServer()
+	recorder := NewRecorder(nil)
+	signer := signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash)
+	client := aas.NewClient(server.URL, "TEST_IS", signer).WithHTTPClient(&http.Client{Transport: recorder})
+	scopes := aas.NewScopes(aas.ScopeOpenID, aas.ScopeAPIOrder)
+
+	authURI, err := client.AuthURI(scopes, redirectURI, nil)
+	suite.Require().NoError(err)
+	query, err := server.Authorize(authURI)
+	suite.Require().NoError(err)
+	code, _, err := client.ParseCallback(query)
+	suite.Require().NoError(err)
+	res, err := client.TokenExchange(code, scopes, redirectURI)
+	suite.Require().NoError(err)
+	suite.NotEqual(Redacted, res.AccessToken)
+	server.Close()
+
+	c := recorder.Cassette()
+	suite.Require().Len(c.Interactions, 1)
+	in := c.Interactions[0]
+	suite.NotContains(string(in.Request.Body), code)
+	suite.NotContains(string(in.Response.Body), res.AccessToken)
+	suite.Contains(string(in.Response.Body), `"access_token":"[REDACTED]"`)
+
+	replayer, err := NewReplayerFromCassette(c)
+	suite.Require().NoError(err)
+	client = aas.NewClient(server.URL, "TEST_IS", signer).WithHTTPClient(&http.Client{Transport: replayer})
+	replayed, err := client.TokenExchange("other-code", scopes, redirectURI)
+	suite.Require().NoError(err)
+	suite.Equal(Redacted, replayed.AccessToken)
+	suite.Equal(res.ExpiresIn, replayed.ExpiresIn)
+
+	_, err = client.TokenExchange("other-code", aas.NewScopes(aas.ScopeOpenID), redirectURI)
+	suite.ErrorIs(err, ErrNoInteraction)
+}
+
+func (suite *suiteCassette) TestRedactor() {
+	r := newRedactor(DefaultRedactHeaders, append(DefaultRedactFields, "secret"))
+
+	suite.Run("json", func() {
+		body := r.body("application/json", []byte(`{"orderId":1230254874,"order":"{\"userId\":100,\"id\":1}","items":[{"snils":"1"}]}`))
+		suite.JSONEq(`{"orderId":1230254874,"order":"{\"id\":1,\"userId\":0}","items":[{"snils":"[REDACTED]"}]}`, string(body))
+	})
+
+	suite.Run("form", func() {
+		body := r.body("application/x-www-form-urlencoded", []byte("secret=1&scope=openid"))
+		suite.Equal("scope=openid&secret=%5BREDACTED%5D", string(body))
+	})
+
+	suite.Run("code", func() {
+		r := newDefaultRedactor()
+		body := r.body("application/x-www-form-urlencoded", []byte("code=abc&grant_type=authorization_code"))
+		suite.Equal("code=%5BREDACTED%5D&grant_type=authorization_code", string(body))
+		body = r.body("application/json", []byte(`{"code":"order_access","order":"{\"code\":0}"}`))
+		suite.JSONEq(`{"code":"order_access","order":"{\"code\":0}"}`, string(body))
+	})
+
+	suite.Run("not json", func() {
+		body := r.body("text/plain", []byte(`{"secret":1}`))
+		suite.Equal(`{"secret":1}`, string(body))
+	})
+
+	suite.Run("url", func() {
+		u, err := http.NewRequest(http.MethodGet, "http://localhost/path?secret=1&a=2", nil)
+		suite.Require().NoError(err)
+		suite.Equal("http://localhost/path?a=2&secret=%5BREDACTED%5D", r.url(u.URL))
+	})
+}
+
+func (suite *suiteCassette) TestBody() {
+	c := &Cassette{Version: Version, Interactions: []Interaction{{
+		Request:  Request{Method: http.MethodGet, URL: "http://localhost/", Body: Body("text")},
+		Response: Response{StatusCode: http.StatusOK, Body: Body{0xff, 0x00}},
+	}}}
+	path := filepath.Join(suite.T().TempDir(), "body.json")
+	suite.Require().NoError(c.Save(path))
+	loaded, err := Load(path)
+	suite.Require().NoError(err)
+	suite.Equal(c, loaded)
+
+	_, err = Load(filepath.Join(suite.T().TempDir(), "missing.json"))
+	suite.ErrorIs(err, ErrCassette)
+}
@@ -0,0 +1,30 @@
+// Пакет cassette - запись и воспроизведение HTTP-запросов к ЕПГУ и ЕСИА для детерминированных тестов.
+//
+// [Recorder] записывает запросы и ответы [apipgu.Client] и [aas.Client] в файл кассеты (JSON),
+// заменяя маркеры доступа и персональные данные на [Redacted]. [Replayer] возвращает записанные ответы
+// без обращения к сети, поэтому сессия, записанная на тестовом контуре, становится регрессионным тестом.
+//
+// Замененные значения не восстанавливаются при воспроизведении: например, маркер доступа
+// из ответа ЕСИА нельзя разобрать функцией [aas.ParseTokenClaims].
+//
+// Запись:
+//
+//	recorder := cassette.NewRecorder(nil)
+//	client := apipgu.NewClient("https://svcdev-beta.test.gosuslugi.ru").
+//		WithHTTPClient(&http.Client{Transport: recorder})
+//	orderId, err := client.OrderPush(token, meta, archive)
+//	...
+//	err = recorder.Save("testdata/order-push.json")
+//
+// Воспроизведение:
+//
+//	replayer, err := cassette.NewReplayer("testdata/order-push.json")
+//	...
+//	client := apipgu.NewClient("https://svcdev-beta.test.gosuslugi.ru").
+//		WithHTTPClient(&http.Client{Transport: replayer})
+//	orderId, err := client.OrderPush(token, meta, archive)
+//
+// [apipgu.Client]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#Client
+// [aas.Client]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/aas#Client
+// [aas.ParseTokenClaims]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/aas#ParseTokenClaims
+package cassette
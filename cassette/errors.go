@@ -0,0 +1,10 @@
+package cassette
+
+import "errors"
+
+// Ошибки пакета cassette
+var (
+	ErrCassette      = errors.New("ошибка файла кассеты")
+	ErrNoInteraction = errors.New("в кассете нет подходящего запроса")
+	ErrRecord        = errors.New("ошибка записи запроса")
+)
@@ -0,0 +1,259 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+// Redacted - значение, которым заменяются маркеры доступа и персональные данные.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactHeaders - заголовки, значения которых заменяются на [Redacted].
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// DefaultRedactFields - поля JSON, параметры форм и query-параметры,
+// значения которых заменяются на [Redacted]: маркеры доступа, подписи и персональные данные.
+// Числовые значения полей JSON заменяются на 0.
+// Совпадает со списком [utils.RedactFields], по которому те же поля удаляются из журнала HAR.
+//
+// [utils.RedactFields]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/utils#RedactFields
+var DefaultRedactFields = slices.Clone(utils.RedactFields)
+
+// DefaultRedactFormFields - параметры форм и query-параметры, значения которых заменяются на [Redacted]
+// только в формах и query: код авторизации ЕСИА. Поле code в JSON - код ошибки или результата ЕПГУ,
+// по которому определяются ошибки ErrCodeXXXX, поэтому в JSON оно не заменяется.
+var DefaultRedactFormFields = slices.Clone(utils.RedactFormFields)
+
+// DefaultIgnoreFields - поля, значения которых не учитываются при сопоставлении запросов [Replayer]:
+// время запроса к ЕСИА меняется при каждом запросе.
+var DefaultIgnoreFields = []string{"timestamp"}
+
+var (
+	reGUID   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	reBinary = regexp.MustCompile(`\[ \d+ bytes of binary data\.\.\. ]`)
+)
+
+// redactor - заменяет значения заголовков и полей на [Redacted].
+type redactor struct {
+	headers map[string]struct{}
+	fields  map[string]struct{}
+	form    map[string]struct{} // Только параметры форм и query-параметры
+}
+
+func newRedactor(headers, fields []string) *redactor {
+	r := &redactor{
+		headers: make(map[string]struct{}),
+		fields:  make(map[string]struct{}),
+		form:    make(map[string]struct{}),
+	}
+	r.add(headers, fields)
+	return r
+}
+
+// newDefaultRedactor - redactor с заголовками [DefaultRedactHeaders], полями [DefaultRedactFields]
+// и параметрами [DefaultRedactFormFields].
+func newDefaultRedactor() *redactor {
+	r := newRedactor(DefaultRedactHeaders, DefaultRedactFields)
+	for _, f := range DefaultRedactFormFields {
+		r.form[f] = struct{}{}
+	}
+	return r
+}
+
+// add - добавляет заголовки и поля.
+func (r *redactor) add(headers, fields []string) {
+	for _, h := range headers {
+		r.headers[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	for _, f := range fields {
+		r.fields[f] = struct{}{}
+	}
+}
+
+// header - возвращает копию заголовков с замененными значениями.
+func (r *redactor) header(h http.Header) http.Header {
+	res := h.Clone()
+	for name := range res {
+		if _, ok := r.headers[http.CanonicalHeaderKey(name)]; ok {
+			res[name] = []string{Redacted}
+		}
+	}
+	return res
+}
+
+// url - возвращает URL с замененными значениями query-параметров.
+func (r *redactor) url(u *url.URL) string {
+	res := *u
+	if res.RawQuery != "" {
+		res.RawQuery = r.values(res.Query()).Encode()
+	}
+	return res.String()
+}
+
+// values - заменяет значения параметров формы.
+func (r *redactor) values(v url.Values) url.Values {
+	for name := range v {
+		_, field := r.fields[name]
+		_, form := r.form[name]
+		if field || form {
+			v[name] = []string{Redacted}
+		}
+	}
+	return v
+}
+
+// body - возвращает тело с замененными значениями полей в зависимости от типа содержимого:
+// JSON, форма или multipart. Multipart-содержимое приводится к виду без разделителя (boundary),
+// двоичные части заменяются их размером, как в [utils.LogReq].
+//
+// [utils.LogReq]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/utils#LogReq
+func (r *redactor) body(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if res, err := r.multipart(body, params["boundary"]); err == nil {
+			return res
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if v, err := url.ParseQuery(string(body)); err == nil {
+			return []byte(r.values(v).Encode())
+		}
+	case isJSON(mediaType, body):
+		if v, err := decodeJSON(body); err == nil {
+			if res, err := json.Marshal(r.json(v)); err == nil {
+				return res
+			}
+		}
+	}
+	return body
+}
+
+// json - заменяет значения полей JSON, в том числе во вложенных JSON-объектах,
+// переданных строкой (например, поле order ответа ЕПГУ).
+func (r *redactor) json(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if _, ok := r.fields[key]; ok {
+				v[key] = redactValue(value)
+				continue
+			}
+			v[key] = r.json(value)
+		}
+	case []any:
+		for i := range v {
+			v[i] = r.json(v[i])
+		}
+	case string:
+		if strings.HasPrefix(v, "{") {
+			if nested, err := decodeJSON([]byte(v)); err == nil {
+				if res, err := json.Marshal(r.json(nested)); err == nil {
+					return string(res)
+				}
+			}
+		}
+	}
+	return v
+}
+
+// redactValue - заменяет значение поля JSON с сохранением типа, чтобы ответ можно было разобрать:
+// числа заменяются на 0, остальные значения - на [Redacted].
+func redactValue(v any) any {
+	if _, ok := v.(json.Number); ok {
+		return json.Number("0")
+	}
+	return Redacted
+}
+
+// multipart - приводит multipart-содержимое к виду без разделителя.
+func (r *redactor) multipart(body []byte, boundary string) ([]byte, error) {
+	if boundary == "" {
+		return nil, fmt.Errorf("не указан boundary")
+	}
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	buf := &bytes.Buffer{}
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		contentType := part.Header.Get("Content-Type")
+		buf.WriteString("Content-Disposition: " + part.Header.Get("Content-Disposition") + "\n")
+		if contentType != "" {
+			buf.WriteString("Content-Type: " + contentType + "\n")
+		}
+		buf.WriteString("\n")
+		if part.FileName() != "" || contentType == "application/octet-stream" {
+			_, _ = fmt.Fprintf(buf, "[ %d bytes of binary data... ]", len(data))
+		} else {
+			buf.Write(r.body(contentType, data))
+		}
+		buf.WriteString("\n--\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeJSON - разбирает JSON без потери точности чисел.
+func decodeJSON(data []byte) (any, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("лишние данные после JSON")
+	}
+	return v, nil
+}
+
+// isJSON - проверяет, что содержимое в формате JSON.
+func isJSON(mediaType string, body []byte) bool {
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return mediaType == "" && len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// matchKey - ключ сопоставления записанного запроса и запроса при воспроизведении:
+// метод, путь, query-параметры и тело запроса без учета полей ignore, GUID и размеров двоичных данных.
+// Значения query-параметров и тело должны быть предварительно обработаны [redactor].
+func matchKey(method, rawURL, contentType string, body []byte, ignore *redactor) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := ignore.values(u.Query()).Encode()
+
+	// multipart-содержимое уже приведено к виду без разделителя
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body = ignore.body(contentType, body)
+	}
+	key := method + " " + u.Path + "?" + query + "\n" + string(body)
+	key = reGUID.ReplaceAllString(key, "{guid}")
+	key = reBinary.ReplaceAllString(key, "[ binary data ]")
+	return key, nil
+}
@@ -0,0 +1,122 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Recorder - [http.RoundTripper], который выполняет запросы через транспорт next
+// и записывает запросы и ответы в кассету.
+//
+// Перед записью значения заголовков [DefaultRedactHeaders], полей и параметров [DefaultRedactFields]
+// и параметров [DefaultRedactFormFields] в запросах и ответах заменяются на [Redacted]. Multipart-содержимое запросов записывается
+// без разделителя, двоичные части (файлы архива заявления) - только размером.
+//
+// Пример:
+//
+//	recorder := cassette.NewRecorder(nil)
+//	client := apipgu.NewClient(baseURI).WithHTTPClient(&http.Client{Transport: recorder})
+//	...
+//	err = recorder.Save("testdata/order-push.json")
+type Recorder struct {
+	next     http.RoundTripper
+	mu       sync.Mutex
+	redactor *redactor
+	cassette Cassette
+}
+
+// NewRecorder - конструктор [Recorder].
+// Если next равен nil, используется [http.DefaultTransport].
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		next:     next,
+		redactor: newDefaultRedactor(),
+		cassette: Cassette{Version: Version, Interactions: []Interaction{}},
+	}
+}
+
+// WithRedactHeaders - добавляет заголовки, значения которых заменяются на [Redacted].
+func (r *Recorder) WithRedactHeaders(headers ...string) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactor.add(headers, nil)
+	return r
+}
+
+// WithRedactFields - добавляет поля JSON, параметры форм и query-параметры,
+// значения которых заменяются на [Redacted].
+func (r *Recorder) WithRedactFields(fields ...string) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactor.add(nil, fields)
+	return r
+}
+
+// RoundTrip - реализация [http.RoundTripper].
+//
+// Возвращает ответ транспорта next либо цепочку ошибок из [ErrRecord] и других.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRecord, err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRecord, err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	// длина тела ответа после замены значений может измениться
+	resHeader := r.redactor.header(res.Header)
+	resHeader.Del("Content-Length")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    r.redactor.url(req.URL),
+			Header: r.redactor.header(req.Header),
+			Body:   r.redactor.body(req.Header.Get("Content-Type"), reqBody),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     resHeader,
+			Body:       r.redactor.body(res.Header.Get("Content-Type"), resBody),
+		},
+	})
+	return res, nil
+}
+
+// Cassette - возвращает копию записанной кассеты.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.cassette
+	c.Interactions = append([]Interaction{}, r.cassette.Interactions...)
+	return &c
+}
+
+// Save - записывает кассету в файл path, см [Cassette.Save].
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
@@ -0,0 +1,144 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Replayer - [http.RoundTripper], который возвращает записанные в кассету ответы без обращения к сети.
+//
+// Запрос сопоставляется с записанным по методу, пути, query-параметрам и телу запроса.
+// Тело нормализуется так же, как при записи [Recorder], кроме того не учитываются:
+// разделители multipart-содержимого, GUID, размеры двоичных частей и поля [DefaultIgnoreFields].
+// Каждый записанный ответ возвращается один раз, в порядке записи.
+//
+// Пример:
+//
+//	replayer, err := cassette.NewReplayer("testdata/order-push.json")
+//	...
+//	client := apipgu.NewClient("https://svcdev-beta.test.gosuslugi.ru").
+//		WithHTTPClient(&http.Client{Transport: replayer})
+type Replayer struct {
+	mu           sync.Mutex
+	redactor     *redactor
+	ignore       *redactor
+	interactions []Interaction
+	keys         []string
+	used         []bool
+}
+
+// NewReplayer - конструктор [Replayer], читает кассету из файла path.
+//
+// Возвращает цепочку ошибок из [ErrCassette] и других.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayerFromCassette(c)
+}
+
+// NewReplayerFromCassette - конструктор [Replayer] из кассеты c.
+//
+// Возвращает цепочку ошибок из [ErrCassette] и других.
+func NewReplayerFromCassette(c *Cassette) (*Replayer, error) {
+	r := &Replayer{
+		redactor:     newDefaultRedactor(),
+		ignore:       newRedactor(nil, DefaultIgnoreFields),
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}
+	if err := r.index(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WithRedactFields - добавляет поля, значения которых были заменены при записи, см [Recorder.WithRedactFields].
+func (r *Replayer) WithRedactFields(fields ...string) *Replayer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactor.add(nil, fields)
+	return r
+}
+
+// WithIgnoreFields - добавляет поля JSON, параметры форм и query-параметры,
+// значения которых не учитываются при сопоставлении запросов.
+func (r *Replayer) WithIgnoreFields(fields ...string) *Replayer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ignore.add(nil, fields)
+	_ = r.index()
+	return r
+}
+
+// index - рассчитывает ключи сопоставления записанных запросов.
+func (r *Replayer) index() error {
+	r.keys = make([]string, len(r.interactions))
+	for i, in := range r.interactions {
+		key, err := matchKey(in.Request.Method, in.Request.URL, in.Request.Header.Get("Content-Type"), in.Request.Body, r.ignore)
+		if err != nil {
+			return fmt.Errorf("%w: запрос %d: %w", ErrCassette, i, err)
+		}
+		r.keys[i] = key
+	}
+	return nil
+}
+
+// RoundTrip - реализация [http.RoundTripper].
+//
+// Если подходящий запрос не найден, возвращает ошибку [ErrNoInteraction].
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	contentType := req.Header.Get("Content-Type")
+	key, err := matchKey(req.Method, r.redactor.url(req.URL), contentType, r.redactor.body(contentType, body), r.ignore)
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range r.keys {
+		if r.used[i] || k != key {
+			continue
+		}
+		r.used[i] = true
+		rec := r.interactions[i].Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+			StatusCode:    rec.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        rec.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(rec.Body)),
+			ContentLength: int64(len(rec.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.Path)
+}
+
+// Remaining - возвращает количество записанных запросов, которые еще не были воспроизведены.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
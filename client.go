@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ofstudio/go-api-epgu/utils"
 )
@@ -30,6 +34,7 @@ type Client struct {
 	chunkSize  int
 	debug      bool
 	logger     utils.Logger
+	har        *utils.HAR
 }
 
 // NewClient - конструктор [Client].
@@ -58,6 +63,14 @@ func (c *Client) WithDebug(logger utils.Logger) *Client {
 	return c
 }
 
+// WithHAR - включает запись HTTP-запросов и ответов к ЕПГУ в журнал har в формате HAR 1.2.
+// Комментарий к записи журнала содержит наименование метода клиента и номер заявления,
+// например "OrderInfo orderId=1230254874", см [utils.HAR].
+func (c *Client) WithHAR(har *utils.HAR) *Client {
+	c.har = har
+	return c
+}
+
 // WithHTTPClient - устанавливает http-клиент для запросов к ЕПГУ.
 func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
 	if httpClient != nil {
@@ -95,6 +108,7 @@ func (c *Client) WithChunkSize(n int) *Client {
 func (c *Client) OrderCreate(token string, meta OrderMeta) (int, error) {
 	orderIdResponse := &dtoOrderIdResponse{}
 	if err := c.requestJSON(
+		operation{name: "OrderCreate"},
 		http.MethodPost,
 		"/api/gusmev/order",
 		"application/json; charset=utf-8",
@@ -168,6 +182,7 @@ func (c *Client) OrderPushChunked(token string, orderId int, archive *Archive) e
 		// make request
 		orderIdResponse := &dtoOrderIdResponse{}
 		if err := c.requestJSON(
+			operation{name: "OrderPushChunked", orderId: orderId},
 			http.MethodPost,
 			"/api/gusmev/push/chunked",
 			"multipart/form-data; boundary="+w.Boundary(),
@@ -221,6 +236,7 @@ func (c *Client) OrderPush(token string, meta OrderMeta, archive *Archive) (int,
 
 	orderIdResponse := &dtoOrderIdResponse{}
 	if err := c.requestJSON(
+		operation{name: "OrderPush"},
 		http.MethodPost,
 		"/api/gusmev/push",
 		"multipart/form-data; boundary="+w.Boundary(),
@@ -237,6 +253,44 @@ func (c *Client) OrderPush(token string, meta OrderMeta, archive *Archive) (int,
 	return orderIdResponse.OrderId, nil
 }
 
+// OrderPushService - создание заявления по услуге svc и загрузка архива заявления.
+//
+// Если номер заявления должен быть указан в файлах архива ([Service.EmbedsOrderId]),
+// заявление создается методом [Client.OrderCreate], затем архив загружается методом [Client.OrderPushChunked].
+// Иначе архив формируется заранее и отправляется методом [Client.OrderPush].
+//
+// В случае успеха возвращает номер созданного заявления.
+// Если заявление создано, но архив не загружен, возвращает номер заявления вместе с ошибкой.
+// В случае ошибки возвращает цепочку из [ErrPushService] и следующих возможных ошибок:
+//   - [ErrService] - ошибка формирования архива услугой
+//   - ошибок [Client.OrderCreate], [Client.OrderPushChunked] или [Client.OrderPush]
+func (c *Client) OrderPushService(token string, svc Service) (int, error) {
+	if !svc.EmbedsOrderId() {
+		archive, err := svc.Archive(0)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrPushService, err)
+		}
+		orderId, err := c.OrderPush(token, svc.Meta(), archive)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrPushService, err)
+		}
+		return orderId, nil
+	}
+
+	orderId, err := c.OrderCreate(token, svc.Meta())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrPushService, err)
+	}
+	archive, err := svc.Archive(orderId)
+	if err == nil {
+		err = c.OrderPushChunked(token, orderId, archive)
+	}
+	if err != nil {
+		return orderId, fmt.Errorf("%w: %w", ErrPushService, err)
+	}
+	return orderId, nil
+}
+
 // OrderInfo - запрос детальной информации по отправленному заявлению.
 //
 //	POST /api/gusmev/order/{orderId}
@@ -254,6 +308,7 @@ func (c *Client) OrderInfo(token string, orderId int) (*OrderInfo, error) {
 
 	orderInfoResponse := &dtoOrderInfoResponse{}
 	if err := c.requestJSON(
+		operation{name: "OrderInfo", orderId: orderId},
 		http.MethodPost,
 		fmt.Sprintf("/api/gusmev/order/%d", orderId),
 		"",
@@ -281,6 +336,95 @@ func (c *Client) OrderInfo(token string, orderId int) (*OrderInfo, error) {
 	return orderInfo, nil
 }
 
+// OrdersStatus - получение текущих статусов заявлений по списку номеров.
+//
+//	GET /api/gusmev/order/getOrdersStatus?pageNum={pageNum}&pageSize={pageSize}&orderIds={orderIds}
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.3. Получение статусов заявлений".
+//
+// Параметры pageNum (начиная с 0) и pageSize - необязательные: если pageSize равен 0,
+// параметры страницы не передаются.
+//
+// В случае успеха возвращает страницу статусов заявлений в порядке orderIds.
+// В случае ошибки возвращает цепочку из [ErrOrdersStatus] и следующих возможных ошибок:
+//   - [ErrWrongOrderID] - не передан ни один номер заявления
+//   - [ErrRequest] - ошибка HTTP-запроса
+//   - [ErrJSONUnmarshal] - ошибка разбора ответа
+//   - HTTP-ошибок ErrStatusXXXX (например, [ErrStatusUnauthorized])
+//   - Ошибок ЕПГУ: ErrCodeXXXX (например, [ErrCodeBadRequest])
+func (c *Client) OrdersStatus(token string, orderIds []int, pageNum, pageSize int) (*OrdersStatus, error) {
+	if len(orderIds) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrOrdersStatus, ErrWrongOrderID)
+	}
+	ids := make([]string, len(orderIds))
+	for i, id := range orderIds {
+		ids[i] = strconv.Itoa(id)
+	}
+	query := pageQuery(pageNum, pageSize)
+	query.Set("orderIds", strings.Join(ids, ","))
+
+	ordersStatus := &OrdersStatus{}
+	if err := c.requestJSON(
+		operation{name: "OrdersStatus"},
+		http.MethodGet,
+		"/api/gusmev/order/getOrdersStatus?"+query.Encode(),
+		"",
+		token,
+		nil,
+		ordersStatus,
+	); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOrdersStatus, err)
+	}
+	return ordersStatus, nil
+}
+
+// OrdersUpdatedAfter - получение текущих статусов заявлений, обновленных после даты и времени after.
+//
+//	GET /api/gusmev/order/getUpdatedAfter?pageNum={pageNum}&pageSize={pageSize}&updatedAfter={updatedAfter}
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.3. Получение статусов заявлений".
+//
+// Дата и время after передаются в часовом поясе [Moscow].
+// Параметры pageNum (начиная с 0) и pageSize - необязательные: если pageSize равен 0,
+// параметры страницы не передаются.
+//
+// В случае успеха возвращает страницу статусов заявлений.
+// В случае ошибки возвращает цепочку из [ErrOrdersUpdatedAfter] и следующих возможных ошибок:
+//   - [ErrRequest] - ошибка HTTP-запроса
+//   - [ErrJSONUnmarshal] - ошибка разбора ответа
+//   - HTTP-ошибок ErrStatusXXXX (например, [ErrStatusUnauthorized])
+//   - Ошибок ЕПГУ: ErrCodeXXXX (например, [ErrCodeBadRequest])
+func (c *Client) OrdersUpdatedAfter(token string, after time.Time, pageNum, pageSize int) (*OrdersStatus, error) {
+	query := pageQuery(pageNum, pageSize)
+	query.Set("updatedAfter", after.In(Moscow).Format(apipguLocalLayout))
+
+	ordersStatus := &OrdersStatus{}
+	if err := c.requestJSON(
+		operation{name: "OrdersUpdatedAfter"},
+		http.MethodGet,
+		"/api/gusmev/order/getUpdatedAfter?"+query.Encode(),
+		"",
+		token,
+		nil,
+		ordersStatus,
+	); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOrdersUpdatedAfter, err)
+	}
+	return ordersStatus, nil
+}
+
+// pageQuery - параметры страницы pageNum и pageSize; пустые, если pageSize равен 0.
+func pageQuery(pageNum, pageSize int) url.Values {
+	query := url.Values{}
+	if pageSize > 0 {
+		query.Set("pageNum", strconv.Itoa(pageNum))
+		query.Set("pageSize", strconv.Itoa(pageSize))
+	}
+	return query
+}
+
 // OrderCancel - отмена заявления.
 //
 //	POST /api/gusmev/order/{orderId}/cancel
@@ -306,6 +450,7 @@ func (c *Client) OrderInfo(token string, orderId int) (*OrderInfo, error) {
 // возможность отмены. Вероятно, спецификация метода будет изменена в будущем.
 func (c *Client) OrderCancel(token string, orderId int) error {
 	if _, err := c.requestBody(
+		operation{name: "OrderCancel", orderId: orderId},
 		http.MethodPost,
 		fmt.Sprintf("/api/gusmev/order/%d/cancel", orderId),
 		"application/json; charset=utf-8",
@@ -338,6 +483,7 @@ func (c *Client) AttachmentDownload(token string, link string) ([]byte, error) {
 	}
 
 	resBody, err := c.requestBody(
+		operation{name: "AttachmentDownload", orderId: attachmentOrderId(link)},
 		http.MethodGet,
 		"/api/storage/v2/files"+uri,
 		"",
@@ -373,6 +519,16 @@ func attachmentURI(link string) (string, error) {
 	return fmt.Sprintf("/%s/%s/download?mnemonic=%s", matches[1], matches[3], matches[2]), nil
 }
 
+// attachmentOrderId - возвращает номер заявления из ссылки на файл ({objectId}) или 0.
+func attachmentOrderId(link string) int {
+	matches := reAttachmentURI.FindStringSubmatch(link)
+	if len(matches) != 4 {
+		return 0
+	}
+	orderId, _ := strconv.Atoi(matches[1])
+	return orderId
+}
+
 // Dict - получение справочных данных.
 //
 //	POST /api/nsi/v1/dictionary/{code}
@@ -408,6 +564,7 @@ func (c *Client) Dict(code string, filter, parent string, pageNum, pageSize int)
 
 	dictResponse := &dtoDictResponse{}
 	if err := c.requestJSON(
+		operation{name: "Dict"},
 		http.MethodPost,
 		fmt.Sprintf("/api/nsi/v1/dictionary/%s", code),
 		"application/json; charset=utf-8",
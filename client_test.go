@@ -9,8 +9,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/utils"
 )
 
 func TestClient(t *testing.T) {
@@ -635,6 +638,98 @@ func (suite *suiteTestClient) TestOrderInfo() {
 	})
 }
 
+func (suite *suiteTestClient) TestOrdersStatus() {
+
+	suite.Run("200 success", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			suite.Equal(http.MethodGet, r.Method)
+			suite.Equal("/api/gusmev/order/getOrdersStatus", r.URL.Path)
+			suite.Equal("123456,654321", r.URL.Query().Get("orderIds"))
+			suite.Equal("0", r.URL.Query().Get("pageNum"))
+			suite.Equal("10", r.URL.Query().Get("pageSize"))
+			suite.Equal("Bearer test-token", r.Header.Get("Authorization"))
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(ordersStatusSuccessResponse))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		status, err := client.OrdersStatus(testToken, []int{123456, 654321}, 0, 10)
+		suite.NoError(err)
+		suite.Require().NotNil(status)
+		suite.Equal(2, status.TotalCount)
+		suite.Require().Len(status.Content, 2)
+		suite.Equal(OrderSearchFound, status.Content[0].OrderSearchStatus)
+		suite.Require().NotNil(status.Content[0].Status)
+		suite.Equal(2, status.Content[0].Status.StatusId)
+		suite.Equal(time.Date(2023, 12, 13, 11, 23, 11, 429000000, time.UTC), status.Content[0].Status.Updated.UTC())
+		suite.Equal(OrderSearchNotFound, status.Content[1].OrderSearchStatus)
+		suite.Nil(status.Content[1].Status)
+	})
+
+	suite.Run("no order ids", func() {
+		client := NewClient("")
+		status, err := client.OrdersStatus(testToken, nil, 0, 0)
+		suite.ErrorIs(err, ErrOrdersStatus)
+		suite.ErrorIs(err, ErrWrongOrderID)
+		suite.Nil(status)
+	})
+
+	suite.Run("401 unauthorized", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		status, err := client.OrdersStatus(testToken, []int{123456}, 0, 0)
+		suite.ErrorIs(err, ErrOrdersStatus)
+		suite.ErrorIs(err, ErrStatusUnauthorized)
+		suite.Nil(status)
+	})
+}
+
+func (suite *suiteTestClient) TestOrdersUpdatedAfter() {
+
+	suite.Run("200 success", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			suite.Equal(http.MethodGet, r.Method)
+			suite.Equal("/api/gusmev/order/getUpdatedAfter", r.URL.Path)
+			suite.Equal("2023-12-13T14:00:00.000", r.URL.Query().Get("updatedAfter"))
+			suite.False(r.URL.Query().Has("pageNum"))
+			suite.False(r.URL.Query().Has("pageSize"))
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(ordersStatusSuccessResponse))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		status, err := client.OrdersUpdatedAfter(testToken, time.Date(2023, 12, 13, 11, 0, 0, 0, time.UTC), 0, 0)
+		suite.NoError(err)
+		suite.Require().NotNil(status)
+		suite.Equal(2, status.Count)
+	})
+
+	suite.Run("400 bad request", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"bad_request","message":"некорректный параметр updatedAfter"}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		status, err := client.OrdersUpdatedAfter(testToken, time.Now(), 0, 0)
+		suite.ErrorIs(err, ErrOrdersUpdatedAfter)
+		suite.ErrorIs(err, ErrCodeBadRequest)
+		suite.Nil(status)
+	})
+}
+
 func (suite *suiteTestClient) TestOrderCancel() {
 
 	suite.Run("200 success", func() {
@@ -1000,6 +1095,26 @@ func (suite *suiteTestClient) TestDict() {
 
 }
 
+func (suite *suiteTestClient) TestWithHAR() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(orderInfoSuccessResponse))
+	}))
+	defer server.Close()
+
+	har := utils.NewHAR()
+	client := NewClient(server.URL).WithHAR(har)
+	_, err := client.OrderInfo(testToken, 123456)
+	suite.NoError(err)
+
+	entries := har.Entries()
+	suite.Require().Len(entries, 1)
+	suite.Equal("OrderInfo orderId=123456", entries[0].Comment)
+	suite.Equal(http.StatusOK, entries[0].Response.Status)
+	suite.Contains(entries[0].Request.Headers, utils.HARNameValue{Name: "Authorization", Value: "***"})
+}
+
 var (
 	testToken = "test-token"
 	testMeta  = OrderMeta{Region: "test-region", ServiceCode: "test-service", TargetCode: "test-target"}
@@ -1011,10 +1126,11 @@ const (
 )
 
 const (
-	dictSuccessSimpleResponse  = `{"error":{"code":0,"message":"operation completed"},"fieldErrors":[],"total":5004,"items":[{"value":"0550041","title":"1.Клиентская служба (на правах отдела) в Белозерском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}},{"value":"0550091","title":"1. Клиентская служба (на правах  отдела) в Лебяжьевском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}}]}`
-	dictSuccessSimpleWant      = `[{"value":"0550041","title":"1.Клиентская служба (на правах отдела) в Белозерском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}},{"value":"0550091","title":"1. Клиентская служба (на правах  отдела) в Лебяжьевском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}}]`
-	dictSuccessComplexResponse = `{"error":{"code":0,"message":"operation completed"},"fieldErrors":[],"total":1000,"items":[ {"value": "049514608", "title": "049514608 - АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан", "isLeaf": true, "children": [], "attributes": [ { "name": "ID", "type": "STRING", "value": { "asString": "049514608", "typeOfValue": "STRING", "value": "049514608" }, "valueAsOfType": "049514608" }, { "name": "NAME", "type": "STRING", "value": { "asString": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан", "typeOfValue": "STRING", "value": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан" }, "valueAsOfType": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан" }, { "name": "BIC", "type": "STRING", "value": { "asString": "049514608", "typeOfValue": "STRING", "value": "049514608" }, "valueAsOfType": "049514608" }, { "name": "CORR_ACCOUNT", "type": "STRING", "value": { "asString": "30101810500000000608", "typeOfValue": "STRING", "value": "30101810500000000608" }, "valueAsOfType": "30101810500000000608" } ], "attributeValues": { "ID": "049514608", "CORR_ACCOUNT": "30101810500000000608", "BIC": "049514608", "NAME": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан" } }, { "value": "041012765", "title": "041012765 - \"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск", "isLeaf": true, "children": [], "attributes": [ { "name": "ID", "type": "STRING", "value": { "asString": "041012765", "typeOfValue": "STRING", "value": "041012765" }, "valueAsOfType": "041012765" }, { "name": "NAME", "type": "STRING", "value": { "asString": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск", "typeOfValue": "STRING", "value": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск" }, "valueAsOfType": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск" }, { "name": "BIC", "type": "STRING", "value": { "asString": "041012765", "typeOfValue": "STRING", "value": "041012765" }, "valueAsOfType": "041012765" }, { "name": "CORR_ACCOUNT", "type": "STRING", "value": { "asString": "30101810300000000765", "typeOfValue": "STRING", "value": "30101810300000000765" }, "valueAsOfType": "30101810300000000765" } ], "attributeValues": { "ID": "041012765", "CORR_ACCOUNT": "30101810300000000765", "BIC": "041012765", "NAME": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск" } }]}`
-	dictSuccessComplexWant     = `[{"value":"049514608","title":"049514608 - АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан","isLeaf":true,"children":[],"attributes":[{"name":"ID","type":"STRING","value":{"asString":"049514608","typeOfValue":"STRING","value":"049514608"},"valueAsOfType":"049514608"},{"name":"NAME","type":"STRING","value":{"asString":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан","typeOfValue":"STRING","value":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан"},"valueAsOfType":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан"},{"name":"BIC","type":"STRING","value":{"asString":"049514608","typeOfValue":"STRING","value":"049514608"},"valueAsOfType":"049514608"},{"name":"CORR_ACCOUNT","type":"STRING","value":{"asString":"30101810500000000608","typeOfValue":"STRING","value":"30101810500000000608"},"valueAsOfType":"30101810500000000608"}],"attributeValues":{"ID":"049514608","CORR_ACCOUNT":"30101810500000000608","BIC":"049514608","NAME":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан"}},{"value":"041012765","title":"041012765 - \"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск","isLeaf":true,"children":[],"attributes":[{"name":"ID","type":"STRING","value":{"asString":"041012765","typeOfValue":"STRING","value":"041012765"},"valueAsOfType":"041012765"},{"name":"NAME","type":"STRING","value":{"asString":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск","typeOfValue":"STRING","value":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск"},"valueAsOfType":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск"},{"name":"BIC","type":"STRING","value":{"asString":"041012765","typeOfValue":"STRING","value":"041012765"},"valueAsOfType":"041012765"},{"name":"CORR_ACCOUNT","type":"STRING","value":{"asString":"30101810300000000765","typeOfValue":"STRING","value":"30101810300000000765"},"valueAsOfType":"30101810300000000765"}],"attributeValues":{"ID":"041012765","CORR_ACCOUNT":"30101810300000000765","BIC":"041012765","NAME":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск"}}]`
-	dictSuccessEmptyResponse   = `{"error":{"code":0,"message":"operation completed"},"fieldErrors":[],"total":5004,"items":[]}`
-	dictErrorResponse          = `{"error":{"code":7,"message":"Entity not found"},"fieldErrors":[],"total":0,"items":[]}`
+	dictSuccessSimpleResponse   = `{"error":{"code":0,"message":"operation completed"},"fieldErrors":[],"total":5004,"items":[{"value":"0550041","title":"1.Клиентская служба (на правах отдела) в Белозерском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}},{"value":"0550091","title":"1. Клиентская служба (на правах  отдела) в Лебяжьевском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}}]}`
+	dictSuccessSimpleWant       = `[{"value":"0550041","title":"1.Клиентская служба (на правах отдела) в Белозерском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}},{"value":"0550091","title":"1. Клиентская служба (на правах  отдела) в Лебяжьевском районе","isLeaf":true,"children":[],"attributes":[],"attributeValues":{}}]`
+	ordersStatusSuccessResponse = `{"count":2,"totalCount":2,"content":[{"orderId":123456,"orderSearchStatus":"FOUND","status":{"statusId":2,"statusName":"Заявление получено ведомством","updated":"2023-12-13T14:23:11.429"}},{"orderId":654321,"orderSearchStatus":"NOT_FOUND","status":null}]}`
+	dictSuccessComplexResponse  = `{"error":{"code":0,"message":"operation completed"},"fieldErrors":[],"total":1000,"items":[ {"value": "049514608", "title": "049514608 - АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан", "isLeaf": true, "children": [], "attributes": [ { "name": "ID", "type": "STRING", "value": { "asString": "049514608", "typeOfValue": "STRING", "value": "049514608" }, "valueAsOfType": "049514608" }, { "name": "NAME", "type": "STRING", "value": { "asString": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан", "typeOfValue": "STRING", "value": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан" }, "valueAsOfType": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан" }, { "name": "BIC", "type": "STRING", "value": { "asString": "049514608", "typeOfValue": "STRING", "value": "049514608" }, "valueAsOfType": "049514608" }, { "name": "CORR_ACCOUNT", "type": "STRING", "value": { "asString": "30101810500000000608", "typeOfValue": "STRING", "value": "30101810500000000608" }, "valueAsOfType": "30101810500000000608" } ], "attributeValues": { "ID": "049514608", "CORR_ACCOUNT": "30101810500000000608", "BIC": "049514608", "NAME": "АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан" } }, { "value": "041012765", "title": "041012765 - \"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск", "isLeaf": true, "children": [], "attributes": [ { "name": "ID", "type": "STRING", "value": { "asString": "041012765", "typeOfValue": "STRING", "value": "041012765" }, "valueAsOfType": "041012765" }, { "name": "NAME", "type": "STRING", "value": { "asString": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск", "typeOfValue": "STRING", "value": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск" }, "valueAsOfType": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск" }, { "name": "BIC", "type": "STRING", "value": { "asString": "041012765", "typeOfValue": "STRING", "value": "041012765" }, "valueAsOfType": "041012765" }, { "name": "CORR_ACCOUNT", "type": "STRING", "value": { "asString": "30101810300000000765", "typeOfValue": "STRING", "value": "30101810300000000765" }, "valueAsOfType": "30101810300000000765" } ], "attributeValues": { "ID": "041012765", "CORR_ACCOUNT": "30101810300000000765", "BIC": "041012765", "NAME": "\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск" } }]}`
+	dictSuccessComplexWant      = `[{"value":"049514608","title":"049514608 - АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан","isLeaf":true,"children":[],"attributes":[{"name":"ID","type":"STRING","value":{"asString":"049514608","typeOfValue":"STRING","value":"049514608"},"valueAsOfType":"049514608"},{"name":"NAME","type":"STRING","value":{"asString":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан","typeOfValue":"STRING","value":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан"},"valueAsOfType":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан"},{"name":"BIC","type":"STRING","value":{"asString":"049514608","typeOfValue":"STRING","value":"049514608"},"valueAsOfType":"049514608"},{"name":"CORR_ACCOUNT","type":"STRING","value":{"asString":"30101810500000000608","typeOfValue":"STRING","value":"30101810500000000608"},"valueAsOfType":"30101810500000000608"}],"attributeValues":{"ID":"049514608","CORR_ACCOUNT":"30101810500000000608","BIC":"049514608","NAME":"АБАКАНСКОЕ ОТДЕЛЕНИЕ N8602 ПАО СБЕРБАНК г Абакан"}},{"value":"041012765","title":"041012765 - \"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск","isLeaf":true,"children":[],"attributes":[{"name":"ID","type":"STRING","value":{"asString":"041012765","typeOfValue":"STRING","value":"041012765"},"valueAsOfType":"041012765"},{"name":"NAME","type":"STRING","value":{"asString":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск","typeOfValue":"STRING","value":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск"},"valueAsOfType":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск"},{"name":"BIC","type":"STRING","value":{"asString":"041012765","typeOfValue":"STRING","value":"041012765"},"valueAsOfType":"041012765"},{"name":"CORR_ACCOUNT","type":"STRING","value":{"asString":"30101810300000000765","typeOfValue":"STRING","value":"30101810300000000765"},"valueAsOfType":"30101810300000000765"}],"attributeValues":{"ID":"041012765","CORR_ACCOUNT":"30101810300000000765","BIC":"041012765","NAME":"\"Азиатско-Тихоокеанский Банк\" (АО) г Благовещенск"}}]`
+	dictSuccessEmptyResponse    = `{"error":{"code":0,"message":"operation completed"},"fieldErrors":[],"total":5004,"items":[]}`
+	dictErrorResponse           = `{"error":{"code":7,"message":"Entity not found"},"fieldErrors":[],"total":0,"items":[]}`
 )
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// dtoDictPage - страница справочника.
+type dtoDictPage struct {
+	Total int               `json:"total"`
+	Items []apipgu.DictItem `json:"items"`
+}
+
+// dict - GET /dictionaries/{code}
+func (g *gateway) dict(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	query := r.URL.Query()
+	filter := query.Get("filter")
+	if filter == "" {
+		filter = apipgu.DictFilterOneLevel
+	}
+	if filter != apipgu.DictFilterOneLevel && filter != apipgu.DictFilterSubTree {
+		writeError(w, fmt.Errorf("%w: некорректный параметр filter", errBadRequest))
+		return
+	}
+	pageNum, err := queryInt(r, "page")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	pageSize, err := queryInt(r, "size")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	items, total, err := g.client.Dict(vars["code"], filter, query.Get("parent"), pageNum, pageSize)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dtoDictPage{Total: total, Items: items})
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/environment"
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+)
+
+// Ошибки шлюза.
+var (
+	errBadRequest   = errors.New("неверный запрос")
+	errUnauthorized = errors.New("не передан маркер доступа")
+	errNotFound     = errors.New("метод не найден")
+)
+
+// gateway - HTTP-шлюз к API ЕПГУ.
+type gateway struct {
+	client  *apipgu.Client
+	store   aas.TokenStore
+	tracker *aas.ConsentTracker // nil - обновление маркеров доступа недоступно
+	mu      sync.Mutex          // Обновление маркеров доступа по одному
+}
+
+func newGateway(client *apipgu.Client, store aas.TokenStore) *gateway {
+	return &gateway{client: client, store: store}
+}
+
+// withTracker - включает обновление маркеров доступа из хранилища.
+func (g *gateway) withTracker(tracker *aas.ConsentTracker) *gateway {
+	g.tracker = tracker
+	return g
+}
+
+// route - метод API шлюза. Описание метода используется в OpenAPI, см [gateway.openAPI].
+type route struct {
+	method   string
+	path     string  // Путь с параметрами в фигурных скобках: /orders/{orderId}
+	summary  string  // Краткое описание
+	query    []param // Параметры запроса
+	body     any     // Тело запроса (JSON); nil - без тела
+	response any     // Ответ (JSON); nil - без содержимого
+	binary   string  // Тип содержимого двоичного ответа вместо response
+	auth     bool    // Требуется маркер доступа пользователя
+	each     bool    // Метод описывается в OpenAPI отдельно для каждой услуги реестра: тело запроса - apipgu.ServiceEntry.Data
+	handler  func(g *gateway, w http.ResponseWriter, r *http.Request, vars map[string]string)
+}
+
+// param - параметр запроса.
+type param struct {
+	name        string
+	typ         string // Тип OpenAPI: string, integer
+	description string
+	required    bool
+}
+
+// routes - методы API шлюза.
+var routes = []route{
+	{
+		method: http.MethodGet, path: "/services", summary: "Услуги, заявления по которым принимает шлюз",
+		response: []dtoService{}, handler: (*gateway).services,
+	},
+	{
+		method: http.MethodPost, path: "/services/{serviceCode}/orders", auth: true, each: true,
+		summary:  "Создание заявления и отправка архива",
+		query:    []param{{"targetCode", "string", "идентификатор цели, если у услуги их несколько", false}},
+		response: dtoOrderCreated{}, handler: (*gateway).orderCreate,
+	},
+	{
+		method: http.MethodGet, path: "/orders", auth: true,
+		summary: "Текущие статусы заявлений по номерам или обновленных после даты и времени",
+		query: []param{
+			{"id", "string", "номера заявлений через запятую", false},
+			{"updatedAfter", "string", "дата и время в формате RFC 3339, вместо id", false},
+			{"page", "integer", "номер страницы", false},
+			{"size", "integer", "количество заявлений на странице", false},
+		},
+		response: apipgu.OrdersStatus{}, handler: (*gateway).ordersStatus,
+	},
+	{
+		method: http.MethodGet, path: "/orders/{orderId}", auth: true, summary: "Детали заявления",
+		response: apipgu.OrderInfo{}, handler: (*gateway).orderInfo,
+	},
+	{
+		method: http.MethodPost, path: "/orders/{orderId}/cancel", auth: true, summary: "Отмена заявления",
+		handler: (*gateway).orderCancel,
+	},
+	{
+		method: http.MethodGet, path: "/files", auth: true, summary: "Скачивание файла заявления по ссылке из деталей заявления",
+		query:  []param{{"link", "string", "ссылка на файл", true}},
+		binary: "application/octet-stream", handler: (*gateway).fileDownload,
+	},
+	{
+		method: http.MethodGet, path: "/dictionaries/{code}", summary: "Справочник ЕПГУ",
+		query: []param{
+			{"filter", "string", "ONELEVEL или SUBTREE", false},
+			{"parent", "string", "код родительского элемента", false},
+			{"page", "integer", "номер страницы", false},
+			{"size", "integer", "количество элементов на странице", false},
+		},
+		response: dtoDictPage{}, handler: (*gateway).dict,
+	},
+	{
+		method: http.MethodPut, path: "/tokens/{oid}", summary: "Сохранение маркера доступа пользователя в хранилище шлюза",
+		body: aas.TokenRecord{}, response: dtoToken{}, handler: (*gateway).tokenSave,
+	},
+	{
+		method: http.MethodGet, path: "/tokens/{oid}", summary: "Сведения о маркере доступа пользователя (без маркера)",
+		response: dtoToken{}, handler: (*gateway).tokenInfo,
+	},
+	{
+		method: http.MethodDelete, path: "/tokens/{oid}", summary: "Удаление маркера доступа пользователя из хранилища",
+		handler: (*gateway).tokenDelete,
+	},
+}
+
+// ServeHTTP - реализация [http.Handler].
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, rt := range routes {
+		if vars, ok := rt.match(r.URL.Path); ok && rt.method == r.Method {
+			rt.handler(g, w, r, vars)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, dtoError{Error: errNotFound.Error()})
+}
+
+// match - сопоставляет путь запроса с путем метода и возвращает значения параметров пути.
+func (rt route) match(path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(rt.path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	vars := map[string]string{}
+	for i := range want {
+		if name, ok := strings.CutPrefix(want[i], "{"); ok {
+			vars[strings.TrimSuffix(name, "}")] = got[i]
+		} else if want[i] != got[i] {
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+// dtoError - ответ с ошибкой.
+type dtoError struct {
+	Error        string `json:"error"`
+	OrderId      int    `json:"orderId,omitempty"`      // Заявление создано, но архив не отправлен
+	ReconsentURI string `json:"reconsentUri,omitempty"` // Ссылка для повторного согласия пользователя
+}
+
+// writeError - ответ с ошибкой: HTTP-код определяется по цепочке ошибок.
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, httpStatus(err), dtoError{Error: err.Error()})
+}
+
+// httpStatus - HTTP-код ответа шлюза для ошибки err.
+// Ошибки ЕПГУ, не связанные с запросом пользователя шлюза, возвращаются как 502.
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest),
+		errors.Is(err, apipgu.ErrWrongOrderID),
+		errors.Is(err, apipgu.ErrInvalidFileLink),
+		errors.Is(err, apipgu.ErrStatusBadRequest),
+		errors.Is(err, aas.ErrInvalidOID),
+		errors.Is(err, aas.ErrTokenParse),
+		errors.Is(err, environment.ErrContourMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, errUnauthorized),
+		errors.Is(err, aas.ErrTokenNotFound),
+		errors.Is(err, apipgu.ErrStatusUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, aas.ErrConsentExpired),
+		errors.Is(err, aas.ErrConsentRevoked),
+		errors.Is(err, apipgu.ErrStatusForbidden),
+		errors.Is(err, apipgu.ErrCodeAccessDeniedPersonPermissions):
+		return http.StatusForbidden
+	case errors.Is(err, apipgu.ErrStatusOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apipgu.ErrStatusUnableToHandleRequest):
+		return http.StatusConflict
+	case errors.Is(err, apipgu.ErrStatusTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(err, apipgu.ErrStatusGatewayTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// writeJSON - ответ с JSON-содержимым.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(body)
+}
+
+// readJSON - читает JSON-тело запроса размером не более maxBodySize.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", errBadRequest, err)
+	}
+	return nil
+}
+
+// maxBodySize - максимальный размер тела запроса.
+const maxBodySize = 32 << 20
+
+// queryInt - целочисленный параметр запроса; пустой параметр - 0.
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: некорректный параметр %s", errBadRequest, name)
+	}
+	return n, nil
+}
+
+// apiKeyAuth - проверяет ключ API шлюза в заголовке X-API-Key; пустой key - без проверки.
+func apiKeyAuth(next http.Handler, key string) http.Handler {
+	if key == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(key)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, dtoError{Error: "отказ в доступе"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkListenAddr - проверяет, что шлюз без ключа API слушает только локальный адрес:
+// иначе любой клиент сети может действовать от имени пользователей хранилища (X-ESIA-OID)
+// и заменять их маркеры доступа (PUT /tokens/{oid}).
+func checkListenAddr(addr, key string) error {
+	if key != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес шлюза '%s': %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return nil
+	}
+	return fmt.Errorf("для адреса %s, отличного от локального, задайте ключ API EPGU_GATEWAY_API_KEY", addr)
+}
+
+// statusWriter - сохраняет HTTP-код ответа для журнала.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests - журналирует запросы.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, sw.status, time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/environment"
+	"github.com/ofstudio/go-api-epgu/epgutest"
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/esiatest"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+const (
+	testToken       = "test-token"
+	testAPIKey      = "test-api-key"
+	testClientId    = "TEST_IS"
+	testRedirectURI = "http://localhost/callback"
+	testServiceCode = "10000000109"
+	testOrderData   = `{"okato":"92000000000","oktmo":"92000000000","zdp":{"Applicant":{"SNILS":"715-398-174 20"}}}`
+)
+
+var testMeta = apipgu.OrderMeta{Region: "92000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"}
+
+func TestGateway(t *testing.T) {
+	suite.Run(t, new(suiteGateway))
+}
+
+type suiteGateway struct {
+	suite.Suite
+	epgu       *epgutest.Server
+	esia       *esiatest.Server
+	esiaServer *httptest.Server
+	esiaTokens atomic.Int32 // Количество запросов к /token ЕСИА
+	esiaClient *aas.Client
+	store      aas.TokenStore
+	server     *httptest.Server
+	scopes     aas.Scopes
+	epguClient *apipgu.Client
+	gw         *gateway
+}
+
+func (suite *suiteGateway) SetupTest() {
+	suite.epgu = epgutest.NewServer()
+	suite.epguClient = apipgu.NewClient(suite.epgu.URL)
+
+	suite.esia = esiatest.New().WithClient(testClientId, testRedirectURI)
+	suite.esiaTokens.Store(0)
+	suite.esiaServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == aas.TokenEndpoint {
+			suite.esiaTokens.Add(1)
+		}
+		suite.esia.ServeHTTP(w, r)
+	}))
+	suite.esia.URL = suite.esiaServer.URL
+	suite.esiaClient = aas.NewClient(suite.esia.URL, testClientId, signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash))
+	suite.scopes = aas.NewScopes(aas.ScopeOpenID, aas.ScopeAPIOrder)
+
+	suite.store = aas.NewMemoryTokenStore()
+	suite.gw = newGateway(suite.epguClient, suite.store).
+		withTracker(aas.NewConsentTracker(suite.esiaClient, suite.store))
+	suite.server = httptest.NewServer(apiKeyAuth(suite.gw, testAPIKey))
+}
+
+func (suite *suiteGateway) TearDownTest() {
+	suite.server.Close()
+	suite.esiaServer.Close()
+	suite.epgu.Close()
+}
+
+func (suite *suiteGateway) SetupSubTest() {
+	suite.TearDownTest()
+	suite.SetupTest()
+}
+
+// do - запрос к шлюзу с ключом API и заголовками headers (попарно: имя, значение).
+// Возвращает HTTP-код и тело ответа.
+func (suite *suiteGateway) do(method, path, body string, headers ...string) (int, []byte) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, suite.server.URL+path, reader)
+	suite.Require().NoError(err)
+	req.Header.Set("X-API-Key", testAPIKey)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+	//goland:noinspection ALL
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	suite.Require().NoError(err)
+	return res.StatusCode, data
+}
+
+// login - вход пользователя в ЕСИА и сохранение маркера доступа в хранилище шлюза.
+func (suite *suiteGateway) login() *aas.TokenRecord {
+	authURI, err := suite.esiaClient.AuthURI(suite.scopes, testRedirectURI, nil)
+	suite.Require().NoError(err)
+	query, err := suite.esia.Authorize(authURI)
+	suite.Require().NoError(err)
+	code, _, err := suite.esiaClient.ParseCallback(query)
+	suite.Require().NoError(err)
+	res, err := suite.esiaClient.TokenExchange(code, suite.scopes, testRedirectURI)
+	suite.Require().NoError(err)
+	record, err := aas.NewConsentTracker(suite.esiaClient, suite.store).Grant(res, suite.scopes, testRedirectURI, nil)
+	suite.Require().NoError(err)
+	return record
+}
+
+// orderCreate - создает заявление через шлюз и возвращает его номер.
+func (suite *suiteGateway) orderCreate() int {
+	status, body := suite.do(http.MethodPost, "/services/"+testServiceCode+"/orders", testOrderData, "Authorization", "Bearer "+testToken)
+	suite.Require().Equal(http.StatusCreated, status, string(body))
+	res := dtoOrderCreated{}
+	suite.Require().NoError(json.Unmarshal(body, &res))
+	return res.OrderId
+}
+
+// expire - отмечает маркер доступа пользователя в хранилище как истекший.
+func (suite *suiteGateway) expire(oid string) {
+	record, err := suite.store.Load(oid)
+	suite.Require().NoError(err)
+	record.ExpiresAt = time.Now().Add(-time.Minute)
+	suite.Require().NoError(suite.store.Save(record))
+}
+
+func (suite *suiteGateway) TestRouteMatch() {
+	tests := []struct {
+		path  string
+		route string
+		want  map[string]string
+	}{
+		{"/services", "/services", map[string]string{}},
+		{"/services/", "/services", map[string]string{}},
+		{"/services/10000000109/orders", "/services/{serviceCode}/orders", map[string]string{"serviceCode": "10000000109"}},
+		{"/orders/42/cancel", "/orders/{orderId}/cancel", map[string]string{"orderId": "42"}},
+		{"/orders/42", "/orders/{orderId}", map[string]string{"orderId": "42"}},
+		{"/orders/42", "/orders/{orderId}/cancel", nil},
+		{"/orders/42/cancel", "/orders/{orderId}", nil},
+		{"/orders", "/orders/{orderId}", nil},
+		{"/order/42", "/orders/{orderId}", nil},
+		{"/tokens/1000000000", "/tokens/{oid}", map[string]string{"oid": "1000000000"}},
+	}
+	for _, tt := range tests {
+		vars, ok := route{path: tt.route}.match(tt.path)
+		suite.Equal(tt.want != nil, ok, "%s ~ %s", tt.path, tt.route)
+		if tt.want != nil {
+			suite.Equal(tt.want, vars, "%s ~ %s", tt.path, tt.route)
+		}
+	}
+}
+
+func (suite *suiteGateway) TestHTTPStatus() {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errBadRequest, http.StatusBadRequest},
+		{fmt.Errorf("%w: %w", errBadRequest, apipgu.ErrServiceData), http.StatusBadRequest},
+		{apipgu.ErrWrongOrderID, http.StatusBadRequest},
+		{apipgu.ErrInvalidFileLink, http.StatusBadRequest},
+		{apipgu.ErrStatusBadRequest, http.StatusBadRequest},
+		{aas.ErrInvalidOID, http.StatusBadRequest},
+		{aas.ErrTokenParse, http.StatusBadRequest},
+		{environment.ErrContourMismatch, http.StatusBadRequest},
+		{errUnauthorized, http.StatusUnauthorized},
+		{aas.ErrTokenNotFound, http.StatusUnauthorized},
+		{fmt.Errorf("%w: %w", apipgu.ErrOrderInfo, apipgu.ErrStatusUnauthorized), http.StatusUnauthorized},
+		{fmt.Errorf("%w: %w", aas.ErrConsent, aas.ErrConsentExpired), http.StatusForbidden},
+		{fmt.Errorf("%w: %w", aas.ErrConsent, aas.ErrConsentRevoked), http.StatusForbidden},
+		{apipgu.ErrStatusForbidden, http.StatusForbidden},
+		{apipgu.ErrCodeAccessDeniedPersonPermissions, http.StatusForbidden},
+		{apipgu.ErrStatusOrderNotFound, http.StatusNotFound},
+		{apipgu.ErrStatusUnableToHandleRequest, http.StatusConflict},
+		{apipgu.ErrStatusTooManyRequests, http.StatusTooManyRequests},
+		{apipgu.ErrStatusGatewayTimeout, http.StatusGatewayTimeout},
+		{apipgu.ErrStatusInternalError, http.StatusBadGateway},
+		{errors.New("unknown"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		suite.Equal(tt.want, httpStatus(tt.err), tt.err.Error())
+	}
+}
+
+func (suite *suiteGateway) TestCheckListenAddr() {
+	tests := []struct {
+		addr    string
+		key     string
+		wantErr bool
+	}{
+		{"localhost:8080", "", false},
+		{"127.0.0.1:8080", "", false},
+		{"[::1]:8080", "", false},
+		{"0.0.0.0:8080", "", true},
+		{":8080", "", true},
+		{"192.168.1.10:8080", "", true},
+		{"0.0.0.0:8080", testAPIKey, false},
+		{":8080", testAPIKey, false},
+		{"localhost", "", true},
+	}
+	for _, tt := range tests {
+		err := checkListenAddr(tt.addr, tt.key)
+		suite.Equal(tt.wantErr, err != nil, "%s key=%q: %v", tt.addr, tt.key, err)
+	}
+}
+
+func (suite *suiteGateway) TestAPIKey() {
+	suite.Run("no key", func() {
+		res, err := http.Get(suite.server.URL + "/services")
+		suite.Require().NoError(err)
+		_ = res.Body.Close()
+		suite.Equal(http.StatusUnauthorized, res.StatusCode)
+	})
+
+	suite.Run("wrong key", func() {
+		req, err := http.NewRequest(http.MethodGet, suite.server.URL+"/services", nil)
+		suite.Require().NoError(err)
+		req.Header.Set("X-API-Key", "wrong")
+		res, err := http.DefaultClient.Do(req)
+		suite.Require().NoError(err)
+		_ = res.Body.Close()
+		suite.Equal(http.StatusUnauthorized, res.StatusCode)
+	})
+
+	suite.Run("valid key", func() {
+		status, _ := suite.do(http.MethodGet, "/services", "")
+		suite.Equal(http.StatusOK, status)
+	})
+}
+
+func (suite *suiteGateway) TestNotFound() {
+	suite.Run("unknown path", func() {
+		status, body := suite.do(http.MethodGet, "/unknown", "")
+		suite.Equal(http.StatusNotFound, status)
+		suite.JSONEq(`{"error":"метод не найден"}`, string(body))
+	})
+
+	suite.Run("wrong method", func() {
+		status, _ := suite.do(http.MethodDelete, "/services", "")
+		suite.Equal(http.StatusNotFound, status)
+	})
+}
+
+func (suite *suiteGateway) TestServices() {
+	status, body := suite.do(http.MethodGet, "/services", "")
+	suite.Require().Equal(http.StatusOK, status)
+	var res []dtoService
+	suite.Require().NoError(json.Unmarshal(body, &res))
+	suite.Contains(res, dtoService{
+		ServiceCode: testServiceCode,
+		TargetCode:  "-10000000109",
+		Name:        "Доставка пенсии и социальных выплат СФР",
+		Path:        "/services/10000000109/orders",
+	})
+}
+
+func (suite *suiteGateway) TestOrderCreate() {
+	path := "/services/" + testServiceCode + "/orders"
+
+	suite.Run("success", func() {
+		status, body := suite.do(http.MethodPost, path, testOrderData, "Authorization", "Bearer "+testToken)
+		suite.Require().Equal(http.StatusCreated, status, string(body))
+		res := dtoOrderCreated{}
+		suite.Require().NoError(json.Unmarshal(body, &res))
+		suite.NotZero(res.OrderId)
+		suite.NotEmpty(suite.epgu.Archive(res.OrderId))
+		suite.Equal(1, suite.epgu.Requests(epgutest.OpOrderCreate))
+	})
+
+	suite.Run("push error returns orderId", func() {
+		suite.epgu.WithFault(epgutest.OpPushChunked, epgutest.Fault{Status: http.StatusInternalServerError, Code: "internal_error", Message: "test"})
+		status, body := suite.do(http.MethodPost, path, testOrderData, "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusBadGateway, status, string(body))
+		res := dtoError{}
+		suite.Require().NoError(json.Unmarshal(body, &res))
+		suite.NotEmpty(res.Error)
+		suite.NotZero(res.OrderId)
+		suite.Empty(suite.epgu.Archive(res.OrderId))
+	})
+
+	suite.Run("unknown service", func() {
+		status, _ := suite.do(http.MethodPost, "/services/1/orders", testOrderData, "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusNotFound, status)
+		suite.Equal(0, suite.epgu.Requests(epgutest.OpOrderCreate))
+	})
+
+	suite.Run("bad data", func() {
+		status, _ := suite.do(http.MethodPost, path, `{"okato":"92000000000"}`, "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusBadRequest, status)
+		status, _ = suite.do(http.MethodPost, path, `{"unknown":1}`, "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusBadRequest, status)
+		suite.Equal(0, suite.epgu.Requests(epgutest.OpOrderCreate))
+	})
+
+	suite.Run("no token", func() {
+		status, body := suite.do(http.MethodPost, path, testOrderData)
+		suite.Equal(http.StatusUnauthorized, status, string(body))
+		suite.Equal(0, suite.epgu.Requests(epgutest.OpOrderCreate))
+	})
+}
+
+func (suite *suiteGateway) TestOrders() {
+	suite.Run("order info", func() {
+		orderId := suite.orderCreate()
+		status, body := suite.do(http.MethodGet, fmt.Sprintf("/orders/%d", orderId), "", "Authorization", "Bearer "+testToken)
+		suite.Require().Equal(http.StatusOK, status, string(body))
+		info := apipgu.OrderInfo{}
+		suite.Require().NoError(json.Unmarshal(body, &info))
+		suite.Require().NotNil(info.Order)
+		suite.Equal(orderId, info.Order.Id)
+	})
+
+	suite.Run("bad orderId", func() {
+		for _, id := range []string{"abc", "0", "-1"} {
+			status, _ := suite.do(http.MethodGet, "/orders/"+id, "", "Authorization", "Bearer "+testToken)
+			suite.Equal(http.StatusBadRequest, status, id)
+		}
+		suite.Equal(0, suite.epgu.Requests(epgutest.OpOrderInfo))
+	})
+
+	suite.Run("orders status", func() {
+		status, _ := suite.do(http.MethodGet, "/orders?id=1,x", "", "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusBadRequest, status)
+		status, _ = suite.do(http.MethodGet, "/orders?id=1&updatedAfter=2024-01-01T00:00:00Z", "", "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusBadRequest, status)
+		status, _ = suite.do(http.MethodGet, "/orders", "", "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("EPGU error mapping", func() {
+		suite.epgu.WithFault(epgutest.OpOrderInfo, epgutest.Fault{Status: http.StatusTooManyRequests, Code: "too_many_requests", Message: "test"})
+		status, _ := suite.do(http.MethodGet, "/orders/1", "", "Authorization", "Bearer "+testToken)
+		suite.Equal(http.StatusTooManyRequests, status)
+	})
+}
+
+func (suite *suiteGateway) TestTokens() {
+	suite.Run("save, info and delete", func() {
+		authURI, err := suite.esiaClient.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		query, err := suite.esia.Authorize(authURI)
+		suite.Require().NoError(err)
+		code, _, err := suite.esiaClient.ParseCallback(query)
+		suite.Require().NoError(err)
+		res, err := suite.esiaClient.TokenExchange(code, suite.scopes, testRedirectURI)
+		suite.Require().NoError(err)
+
+		body, err := json.Marshal(aas.TokenRecord{AccessToken: res.AccessToken, Scope: suite.scopes, RedirectURI: testRedirectURI})
+		suite.Require().NoError(err)
+		status, data := suite.do(http.MethodPut, "/tokens/"+esiatest.DefaultOID, string(body))
+		suite.Require().Equal(http.StatusOK, status, string(data))
+		token := dtoToken{}
+		suite.Require().NoError(json.Unmarshal(data, &token))
+		suite.Equal(esiatest.DefaultOID, token.OID)
+		suite.Equal(aas.ConsentActive, token.Consent)
+		suite.False(token.ExpiresAt.IsZero())
+		suite.NotContains(string(data), res.AccessToken)
+
+		status, data = suite.do(http.MethodGet, "/tokens/"+esiatest.DefaultOID, "")
+		suite.Equal(http.StatusOK, status)
+		suite.NotContains(string(data), res.AccessToken)
+
+		status, _ = suite.do(http.MethodDelete, "/tokens/"+esiatest.DefaultOID, "")
+		suite.Equal(http.StatusNoContent, status)
+		status, _ = suite.do(http.MethodGet, "/tokens/"+esiatest.DefaultOID, "")
+		suite.Equal(http.StatusNotFound, status)
+	})
+
+	suite.Run("save with foreign OID", func() {
+		record := suite.login()
+		body, err := json.Marshal(aas.TokenRecord{AccessToken: record.AccessToken})
+		suite.Require().NoError(err)
+		status, _ := suite.do(http.MethodPut, "/tokens/1000000001", string(body))
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("save malformed token", func() {
+		status, _ := suite.do(http.MethodPut, "/tokens/"+esiatest.DefaultOID, `{"access_token":"malformed"}`)
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("request by X-ESIA-OID", func() {
+		suite.login()
+		orderId, err := suite.epguClient.OrderCreate(testToken, testMeta)
+		suite.Require().NoError(err)
+		status, body := suite.do(http.MethodGet, fmt.Sprintf("/orders/%d", orderId), "", "X-ESIA-OID", esiatest.DefaultOID)
+		suite.Equal(http.StatusOK, status, string(body))
+	})
+
+	suite.Run("unknown X-ESIA-OID", func() {
+		status, _ := suite.do(http.MethodGet, "/orders/1", "", "X-ESIA-OID", "1000000001")
+		suite.Equal(http.StatusUnauthorized, status)
+	})
+}
+
+func (suite *suiteGateway) TestTokenRefresh() {
+	suite.Run("concurrent requests refresh token once", func() {
+		record := suite.login()
+		suite.expire(record.OID)
+		orderId, err := suite.epguClient.OrderCreate(testToken, testMeta)
+		suite.Require().NoError(err)
+		before := suite.esiaTokens.Load()
+
+		const n = 10
+		var wg sync.WaitGroup
+		statuses := make([]int, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/orders/%d", suite.server.URL, orderId), nil)
+				req.Header.Set("X-API-Key", testAPIKey)
+				req.Header.Set("X-ESIA-OID", record.OID)
+				res, err := http.DefaultClient.Do(req)
+				if err != nil {
+					return
+				}
+				_ = res.Body.Close()
+				statuses[i] = res.StatusCode
+			}(i)
+		}
+		wg.Wait()
+
+		for _, status := range statuses {
+			suite.Equal(http.StatusOK, status)
+		}
+		suite.Equal(int32(1), suite.esiaTokens.Load()-before)
+		updated, err := suite.store.Load(record.OID)
+		suite.Require().NoError(err)
+		suite.NotEqual(record.AccessToken, updated.AccessToken)
+		suite.True(suite.esia.Valid(updated.AccessToken))
+		suite.False(updated.Expired(tokenLeeway))
+	})
+
+	suite.Run("consent revoked", func() {
+		record := suite.login()
+		suite.expire(record.OID)
+		suite.esia.Revoke(testClientId, record.OID)
+
+		status, body := suite.do(http.MethodGet, "/orders/1", "", "X-ESIA-OID", record.OID)
+		suite.Equal(http.StatusForbidden, status, string(body))
+		res := dtoError{}
+		suite.Require().NoError(json.Unmarshal(body, &res))
+		suite.True(strings.HasPrefix(res.ReconsentURI, suite.esia.URL), res.ReconsentURI)
+
+		status, body = suite.do(http.MethodGet, "/tokens/"+record.OID, "")
+		suite.Equal(http.StatusOK, status)
+		suite.Contains(string(body), `"consent": "revoked"`)
+	})
+
+	suite.Run("without tracker", func() {
+		suite.gw.tracker = nil
+		record := suite.login()
+		suite.expire(record.OID)
+		status, _ := suite.do(http.MethodGet, "/orders/1", "", "X-ESIA-OID", record.OID)
+		suite.Equal(http.StatusUnauthorized, status)
+	})
+}
+
+func (suite *suiteGateway) TestOpenAPI() {
+	doc := openAPIDocument()
+	data, err := json.Marshal(doc)
+	suite.Require().NoError(err)
+	suite.True(json.Valid(data))
+
+	paths, ok := doc["paths"].(object)
+	suite.Require().True(ok)
+	for _, rt := range routes {
+		path := rt.path
+		if rt.each {
+			path = strings.Replace(rt.path, "{serviceCode}", testServiceCode, 1)
+		}
+		item, ok := paths[path].(object)
+		if suite.True(ok, path) {
+			suite.Contains(item, strings.ToLower(rt.method), path)
+		}
+	}
+
+	suite.Run("handler", func() {
+		w := httptest.NewRecorder()
+		suite.gw.openAPI(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		suite.Equal(http.StatusOK, w.Code)
+		suite.True(json.Valid(w.Body.Bytes()))
+		suite.True(bytes.Contains(w.Body.Bytes(), []byte(`"/services/10000000109/orders"`)))
+	})
+}
@@ -0,0 +1,144 @@
+// HTTP-шлюз к API ЕПГУ для информационных систем на других языках программирования.
+//
+// Принимает данные заявления в формате JSON, формирует архив заявления пакетом услуги
+// и отправляет его в ЕПГУ; возвращает статусы и детали заявлений, файлы и справочники.
+// Обновление маркеров доступа ЕСИА и ограничение частоты запросов к API ЕПГУ выполняются шлюзом.
+// Описание API шлюза в формате OpenAPI 3: GET /openapi.json.
+//
+// # Запуск
+//
+//	epgu-gateway -addr 127.0.0.1:8080 -config epgu.yaml -signer https://signer.local -token-dir /var/lib/epgu-gateway
+//
+// Среда, адреса ЕПГУ и ЕСИА, таймауты и ограничение частоты запросов задаются файлом конфигурации
+// (флаг -config или переменная окружения EPGU_CONFIG) и переменными окружения, см environment.LoadConfig.
+//
+// # Маркер доступа
+//
+// Маркер доступа пользователя передается в заголовке "Authorization: Bearer {маркер}" либо
+// заголовком "X-ESIA-OID: {oid}" - тогда маркер берется из хранилища шлюза и при истечении
+// срока действия обновляется в ЕСИА (aas.ConsentTracker). Записи хранилища добавляются
+// методом PUT /tokens/{oid} или утилитой esia с тем же каталогом token_dir и ключом ESIA_TOKEN_KEY.
+//
+// Обновление маркеров требует провайдера подписи запросов к ЕСИА (флаг -signer):
+//   - URL сервиса подписи, например esia-signer; токен сервиса - переменная окружения ESIA_SIGNER_TOKEN
+//   - nop - подпись тестового провайдера esiatest, только для песочницы epgu-sandbox
+//
+// # Доступ
+//
+// Если задана переменная окружения EPGU_GATEWAY_API_KEY, запросы к шлюзу (кроме /openapi.json)
+// должны содержать заголовок "X-API-Key: {ключ}". Без ключа шлюз запускается только
+// на локальном адресе (127.0.0.1, ::1, localhost): любой клиент, имеющий доступ к шлюзу,
+// может действовать от имени пользователей хранилища маркеров.
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/environment"
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/esiatest"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags)
+
+	var (
+		addr       = flag.String("addr", "127.0.0.1:8080", "адрес шлюза")
+		configPath = flag.String("config", os.Getenv("EPGU_CONFIG"), "файл конфигурации (YAML или JSON)")
+		signerURI  = flag.String("signer", os.Getenv("ESIA_SIGNER_URL"), "сервис подписи запросов к ЕСИА; nop - для песочницы; пусто - без обновления маркеров")
+		tokenDir   = flag.String("token-dir", os.Getenv("ESIA_TOKEN_DIR"), "каталог хранилища маркеров доступа; пусто - в памяти")
+		verbose    = flag.Bool("v", false, "журналировать запросы")
+	)
+	flag.Parse()
+
+	apiKey := os.Getenv("EPGU_GATEWAY_API_KEY")
+	if err := checkListenAddr(*addr, apiKey); err != nil {
+		log.Fatal(err)
+	}
+
+	cfg, err := environment.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	env, err := cfg.Env()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := env.EPGUClient()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, err := tokenStore(*tokenDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gw := newGateway(client, store)
+
+	if *signerURI != "" {
+		if cfg.ESIAClientId == "" {
+			log.Fatal("для обновления маркеров доступа укажите esia_client_id или ESIA_CLIENT_ID")
+		}
+		signer, err := newSigner(*signerURI)
+		if err != nil {
+			log.Fatal(err)
+		}
+		esia, err := env.ESIAClient(cfg.ESIAClientId, signer)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gw.withTracker(aas.NewConsentTracker(esia, store))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", gw.openAPI)
+	mux.Handle("/", apiKeyAuth(gw, apiKey))
+
+	var handler http.Handler = mux
+	if *verbose {
+		handler = logRequests(mux)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("epgu-gateway: http://%s, среда %s: %s, обновление маркеров: %t",
+		*addr, env.Name, env.EPGUBaseURI, gw.tracker != nil)
+	log.Fatal(server.ListenAndServe())
+}
+
+// tokenStore - хранилище маркеров доступа: зашифрованные файлы в каталоге dir
+// с ключом из переменной окружения ESIA_TOKEN_KEY (base64) либо память процесса.
+func tokenStore(dir string) (aas.TokenStore, error) {
+	if dir == "" {
+		return aas.NewMemoryTokenStore(), nil
+	}
+	key, err := base64.StdEncoding.DecodeString(os.Getenv("ESIA_TOKEN_KEY"))
+	if err != nil {
+		return nil, err
+	}
+	return aas.NewFileTokenStore(dir, key)
+}
+
+// newSigner - провайдер подписи запросов к ЕСИА, см флаг -signer.
+func newSigner(uri string) (signature.Provider, error) {
+	if uri == "nop" {
+		return signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash), nil
+	}
+	remote := signature.NewRemote(strings.TrimSuffix(uri, "/")).
+		WithBearerToken(os.Getenv("ESIA_SIGNER_TOKEN")).
+		WithTimeout(30 * time.Second)
+	if _, err := remote.LoadCertHash(); err != nil {
+		return nil, err
+	}
+	return remote, nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/services/sfr"
+)
+
+// object - объект документа OpenAPI.
+type object = map[string]any
+
+// openAPI - GET /openapi.json: описание API шлюза в формате OpenAPI 3.
+// Схемы тел запросов и ответов строятся по типам Go, см [schemas].
+func (g *gateway) openAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusNotFound, dtoError{Error: errNotFound.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, openAPIDocument())
+}
+
+// openAPIDocument - документ OpenAPI по методам [routes] и услугам реестра [registry].
+func openAPIDocument() object {
+	s := &schemas{components: object{}}
+	errorResponse := object{
+		"description": "Ошибка",
+		"content":     object{"application/json": object{"schema": s.of(reflect.TypeOf(dtoError{}))}},
+	}
+
+	paths := object{}
+	for _, rt := range routes {
+		if !rt.each {
+			addOperation(paths, rt.path, rt, s, errorResponse)
+			continue
+		}
+		for _, entry := range registry.Entries() {
+			path := strings.Replace(rt.path, "{serviceCode}", entry.ServiceCode, 1)
+			if _, ok := paths[path]; ok {
+				continue // услуга с несколькими целями: описывается первая
+			}
+			op := rt
+			op.summary = rt.summary + ": " + entry.Name
+			op.body = entry.Data
+			addOperation(paths, path, op, s, errorResponse)
+		}
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":       "epgu-gateway",
+			"description": "HTTP-шлюз к API ЕПГУ",
+			"version":     "1",
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": s.components,
+			"securitySchemes": object{
+				"apiKey":  object{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer":  object{"type": "http", "scheme": "bearer", "description": "Маркер доступа ЕСИА"},
+				"esiaOID": object{"type": "apiKey", "in": "header", "name": "X-ESIA-OID", "description": "OID пользователя в хранилище шлюза"},
+			},
+		},
+		"security": []object{{"apiKey": []string{}}},
+	}
+}
+
+// addOperation - добавляет описание метода rt в paths.
+func addOperation(paths object, path string, rt route, s *schemas, errorResponse object) {
+	var params []object
+	for _, part := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(part, "{"); ok {
+			name = strings.TrimSuffix(name, "}")
+			typ := "string"
+			if name == "orderId" {
+				typ = "integer"
+			}
+			params = append(params, object{"name": name, "in": "path", "required": true, "schema": object{"type": typ}})
+		}
+	}
+	for _, p := range rt.query {
+		params = append(params, object{
+			"name": p.name, "in": "query", "required": p.required,
+			"description": p.description, "schema": object{"type": p.typ},
+		})
+	}
+
+	responses := object{"default": errorResponse}
+	switch {
+	case rt.binary != "":
+		responses["200"] = object{
+			"description": "Файл",
+			"content":     object{rt.binary: object{"schema": object{"type": "string", "format": "binary"}}},
+		}
+	case rt.response == nil:
+		responses["204"] = object{"description": "Выполнено"}
+	default:
+		status := "200"
+		if rt.method == http.MethodPost {
+			status = "201"
+		}
+		responses[status] = object{
+			"description": "Выполнено",
+			"content":     object{"application/json": object{"schema": s.of(reflect.TypeOf(rt.response))}},
+		}
+	}
+
+	op := object{"summary": rt.summary, "responses": responses}
+	if params != nil {
+		op["parameters"] = params
+	}
+	if rt.body != nil {
+		op["requestBody"] = object{
+			"required": true,
+			"content":  object{"application/json": object{"schema": s.of(reflect.TypeOf(rt.body))}},
+		}
+	}
+	if rt.auth {
+		op["security"] = []object{{"apiKey": []string{}, "bearer": []string{}}, {"apiKey": []string{}, "esiaOID": []string{}}}
+	}
+
+	item, ok := paths[path].(object)
+	if !ok {
+		item = object{}
+		paths[path] = item
+	}
+	item[strings.ToLower(rt.method)] = op
+}
+
+// schemas - схемы OpenAPI типов Go. Структуры описываются в components.schemas,
+// имена свойств - по тегам json, как в encoding/json.
+type schemas struct {
+	components object
+}
+
+// scalars - схемы типов с собственным форматом JSON.
+var scalars = map[reflect.Type]object{
+	reflect.TypeOf(time.Time{}):            {"type": "string", "format": "date-time"},
+	reflect.TypeOf(apipgu.DateTime{}):      {"type": "string", "example": "2023-11-02T07:27:22.586+0300"},
+	reflect.TypeOf(apipgu.LocalDateTime{}): {"type": "string", "example": "2023-11-02T07:27:22.586"},
+	reflect.TypeOf(sfr.Date{}):             {"type": "string", "format": "date", "example": "2023-04-13"},
+	reflect.TypeOf(sfr.DateTime{}):         {"type": "string", "example": "2023-04-13T12:00:00"},
+	reflect.TypeOf(sfr.SNILS{}):            {"type": "string", "example": "000-666-666 99"},
+	reflect.TypeOf(json.RawMessage{}):      {},
+}
+
+// of - схема типа t.
+func (s *schemas) of(t reflect.Type) object {
+	if schema, ok := scalars[t]; ok {
+		return schema
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return object{"type": "string", "format": "byte"}
+		}
+		return object{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Struct:
+		return s.ref(t)
+	}
+	return object{}
+}
+
+// ref - ссылка на схему структуры t в components.schemas.
+func (s *schemas) ref(t reflect.Type) object {
+	name := t.String()
+	if t.PkgPath() == "main" {
+		name = t.Name()
+	}
+	ref := object{"$ref": "#/components/schemas/" + name}
+	if _, ok := s.components[name]; ok {
+		return ref
+	}
+	props := object{}
+	s.components[name] = object{"type": "object", "properties": props}
+	s.properties(t, props)
+	return ref
+}
+
+// properties - добавляет в props свойства полей структуры t, включая поля встроенных структур.
+func (s *schemas) properties(t reflect.Type, props object) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			if _, ok := scalars[f.Type]; !ok {
+				s.properties(f.Type, props)
+				continue
+			}
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = s.of(f.Type)
+	}
+}
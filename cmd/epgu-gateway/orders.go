@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// dtoService - услуга в списке услуг.
+type dtoService struct {
+	ServiceCode string `json:"serviceCode"`
+	TargetCode  string `json:"targetCode"`
+	Name        string `json:"name"`
+	Path        string `json:"path"` // Метод создания заявления
+}
+
+// dtoOrderCreated - ответ на создание заявления.
+type dtoOrderCreated struct {
+	OrderId int `json:"orderId"`
+}
+
+// services - GET /services
+func (g *gateway) services(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	entries := registry.Entries()
+	res := make([]dtoService, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, dtoService{
+			ServiceCode: e.ServiceCode,
+			TargetCode:  e.TargetCode,
+			Name:        e.Name,
+			Path:        "/services/" + e.ServiceCode + "/orders",
+		})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// orderCreate - POST /services/{serviceCode}/orders[?targetCode=...], тело запроса - данные заявления услуги.
+func (g *gateway) orderCreate(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: %w", errBadRequest, err))
+		return
+	}
+	svc, err := registry.NewService(vars["serviceCode"], r.URL.Query().Get("targetCode"), data)
+	switch {
+	case errors.Is(err, apipgu.ErrServiceNotFound):
+		writeJSON(w, http.StatusNotFound, dtoError{Error: err.Error()})
+		return
+	case errors.Is(err, apipgu.ErrServiceData):
+		writeError(w, fmt.Errorf("%w: %w", errBadRequest, err))
+		return
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, dtoError{Error: err.Error()})
+		return
+	}
+	token, err := g.token(w, r)
+	if err != nil {
+		return
+	}
+
+	orderId, err := g.client.OrderPushService(token, svc)
+	if err != nil {
+		writeJSON(w, httpStatus(err), dtoError{Error: err.Error(), OrderId: orderId})
+		return
+	}
+	writeJSON(w, http.StatusCreated, dtoOrderCreated{OrderId: orderId})
+}
+
+// ordersStatus - GET /orders?id=...|updatedAfter=...
+func (g *gateway) ordersStatus(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	query := r.URL.Query()
+	pageNum, err := queryInt(r, "page")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	pageSize, err := queryInt(r, "size")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var (
+		ids   []int
+		after time.Time
+	)
+	switch {
+	case query.Has("id") && query.Has("updatedAfter"):
+		writeError(w, fmt.Errorf("%w: укажите id или updatedAfter", errBadRequest))
+		return
+	case query.Has("id"):
+		for _, v := range query["id"] {
+			for _, s := range strings.Split(v, ",") {
+				id, err := strconv.Atoi(strings.TrimSpace(s))
+				if err != nil {
+					writeError(w, fmt.Errorf("%w: %w: '%s'", errBadRequest, apipgu.ErrWrongOrderID, s))
+					return
+				}
+				ids = append(ids, id)
+			}
+		}
+	case query.Has("updatedAfter"):
+		if after, err = time.Parse(time.RFC3339, query.Get("updatedAfter")); err != nil {
+			writeError(w, fmt.Errorf("%w: updatedAfter: %w", errBadRequest, err))
+			return
+		}
+	default:
+		writeError(w, fmt.Errorf("%w: укажите id или updatedAfter", errBadRequest))
+		return
+	}
+
+	token, err := g.token(w, r)
+	if err != nil {
+		return
+	}
+	var res *apipgu.OrdersStatus
+	if ids != nil {
+		res, err = g.client.OrdersStatus(token, ids, pageNum, pageSize)
+	} else {
+		res, err = g.client.OrdersUpdatedAfter(token, after, pageNum, pageSize)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// orderInfo - GET /orders/{orderId}
+func (g *gateway) orderInfo(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	orderId, err := parseOrderId(vars["orderId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	token, err := g.token(w, r)
+	if err != nil {
+		return
+	}
+	info, err := g.client.OrderInfo(token, orderId)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// orderCancel - POST /orders/{orderId}/cancel
+func (g *gateway) orderCancel(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	orderId, err := parseOrderId(vars["orderId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	token, err := g.token(w, r)
+	if err != nil {
+		return
+	}
+	if err = g.client.OrderCancel(token, orderId); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fileDownload - GET /files?link=...
+func (g *gateway) fileDownload(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	link := r.URL.Query().Get("link")
+	if link == "" {
+		writeError(w, fmt.Errorf("%w: не указан link", errBadRequest))
+		return
+	}
+	token, err := g.token(w, r)
+	if err != nil {
+		return
+	}
+	data, err := g.client.AttachmentDownload(token, link)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filename := path.Base(link)
+	contentType := mime.TypeByExtension(path.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	_, _ = w.Write(data)
+}
+
+// parseOrderId - номер заявления из параметра пути.
+func parseOrderId(s string) (int, error) {
+	orderId, err := strconv.Atoi(s)
+	if err != nil || orderId <= 0 {
+		return 0, fmt.Errorf("%w: %w: '%s'", errBadRequest, apipgu.ErrWrongOrderID, s)
+	}
+	return orderId, nil
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	apipgu "github.com/ofstudio/go-api-epgu"
+
+	// Услуги шлюза регистрируются в реестре apipgu.DefaultServices
+	_ "github.com/ofstudio/go-api-epgu/services/sfr/zdp-10000000109"
+)
+
+// registry - услуги, заявления по которым принимает шлюз.
+var registry = apipgu.DefaultServices
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+)
+
+// tokenLeeway - маркер доступа обновляется, если его срок действия истекает в течение tokenLeeway.
+const tokenLeeway = time.Minute
+
+// dtoToken - сведения о маркере доступа пользователя в хранилище шлюза.
+type dtoToken struct {
+	OID              string            `json:"oid"`
+	ExpiresAt        time.Time         `json:"expiresAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+	Scope            string            `json:"scope"`
+	Consent          aas.ConsentStatus `json:"consent"`
+	ConsentExpiresAt *time.Time        `json:"consentExpiresAt,omitempty"`
+}
+
+func newDtoToken(record *aas.TokenRecord) dtoToken {
+	res := dtoToken{
+		OID:       record.OID,
+		ExpiresAt: record.ExpiresAt,
+		UpdatedAt: record.UpdatedAt,
+		Scope:     record.Scope.String(),
+		Consent:   record.Consent.Status(),
+	}
+	if !record.Consent.ExpiresAt.IsZero() {
+		res.ConsentExpiresAt = &record.Consent.ExpiresAt
+	}
+	return res
+}
+
+// token - маркер доступа пользователя из заголовка Authorization или из хранилища по заголовку X-ESIA-OID.
+// Маркер из хранилища обновляется, если срок его действия истекает.
+// В случае ошибки записывает ответ с ошибкой.
+func (g *gateway) token(w http.ResponseWriter, r *http.Request) (string, error) {
+	token, err := g.userToken(r)
+	if err != nil {
+		res := dtoError{Error: err.Error()}
+		if g.tracker != nil && (errors.Is(err, aas.ErrConsentExpired) || errors.Is(err, aas.ErrConsentRevoked)) {
+			res.ReconsentURI, _ = g.tracker.ReconsentURI(r.Header.Get("X-ESIA-OID"))
+		}
+		writeJSON(w, httpStatus(err), res)
+		return "", err
+	}
+	return token, nil
+}
+
+func (g *gateway) userToken(r *http.Request) (string, error) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token, nil
+	}
+	oid := r.Header.Get("X-ESIA-OID")
+	if oid == "" {
+		return "", fmt.Errorf("%w: укажите заголовок Authorization или X-ESIA-OID", errUnauthorized)
+	}
+
+	record, err := g.store.Load(oid)
+	if err != nil {
+		return "", err
+	}
+	if !record.Expired(tokenLeeway) {
+		return record.AccessToken, nil
+	}
+	if g.tracker == nil {
+		return "", fmt.Errorf("%w: истек срок действия маркера доступа [oid='%s'], обновление не настроено", errUnauthorized, oid)
+	}
+
+	// маркер мог обновить параллельный запрос
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if record, err = g.store.Load(oid); err != nil {
+		return "", err
+	}
+	if !record.Expired(tokenLeeway) {
+		return record.AccessToken, nil
+	}
+	if record, err = g.tracker.Refresh(oid); err != nil {
+		return "", err
+	}
+	return record.AccessToken, nil
+}
+
+// tokenSave - PUT /tokens/{oid}, тело запроса - [aas.TokenRecord].
+// Срок действия маркера и сведения о согласии, если не указаны, рассчитываются по маркеру и permissions.
+func (g *gateway) tokenSave(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	record := &aas.TokenRecord{}
+	if err := readJSON(w, r, record); err != nil {
+		writeError(w, err)
+		return
+	}
+	claims, err := aas.ParseTokenClaims(record.AccessToken)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if claims.OID() != vars["oid"] || (record.OID != "" && record.OID != vars["oid"]) {
+		writeError(w, fmt.Errorf("%w: '%s'", aas.ErrInvalidOID, claims.OID()))
+		return
+	}
+
+	now := time.Now()
+	record.OID = vars["oid"]
+	if record.ExpiresAt.IsZero() {
+		record.ExpiresAt = claims.Expiry()
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = now
+	}
+	if record.Consent.GrantedAt.IsZero() {
+		record.Consent = aas.NewConsent(record.Permissions, now)
+	}
+	if err = g.store.Save(record); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newDtoToken(record))
+}
+
+// tokenInfo - GET /tokens/{oid}
+func (g *gateway) tokenInfo(w http.ResponseWriter, _ *http.Request, vars map[string]string) {
+	record, err := g.store.Load(vars["oid"])
+	if errors.Is(err, aas.ErrTokenNotFound) {
+		writeJSON(w, http.StatusNotFound, dtoError{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newDtoToken(record))
+}
+
+// tokenDelete - DELETE /tokens/{oid}
+func (g *gateway) tokenDelete(w http.ResponseWriter, _ *http.Request, vars map[string]string) {
+	if err := g.store.Delete(vars["oid"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
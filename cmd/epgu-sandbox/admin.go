@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ofstudio/go-api-epgu/epgutest"
+)
+
+// admin - API администратора песочницы.
+type admin struct {
+	server *epgutest.Server
+	dir    string // Каталог файла сценариев для файлов ответа в статусах
+}
+
+// dtoOrder - заявление в списке заявлений.
+type dtoOrder struct {
+	OrderId    int    `json:"orderId"`
+	StatusId   *int   `json:"statusId"` // nil - архив заявления не загружен
+	StatusName string `json:"statusName,omitempty"`
+	Closed     bool   `json:"closed"`
+}
+
+// dtoError - ответ с ошибкой.
+type dtoError struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP - реализация [http.Handler].
+func (a *admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin"), "/"), "/")
+	post, get := r.Method == http.MethodPost, r.Method == http.MethodGet
+
+	switch {
+	case get && len(parts) == 1 && parts[0] == "orders":
+		a.orders(w)
+	case len(parts) >= 2 && parts[0] == "orders":
+		orderId, err := strconv.Atoi(parts[1])
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, dtoError{"некорректный номер заявления"})
+			return
+		}
+		switch {
+		case get && len(parts) == 2:
+			a.order(w, orderId)
+		case get && len(parts) == 3 && parts[2] == "archive":
+			a.archive(w, orderId)
+		case post && len(parts) == 3 && parts[2] == "advance":
+			a.advance(w, orderId)
+		case post && len(parts) == 3 && parts[2] == "status":
+			a.status(w, r, orderId)
+		default:
+			writeJSON(w, http.StatusNotFound, dtoError{"метод не найден"})
+		}
+	case post && len(parts) == 1 && parts[0] == "faults":
+		a.addFault(w, r)
+	case r.Method == http.MethodDelete && len(parts) == 1 && parts[0] == "faults":
+		a.server.ClearFaults()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusNotFound, dtoError{"метод не найден"})
+	}
+}
+
+// orders - GET /admin/orders
+func (a *admin) orders(w http.ResponseWriter) {
+	ids := a.server.Orders()
+	res := make([]dtoOrder, 0, len(ids))
+	for _, id := range ids {
+		o := dtoOrder{OrderId: id}
+		if details := a.server.Order(id); details != nil {
+			o.StatusId = &details.OrderStatusId
+			o.StatusName = details.OrderStatusName
+			o.Closed = details.Closed
+		}
+		res = append(res, o)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// order - GET /admin/orders/{orderId}
+func (a *admin) order(w http.ResponseWriter, orderId int) {
+	details := a.server.Order(orderId)
+	if details == nil {
+		writeJSON(w, http.StatusNotFound, dtoError{epgutest.ErrOrderNotPushed.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// archive - GET /admin/orders/{orderId}/archive
+func (a *admin) archive(w http.ResponseWriter, orderId int) {
+	data := a.server.Archive(orderId)
+	if data == nil {
+		writeJSON(w, http.StatusNotFound, dtoError{epgutest.ErrOrderNotPushed.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	_, _ = w.Write(data)
+}
+
+// advance - POST /admin/orders/{orderId}/advance
+func (a *admin) advance(w http.ResponseWriter, orderId int) {
+	if err := a.server.Advance(orderId); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.order(w, orderId)
+}
+
+// status - POST /admin/orders/{orderId}/status, тело запроса - [StepConfig].
+// Файлы ответа читаются только из каталога файла сценариев, см [StepConfig.checkLocalPaths].
+func (a *admin) status(w http.ResponseWriter, r *http.Request, orderId int) {
+	stc := StepConfig{}
+	if err := json.NewDecoder(r.Body).Decode(&stc); err != nil {
+		writeJSON(w, http.StatusBadRequest, dtoError{err.Error()})
+		return
+	}
+	if err := stc.checkLocalPaths(); err != nil {
+		writeJSON(w, http.StatusBadRequest, dtoError{err.Error()})
+		return
+	}
+	step, err := stc.step(a.dir)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, dtoError{err.Error()})
+		return
+	}
+	if err = a.server.SetStatus(orderId, step); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.order(w, orderId)
+}
+
+// addFault - POST /admin/faults, тело запроса - [FaultConfig].
+func (a *admin) addFault(w http.ResponseWriter, r *http.Request) {
+	fc := FaultConfig{}
+	if err := json.NewDecoder(r.Body).Decode(&fc); err != nil {
+		writeJSON(w, http.StatusBadRequest, dtoError{err.Error()})
+		return
+	}
+	fault, err := fc.fault()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, dtoError{err.Error()})
+		return
+	}
+	a.server.WithFault(fc.Op, fault)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError - ответ с ошибкой [epgutest.Server].
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusConflict
+	if errors.Is(err, epgutest.ErrOrderNotFound) {
+		status = http.StatusNotFound
+	}
+	writeJSON(w, status, dtoError{err.Error()})
+}
+
+// writeJSON - ответ с JSON-содержимым.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(body)
+}
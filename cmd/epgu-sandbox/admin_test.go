@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/epgutest"
+)
+
+const testAdminToken = "test-admin-token"
+
+var testMeta = apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"}
+
+func TestAdmin(t *testing.T) {
+	suite.Run(t, new(suiteAdmin))
+}
+
+type suiteAdmin struct {
+	suite.Suite
+	epgu   *epgutest.Server
+	client *apipgu.Client
+	server *httptest.Server
+	dir    string
+}
+
+func (suite *suiteAdmin) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.dir, "resp.pdf"), []byte("%PDF"), 0o644))
+
+	suite.epgu = epgutest.NewServer().WithScenario(testMeta.ServiceCode, epgutest.Scenario{Steps: []epgutest.Step{
+		{StatusId: 2, Title: "Заявление получено ведомством", CancelAllowed: true},
+		{StatusId: 3, Title: "Исполнено", Final: true},
+	}})
+	suite.client = apipgu.NewClient(suite.epgu.URL)
+	suite.server = httptest.NewServer(adminAuth(&admin{server: suite.epgu, dir: suite.dir}, testAdminToken))
+}
+
+func (suite *suiteAdmin) TearDownTest() {
+	suite.server.Close()
+	suite.epgu.Close()
+}
+
+func (suite *suiteAdmin) SetupSubTest() {
+	suite.TearDownTest()
+	suite.SetupTest()
+}
+
+// do - запрос к API администратора. Возвращает HTTP-код и тело ответа.
+func (suite *suiteAdmin) do(method, path, body string) (int, []byte) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, suite.server.URL+path, reader)
+	suite.Require().NoError(err)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	res, err := http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+	//goland:noinspection ALL
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	suite.Require().NoError(err)
+	return res.StatusCode, data
+}
+
+// push - создает заявление и отправляет архив.
+func (suite *suiteAdmin) push() int {
+	orderId, err := suite.client.OrderPush(testToken, testMeta, testArchive())
+	suite.Require().NoError(err)
+	return orderId
+}
+
+// details - детали заявления из ответа API администратора.
+func (suite *suiteAdmin) details(body []byte) apipgu.OrderDetails {
+	details := apipgu.OrderDetails{}
+	suite.Require().NoError(json.Unmarshal(body, &details))
+	return details
+}
+
+func (suite *suiteAdmin) TestRouter() {
+	orderId := suite.push()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"orders", http.MethodGet, "/admin/orders", http.StatusOK},
+		{"orders trailing slash", http.MethodGet, "/admin/orders/", http.StatusOK},
+		{"order", http.MethodGet, "/admin/orders/{orderId}", http.StatusOK},
+		{"archive", http.MethodGet, "/admin/orders/{orderId}/archive", http.StatusOK},
+		{"unknown path", http.MethodGet, "/admin/unknown", http.StatusNotFound},
+		{"unknown order op", http.MethodPost, "/admin/orders/{orderId}/unknown", http.StatusNotFound},
+		{"wrong method for order", http.MethodPost, "/admin/orders/{orderId}", http.StatusNotFound},
+		{"wrong method for advance", http.MethodGet, "/admin/orders/{orderId}/advance", http.StatusNotFound},
+		{"wrong method for faults", http.MethodGet, "/admin/faults", http.StatusNotFound},
+		{"bad orderId", http.MethodGet, "/admin/orders/abc", http.StatusBadRequest},
+		{"bad orderId advance", http.MethodPost, "/admin/orders/abc/advance", http.StatusBadRequest},
+		{"order not found", http.MethodGet, "/admin/orders/999", http.StatusNotFound},
+		{"archive not found", http.MethodGet, "/admin/orders/999/archive", http.StatusNotFound},
+		{"advance not found", http.MethodPost, "/admin/orders/999/advance", http.StatusNotFound},
+		{"clear faults", http.MethodDelete, "/admin/faults", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		status, body := suite.do(tt.method, strings.Replace(tt.path, "{orderId}", strconv.Itoa(orderId), 1), "")
+		suite.Equal(tt.status, status, "%s: %s", tt.name, body)
+	}
+}
+
+func (suite *suiteAdmin) TestAuth() {
+	res, err := http.Get(suite.server.URL + "/admin/orders")
+	suite.Require().NoError(err)
+	_ = res.Body.Close()
+	suite.Equal(http.StatusUnauthorized, res.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, suite.server.URL+"/admin/orders", nil)
+	suite.Require().NoError(err)
+	req.Header.Set("Authorization", "Bearer wrong")
+	res, err = http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+	_ = res.Body.Close()
+	suite.Equal(http.StatusUnauthorized, res.StatusCode)
+}
+
+func (suite *suiteAdmin) TestOrders() {
+	created, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+	pushed := suite.push()
+
+	status, body := suite.do(http.MethodGet, "/admin/orders", "")
+	suite.Require().Equal(http.StatusOK, status)
+	var orders []dtoOrder
+	suite.Require().NoError(json.Unmarshal(body, &orders))
+	suite.Require().Len(orders, 2)
+	suite.Equal(created, orders[0].OrderId)
+	suite.Nil(orders[0].StatusId)
+	suite.Equal(pushed, orders[1].OrderId)
+	suite.Require().NotNil(orders[1].StatusId)
+	suite.Equal(21, *orders[1].StatusId)
+	suite.False(orders[1].Closed)
+
+	status, body = suite.do(http.MethodGet, "/admin/orders/"+strconv.Itoa(created), "")
+	suite.Equal(http.StatusNotFound, status)
+	suite.Contains(string(body), epgutest.ErrOrderNotPushed.Error())
+
+	status, body = suite.do(http.MethodGet, "/admin/orders/"+strconv.Itoa(pushed)+"/archive", "")
+	suite.Equal(http.StatusOK, status)
+	suite.Equal(testArchive().Data, body)
+}
+
+func (suite *suiteAdmin) TestAdvance() {
+	orderId := suite.push()
+	path := "/admin/orders/" + strconv.Itoa(orderId) + "/advance"
+
+	status, body := suite.do(http.MethodPost, path, "")
+	suite.Require().Equal(http.StatusOK, status, string(body))
+	details := suite.details(body)
+	suite.Equal(2, details.OrderStatusId)
+	suite.False(details.Closed)
+
+	status, body = suite.do(http.MethodPost, path, "")
+	suite.Require().Equal(http.StatusOK, status, string(body))
+	details = suite.details(body)
+	suite.Equal(3, details.OrderStatusId)
+	suite.True(details.Closed)
+
+	status, body = suite.do(http.MethodPost, path, "")
+	suite.Equal(http.StatusConflict, status)
+	suite.Contains(string(body), epgutest.ErrNoSteps.Error())
+
+	info, err := suite.client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(info.Order)
+	suite.Equal(3, info.Order.OrderStatusId)
+}
+
+func (suite *suiteAdmin) TestStatus() {
+	suite.Run("success", func() {
+		orderId := suite.push()
+		status, body := suite.do(http.MethodPost, "/admin/orders/"+strconv.Itoa(orderId)+"/status",
+			`{"statusId": 10, "title": "Отказано", "final": true, "files": [{"path": "resp.pdf"}, {"name": "resp.xml", "data": "<resp/>"}]}`)
+		suite.Require().Equal(http.StatusOK, status, string(body))
+		details := suite.details(body)
+		suite.Equal(10, details.OrderStatusId)
+		suite.True(details.Closed)
+		suite.Require().Len(details.OrderResponseFiles, 2)
+		suite.Equal("resp.pdf", details.OrderResponseFiles[0].FileName)
+
+		data, err := suite.client.AttachmentDownload(testToken, details.OrderResponseFiles[0].Link)
+		suite.Require().NoError(err)
+		suite.Equal([]byte("%PDF"), data)
+	})
+
+	suite.Run("file paths outside scenarios dir", func() {
+		orderId := suite.push()
+		for _, path := range []string{"../secret.txt", filepath.Join(filepath.Dir(suite.dir), "secret.txt"), "/etc/passwd"} {
+			body, err := json.Marshal(StepConfig{StatusId: 10, Files: []FileConfig{{Path: path}}})
+			suite.Require().NoError(err)
+			status, res := suite.do(http.MethodPost, "/admin/orders/"+strconv.Itoa(orderId)+"/status", string(body))
+			suite.Equal(http.StatusBadRequest, status, path)
+			suite.Contains(string(res), "должен быть относительным", path)
+		}
+		suite.Empty(suite.epgu.Order(orderId).OrderResponseFiles)
+		suite.Equal(21, suite.epgu.Order(orderId).OrderStatusId)
+	})
+
+	suite.Run("file not found", func() {
+		orderId := suite.push()
+		status, _ := suite.do(http.MethodPost, "/admin/orders/"+strconv.Itoa(orderId)+"/status", `{"statusId": 10, "files": [{"path": "not-found.pdf"}]}`)
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("malformed body", func() {
+		orderId := suite.push()
+		status, _ := suite.do(http.MethodPost, "/admin/orders/"+strconv.Itoa(orderId)+"/status", `{"statusId": "10"}`)
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("order not found", func() {
+		status, _ := suite.do(http.MethodPost, "/admin/orders/999/status", `{"statusId": 10}`)
+		suite.Equal(http.StatusNotFound, status)
+	})
+}
+
+func (suite *suiteAdmin) TestFaults() {
+	orderId := suite.push()
+
+	status, body := suite.do(http.MethodPost, "/admin/faults", `{"op": "OrderInfo", "status": 502, "times": 1}`)
+	suite.Require().Equal(http.StatusNoContent, status, string(body))
+	_, err := suite.client.OrderInfo(testToken, orderId)
+	suite.ErrorIs(err, apipgu.ErrStatusBadGateway)
+	_, err = suite.client.OrderInfo(testToken, orderId)
+	suite.NoError(err)
+
+	status, _ = suite.do(http.MethodPost, "/admin/faults", `{"op": "OrderInfo", "status": 502}`)
+	suite.Require().Equal(http.StatusNoContent, status)
+	status, _ = suite.do(http.MethodDelete, "/admin/faults", "")
+	suite.Require().Equal(http.StatusNoContent, status)
+	_, err = suite.client.OrderInfo(testToken, orderId)
+	suite.NoError(err)
+
+	status, body = suite.do(http.MethodPost, "/admin/faults", `{"op": "OrderDelete", "status": 502}`)
+	suite.Equal(http.StatusBadRequest, status)
+	suite.Contains(string(body), "неизвестный метод API ЕПГУ")
+
+	status, _ = suite.do(http.MethodPost, "/admin/faults", `{"op": "OrderInfo", "delay": "abc"}`)
+	suite.Equal(http.StatusBadRequest, status)
+}
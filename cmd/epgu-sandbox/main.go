@@ -0,0 +1,137 @@
+// Песочница API ЕПГУ и ЕСИА для локальной разработки и тестирования.
+//
+// Запускает фейковые серверы epgutest и esiatest на одном адресе. Сценарии обработки заявлений
+// по кодам услуг (статусы с задержками, коды обработки Приложения 1 Спецификации, файлы ответа),
+// ошибки (серии HTTP 429, недоступность HTTP 502, медленные ответы на чанки, limitation_exception)
+// и справочники загружаются из файла сценариев в формате JSON, см Config.
+//
+// # Запуск
+//
+//	epgu-sandbox -addr 127.0.0.1:8090 -scenarios scenarios.json
+//
+// Адрес песочницы указывается в apipgu.NewClient и aas.NewClient вместо адресов ЕПГУ и ЕСИА.
+// Если в файле сценариев не заданы маркеры доступа (tokens), API ЕПГУ принимает любой маркер,
+// в том числе выданный ЕСИА песочницы. Подпись client_secret запросов к ЕСИА не проверяется;
+// с флагом -esia-strict принимается только подпись signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash).
+//
+// Токен API администратора передается через переменную окружения EPGU_SANDBOX_ADMIN_TOKEN:
+// если токен задан, запросы к /admin/ должны содержать заголовок "Authorization: Bearer {токен}".
+//
+// # Эндпоинты
+//   - /api/... - API ЕПГУ, см epgutest
+//   - /aas/oauth2/... - сервер авторизации ЕСИА, см esiatest
+//   - GET /admin/orders - список заявлений и их текущие статусы
+//   - GET /admin/orders/{orderId} - детали заявления
+//   - GET /admin/orders/{orderId}/archive - загруженный архив заявления
+//   - POST /admin/orders/{orderId}/advance - следующий статус сценария
+//   - POST /admin/orders/{orderId}/status - статус вне сценария, тело запроса - StepConfig;
+//     пути файлов ответа (files[].path) - только относительно каталога файла сценариев
+//   - POST /admin/faults - добавить ошибку, тело запроса - FaultConfig
+//   - DELETE /admin/faults - удалить все ошибки
+package main
+
+import (
+	"crypto/subtle"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/epgutest"
+	"github.com/ofstudio/go-api-epgu/esia/esiatest"
+)
+
+func main() {
+	var (
+		addr       = flag.String("addr", "127.0.0.1:8090", "адрес песочницы")
+		scenarios  = flag.String("scenarios", "", "файл сценариев (JSON)")
+		esiaClient = flag.String("esia-client", "", "мнемоника ИС для ЕСИА; пусто - любая ИС")
+		redirects  = flag.String("esia-redirect-uri", "", "разрешенные redirect_uri через запятую (вместе с -esia-client)")
+		esiaStrict = flag.Bool("esia-strict", false, "проверять подпись client_secret тестового провайдера")
+		verbose    = flag.Bool("v", false, "журналировать запросы")
+	)
+	flag.Parse()
+
+	epgu := epgutest.New()
+	dir := "."
+	if *scenarios != "" {
+		cfg, err := loadConfig(*scenarios)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dir = filepath.Dir(*scenarios)
+		if err = cfg.apply(epgu, dir); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("сценарии: %s: услуг %d, ошибок %d", *scenarios, len(cfg.Services), len(cfg.Faults))
+	}
+
+	esia := esiatest.New()
+	if *esiaClient != "" {
+		var uris []string
+		if *redirects != "" {
+			uris = strings.Split(*redirects, ",")
+		}
+		esia.WithClient(*esiaClient, uris...)
+	}
+	if !*esiaStrict {
+		esia.WithVerifier(esiatest.VerifierFunc(func([]byte, []byte, string) error { return nil }))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", epgu)
+	mux.Handle("/aas/", esia)
+	mux.Handle("/admin/", adminAuth(&admin{server: epgu, dir: dir}, os.Getenv("EPGU_SANDBOX_ADMIN_TOKEN")))
+
+	var handler http.Handler = mux
+	if *verbose {
+		handler = logRequests(mux)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("epgu-sandbox: http://%s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+// adminAuth - проверяет токен API администратора; пустой token - без проверки.
+func adminAuth(next http.Handler, token string) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, dtoError{"отказ в доступе"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusWriter - сохраняет HTTP-код ответа для журнала.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests - журналирует запросы.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, sw.status, time.Since(start).Round(time.Millisecond))
+	})
+}
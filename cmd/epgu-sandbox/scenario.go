@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/epgutest"
+)
+
+// Config - файл сценариев песочницы.
+//
+// Пример:
+//
+//	{
+//	  "tokens": ["sandbox-token"],
+//	  "services": {
+//	    "10000000109": {
+//	      "sender": "СФР",
+//	      "steps": [
+//	        {"statusId": 2, "title": "Заявление получено ведомством", "delay": "30s", "cancelAllowed": true},
+//	        {"statusId": 3, "title": "Исполнено", "final": true, "delay": "2m",
+//	         "files": [{"name": "resp.pdf", "path": "files/resp.pdf"}]}
+//	      ]
+//	    },
+//	    "10000000110": {"code": "VALIDATION_ERROR", "message": "Вложения не прошли валидацию"}
+//	  },
+//	  "faults": [
+//	    {"op": "OrderCreate", "status": 429, "times": 5},
+//	    {"op": "OrderInfo", "status": 502, "duration": "1m"},
+//	    {"op": "OrderPushChunked", "delay": "3s"},
+//	    {"op": "OrderPush", "status": 409, "code": "limitation_exception", "message": "Превышены ограничения"}
+//	  ],
+//	  "dicts": {
+//	    "EXTERNAL_BIC": [{"value": "044525225", "title": "ПАО Сбербанк"}]
+//	  }
+//	}
+type Config struct {
+	Tokens   []string                     `json:"tokens"`   // Допустимые маркеры доступа; пусто - любой маркер
+	Services map[string]ScenarioConfig    `json:"services"` // Сценарии по коду услуги serviceCode
+	Faults   []FaultConfig                `json:"faults"`   // Ошибки, действующие с момента запуска
+	Dicts    map[string][]apipgu.DictItem `json:"dicts"`    // Справочники по коду справочника
+}
+
+// ScenarioConfig - сценарий обработки заявлений услуги, см [epgutest.Scenario].
+type ScenarioConfig struct {
+	Code    string       `json:"code"`    // Код обработки заявления из Приложения 1 Спецификации; по умолчанию OK
+	Message string       `json:"message"` // Сообщение к коду обработки
+	Sender  string       `json:"sender"`  // Наименование ведомства
+	Steps   []StepConfig `json:"steps"`   // Статусы заявления после отправки в ведомство
+	Cancel  *StepConfig  `json:"cancel"`  // Статус после отмены заявления
+}
+
+// StepConfig - статус заявления, см [epgutest.Step].
+type StepConfig struct {
+	StatusId      int          `json:"statusId"`
+	Title         string       `json:"title"`
+	Comment       string       `json:"comment"`
+	Final         bool         `json:"final"`
+	CancelAllowed bool         `json:"cancelAllowed"`
+	Delay         Duration     `json:"delay"` // Задержка после предыдущего статуса; 0 - статус устанавливается через API администратора
+	Files         []FileConfig `json:"files"`
+}
+
+// FileConfig - файл ответа ведомства. Содержимое задается файлом path
+// (относительно каталога файла сценариев) или строкой data.
+type FileConfig struct {
+	Name                string `json:"name"`
+	MimeType            string `json:"mimeType"`
+	Path                string `json:"path"`
+	Data                string `json:"data"`
+	HasDigitalSignature bool   `json:"hasDigitalSignature"`
+}
+
+// FaultConfig - правило ошибки метода API ЕПГУ, см [epgutest.Fault].
+type FaultConfig struct {
+	Op       epgutest.Op `json:"op"`       // Метод API ЕПГУ, например "OrderCreate"
+	Status   int         `json:"status"`   // HTTP-код ответа; 0 - только задержка
+	Code     string      `json:"code"`     // Код ошибки ЕПГУ, например "limitation_exception"
+	Message  string      `json:"message"`  // Текст ошибки ЕПГУ
+	Delay    Duration    `json:"delay"`    // Задержка перед ответом
+	Times    int         `json:"times"`    // Количество срабатываний; 0 - без ограничения
+	Duration Duration    `json:"duration"` // Длительность действия с первого срабатывания; 0 - без ограничения
+}
+
+// Duration - длительность в формате [time.ParseDuration] ("30s", "2m").
+type Duration time.Duration
+
+// UnmarshalJSON - реализация [json.Unmarshaler].
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON - реализация [json.Marshaler].
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// ops - методы API ЕПГУ, для которых задаются ошибки.
+var ops = map[epgutest.Op]struct{}{
+	epgutest.OpOrderCreate:  {},
+	epgutest.OpPush:         {},
+	epgutest.OpPushChunked:  {},
+	epgutest.OpOrderInfo:    {},
+	epgutest.OpOrderCancel:  {},
+	epgutest.OpOrdersStatus: {},
+	epgutest.OpUpdatedAfter: {},
+	epgutest.OpDict:         {},
+	epgutest.OpDownload:     {},
+}
+
+// loadConfig - читает файл сценариев.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// apply - настраивает сервер по файлу сценариев; dir - каталог файла сценариев.
+func (cfg *Config) apply(server *epgutest.Server, dir string) error {
+	server.WithTokens(cfg.Tokens...)
+	for serviceCode, sc := range cfg.Services {
+		scenario, err := sc.scenario(dir)
+		if err != nil {
+			return fmt.Errorf("услуга %s: %w", serviceCode, err)
+		}
+		server.WithScenario(serviceCode, scenario)
+	}
+	for _, fc := range cfg.Faults {
+		fault, err := fc.fault()
+		if err != nil {
+			return err
+		}
+		server.WithFault(fc.Op, fault)
+	}
+	for code, items := range cfg.Dicts {
+		server.WithDict(code, items...)
+	}
+	return nil
+}
+
+// scenario - возвращает сценарий услуги.
+func (sc ScenarioConfig) scenario(dir string) (epgutest.Scenario, error) {
+	scenario := epgutest.Scenario{Code: sc.Code, Message: sc.Message, Sender: sc.Sender}
+	for _, stc := range sc.Steps {
+		step, err := stc.step(dir)
+		if err != nil {
+			return scenario, err
+		}
+		scenario.Steps = append(scenario.Steps, step)
+	}
+	if sc.Cancel != nil {
+		step, err := sc.Cancel.step(dir)
+		if err != nil {
+			return scenario, err
+		}
+		scenario.Cancel = &step
+	}
+	return scenario, nil
+}
+
+// step - возвращает статус заявления с содержимым файлов ответа.
+func (stc StepConfig) step(dir string) (epgutest.Step, error) {
+	step := epgutest.Step{
+		StatusId:      stc.StatusId,
+		Title:         stc.Title,
+		Comment:       stc.Comment,
+		Final:         stc.Final,
+		CancelAllowed: stc.CancelAllowed,
+		Delay:         time.Duration(stc.Delay),
+	}
+	for _, fc := range stc.Files {
+		data := []byte(fc.Data)
+		if fc.Path != "" {
+			path := fc.Path
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			var err error
+			if data, err = os.ReadFile(path); err != nil {
+				return step, fmt.Errorf("статус %d: %w", stc.StatusId, err)
+			}
+		}
+		name := fc.Name
+		if name == "" {
+			name = filepath.Base(fc.Path)
+		}
+		step.Files = append(step.Files, epgutest.File{
+			Name:                name,
+			MimeType:            fc.MimeType,
+			Data:                data,
+			HasDigitalSignature: fc.HasDigitalSignature,
+		})
+	}
+	return step, nil
+}
+
+// checkLocalPaths - проверяет, что пути файлов ответа относительные и не выходят за каталог файла сценариев.
+// Применяется к статусам из API администратора: иначе через файлы ответа можно прочитать любой файл песочницы.
+func (stc StepConfig) checkLocalPaths() error {
+	for _, fc := range stc.Files {
+		if fc.Path != "" && !filepath.IsLocal(fc.Path) {
+			return fmt.Errorf("статус %d: путь к файлу '%s' должен быть относительным и не выходить за каталог файла сценариев", stc.StatusId, fc.Path)
+		}
+	}
+	return nil
+}
+
+// fault - возвращает ошибку метода API ЕПГУ.
+func (fc FaultConfig) fault() (epgutest.Fault, error) {
+	if _, ok := ops[fc.Op]; !ok {
+		return epgutest.Fault{}, fmt.Errorf("неизвестный метод API ЕПГУ: '%s'", fc.Op)
+	}
+	return epgutest.Fault{
+		Status:   fc.Status,
+		Code:     fc.Code,
+		Message:  fc.Message,
+		Delay:    time.Duration(fc.Delay),
+		Times:    fc.Times,
+		Duration: time.Duration(fc.Duration),
+	}, nil
+}
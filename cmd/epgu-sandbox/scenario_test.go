@@ -0,0 +1,326 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/epgutest"
+)
+
+const testToken = "test-token"
+
+func TestScenario(t *testing.T) {
+	suite.Run(t, new(suiteScenario))
+}
+
+type suiteScenario struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *suiteScenario) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.dir, "files"), 0o755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.dir, "files", "resp.pdf"), []byte("%PDF"), 0o644))
+}
+
+// writeConfig - записывает файл сценариев во временный каталог и возвращает путь к нему.
+func (suite *suiteScenario) writeConfig(data string) string {
+	path := filepath.Join(suite.dir, "scenarios.json")
+	suite.Require().NoError(os.WriteFile(path, []byte(data), 0o644))
+	return path
+}
+
+// testArchive - архив заявления для отправки в песочницу.
+func testArchive() *apipgu.Archive {
+	archive, err := apipgu.NewArchive("test-archive", apipgu.ArchiveFile{Filename: "req.xml", Data: []byte("<req/>")})
+	if err != nil {
+		panic(err)
+	}
+	return archive
+}
+
+func (suite *suiteScenario) TestLoadConfigExample() {
+	cfg, err := loadConfig("scenarios.example.json")
+	suite.Require().NoError(err)
+	suite.Len(cfg.Services, 2)
+	suite.Len(cfg.Faults, 2)
+	suite.Equal(Duration(30*time.Second), cfg.Services["10000000109"].Steps[0].Delay)
+	suite.Equal(Duration(2*time.Second), cfg.Faults[1].Delay)
+
+	now := time.Now()
+	server := epgutest.NewServer().WithClock(func() time.Time { return now })
+	defer server.Close()
+	suite.Require().NoError(cfg.apply(server, "."))
+	client := apipgu.NewClient(server.URL)
+
+	suite.Run("faults", func() {
+		for i := 0; i < 3; i++ {
+			_, err := client.OrderCreate(testToken, apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"})
+			suite.ErrorIs(err, apipgu.ErrStatusTooManyRequests)
+		}
+		_, err := client.OrderCreate(testToken, apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"})
+		suite.NoError(err)
+	})
+
+	suite.Run("steps with delays", func() {
+		meta := apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"}
+		orderId, err := client.OrderPush(testToken, meta, testArchive())
+		suite.Require().NoError(err)
+
+		// даты статусов после отправки возрастают на 1 мс при остановленных часах
+		now = now.Add(30*time.Second + time.Second)
+		suite.Require().NotNil(server.Order(orderId))
+		suite.Equal(2, server.Order(orderId).OrderStatusId)
+
+		now = now.Add(2 * time.Minute)
+		details := server.Order(orderId)
+		suite.Equal(3, details.OrderStatusId)
+		suite.True(details.Closed)
+		suite.Require().Len(details.OrderResponseFiles, 1)
+		suite.Equal("resp.xml", details.OrderResponseFiles[0].FileName)
+	})
+
+	suite.Run("processing code", func() {
+		meta := apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000110", TargetCode: "-10000000110"}
+		orderId, err := client.OrderPush(testToken, meta, testArchive())
+		suite.Require().NoError(err)
+		info, err := client.OrderInfo(testToken, orderId)
+		suite.Require().NoError(err)
+		suite.Equal("VALIDATION_ERROR", info.Code)
+		suite.Equal("Вложения не прошли валидацию", info.Message)
+	})
+
+	suite.Run("dicts", func() {
+		items, total, err := client.Dict("EXTERNAL_BIC", apipgu.DictFilterOneLevel, "", 0, 0)
+		suite.Require().NoError(err)
+		suite.Equal(1, total)
+		suite.Equal([]apipgu.DictItem{{Value: "044525225", Title: "ПАО Сбербанк"}}, items)
+	})
+}
+
+func (suite *suiteScenario) TestLoadConfigErrors() {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"malformed json", `{"services":`},
+		{"wrong type", `{"tokens":"sandbox-token"}`},
+		{"malformed duration", `{"faults":[{"op":"OrderCreate","delay":"30"}]}`},
+		{"duration as number", `{"faults":[{"op":"OrderCreate","delay":30}]}`},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			path := suite.writeConfig(tt.config)
+			cfg, err := loadConfig(path)
+			suite.Error(err)
+			suite.ErrorContains(err, path)
+			suite.Nil(cfg)
+		})
+	}
+
+	suite.Run("file not found", func() {
+		_, err := loadConfig(filepath.Join(suite.dir, "not-found.json"))
+		suite.ErrorIs(err, os.ErrNotExist)
+	})
+}
+
+func (suite *suiteScenario) TestApply() {
+	suite.Run("success", func() {
+		cfg, err := loadConfig(suite.writeConfig(`{
+			"tokens": ["sandbox-token"],
+			"services": {"1": {"steps": [{"statusId": 3, "files": [{"path": "files/resp.pdf"}]}]}},
+			"faults": [{"op": "OrderInfo", "status": 502}]
+		}`))
+		suite.Require().NoError(err)
+		server := epgutest.NewServer()
+		defer server.Close()
+		suite.Require().NoError(cfg.apply(server, suite.dir))
+
+		client := apipgu.NewClient(server.URL)
+		_, err = client.OrderCreate(testToken, apipgu.OrderMeta{Region: "45000000000", ServiceCode: "1", TargetCode: "-1"})
+		suite.ErrorIs(err, apipgu.ErrStatusUnauthorized)
+		orderId, err := client.OrderPush("sandbox-token", apipgu.OrderMeta{Region: "45000000000", ServiceCode: "1", TargetCode: "-1"}, testArchive())
+		suite.Require().NoError(err)
+		_, err = client.OrderInfo("sandbox-token", orderId)
+		suite.ErrorIs(err, apipgu.ErrStatusBadGateway)
+	})
+
+	suite.Run("unknown op", func() {
+		cfg := &Config{Faults: []FaultConfig{{Op: "Unknown", Status: http.StatusBadGateway}}}
+		suite.ErrorContains(cfg.apply(epgutest.New(), suite.dir), "неизвестный метод API ЕПГУ: 'Unknown'")
+	})
+
+	suite.Run("file not found", func() {
+		cfg := &Config{Services: map[string]ScenarioConfig{
+			"1": {Steps: []StepConfig{{StatusId: 3, Files: []FileConfig{{Path: "files/not-found.pdf"}}}}},
+		}}
+		err := cfg.apply(epgutest.New(), suite.dir)
+		suite.ErrorIs(err, os.ErrNotExist)
+		suite.ErrorContains(err, "услуга 1: статус 3")
+	})
+
+	suite.Run("cancel step file not found", func() {
+		cfg := &Config{Services: map[string]ScenarioConfig{
+			"1": {Cancel: &StepConfig{StatusId: 4, Files: []FileConfig{{Path: "not-found.pdf"}}}},
+		}}
+		suite.ErrorIs(cfg.apply(epgutest.New(), suite.dir), os.ErrNotExist)
+	})
+}
+
+func (suite *suiteScenario) TestStep() {
+	abs := filepath.Join(suite.dir, "files", "resp.pdf")
+	tests := []struct {
+		name    string
+		config  StepConfig
+		want    epgutest.Step
+		wantErr error
+	}{
+		{
+			name: "fields",
+			config: StepConfig{
+				StatusId: 3, Title: "Исполнено", Comment: "Комментарий", Final: true, CancelAllowed: true,
+				Delay: Duration(time.Minute),
+			},
+			want: epgutest.Step{
+				StatusId: 3, Title: "Исполнено", Comment: "Комментарий", Final: true, CancelAllowed: true,
+				Delay: time.Minute,
+			},
+		},
+		{
+			name:   "file data",
+			config: StepConfig{StatusId: 3, Files: []FileConfig{{Name: "resp.xml", MimeType: "application/xml", Data: "<resp/>", HasDigitalSignature: true}}},
+			want:   epgutest.Step{StatusId: 3, Files: []epgutest.File{{Name: "resp.xml", MimeType: "application/xml", Data: []byte("<resp/>"), HasDigitalSignature: true}}},
+		},
+		{
+			name:   "relative path, name from path",
+			config: StepConfig{StatusId: 3, Files: []FileConfig{{Path: "files/resp.pdf"}}},
+			want:   epgutest.Step{StatusId: 3, Files: []epgutest.File{{Name: "resp.pdf", Data: []byte("%PDF")}}},
+		},
+		{
+			name:   "absolute path",
+			config: StepConfig{StatusId: 3, Files: []FileConfig{{Name: "answer.pdf", Path: abs}}},
+			want:   epgutest.Step{StatusId: 3, Files: []epgutest.File{{Name: "answer.pdf", Data: []byte("%PDF")}}},
+		},
+		{
+			name:    "file not found",
+			config:  StepConfig{StatusId: 3, Files: []FileConfig{{Path: "files/not-found.pdf"}}},
+			wantErr: os.ErrNotExist,
+		},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			step, err := tt.config.step(suite.dir)
+			if tt.wantErr != nil {
+				suite.ErrorIs(err, tt.wantErr)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tt.want, step)
+		})
+	}
+}
+
+func (suite *suiteScenario) TestCheckLocalPaths() {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"", false},
+		{"resp.pdf", false},
+		{"files/resp.pdf", false},
+		{"files/../resp.pdf", false},
+		{"/etc/passwd", true},
+		{"../scenarios.json", true},
+		{"files/../../secret", true},
+		{"..", true},
+	}
+	for _, tt := range tests {
+		err := StepConfig{StatusId: 3, Files: []FileConfig{{Data: "<resp/>"}, {Path: tt.path}}}.checkLocalPaths()
+		suite.Equal(tt.wantErr, err != nil, "%q: %v", tt.path, err)
+	}
+}
+
+func (suite *suiteScenario) TestFault() {
+	tests := []struct {
+		name    string
+		config  FaultConfig
+		want    epgutest.Fault
+		wantErr bool
+	}{
+		{
+			name: "all fields",
+			config: FaultConfig{
+				Op: epgutest.OpPush, Status: http.StatusConflict, Code: "limitation_exception", Message: "Превышены ограничения",
+				Delay: Duration(time.Second), Times: 5, Duration: Duration(time.Minute),
+			},
+			want: epgutest.Fault{
+				Status: http.StatusConflict, Code: "limitation_exception", Message: "Превышены ограничения",
+				Delay: time.Second, Times: 5, Duration: time.Minute,
+			},
+		},
+		{
+			name:   "delay only",
+			config: FaultConfig{Op: epgutest.OpPushChunked, Delay: Duration(3 * time.Second)},
+			want:   epgutest.Fault{Delay: 3 * time.Second},
+		},
+		{name: "unknown op", config: FaultConfig{Op: "OrderDelete", Status: http.StatusBadGateway}, wantErr: true},
+		{name: "empty op", config: FaultConfig{Status: http.StatusBadGateway}, wantErr: true},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			fault, err := tt.config.fault()
+			if tt.wantErr {
+				suite.Error(err)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tt.want, fault)
+		})
+	}
+
+	suite.Run("all ops", func() {
+		for op := range ops {
+			_, err := FaultConfig{Op: op}.fault()
+			suite.NoError(err, op)
+		}
+	})
+}
+
+func (suite *suiteScenario) TestDuration() {
+	tests := []struct {
+		json    string
+		want    Duration
+		wantErr bool
+	}{
+		{`"30s"`, Duration(30 * time.Second), false},
+		{`"2m"`, Duration(2 * time.Minute), false},
+		{`"1h30m"`, Duration(90 * time.Minute), false},
+		{`""`, 0, false},
+		{`"30"`, 0, true},
+		{`"abc"`, 0, true},
+		{`30`, 0, true},
+	}
+	for _, tt := range tests {
+		var d Duration
+		err := json.Unmarshal([]byte(tt.json), &d)
+		suite.Equal(tt.wantErr, err != nil, tt.json)
+		suite.Equal(tt.want, d, tt.json)
+	}
+
+	suite.Run("round trip", func() {
+		data, err := json.Marshal(StepConfig{StatusId: 2, Delay: Duration(90 * time.Second)})
+		suite.Require().NoError(err)
+		suite.Contains(string(data), `"delay":"1m30s"`)
+		var stc StepConfig
+		suite.Require().NoError(json.Unmarshal(data, &stc))
+		suite.Equal(Duration(90*time.Second), stc.Delay)
+	})
+}
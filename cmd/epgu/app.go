@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/environment"
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+// Форматы вывода.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// app - клиент API ЕПГУ и параметры вывода для подкоманд.
+type app struct {
+	env     Environment
+	client  *apipgu.Client
+	output  string
+	stdout  io.Writer
+	har     *utils.HAR
+	harPath string
+}
+
+// newApp - для готовой среды клиент создается с ее лимитами и проверкой контура маркера доступа,
+// см environment.Environment.EPGUClient. Дополнительные корневые сертификаты задаются
+// переменной окружения EPGU_ROOT_CA.
+func newApp(env Environment, output string, verbose bool, harPath string) (*app, error) {
+	a := &app{
+		env:     env,
+		client:  apipgu.NewClient(env.BaseURI),
+		output:  output,
+		stdout:  os.Stdout,
+		harPath: harPath,
+	}
+	if env.Preset != "" {
+		preset, err := environment.Preset(env.Preset)
+		if err != nil {
+			return nil, err
+		}
+		preset.EPGUBaseURI = env.BaseURI
+		preset.RootCAFile = os.Getenv("EPGU_ROOT_CA")
+		if a.client, err = preset.EPGUClient(); err != nil {
+			return nil, err
+		}
+	}
+	if verbose {
+		a.client.WithDebug(log.New(os.Stderr, "", log.LstdFlags))
+	}
+	if harPath != "" {
+		a.har = utils.NewHAR()
+		a.client.WithHAR(a.har)
+	}
+	return a, nil
+}
+
+// token - маркер доступа среды.
+func (a *app) token() (string, error) {
+	return a.env.token()
+}
+
+// print - выводит v в формате JSON либо таблицей с помощью функции table.
+func (a *app) print(v any, table func(w io.Writer)) error {
+	if a.output == outputJSON {
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// saveHAR - записывает HAR-файл, если задан флаг -har.
+func (a *app) saveHAR() error {
+	if a.har == nil {
+		return nil
+	}
+	if err := a.har.Save(a.harPath); err != nil {
+		return fmt.Errorf("ошибка записи HAR-файла: %w", err)
+	}
+	return nil
+}
+
+// row - выводит строку таблицы с колонками, разделенными табуляцией.
+func row(w io.Writer, columns ...any) {
+	for i, col := range columns {
+		if i > 0 {
+			_, _ = fmt.Fprint(w, "\t")
+		}
+		_, _ = fmt.Fprint(w, col)
+	}
+	_, _ = fmt.Fprintln(w)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ofstudio/go-api-epgu/environment"
+)
+
+// Config - файл конфигурации утилиты.
+type Config struct {
+	Default      string                 `json:"default"`      // Среда по умолчанию
+	Environments map[string]Environment `json:"environments"` // Среды по названиям
+}
+
+// Environment - среда API ЕПГУ.
+type Environment struct {
+	Preset    string `json:"preset"`     // Готовая среда, см environment.Preset
+	BaseURI   string `json:"base_uri"`   // Адрес API ЕПГУ; по умолчанию - адрес готовой среды
+	TokenFile string `json:"token_file"` // Файл с маркером доступа; ~ - домашний каталог
+}
+
+// loadConfig - читает файл конфигурации path.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// resolveEnvironment - определяет среду по флагам, переменным окружения и файлу конфигурации.
+// Среда, отсутствующая в файле конфигурации, ищется среди готовых сред (svcdev, prod и др.).
+// Адрес baseURI и файл tokenFile, если указаны, заменяют значения среды из файла конфигурации.
+func resolveEnvironment(configPath, envName, baseURI, tokenFile string) (Environment, error) {
+	env := Environment{}
+	found := false
+	if configPath != "" {
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return env, err
+		}
+		if envName == "" {
+			envName = cfg.Default
+		}
+		env, found = cfg.Environments[envName]
+	}
+	if !found && envName != "" {
+		if _, err := environment.Preset(envName); err != nil {
+			return env, err
+		}
+		env = Environment{Preset: envName}
+	}
+	if env.Preset != "" && env.BaseURI == "" {
+		preset, err := environment.Preset(env.Preset)
+		if err != nil {
+			return env, err
+		}
+		env.BaseURI = preset.EPGUBaseURI
+	}
+
+	if baseURI != "" {
+		env.BaseURI = baseURI
+	}
+	if tokenFile != "" {
+		env.TokenFile = tokenFile
+	}
+	if env.BaseURI == "" {
+		return env, errors.New("не задан адрес API ЕПГУ: укажите -env, -base-uri, EPGU_BASE_URI или -config")
+	}
+	env.BaseURI = strings.TrimSuffix(env.BaseURI, "/")
+	return env, nil
+}
+
+// token - маркер доступа из переменной окружения EPGU_TOKEN или из файла среды.
+func (env Environment) token() (string, error) {
+	if token := os.Getenv("EPGU_TOKEN"); token != "" {
+		return token, nil
+	}
+	if env.TokenFile == "" {
+		return "", errors.New("не задан маркер доступа: укажите EPGU_TOKEN или -token-file")
+	}
+	path := env.TokenFile
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, rest)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения маркера доступа: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("пустой маркер доступа в файле %s", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/environment"
+)
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(suiteConfig))
+}
+
+type suiteConfig struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *suiteConfig) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.T().Setenv("EPGU_CONFIG", "")
+	suite.T().Setenv("EPGU_ENV", "")
+	suite.T().Setenv("EPGU_BASE_URI", "")
+	suite.T().Setenv("EPGU_TOKEN", "")
+}
+
+func (suite *suiteConfig) SetupSubTest() {
+	suite.SetupTest()
+}
+
+// writeFile - записывает файл name во временный каталог и возвращает путь к нему.
+func (suite *suiteConfig) writeFile(name, data string) string {
+	path := filepath.Join(suite.dir, name)
+	suite.Require().NoError(os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+// writeConfig - файл конфигурации со средами dev (готовая среда), local (адрес) и dev-uri (готовая среда с адресом).
+func (suite *suiteConfig) writeConfig() string {
+	return suite.writeFile("epgu.json", `{
+		"default": "dev",
+		"environments": {
+			"dev": {"preset": "svcdev", "token_file": "dev.token"},
+			"local": {"base_uri": "http://127.0.0.1:18090/"},
+			"dev-uri": {"preset": "svcdev", "base_uri": "http://127.0.0.1:18091"},
+			"bad": {"preset": "unknown"}
+		}
+	}`)
+}
+
+func (suite *suiteConfig) TestResolveEnvironment() {
+	config := suite.writeConfig()
+	tests := []struct {
+		name       string
+		configPath string
+		envName    string
+		baseURI    string
+		tokenFile  string
+		want       Environment
+	}{
+		{"preset", "", "svcdev", "", "",
+			Environment{Preset: "svcdev", BaseURI: environment.SVCDEV.EPGUBaseURI}},
+		{"base-uri only", "", "", "http://127.0.0.1:8090/", "",
+			Environment{BaseURI: "http://127.0.0.1:8090"}},
+		{"base-uri over preset", "", "prod", "http://127.0.0.1:8090", "",
+			Environment{Preset: "prod", BaseURI: "http://127.0.0.1:8090"}},
+		{"config default", config, "", "", "",
+			Environment{Preset: "svcdev", BaseURI: environment.SVCDEV.EPGUBaseURI, TokenFile: "dev.token"}},
+		{"config env", config, "local", "", "",
+			Environment{BaseURI: "http://127.0.0.1:18090"}},
+		{"config base_uri over preset", config, "dev-uri", "", "",
+			Environment{Preset: "svcdev", BaseURI: "http://127.0.0.1:18091"}},
+		{"base-uri over config", config, "dev-uri", "http://127.0.0.1:8090", "",
+			Environment{Preset: "svcdev", BaseURI: "http://127.0.0.1:8090"}},
+		{"token-file over config", config, "", "", "other.token",
+			Environment{Preset: "svcdev", BaseURI: environment.SVCDEV.EPGUBaseURI, TokenFile: "other.token"}},
+		{"config env over preset name", suite.writeFile("sandbox.json", `{"environments": {"sandbox": {"base_uri": "http://127.0.0.1:18092"}}}`), "sandbox", "", "",
+			Environment{BaseURI: "http://127.0.0.1:18092"}},
+		{"preset missing in config", config, "prod", "", "",
+			Environment{Preset: "prod", BaseURI: environment.Production.EPGUBaseURI}},
+	}
+	for _, tt := range tests {
+		env, err := resolveEnvironment(tt.configPath, tt.envName, tt.baseURI, tt.tokenFile)
+		suite.NoError(err, tt.name)
+		suite.Equal(tt.want, env, tt.name)
+	}
+}
+
+func (suite *suiteConfig) TestResolveEnvironmentErrors() {
+	config := suite.writeConfig()
+	tests := []struct {
+		name       string
+		configPath string
+		envName    string
+		err        error
+		contains   string
+	}{
+		{"nothing", "", "", nil, "не задан адрес API ЕПГУ"},
+		{"unknown env", "", "unknown", environment.ErrUnknownEnvironment, ""},
+		{"unknown env in config", config, "unknown", environment.ErrUnknownEnvironment, ""},
+		{"unknown preset in config", config, "bad", environment.ErrUnknownEnvironment, ""},
+		{"config not found", filepath.Join(suite.dir, "not-found.json"), "", os.ErrNotExist, ""},
+		{"malformed config", suite.writeFile("bad.json", `{"default": 1}`), "", nil, "bad.json"},
+		{"no default in config", suite.writeFile("empty.json", `{"environments": {}}`), "", nil, "не задан адрес API ЕПГУ"},
+	}
+	for _, tt := range tests {
+		_, err := resolveEnvironment(tt.configPath, tt.envName, "", "")
+		suite.Require().Error(err, tt.name)
+		if tt.err != nil {
+			suite.ErrorIs(err, tt.err, tt.name)
+		}
+		suite.Contains(err.Error(), tt.contains, tt.name)
+	}
+}
+
+func (suite *suiteConfig) TestOptions() {
+	suite.Run("env variables", func() {
+		suite.T().Setenv("EPGU_CONFIG", "env.json")
+		suite.T().Setenv("EPGU_ENV", "prod")
+		suite.T().Setenv("EPGU_BASE_URI", "http://env")
+		fs := flag.NewFlagSet("epgu", flag.ContinueOnError)
+		opts := newOptions(fs)
+		suite.Require().NoError(fs.Parse([]string{"order", "info", "1"}))
+		suite.Equal("env.json", *opts.configPath)
+		suite.Equal("prod", *opts.envName)
+		suite.Equal("http://env", *opts.baseURI)
+		suite.Equal(outputTable, *opts.output)
+	})
+
+	suite.Run("flags over env variables", func() {
+		suite.T().Setenv("EPGU_CONFIG", "env.json")
+		suite.T().Setenv("EPGU_ENV", "prod")
+		suite.T().Setenv("EPGU_BASE_URI", "http://env")
+		fs := flag.NewFlagSet("epgu", flag.ContinueOnError)
+		opts := newOptions(fs)
+		suite.Require().NoError(fs.Parse([]string{"-config", "flag.json", "-env", "svcdev", "-base-uri", "http://flag", "-o", "json", "order", "info", "1"}))
+		suite.Equal("flag.json", *opts.configPath)
+		suite.Equal("svcdev", *opts.envName)
+		suite.Equal("http://flag", *opts.baseURI)
+		suite.Equal(outputJSON, *opts.output)
+		suite.Equal([]string{"order", "info", "1"}, fs.Args())
+	})
+}
+
+func (suite *suiteConfig) TestToken() {
+	suite.Run("from file", func() {
+		env := Environment{TokenFile: suite.writeFile("dev.token", " test-token\n")}
+		token, err := env.token()
+		suite.NoError(err)
+		suite.Equal("test-token", token)
+	})
+
+	suite.Run("EPGU_TOKEN over file", func() {
+		suite.T().Setenv("EPGU_TOKEN", "env-token")
+		env := Environment{TokenFile: suite.writeFile("dev.token", "test-token")}
+		token, err := env.token()
+		suite.NoError(err)
+		suite.Equal("env-token", token)
+	})
+
+	suite.Run("home dir", func() {
+		suite.T().Setenv("HOME", suite.dir)
+		suite.writeFile("home.token", "home-token")
+		token, err := Environment{TokenFile: "~/home.token"}.token()
+		suite.NoError(err)
+		suite.Equal("home-token", token)
+	})
+
+	suite.Run("errors", func() {
+		_, err := Environment{}.token()
+		suite.ErrorContains(err, "не задан маркер доступа")
+		_, err = Environment{TokenFile: filepath.Join(suite.dir, "not-found.token")}.token()
+		suite.ErrorIs(err, os.ErrNotExist)
+		_, err = Environment{TokenFile: suite.writeFile("empty.token", "\n")}.token()
+		suite.ErrorContains(err, "пустой маркер доступа")
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// dictPage - страница справочника.
+type dictPage struct {
+	Total int               `json:"total"`
+	Items []apipgu.DictItem `json:"items"`
+}
+
+// dictGet - epgu dict get [-filter] [-parent] [-page] [-size] <code>
+func dictGet(a *app, args []string) error {
+	fs := newFlagSet("dict get")
+	filter := dictFilter(fs)
+	var (
+		parent   = fs.String("parent", "", "код родительского элемента")
+		pageNum  = fs.Int("page", 0, "номер страницы")
+		pageSize = fs.Int("size", 0, "количество элементов на странице; 0 - не передавать")
+	)
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if err := checkDictFilter(*filter); err != nil {
+		return err
+	}
+
+	items, total, err := a.client.Dict(fs.Arg(0), *filter, *parent, *pageNum, *pageSize)
+	if err != nil {
+		return err
+	}
+	return a.print(dictPage{Total: total, Items: items}, dictTable(items, total))
+}
+
+// dictDump - epgu dict dump [-filter] [-parent] [-size] <code>
+func dictDump(a *app, args []string) error {
+	fs := newFlagSet("dict dump")
+	filter := dictFilter(fs)
+	var (
+		parent   = fs.String("parent", "", "код родительского элемента")
+		pageSize = fs.Int("size", 1000, "количество элементов в одном запросе")
+	)
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if err := checkDictFilter(*filter); err != nil {
+		return err
+	}
+	if *pageSize < 1 {
+		return fmt.Errorf("%w: -size должен быть больше 0", errUsage)
+	}
+
+	var (
+		all   []apipgu.DictItem
+		total int
+	)
+	for pageNum := 1; ; pageNum++ {
+		items, n, err := a.client.Dict(fs.Arg(0), *filter, *parent, pageNum, *pageSize)
+		if err != nil {
+			return err
+		}
+		total = n
+		all = append(all, items...)
+		// справочники без постраничного вывода возвращают все элементы сразу
+		if len(items) < *pageSize || len(all) >= total {
+			break
+		}
+	}
+	return a.print(dictPage{Total: total, Items: all}, dictTable(all, total))
+}
+
+func dictFilter(fs *flag.FlagSet) *string {
+	return fs.String("filter", apipgu.DictFilterOneLevel,
+		fmt.Sprintf("тип справочника: %s или %s", apipgu.DictFilterOneLevel, apipgu.DictFilterSubTree))
+}
+
+func checkDictFilter(filter string) error {
+	if filter != apipgu.DictFilterOneLevel && filter != apipgu.DictFilterSubTree {
+		return fmt.Errorf("%w: неизвестный тип справочника '%s'", errUsage, filter)
+	}
+	return nil
+}
+
+// dictTable - таблица элементов справочника.
+func dictTable(items []apipgu.DictItem, total int) func(w io.Writer) {
+	return func(w io.Writer) {
+		row(w, "КОД", "НАИМЕНОВАНИЕ", "РОДИТЕЛЬ")
+		for _, item := range items {
+			row(w, item.Value, strings.TrimSpace(item.Title), item.ParentValue)
+		}
+		row(w)
+		row(w, fmt.Sprintf("Элементов: %d из %d", len(items), total))
+	}
+}
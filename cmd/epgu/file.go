@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// downloaded - скачанный файл.
+type downloaded struct {
+	Link string `json:"link"`
+	Path string `json:"path"`
+	Size int    `json:"size"`
+}
+
+// fileDownload - epgu file download [-dir каталог] [-out файл] <link>... | -order <orderId>
+func fileDownload(a *app, args []string) error {
+	fs := newFlagSet("file download")
+	var (
+		dir     = fs.String("dir", ".", "каталог для файлов")
+		out     = fs.String("out", "", "файл для единственной ссылки; - вывести в stdout")
+		orderId = fs.Int("order", 0, "скачать все файлы ответа заявления")
+		all     = fs.Bool("all", false, "вместе с -order: также файлы, отправленные с заявлением")
+	)
+	if err := parseArgs(fs, args, -1); err != nil {
+		return err
+	}
+	if (*orderId == 0) == (fs.NArg() == 0) {
+		return fmt.Errorf("%w: укажите ссылки на файлы или -order", errUsage)
+	}
+	if *out != "" && fs.NArg() != 1 {
+		return fmt.Errorf("%w: -out допустим только для одной ссылки", errUsage)
+	}
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	links := fs.Args()
+	if *orderId != 0 {
+		info, err := a.client.OrderInfo(token, *orderId)
+		if err != nil {
+			return err
+		}
+		if info.Order != nil {
+			for _, f := range info.Order.OrderResponseFiles {
+				links = append(links, f.Link)
+			}
+			if *all {
+				for _, f := range info.Order.OrderAttachmentFiles {
+					links = append(links, f.Link)
+				}
+			}
+		}
+	}
+
+	files := make([]downloaded, 0, len(links))
+	for _, link := range links {
+		data, err := a.client.AttachmentDownload(token, link)
+		if err != nil {
+			return err
+		}
+		if *out == "-" {
+			_, err = a.stdout.Write(data)
+			return err
+		}
+		path := *out
+		if path == "" {
+			path = filepath.Join(*dir, linkFilename(link))
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err = os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+		files = append(files, downloaded{Link: link, Path: path, Size: len(data)})
+	}
+
+	return a.print(files, func(w io.Writer) {
+		row(w, "ФАЙЛ", "РАЗМЕР", "ССЫЛКА")
+		for _, f := range files {
+			row(w, f.Path, f.Size, f.Link)
+		}
+	})
+}
+
+// linkFilename - имя файла (mnemonic) из ссылки вида
+// "terrabyte://00/1230254874/req_8d8567db-d445-4759-a122-6b4cefeca22c.xml/2".
+func linkFilename(link string) string {
+	parts := strings.Split(link, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" {
+		return "file"
+	}
+	return filepath.Base(parts[len(parts)-2])
+}
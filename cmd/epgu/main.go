@@ -0,0 +1,181 @@
+// Утилита командной строки для работы с API ЕПГУ: статусы и детали заявлений,
+// отправка архива заявления, отмена, скачивание файлов и справочники.
+//
+// # Запуск
+//
+//	epgu [флаги] <команда> <подкоманда> [флаги подкоманды] [аргументы]
+//
+//	epgu order info 1230254874
+//	epgu order status 1230254874 1230254875
+//	epgu order updated-since 24h
+//	epgu order push -service-code 10000000109 -target-code -10000000109 -region 45000000000 ./order
+//	epgu order cancel 1230254874
+//	epgu file download -order 1230254874 -dir ./files
+//	epgu dict get -filter ONELEVEL EXTERNAL_BIC
+//	epgu -o json dict dump EXTERNAL_BIC > bic.json
+//
+// # Маркер доступа
+//
+// Маркер доступа ЕСИА передается через переменную окружения EPGU_TOKEN либо читается из файла:
+// флаг -token-file или параметр token_file среды в файле конфигурации.
+//
+// # Среды
+//
+// Адрес API ЕПГУ задается флагом -base-uri, переменной окружения EPGU_BASE_URI,
+// готовой средой пакета environment (svcdev, svcdev-gost, prod, sandbox)
+// или средой из файла конфигурации (флаг -config или переменная окружения EPGU_CONFIG), см Config:
+//
+//	{
+//	  "default": "dev",
+//	  "environments": {
+//	    "dev": {"preset": "svcdev", "token_file": "~/.epgu/dev.token"},
+//	    "local": {"base_uri": "http://127.0.0.1:18090"}
+//	  }
+//	}
+//
+// Среда выбирается флагом -env или переменной окружения EPGU_ENV; по умолчанию - среда default.
+// Для готовых сред маркер доступа тестового контура ЕСИА не отправляется на продуктовый контур ЕПГУ
+// и наоборот. Для среды prod файл корневых сертификатов НУЦ Минцифры России задается
+// переменной окружения EPGU_ROOT_CA.
+//
+// # Вывод
+//
+// По умолчанию результат выводится таблицей, с флагом -o json - в формате JSON.
+// Флаг -v включает журналирование запросов и ответов в stderr, флаг -har записывает их в HAR-файл.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// errUsage - ошибка в аргументах командной строки.
+var errUsage = errors.New("неверные аргументы")
+
+// command - подкоманда утилиты.
+type command struct {
+	name  string
+	usage string
+	run   func(app *app, args []string) error
+}
+
+// groups - команды утилиты и их подкоманды.
+var groups = []struct {
+	name     string
+	commands []command
+}{
+	{"order", []command{
+		{"info", "<orderId> - детали заявления", orderInfo},
+		{"status", "<orderId>... - текущие статусы заявлений; - читать номера из stdin", orderStatus},
+		{"updated-since", "<время> - статусы заявлений, обновленных после даты и времени или за период (24h)", orderUpdatedSince},
+		{"push", "<каталог|архив.zip> - создание заявления и отправка архива", orderPush},
+		{"cancel", "<orderId> - отмена заявления", orderCancel},
+	}},
+	{"file", []command{
+		{"download", "<link>... | -order <orderId> - скачивание файлов заявления", fileDownload},
+	}},
+	{"dict", []command{
+		{"get", "<code> - страница справочника", dictGet},
+		{"dump", "<code> - все элементы справочника", dictDump},
+	}},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("epgu: ")
+
+	opts := newOptions(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+
+	if *opts.output != outputTable && *opts.output != outputJSON {
+		log.Fatalf("неизвестный формат вывода: %s", *opts.output)
+	}
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := findCommand(args[0], args[1])
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	env, err := resolveEnvironment(*opts.configPath, *opts.envName, *opts.baseURI, *opts.tokenFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app, err := newApp(env, *opts.output, *opts.verbose, *opts.harPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = cmd.run(app, args[2:])
+	if saveErr := app.saveHAR(); saveErr != nil {
+		log.Print(saveErr)
+	}
+	if errors.Is(err, errUsage) {
+		log.Print(err)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// options - общие флаги утилиты.
+type options struct {
+	configPath *string
+	envName    *string
+	baseURI    *string
+	tokenFile  *string
+	output     *string
+	verbose    *bool
+	harPath    *string
+}
+
+// newOptions - определяет общие флаги в fs. Значения по умолчанию для -config, -env и -base-uri
+// берутся из переменных окружения EPGU_CONFIG, EPGU_ENV и EPGU_BASE_URI: флаг важнее переменной.
+func newOptions(fs *flag.FlagSet) *options {
+	return &options{
+		configPath: fs.String("config", os.Getenv("EPGU_CONFIG"), "файл конфигурации (JSON)"),
+		envName:    fs.String("env", os.Getenv("EPGU_ENV"), "среда из файла конфигурации или готовая среда: svcdev, svcdev-gost, prod, sandbox"),
+		baseURI:    fs.String("base-uri", os.Getenv("EPGU_BASE_URI"), "адрес API ЕПГУ (вместо среды)"),
+		tokenFile:  fs.String("token-file", "", "файл с маркером доступа (вместо EPGU_TOKEN)"),
+		output:     fs.String("o", outputTable, "формат вывода: table или json"),
+		verbose:    fs.Bool("v", false, "журналировать запросы и ответы в stderr"),
+		harPath:    fs.String("har", "", "записать запросы и ответы в HAR-файл"),
+	}
+}
+
+func findCommand(group, name string) (command, bool) {
+	for _, g := range groups {
+		if g.name != group {
+			continue
+		}
+		for _, cmd := range g.commands {
+			if cmd.name == name {
+				return cmd, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintln(out, "Использование: epgu [флаги] <команда> <подкоманда> [флаги подкоманды] [аргументы]")
+	_, _ = fmt.Fprintln(out, "\nКоманды:")
+	for _, g := range groups {
+		for _, cmd := range g.commands {
+			_, _ = fmt.Fprintf(out, "  %s %s %s\n", g.name, cmd.name, cmd.usage)
+		}
+	}
+	_, _ = fmt.Fprintln(out, "\nФлаги:")
+	flag.PrintDefaults()
+}
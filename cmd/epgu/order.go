@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// timeLayout - формат даты и времени в таблицах.
+const timeLayout = "2006-01-02 15:04:05"
+
+// orderInfo - epgu order info <orderId>
+func orderInfo(a *app, args []string) error {
+	fs := newFlagSet("order info")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	orderId, err := parseOrderId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	info, err := a.client.OrderInfo(token, orderId)
+	if err != nil {
+		return err
+	}
+	return a.print(info, func(w io.Writer) {
+		if info.Order == nil {
+			row(w, "Код", info.Code)
+			row(w, "Сообщение", info.Message)
+			return
+		}
+		o := info.Order
+		row(w, "Номер", o.Id)
+		row(w, "Услуга", o.ServiceName)
+		row(w, "Статус", fmt.Sprintf("%d %s", o.OrderStatusId, o.OrderStatusName))
+		row(w, "Создано", formatTime(o.OrderDate.Time))
+		row(w, "Обновлено", formatTime(o.Updated.Time))
+		for _, f := range o.OrderAttachmentFiles {
+			row(w, "Файл заявления", f.FileName, f.Link)
+		}
+		for _, f := range o.OrderResponseFiles {
+			row(w, "Файл ответа", f.FileName, f.Link)
+		}
+	})
+}
+
+// orderStatus - epgu order status [-batch n] <orderId>... | -
+func orderStatus(a *app, args []string) error {
+	fs := newFlagSet("order status")
+	batch := fs.Int("batch", 100, "количество номеров заявлений в одном запросе")
+	if err := parseArgs(fs, args, -1); err != nil {
+		return err
+	}
+	if *batch < 1 {
+		return fmt.Errorf("%w: -batch должен быть больше 0", errUsage)
+	}
+
+	values := fs.Args()
+	if len(values) == 1 && values[0] == "-" {
+		var err error
+		if values, err = readWords(os.Stdin); err != nil {
+			return err
+		}
+	}
+	orderIds := make([]int, 0, len(values))
+	for _, value := range values {
+		orderId, err := parseOrderId(value)
+		if err != nil {
+			return err
+		}
+		orderIds = append(orderIds, orderId)
+	}
+	if len(orderIds) == 0 {
+		return fmt.Errorf("%w: не указаны номера заявлений", errUsage)
+	}
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	records := make([]apipgu.OrderStatusRecord, 0, len(orderIds))
+	for start := 0; start < len(orderIds); start += *batch {
+		end := min(start+*batch, len(orderIds))
+		res, err := a.client.OrdersStatus(token, orderIds[start:end], 0, end-start)
+		if err != nil {
+			return err
+		}
+		records = append(records, res.Content...)
+	}
+	return a.print(records, statusTable(records))
+}
+
+// orderUpdatedSince - epgu order updated-since [-page-size n] <время>
+func orderUpdatedSince(a *app, args []string) error {
+	fs := newFlagSet("order updated-since")
+	pageSize := fs.Int("page-size", 100, "количество записей в одном запросе")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if *pageSize < 1 {
+		return fmt.Errorf("%w: -page-size должен быть больше 0", errUsage)
+	}
+	after, err := parseSince(fs.Arg(0), time.Now())
+	if err != nil {
+		return err
+	}
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	records := []apipgu.OrderStatusRecord{}
+	for pageNum := 0; ; pageNum++ {
+		res, err := a.client.OrdersUpdatedAfter(token, after, pageNum, *pageSize)
+		if err != nil {
+			return err
+		}
+		records = append(records, res.Content...)
+		if len(res.Content) == 0 || len(records) >= res.TotalCount {
+			break
+		}
+	}
+	return a.print(records, statusTable(records))
+}
+
+// orderPush - epgu order push [флаги] <каталог|архив.zip>
+func orderPush(a *app, args []string) error {
+	fs := newFlagSet("order push")
+	var (
+		serviceCode = fs.String("service-code", "", "идентификатор формы заявления (serviceCode)")
+		targetCode  = fs.String("target-code", "", "идентификатор цели обращения (targetCode)")
+		region      = fs.String("region", "", "код ОКАТО местоположения пользователя")
+		name        = fs.String("name", "", "имя архива; по умолчанию - имя каталога или файла")
+		chunkSize   = fs.Int("chunk-size", apipgu.DefaultChunkSize, "размер части архива в байтах")
+		single      = fs.Bool("single", false, "отправить архив одним запросом (OrderPush)")
+	)
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	meta := apipgu.OrderMeta{Region: *region, ServiceCode: *serviceCode, TargetCode: *targetCode}
+	if meta.Region == "" || meta.ServiceCode == "" || meta.TargetCode == "" {
+		return fmt.Errorf("%w: укажите -service-code, -target-code и -region", errUsage)
+	}
+	archive, err := readArchive(fs.Arg(0), *name)
+	if err != nil {
+		return err
+	}
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	var orderId int
+	if *single {
+		orderId, err = a.client.OrderPush(token, meta, archive)
+	} else {
+		a.client.WithChunkSize(*chunkSize)
+		if orderId, err = a.client.OrderCreate(token, meta); err == nil {
+			err = a.client.OrderPushChunked(token, orderId, archive)
+		}
+	}
+	if err != nil {
+		if orderId != 0 {
+			return fmt.Errorf("заявление %d: %w", orderId, err)
+		}
+		return err
+	}
+
+	return a.print(map[string]int{"orderId": orderId}, func(w io.Writer) {
+		row(w, "Номер заявления", orderId)
+	})
+}
+
+// orderCancel - epgu order cancel <orderId>
+func orderCancel(a *app, args []string) error {
+	fs := newFlagSet("order cancel")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	orderId, err := parseOrderId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	if err = a.client.OrderCancel(token, orderId); err != nil {
+		return err
+	}
+	return a.print(map[string]any{"orderId": orderId, "cancelled": true}, func(w io.Writer) {
+		row(w, "Заявление отменено", orderId)
+	})
+}
+
+// statusTable - таблица статусов заявлений.
+func statusTable(records []apipgu.OrderStatusRecord) func(w io.Writer) {
+	return func(w io.Writer) {
+		row(w, "ЗАЯВЛЕНИЕ", "ПОИСК", "КОД", "СТАТУС", "ОБНОВЛЕН")
+		for _, r := range records {
+			if r.Status == nil {
+				row(w, r.OrderId, r.OrderSearchStatus, "-", "-", "-")
+				continue
+			}
+			row(w, r.OrderId, r.OrderSearchStatus, r.Status.StatusId, r.Status.StatusName, formatTime(r.Status.Updated.Time))
+		}
+	}
+}
+
+// readArchive - архив заявления из каталога с файлами вложения или из готового zip-архива.
+func readArchive(path, name string) (*apipgu.Archive, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if !stat.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &apipgu.Archive{Name: name, Data: data}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []apipgu.ArchiveFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, apipgu.ArchiveFile{Filename: entry.Name(), Data: data})
+	}
+	return apipgu.NewArchive(name, files...)
+}
+
+// parseSince - дата и время в формате RFC 3339, "2006-01-02T15:04:05" или "2006-01-02"
+// (московское время) либо период до now, например "24h".
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, apipgu.Moscow); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: некорректные дата и время '%s'", errUsage, value)
+}
+
+func parseOrderId(value string) (int, error) {
+	orderId, err := strconv.Atoi(value)
+	if err != nil || orderId <= 0 {
+		return 0, fmt.Errorf("%w: некорректный номер заявления '%s'", errUsage, value)
+	}
+	return orderId, nil
+}
+
+// readWords - слова из r, разделенные пробелами и переводами строк.
+func readWords(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	return words, scanner.Err()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.In(apipgu.Moscow).Format(timeLayout)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseArgs - разбирает флаги подкоманды и проверяет количество аргументов n; -1 - любое количество.
+func parseArgs(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errUsage
+		}
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if n >= 0 && fs.NArg() != n {
+		return fmt.Errorf("%w: %s: ожидается аргументов: %d", errUsage, fs.Name(), n)
+	}
+	return nil
+}
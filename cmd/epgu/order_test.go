@@ -0,0 +1,179 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/epgutest"
+)
+
+const testToken = "test-token"
+
+var testMeta = apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"}
+
+func TestOrder(t *testing.T) {
+	suite.Run(t, new(suiteOrder))
+}
+
+type suiteOrder struct {
+	suite.Suite
+}
+
+func (suite *suiteOrder) TestParseSince() {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"24h", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"90m", time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"0s", now},
+		{"2024-02-01T10:00:00Z", time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)},
+		{"2024-02-01T10:00:00+05:00", time.Date(2024, 2, 1, 5, 0, 0, 0, time.UTC)},
+		{"2024-02-01T10:00:00", time.Date(2024, 2, 1, 7, 0, 0, 0, time.UTC)},
+		{"2024-02-01", time.Date(2024, 1, 31, 21, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		suite.NoError(err, tt.value)
+		suite.True(tt.want.Equal(got), "%s: want %s, got %s", tt.value, tt.want, got)
+	}
+
+	for _, value := range []string{"", "yesterday", "24", "2024-02-30", "01.02.2024", "2024-02-01 10:00:00"} {
+		_, err := parseSince(value, now)
+		suite.ErrorIs(err, errUsage, value)
+	}
+}
+
+func (suite *suiteOrder) TestReadArchive() {
+	suite.Run("directory", func() {
+		dir := filepath.Join(suite.T().TempDir(), "order")
+		suite.Require().NoError(os.MkdirAll(filepath.Join(dir, "subdir"), 0o755))
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, "req.xml"), []byte("<req/>"), 0o644))
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, "trans.xml"), []byte("<trans/>"), 0o644))
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, ".DS_Store"), []byte("hidden"), 0o644))
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, "subdir", "nested.xml"), []byte("<nested/>"), 0o644))
+
+		archive, err := readArchive(dir, "")
+		suite.Require().NoError(err)
+		suite.Equal("order", archive.Name)
+		suite.Equal(map[string]string{"req.xml": "<req/>", "trans.xml": "<trans/>"}, suite.unzip(archive.Data))
+
+		archive, err = readArchive(dir, "custom")
+		suite.Require().NoError(err)
+		suite.Equal("custom", archive.Name)
+	})
+
+	suite.Run("zip file", func() {
+		path := filepath.Join(suite.T().TempDir(), "order.zip")
+		suite.Require().NoError(os.WriteFile(path, []byte("zip-data"), 0o644))
+
+		archive, err := readArchive(path, "")
+		suite.Require().NoError(err)
+		suite.Equal(&apipgu.Archive{Name: "order", Data: []byte("zip-data")}, archive)
+	})
+
+	suite.Run("empty directory", func() {
+		dir := suite.T().TempDir()
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, ".hidden"), []byte("hidden"), 0o644))
+		_, err := readArchive(dir, "")
+		suite.ErrorIs(err, apipgu.ErrNoFiles)
+	})
+
+	suite.Run("not found", func() {
+		_, err := readArchive(filepath.Join(suite.T().TempDir(), "not-found"), "")
+		suite.ErrorIs(err, os.ErrNotExist)
+	})
+}
+
+// unzip - файлы zip-архива по именам.
+func (suite *suiteOrder) unzip(data []byte) map[string]string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	suite.Require().NoError(err)
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		suite.Require().NoError(err)
+		content, err := io.ReadAll(rc)
+		suite.Require().NoError(err)
+		_ = rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func (suite *suiteOrder) TestOrderUpdatedSince() {
+	suite.T().Setenv("EPGU_TOKEN", testToken)
+	server := epgutest.NewServer()
+	defer server.Close()
+
+	client := apipgu.NewClient(server.URL)
+	archive, err := apipgu.NewArchive("order", apipgu.ArchiveFile{Filename: "req.xml", Data: []byte("<req/>")})
+	suite.Require().NoError(err)
+	var pushed []int
+	for i := 0; i < 5; i++ {
+		orderId, err := client.OrderPush(testToken, testMeta, archive)
+		suite.Require().NoError(err)
+		pushed = append(pushed, orderId)
+	}
+	_, err = client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+
+	tests := []struct {
+		name     string
+		pageSize string
+		requests int
+	}{
+		{"several pages", "2", 3},
+		{"exact pages", "5", 1},
+		{"single page", "100", 1},
+		{"one per page", "1", 5},
+	}
+	for _, tt := range tests {
+		before := server.Requests(epgutest.OpUpdatedAfter)
+		records := suite.updatedSince(server.URL, "-page-size", tt.pageSize, "1h")
+		suite.Equal(tt.requests, server.Requests(epgutest.OpUpdatedAfter)-before, tt.name)
+
+		var orderIds []int
+		for _, r := range records {
+			orderIds = append(orderIds, r.OrderId)
+		}
+		suite.ElementsMatch(pushed, orderIds, tt.name)
+	}
+
+	suite.Run("nothing updated", func() {
+		before := server.Requests(epgutest.OpUpdatedAfter)
+		records := suite.updatedSince(server.URL, "-page-size", "2", time.Now().Add(time.Hour).Format(time.RFC3339))
+		suite.Empty(records)
+		suite.Equal(1, server.Requests(epgutest.OpUpdatedAfter)-before)
+	})
+
+	suite.Run("usage errors", func() {
+		a := &app{env: Environment{BaseURI: server.URL}, client: apipgu.NewClient(server.URL), output: outputJSON, stdout: io.Discard}
+		suite.ErrorIs(orderUpdatedSince(a, []string{"-page-size", "0", "1h"}), errUsage)
+		suite.ErrorIs(orderUpdatedSince(a, []string{"yesterday"}), errUsage)
+		suite.ErrorIs(orderUpdatedSince(a, nil), errUsage)
+	})
+}
+
+// updatedSince - выполняет epgu -o json order updated-since args и возвращает выведенные записи.
+func (suite *suiteOrder) updatedSince(baseURI string, args ...string) []apipgu.OrderStatusRecord {
+	a, err := newApp(Environment{BaseURI: baseURI}, outputJSON, false, "")
+	suite.Require().NoError(err)
+	out := &bytes.Buffer{}
+	a.stdout = out
+	suite.Require().NoError(orderUpdatedSince(a, args))
+
+	var records []apipgu.OrderStatusRecord
+	suite.Require().NoError(json.Unmarshal(out.Bytes(), &records))
+	return records
+}
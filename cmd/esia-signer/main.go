@@ -0,0 +1,115 @@
+// Эталонный сервис подписи запросов к ЕСИА для провайдера signature.Remote.
+//
+// Подписывает данные на чистом Go (signature.GOST) ключом из PEM PKCS#8 или из контейнера КриптоПро.
+// Позволяет проверить весь процесс взаимодействия с ЕСИА на одной машине,
+// а также держать закрытый ключ на отдельном сервере.
+//
+// # Запуск
+//
+//	esia-signer -key key.pem -cert cert.cer -addr 127.0.0.1:8080
+//	esia-signer -container /media/flash/abcdefgh.000 -addr :8443 \
+//	    -tls-cert server.crt -tls-key server.key -client-ca ca.crt
+//
+// PIN-код контейнера и токен авторизации передаются через переменные окружения
+// ESIA_SIGNER_PIN и ESIA_SIGNER_TOKEN, чтобы не оставлять их в истории команд.
+//
+// # Эндпоинты
+//   - POST /sign - подпись данных или хэш-кода и хэш сертификата подписи, см signature.RemoteSignRequest
+//   - GET /cert-hash - хэш сертификата, см signature.RemoteCertHashResponse
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", "127.0.0.1:8080", "адрес сервиса")
+		keyPath   = flag.String("key", "", "закрытый ключ в PEM-формате PKCS#8")
+		container = flag.String("container", "", "каталог контейнера КриптоПро (вместо -key)")
+		certPath  = flag.String("cert", "", "сертификат (DER или PEM) для расчета хэша сертификата")
+		certHash  = flag.String("cert-hash", "", "хэш сертификата (вместо -cert)")
+		tlsCert   = flag.String("tls-cert", "", "сертификат TLS сервиса")
+		tlsKey    = flag.String("tls-key", "", "закрытый ключ TLS сервиса")
+		clientCA  = flag.String("client-ca", "", "сертификаты УЦ клиентов для mTLS (PEM)")
+	)
+	flag.Parse()
+
+	provider, err := newProvider(*keyPath, *container, os.Getenv("ESIA_SIGNER_PIN"), *certPath, *certHash)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if provider.CertHash() == "" {
+		log.Print("ВНИМАНИЕ: хэш сертификата не задан: укажите -cert или -cert-hash")
+	}
+
+	token := os.Getenv("ESIA_SIGNER_TOKEN")
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           signature.NewRemoteHandler(provider, token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if *clientCA != "" {
+		pool, err := loadCertPool(*clientCA)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server.TLSConfig = &tls.Config{ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	}
+
+	log.Printf("esia-signer: %s, хэш сертификата: %s", *addr, provider.CertHash())
+	if *tlsCert != "" {
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		if *clientCA != "" {
+			log.Fatal("для -client-ca необходимо указать -tls-cert и -tls-key")
+		}
+		err = server.ListenAndServe()
+	}
+	log.Fatal(err)
+}
+
+// newProvider - создает провайдер подписи по параметрам командной строки.
+func newProvider(keyPath, container, pin, certPath, certHash string) (signature.Provider, error) {
+	var signer *signature.GOST
+	var err error
+	switch {
+	case keyPath != "" && container != "":
+		return nil, errors.New("укажите только один из параметров -key или -container")
+	case keyPath != "":
+		signer, err = signature.NewGOSTFromFile(keyPath, certHash)
+	case container != "":
+		signer, err = signature.NewGOSTFromContainer(container, pin)
+	default:
+		return nil, errors.New("не указан закрытый ключ: -key или -container")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if certPath != "" {
+		return signature.WithCertFile(signer, certPath)
+	}
+	return signer, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("сертификаты УЦ не найдены: " + path)
+	}
+	return pool, nil
+}
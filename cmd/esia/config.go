@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+// Config - файл конфигурации утилиты.
+type Config struct {
+	BaseURI      string          `json:"base_uri"`               // Адрес ЕСИА
+	ClientId     string          `json:"client_id"`              // Мнемоника ИС
+	RedirectURI  string          `json:"redirect_uri"`           // Адрес локального обработчика, зарегистрированный для ИС
+	Scope        string          `json:"scope"`                  // Скоупы через пробел
+	Organization string          `json:"organization,omitempty"` // Название организации для согласия API ЕПГУ (вместо permissions)
+	Permissions  aas.Permissions `json:"permissions,omitempty"`  // Запрашиваемые права доступа
+	Signer       SignerConfig    `json:"signer"`                 // Провайдер подписи
+	TokenDir     string          `json:"token_dir,omitempty"`    // Каталог хранилища маркеров доступа; ~ - домашний каталог
+}
+
+// SignerConfig - параметры провайдера подписи запросов к ЕСИА.
+//
+// Типы провайдеров (type):
+//   - nop - фиксированная подпись signature (тестовые серверы, epgu-sandbox)
+//   - cryptopro - КриптоПро CSP: csptest и container
+//   - gost - ГОСТ на чистом Go: закрытый ключ key в PEM PKCS#8
+//   - container - ГОСТ на чистом Go: каталог контейнера КриптоПро container, PIN-код в ESIA_PIN
+//   - openssl - openssl с модулем ГОСТ: openssl и key
+//   - remote - сервис подписи url (например, esia-signer), токен в ESIA_SIGNER_TOKEN
+//
+// Хэш сертификата задается параметром cert_hash либо рассчитывается по сертификату cert.
+type SignerConfig struct {
+	Type      string `json:"type"`
+	Signature string `json:"signature,omitempty"`
+	CSPTest   string `json:"csptest,omitempty"`
+	Container string `json:"container,omitempty"`
+	Key       string `json:"key,omitempty"`
+	OpenSSL   string `json:"openssl,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Cert      string `json:"cert,omitempty"`
+	CertHash  string `json:"cert_hash,omitempty"`
+}
+
+// loadConfig - читает файл конфигурации path.
+func loadConfig(path string) (*Config, error) {
+	if path == "" {
+		return nil, errors.New("не задан файл конфигурации: укажите -config или ESIA_CONFIG")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.BaseURI == "" || cfg.ClientId == "" {
+		return nil, fmt.Errorf("%s: не заданы base_uri и client_id", path)
+	}
+	cfg.BaseURI = strings.TrimSuffix(cfg.BaseURI, "/")
+	cfg.TokenDir = expandHome(cfg.TokenDir)
+	return cfg, nil
+}
+
+// scopes - запрашиваемые скоупы; по умолчанию - openid.
+func (cfg *Config) scopes() aas.Scopes {
+	if strings.TrimSpace(cfg.Scope) == "" {
+		return aas.NewScopes(aas.ScopeOpenID)
+	}
+	return aas.NewScopes(aas.ParseScopes(cfg.Scope)...)
+}
+
+// permissions - запрашиваемые права доступа: permissions либо согласие API ЕПГУ для organization.
+func (cfg *Config) permissions() aas.Permissions {
+	if len(cfg.Permissions) > 0 || cfg.Organization == "" {
+		return cfg.Permissions
+	}
+	return aas.NewPermissions(aas.NewAPIPGUPermission(cfg.Organization))
+}
+
+// listenAddr - адрес локального обработчика redirect_uri и путь обработчика.
+func (cfg *Config) listenAddr() (string, string, error) {
+	u, err := url.Parse(cfg.RedirectURI)
+	if err != nil || u.Scheme != "http" || u.Host == "" {
+		return "", "", fmt.Errorf("redirect_uri должен быть локальным адресом http://, получено '%s'", cfg.RedirectURI)
+	}
+	host := u.Hostname()
+	if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+		return "", "", fmt.Errorf("redirect_uri должен указывать на localhost, получено '%s'", cfg.RedirectURI)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return host + ":" + port, path, nil
+}
+
+// client - клиент ЕСИА с провайдером подписи из конфигурации.
+func (cfg *Config) client() (*aas.Client, error) {
+	signer, err := cfg.Signer.provider()
+	if err != nil {
+		return nil, fmt.Errorf("провайдер подписи: %w", err)
+	}
+	client := aas.NewClient(cfg.BaseURI, cfg.ClientId, signer)
+	if cfg.RedirectURI != "" {
+		client.WithRedirectURIs(cfg.RedirectURI)
+	}
+	return client, nil
+}
+
+// store - хранилище маркеров доступа; ключ шифрования (base64) - в переменной окружения ESIA_TOKEN_KEY.
+func (cfg *Config) store() (*aas.FileTokenStore, error) {
+	if cfg.TokenDir == "" {
+		return nil, errors.New("не задан каталог хранилища маркеров доступа token_dir")
+	}
+	key, err := base64.StdEncoding.DecodeString(os.Getenv("ESIA_TOKEN_KEY"))
+	if err != nil || len(key) == 0 {
+		return nil, errors.New("не задан ключ хранилища маркеров доступа: ESIA_TOKEN_KEY (base64, 32 байта)")
+	}
+	return aas.NewFileTokenStore(cfg.TokenDir, key)
+}
+
+// provider - провайдер подписи.
+func (sc SignerConfig) provider() (signature.Provider, error) {
+	var (
+		provider signature.Provider
+		err      error
+	)
+	switch sc.Type {
+	case "nop":
+		provider = signature.NewNop(sc.Signature, sc.CertHash)
+	case "cryptopro":
+		if sc.CSPTest == "" || sc.Container == "" {
+			return nil, errors.New("для cryptopro укажите csptest и container")
+		}
+		provider = signature.NewLocalCryptoPro(sc.CSPTest, sc.Container, sc.CertHash)
+	case "gost":
+		provider, err = signature.NewGOSTFromFile(expandHome(sc.Key), sc.CertHash)
+	case "container":
+		provider, err = signature.NewGOSTFromContainer(expandHome(sc.Container), os.Getenv("ESIA_PIN"))
+	case "openssl":
+		if sc.OpenSSL == "" {
+			sc.OpenSSL = "openssl"
+		}
+		provider = signature.NewLocalOpenSSL(sc.OpenSSL, expandHome(sc.Key), sc.CertHash)
+	case "remote":
+		remote := signature.NewRemote(sc.URL).
+			WithBearerToken(os.Getenv("ESIA_SIGNER_TOKEN")).
+			WithTimeout(30 * time.Second)
+		if sc.CertHash != "" {
+			remote.WithCertHash(sc.CertHash)
+		} else if sc.Cert == "" {
+			_, err = remote.LoadCertHash()
+		}
+		provider = remote
+	case "":
+		return nil, errors.New("не указан тип провайдера signer.type")
+	default:
+		return nil, fmt.Errorf("неизвестный тип провайдера '%s'", sc.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sc.Cert != "" {
+		return signature.WithCertFile(provider, expandHome(sc.Cert))
+	}
+	return provider, nil
+}
+
+// expandHome - заменяет префикс ~/ домашним каталогом.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+const testSignerToken = "test-signer-token"
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(suiteConfig))
+}
+
+type suiteConfig struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *suiteConfig) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.T().Setenv("ESIA_SIGNER_TOKEN", testSignerToken)
+	suite.T().Setenv("ESIA_PIN", "")
+}
+
+func (suite *suiteConfig) SetupSubTest() {
+	suite.SetupTest()
+}
+
+func (suite *suiteConfig) TestListenAddr() {
+	tests := []struct {
+		redirectURI string
+		addr        string
+		path        string
+	}{
+		{"http://localhost:8000/callback", "localhost:8000", "/callback"},
+		{"http://127.0.0.1:8000/esia/callback", "127.0.0.1:8000", "/esia/callback"},
+		{"http://[::1]:8000/callback", "::1:8000", "/callback"},
+		{"http://localhost/callback", "localhost:80", "/callback"},
+		{"http://localhost:8000", "localhost:8000", "/"},
+	}
+	for _, tt := range tests {
+		addr, path, err := (&Config{RedirectURI: tt.redirectURI}).listenAddr()
+		suite.NoError(err, tt.redirectURI)
+		suite.Equal(tt.addr, addr, tt.redirectURI)
+		suite.Equal(tt.path, path, tt.redirectURI)
+	}
+
+	for _, redirectURI := range []string{
+		"",
+		"localhost:8000/callback",
+		"https://localhost:8000/callback",
+		"http://example.com:8000/callback",
+		"http://0.0.0.0:8000/callback",
+		"http://192.168.1.10:8000/callback",
+		"http://localhost.example.com/callback",
+		"http://%zz/callback",
+	} {
+		_, _, err := (&Config{RedirectURI: redirectURI}).listenAddr()
+		suite.ErrorContains(err, "redirect_uri должен", redirectURI)
+	}
+}
+
+func (suite *suiteConfig) TestProvider() {
+	suite.Run("nop", func() {
+		provider, err := SignerConfig{Type: "nop", Signature: "sig", CertHash: "HASH"}.provider()
+		suite.Require().NoError(err)
+		suite.IsType(&signature.Nop{}, provider)
+		suite.Equal("HASH", provider.CertHash())
+	})
+
+	suite.Run("nop with cert", func() {
+		cert := suite.writeCert()
+		want, err := signature.CertHashFromFile(cert)
+		suite.Require().NoError(err)
+
+		provider, err := SignerConfig{Type: "nop", Signature: "sig", CertHash: "IGNORED", Cert: cert}.provider()
+		suite.Require().NoError(err)
+		suite.Equal(want, provider.CertHash())
+	})
+
+	suite.Run("cryptopro", func() {
+		provider, err := SignerConfig{Type: "cryptopro", CSPTest: "/opt/cprocsp/bin/csptest", Container: "test", CertHash: "HASH"}.provider()
+		suite.Require().NoError(err)
+		suite.IsType(&signature.LocalCryptoPro{}, provider)
+		suite.Equal("HASH", provider.CertHash())
+	})
+
+	suite.Run("openssl", func() {
+		provider, err := SignerConfig{Type: "openssl", Key: "key.pem", CertHash: "HASH"}.provider()
+		suite.Require().NoError(err)
+		suite.IsType(&signature.LocalOpenSSL{}, provider)
+	})
+
+	suite.Run("remote with cert_hash", func() {
+		var requests atomic.Int32
+		server := suite.signer(&requests)
+		defer server.Close()
+
+		provider, err := SignerConfig{Type: "remote", URL: server.URL, CertHash: "HASH"}.provider()
+		suite.Require().NoError(err)
+		suite.IsType(&signature.Remote{}, provider)
+		suite.Equal("HASH", provider.CertHash())
+		suite.Equal(int32(0), requests.Load())
+	})
+
+	suite.Run("remote with cert", func() {
+		var requests atomic.Int32
+		server := suite.signer(&requests)
+		defer server.Close()
+		cert := suite.writeCert()
+		want, err := signature.CertHashFromFile(cert)
+		suite.Require().NoError(err)
+
+		provider, err := SignerConfig{Type: "remote", URL: server.URL, Cert: cert}.provider()
+		suite.Require().NoError(err)
+		suite.Equal(want, provider.CertHash())
+		suite.Equal(int32(0), requests.Load())
+	})
+
+	suite.Run("remote loads cert hash", func() {
+		var requests atomic.Int32
+		server := suite.signer(&requests)
+		defer server.Close()
+
+		provider, err := SignerConfig{Type: "remote", URL: server.URL}.provider()
+		suite.Require().NoError(err)
+		suite.Equal("REMOTE_HASH", provider.CertHash())
+		suite.Equal(int32(1), requests.Load())
+	})
+
+	suite.Run("remote wrong token", func() {
+		var requests atomic.Int32
+		server := suite.signer(&requests)
+		defer server.Close()
+		suite.T().Setenv("ESIA_SIGNER_TOKEN", "wrong")
+
+		_, err := SignerConfig{Type: "remote", URL: server.URL}.provider()
+		suite.ErrorIs(err, signature.ErrRemoteResponse)
+	})
+
+	suite.Run("errors", func() {
+		tests := []struct {
+			name     string
+			config   SignerConfig
+			err      error
+			contains string
+		}{
+			{"no type", SignerConfig{}, nil, "не указан тип провайдера"},
+			{"unknown type", SignerConfig{Type: "unknown"}, nil, "неизвестный тип провайдера 'unknown'"},
+			{"cryptopro without csptest", SignerConfig{Type: "cryptopro", Container: "test"}, nil, "укажите csptest и container"},
+			{"cryptopro without container", SignerConfig{Type: "cryptopro", CSPTest: "csptest"}, nil, "укажите csptest и container"},
+			{"gost key not found", SignerConfig{Type: "gost", Key: filepath.Join(suite.dir, "not-found.pem")}, signature.ErrKeyRead, ""},
+			{"container not found", SignerConfig{Type: "container", Container: filepath.Join(suite.dir, "not-found.000")}, signature.ErrContainerRead, ""},
+			{"cert not found", SignerConfig{Type: "nop", Cert: filepath.Join(suite.dir, "not-found.cer")}, signature.ErrCertRead, ""},
+			{"malformed cert", SignerConfig{Type: "nop", Cert: suite.writeFile("bad.cer", "not a certificate")}, signature.ErrCertParse, ""},
+		}
+		for _, tt := range tests {
+			provider, err := tt.config.provider()
+			suite.Nil(provider, tt.name)
+			suite.Require().Error(err, tt.name)
+			if tt.err != nil {
+				suite.ErrorIs(err, tt.err, tt.name)
+			}
+			suite.Contains(err.Error(), tt.contains, tt.name)
+		}
+	})
+}
+
+// signer - сервис подписи с хэшем сертификата REMOTE_HASH; requests - счетчик запросов.
+func (suite *suiteConfig) signer(requests *atomic.Int32) *httptest.Server {
+	handler := signature.NewRemoteHandler(signature.NewNop("sig", "REMOTE_HASH"), testSignerToken)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+// writeFile - записывает файл name во временный каталог и возвращает путь к нему.
+func (suite *suiteConfig) writeFile(name, data string) string {
+	path := filepath.Join(suite.dir, name)
+	suite.Require().NoError(os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+// writeCert - записывает самоподписанный сертификат в PEM и возвращает путь к нему.
+func (suite *suiteConfig) writeCert() string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	suite.Require().NoError(err)
+	return suite.writeFile("cert.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+}
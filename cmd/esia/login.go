@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+)
+
+// callbackResult - результат обратного вызова на redirect_uri.
+type callbackResult struct {
+	res *aas.TokenExchangeResponse
+	err error
+}
+
+// login - esia login [-token-out файл] [-no-browser] [-timeout 5m]
+func login(a *app, args []string) error {
+	fs := newFlagSet("login")
+	var (
+		tokenOut  = fs.String("token-out", "", "записать маркер доступа в файл (например, для epgu -token-file)")
+		noBrowser = fs.Bool("no-browser", false, "не открывать ссылку в браузере")
+		timeout   = fs.Duration("timeout", 5*time.Minute, "время ожидания обратного вызова")
+	)
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+	addr, path, err := cfg.listenAddr()
+	if err != nil {
+		return err
+	}
+	client, err := a.client(cfg)
+	if err != nil {
+		return err
+	}
+
+	scopes, permissions := cfg.scopes(), cfg.permissions()
+	authURI, err := client.AuthURI(scopes, cfg.RedirectURI, permissions)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(authURI)
+	if err != nil {
+		return err
+	}
+	state := u.Query().Get("state")
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("обработчик redirect_uri: %w", err)
+	}
+	results := make(chan callbackResult, 1)
+	mux := http.NewServeMux()
+	mux.Handle(path, callbackHandler(client, state, scopes, cfg.RedirectURI, results))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	log.Printf("ссылка на страницу предоставления прав доступа ЕСИА:\n%s", authURI)
+	if !*noBrowser {
+		if err = openBrowser(authURI); err != nil {
+			log.Printf("не удалось открыть браузер: %v; откройте ссылку вручную", err)
+		}
+	}
+	log.Printf("ожидание обратного вызова на %s", cfg.RedirectURI)
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-time.After(*timeout):
+		return errors.New("истекло время ожидания обратного вызова")
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	record, err := aas.NewTokenRecord(result.res, scopes, cfg.RedirectURI, permissions)
+	if err != nil {
+		return err
+	}
+	return a.saveToken(cfg, record, *tokenOut)
+}
+
+// callbackHandler - обработчик обратного вызова ЕСИА на redirect_uri для входа с параметром state:
+// обменивает код авторизации на маркер доступа и передает результат в results.
+// Запросы с другим state, в том числе с ошибкой ЕСИА, не относятся к текущему входу:
+// обработчик отвечает на них ошибкой и продолжает ожидание.
+func callbackHandler(client *aas.Client, state string, scopes aas.Scopes, redirectURI string, results chan<- callbackResult) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code, gotState, err := client.ParseCallback(r.URL.Query())
+		if gotState != state {
+			http.Error(w, "неизвестный параметр state", http.StatusBadRequest)
+			return
+		}
+		var res *aas.TokenExchangeResponse
+		if err == nil {
+			res, err = client.TokenExchange(code, scopes, redirectURI)
+		}
+		callbackPage(w, err)
+		select {
+		case results <- callbackResult{res: res, err: err}:
+		default:
+		}
+	}
+}
+
+// callbackPage - ответ браузеру на обратный вызов.
+func callbackPage(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	message := "Маркер доступа получен. Окно можно закрыть."
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		message = "Ошибка: " + err.Error()
+	}
+	_, _ = io.WriteString(w, "<!DOCTYPE html><html><body><p>"+html.EscapeString(message)+"</p></body></html>")
+}
+
+// openBrowser - открывает uri в браузере по умолчанию.
+func openBrowser(uri string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", uri)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", uri)
+	default:
+		cmd = exec.Command("xdg-open", uri)
+	}
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	return cmd.Start()
+}
+
+// client - клиент ЕСИА по конфигурации cfg.
+func (a *app) client(cfg *Config) (*aas.Client, error) {
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+	if a.verbose {
+		client.WithDebug(log.New(os.Stderr, "", log.LstdFlags))
+	}
+	return client, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/esiatest"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+const (
+	testClientId    = "TEST_CLIENT"
+	testRedirectURI = "http://localhost:8000/callback"
+)
+
+func TestLogin(t *testing.T) {
+	suite.Run(t, new(suiteLogin))
+}
+
+type suiteLogin struct {
+	suite.Suite
+	esia       *esiatest.Server
+	esiaServer *httptest.Server
+	tokens     atomic.Int32 // Количество запросов к ЕСИА за маркером доступа
+	client     *aas.Client
+	scopes     aas.Scopes
+	state      string
+	results    chan callbackResult
+	handler    http.Handler
+}
+
+func (suite *suiteLogin) SetupTest() {
+	suite.esia = esiatest.New().WithClient(testClientId, testRedirectURI)
+	suite.tokens.Store(0)
+	suite.esiaServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == aas.TokenEndpoint {
+			suite.tokens.Add(1)
+		}
+		suite.esia.ServeHTTP(w, r)
+	}))
+	suite.esia.URL = suite.esiaServer.URL
+	suite.client = aas.NewClient(suite.esia.URL, testClientId, signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash)).
+		WithRedirectURIs(testRedirectURI)
+	suite.scopes = aas.NewScopes(aas.ScopeOpenID)
+
+	authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+	suite.Require().NoError(err)
+	u, err := url.Parse(authURI)
+	suite.Require().NoError(err)
+	suite.state = u.Query().Get("state")
+	suite.Require().NotEmpty(suite.state)
+
+	suite.results = make(chan callbackResult, 1)
+	suite.handler = callbackHandler(suite.client, suite.state, suite.scopes, testRedirectURI, suite.results)
+}
+
+func (suite *suiteLogin) TearDownTest() {
+	suite.esiaServer.Close()
+}
+
+func (suite *suiteLogin) SetupSubTest() {
+	suite.TearDownTest()
+	suite.SetupTest()
+}
+
+// authorize - параметры обратного вызова от ЕСИА для входа suite.state.
+func (suite *suiteLogin) authorize() url.Values {
+	authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+	suite.Require().NoError(err)
+	query, err := suite.esia.Authorize(authURI)
+	suite.Require().NoError(err)
+	query.Set("state", suite.state)
+	return query
+}
+
+// callback - обратный вызов на redirect_uri с параметрами query. Возвращает HTTP-код ответа.
+func (suite *suiteLogin) callback(query url.Values) int {
+	w := httptest.NewRecorder()
+	suite.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?"+query.Encode(), nil))
+	return w.Code
+}
+
+// result - результат входа либо nil, если обработчик его не передал.
+func (suite *suiteLogin) result() *callbackResult {
+	select {
+	case result := <-suite.results:
+		return &result
+	default:
+		return nil
+	}
+}
+
+func (suite *suiteLogin) TestCallback() {
+	suite.Run("success", func() {
+		suite.Equal(http.StatusOK, suite.callback(suite.authorize()))
+		result := suite.result()
+		suite.Require().NotNil(result)
+		suite.Require().NoError(result.err)
+		suite.True(suite.esia.Valid(result.res.AccessToken))
+		suite.Equal(int32(1), suite.tokens.Load())
+	})
+
+	suite.Run("state mismatch", func() {
+		query := suite.authorize()
+		query.Set("state", "00000000-0000-0000-0000-000000000000")
+		suite.Equal(http.StatusBadRequest, suite.callback(query))
+		suite.Nil(suite.result())
+		suite.Equal(int32(0), suite.tokens.Load())
+
+		// вход продолжается: обратный вызов с верным state обрабатывается
+		suite.Equal(http.StatusOK, suite.callback(suite.authorize()))
+		result := suite.result()
+		suite.Require().NotNil(result)
+		suite.NoError(result.err)
+	})
+
+	suite.Run("no state", func() {
+		query := suite.authorize()
+		query.Del("state")
+		suite.Equal(http.StatusBadRequest, suite.callback(query))
+		suite.Nil(suite.result())
+		suite.Equal(int32(0), suite.tokens.Load())
+	})
+
+	suite.Run("ESIA error with other state", func() {
+		query := url.Values{
+			"state":             {"00000000-0000-0000-0000-000000000000"},
+			"error":             {"access_denied"},
+			"error_description": {"ESIA-007004: The resource owner or authorization server denied the request"},
+		}
+		suite.Equal(http.StatusBadRequest, suite.callback(query))
+		suite.Nil(suite.result())
+	})
+
+	suite.Run("ESIA error", func() {
+		suite.esia.WithDecision(func(esiatest.AuthRequest) esiatest.Decision { return esiatest.Decision{Deny: true} })
+		suite.Equal(http.StatusBadRequest, suite.callback(suite.authorize()))
+		result := suite.result()
+		suite.Require().NotNil(result)
+		suite.ErrorIs(result.err, aas.ErrParseCallback)
+		suite.ErrorIs(result.err, aas.ErrESIA_007004)
+		suite.Equal(int32(0), suite.tokens.Load())
+	})
+
+	suite.Run("code reused", func() {
+		query := suite.authorize()
+		suite.Equal(http.StatusOK, suite.callback(query))
+		suite.Require().NotNil(suite.result())
+
+		suite.Equal(http.StatusBadRequest, suite.callback(query))
+		result := suite.result()
+		suite.Require().NotNil(result)
+		suite.ErrorIs(result.err, aas.ErrTokenExchange)
+	})
+}
@@ -0,0 +1,180 @@
+// Утилита командной строки для получения маркеров доступа ЕСИА без написания кода.
+//
+// # Запуск
+//
+//	esia [флаги] <команда> [флаги команды] [аргументы]
+//
+//	esia -config esia.json login -token-out ~/.epgu/dev.token
+//	esia -config esia.json refresh -oid 1000572618
+//	esia decode eyJhbGciOi...
+//	esia hash-cert cert.cer
+//
+// # Команды
+//   - login - открывает в браузере ссылку на страницу предоставления прав доступа ЕСИА (Client.AuthURI),
+//     принимает обратный вызов на локальном redirect_uri, обменивает код на маркер доступа
+//     и сохраняет его в хранилище маркеров и (или) в файл
+//   - refresh - обновляет маркер доступа пользователя из хранилища (Client.TokenUpdate)
+//   - decode - выводит утверждения маркера доступа
+//   - hash-cert - рассчитывает хэш сертификата (client_certificate_hash)
+//
+// # Конфигурация
+//
+// Параметры ИС и провайдер подписи задаются в файле конфигурации (флаг -config или переменная
+// окружения ESIA_CONFIG), см Config и SignerConfig:
+//
+//	{
+//	  "base_uri": "https://esia-portal1.test.gosuslugi.ru",
+//	  "client_id": "MNEMONIC",
+//	  "redirect_uri": "http://localhost:8000/callback",
+//	  "scope": "openid http://lk.gosuslugi.ru/api-order",
+//	  "organization": "ООО Ромашка",
+//	  "signer": {"type": "cryptopro", "csptest": "/opt/cprocsp/bin/csptest", "container": "X9X1XYZA9EZZWZ42", "cert": "cert.cer"},
+//	  "token_dir": "~/.esia/tokens"
+//	}
+//
+// Адрес redirect_uri должен быть зарегистрирован для ИС на Технологическом портале ЕСИА
+// и указывать на localhost: на время выполнения команды login на нем запускается обработчик.
+//
+// Секреты передаются через переменные окружения, чтобы не хранить их в файле конфигурации:
+//   - ESIA_TOKEN_KEY - ключ хранилища маркеров доступа (base64, 32 байта)
+//   - ESIA_PIN - PIN-код контейнера КриптоПро для провайдера container
+//   - ESIA_SIGNER_TOKEN - токен сервиса подписи для провайдера remote
+//
+// Флаг -v включает журналирование запросов и ответов в stderr, флаг -o json - вывод в формате JSON.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+)
+
+// errUsage - ошибка в аргументах командной строки.
+var errUsage = errors.New("неверные аргументы")
+
+// Форматы вывода.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// app - параметры, общие для команд.
+type app struct {
+	configPath string
+	output     string
+	verbose    bool
+	stdout     io.Writer
+}
+
+// command - команда утилиты.
+type command struct {
+	name  string
+	usage string
+	run   func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"login", "[-token-out файл] [-no-browser] - получение маркера доступа", login},
+	{"refresh", "-oid <oid> [-token-out файл] - обновление маркера доступа из хранилища", refresh},
+	{"decode", "[маркер | -oid <oid>] - утверждения маркера доступа; без аргументов - из stdin", decode},
+	{"hash-cert", "<сертификат>... - хэш сертификата (DER или PEM)", hashCert},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("esia: ")
+
+	a := &app{stdout: os.Stdout}
+	flag.StringVar(&a.configPath, "config", os.Getenv("ESIA_CONFIG"), "файл конфигурации (JSON)")
+	flag.StringVar(&a.output, "o", outputTable, "формат вывода: table или json")
+	flag.BoolVar(&a.verbose, "v", false, "журналировать запросы и ответы в stderr")
+	flag.Usage = usage
+	flag.Parse()
+
+	if a.output != outputTable && a.output != outputJSON {
+		log.Fatalf("неизвестный формат вывода: %s", a.output)
+	}
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(a, flag.Args()[1:])
+	if errors.Is(err, errUsage) {
+		log.Print(err)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// print - выводит v в формате JSON либо таблицей с помощью функции table.
+func (a *app) print(v any, table func(w io.Writer)) error {
+	if a.output == outputJSON {
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// row - выводит строку таблицы с колонками, разделенными табуляцией.
+func row(w io.Writer, columns ...any) {
+	for i, col := range columns {
+		if i > 0 {
+			_, _ = fmt.Fprint(w, "\t")
+		}
+		_, _ = fmt.Fprint(w, col)
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseArgs - разбирает флаги команды и проверяет количество аргументов n; -1 - любое количество.
+func parseArgs(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errUsage
+		}
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if n >= 0 && fs.NArg() != n {
+		return fmt.Errorf("%w: %s: ожидается аргументов: %d", errUsage, fs.Name(), n)
+	}
+	return nil
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintln(out, "Использование: esia [флаги] <команда> [флаги команды] [аргументы]")
+	_, _ = fmt.Fprintln(out, "\nКоманды:")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(out, "  %s %s\n", cmd.name, cmd.usage)
+	}
+	_, _ = fmt.Fprintln(out, "\nФлаги:")
+	flag.PrintDefaults()
+}
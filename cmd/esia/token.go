@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+// timeLayout - формат даты и времени в таблицах.
+const timeLayout = "2006-01-02 15:04:05 -0700"
+
+// tokenResult - результат команд login и refresh.
+type tokenResult struct {
+	OID         string    `json:"oid"`
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Scope       string    `json:"scope"`
+	Stored      bool      `json:"stored"`               // Запись сохранена в хранилище token_dir
+	TokenFile   string    `json:"token_file,omitempty"` // Файл с маркером доступа
+}
+
+// saveToken - сохраняет запись в хранилище (если задан token_dir) и маркер доступа в файл tokenOut.
+func (a *app) saveToken(cfg *Config, record *aas.TokenRecord, tokenOut string) error {
+	result := tokenResult{
+		OID:         record.OID,
+		AccessToken: record.AccessToken,
+		ExpiresAt:   record.ExpiresAt,
+		Scope:       record.Scope.String(),
+		TokenFile:   tokenOut,
+	}
+	if cfg.TokenDir != "" {
+		store, err := cfg.store()
+		if err != nil {
+			return err
+		}
+		if err = store.Save(record); err != nil {
+			return err
+		}
+		result.Stored = true
+	}
+	if tokenOut != "" {
+		if err := os.WriteFile(expandHome(tokenOut), []byte(record.AccessToken+"\n"), 0o600); err != nil {
+			return err
+		}
+	}
+
+	return a.print(result, func(w io.Writer) {
+		row(w, "OID", result.OID)
+		row(w, "Действует до", result.ExpiresAt.Format(timeLayout))
+		row(w, "Скоупы", result.Scope)
+		if result.Stored {
+			row(w, "Хранилище", cfg.TokenDir)
+		}
+		if result.TokenFile != "" {
+			row(w, "Файл", result.TokenFile)
+		}
+		if !result.Stored && result.TokenFile == "" {
+			row(w, "Маркер доступа", result.AccessToken)
+		}
+	})
+}
+
+// refresh - esia refresh -oid <oid> [-token-out файл]
+func refresh(a *app, args []string) error {
+	fs := newFlagSet("refresh")
+	var (
+		oid      = fs.String("oid", "", "OID пользователя")
+		tokenOut = fs.String("token-out", "", "записать маркер доступа в файл")
+	)
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *oid == "" {
+		return fmt.Errorf("%w: не указан -oid", errUsage)
+	}
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+	store, err := cfg.store()
+	if err != nil {
+		return err
+	}
+	record, err := store.Load(*oid)
+	if err != nil {
+		return err
+	}
+	client, err := a.client(cfg)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.TokenUpdate(record.OID, record.RedirectURI)
+	if err != nil {
+		return err
+	}
+	if err = record.Update(res); err != nil {
+		return err
+	}
+	return a.saveToken(cfg, record, *tokenOut)
+}
+
+// decode - esia decode [маркер | -oid <oid>]
+func decode(a *app, args []string) error {
+	fs := newFlagSet("decode")
+	oid := fs.String("oid", "", "маркер доступа пользователя из хранилища")
+	if err := parseArgs(fs, args, -1); err != nil {
+		return err
+	}
+
+	var token string
+	switch {
+	case *oid != "" && fs.NArg() > 0:
+		return fmt.Errorf("%w: укажите маркер доступа или -oid", errUsage)
+	case *oid != "":
+		cfg, err := loadConfig(a.configPath)
+		if err != nil {
+			return err
+		}
+		store, err := cfg.store()
+		if err != nil {
+			return err
+		}
+		record, err := store.Load(*oid)
+		if err != nil {
+			return err
+		}
+		token = record.AccessToken
+	case fs.NArg() == 1:
+		token = fs.Arg(0)
+	case fs.NArg() == 0:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		token = strings.TrimSpace(string(data))
+	default:
+		return fmt.Errorf("%w: ожидается один маркер доступа", errUsage)
+	}
+
+	claims, err := aas.ParseTokenClaims(token)
+	if err != nil {
+		return err
+	}
+	return a.print(claims, func(w io.Writer) {
+		row(w, "OID", claims.OID())
+		row(w, "ИС", claims.ClientId)
+		row(w, "Выдан", claims.Issuer)
+		row(w, "Скоупы", claims.Scope)
+		row(w, "Сессия", claims.SessionId)
+		row(w, "Время выдачи", unixTime(claims.IssuedAt))
+		row(w, "Действует с", unixTime(claims.NotBefore))
+		row(w, "Действует до", unixTime(claims.ExpiresAt))
+		if expiry := claims.Expiry(); !expiry.IsZero() {
+			if left := time.Until(expiry); left > 0 {
+				row(w, "Осталось", left.Round(time.Second))
+			} else {
+				row(w, "Осталось", "срок действия истек")
+			}
+		}
+	})
+}
+
+// certHashResult - хэш сертификата.
+type certHashResult struct {
+	Path     string `json:"path"`
+	CertHash string `json:"cert_hash"`
+}
+
+// hashCert - esia hash-cert <сертификат>...
+func hashCert(a *app, args []string) error {
+	fs := newFlagSet("hash-cert")
+	if err := parseArgs(fs, args, -1); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: не указан файл сертификата", errUsage)
+	}
+
+	results := make([]certHashResult, 0, fs.NArg())
+	var errs []error
+	for _, path := range fs.Args() {
+		hash, err := signature.CertHashFromFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		results = append(results, certHashResult{Path: path, CertHash: hash})
+	}
+	if err := a.print(results, func(w io.Writer) {
+		for _, r := range results {
+			row(w, r.CertHash, r.Path)
+		}
+	}); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func unixTime(sec int64) string {
+	if sec == 0 {
+		return "-"
+	}
+	return time.Unix(sec, 0).Format(timeLayout)
+}
@@ -33,3 +33,35 @@ func (d DateTime) MarshalJSON() ([]byte, error) {
 	}
 	return []byte(fmt.Sprintf(`"%s"`, d.Time.Format(apipguLayout))), nil
 }
+
+// "updated": "2022-12-21T20:49:37.672"
+const apipguLocalLayout = "2006-01-02T15:04:05.000"
+
+// Moscow - часовой пояс дат и времени API ЕПГУ, переданных без смещения (московское время, UTC+3).
+var Moscow = time.FixedZone("MSK", 3*60*60)
+
+// LocalDateTime - дата и время в формате API ЕПГУ без смещения, в часовом поясе [Moscow].
+// Используется в ответах методов [Client.OrdersStatus] и [Client.OrdersUpdatedAfter].
+//
+//	2022-12-21T20:49:37.672
+type LocalDateTime struct {
+	time.Time
+}
+
+func (d *LocalDateTime) UnmarshalJSON(b []byte) (err error) {
+	s := string(b)
+	if s == "null" {
+		d.Time = time.Time{}
+		return
+	}
+	s = strings.Trim(string(b), `"`)
+	d.Time, err = time.ParseInLocation(apipguLocalLayout, s, Moscow)
+	return
+}
+
+func (d LocalDateTime) MarshalJSON() ([]byte, error) {
+	if d.Time.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(fmt.Sprintf(`"%s"`, d.Time.In(Moscow).Format(apipguLocalLayout))), nil
+}
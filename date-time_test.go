@@ -64,3 +64,45 @@ func (suite *suiteTestDateTime) TestUnmarshalJSON() {
 		suite.Equal(time.Time{}, dt.Time)
 	})
 }
+
+func TestLocalDateTime(t *testing.T) {
+	suite.Run(t, new(suiteTestLocalDateTime))
+}
+
+type suiteTestLocalDateTime struct {
+	suite.Suite
+}
+
+func (suite *suiteTestLocalDateTime) TestMarshalJSON() {
+	suite.Run("2022-12-21T20:49:37.672", func() {
+		dt := LocalDateTime{time.Date(2022, 12, 21, 17, 49, 37, 672000000, time.UTC)}
+		b, err := json.Marshal(dt)
+		suite.NoError(err)
+		suite.Equal(`"2022-12-21T20:49:37.672"`, string(b))
+	})
+
+	suite.Run("null time", func() {
+		b, err := json.Marshal(LocalDateTime{})
+		suite.NoError(err)
+		suite.Equal(`null`, string(b))
+	})
+}
+
+func (suite *suiteTestLocalDateTime) TestUnmarshalJSON() {
+	suite.Run("2022-12-21T20:49:37.672", func() {
+		var dt LocalDateTime
+		suite.NoError(json.Unmarshal([]byte(`"2022-12-21T20:49:37.672"`), &dt))
+		suite.True(time.Date(2022, 12, 21, 17, 49, 37, 672000000, time.UTC).Equal(dt.Time))
+	})
+
+	suite.Run("null time", func() {
+		var dt LocalDateTime
+		suite.NoError(json.Unmarshal([]byte(`null`), &dt))
+		suite.True(dt.IsZero())
+	})
+
+	suite.Run("invalid", func() {
+		var dt LocalDateTime
+		suite.Error(json.Unmarshal([]byte(`"yesterday"`), &dt))
+	})
+}
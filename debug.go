@@ -2,6 +2,7 @@ package apipgu
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ofstudio/go-api-epgu/utils"
 )
@@ -17,3 +18,15 @@ func (c *Client) logRes(res *http.Response) {
 		utils.LogRes(res, c.logger)
 	}
 }
+
+// operation - операция клиента для журнала HAR: наименование метода и номер заявления.
+type operation struct {
+	name    string
+	orderId int
+}
+
+func (c *Client) harRecord(op operation, req *http.Request, res *http.Response, err error, started time.Time) {
+	if c.har != nil {
+		c.har.Record(req, res, err, started, utils.HARComment(op.name, op.orderId))
+	}
+}
@@ -8,16 +8,41 @@
 //   - [Client.OrderCreate] — создание заявления
 //   - [Client.OrderPushChunked] — загрузка архива по частям
 //   - [Client.OrderPush] — формирование заявления единым методом
+//   - [Client.OrderPushService] — создание заявления по услуге [Service]
 //   - [Client.OrderInfo] — запрос детальной информации по отправленному заявлению
+//   - [Client.OrdersStatus] — текущие статусы заявлений по списку номеров
+//   - [Client.OrdersUpdatedAfter] — текущие статусы заявлений, обновленных после даты
 //   - [Client.OrderCancel] — отмена заявления
 //   - [Client.AttachmentDownload] — скачивание файла вложения созданного заявления
+//   - [Client.AttachmentDownloadVerified] — скачивание файла с проверкой электронной подписи
 //   - [Client.Dict] — получение справочных данных
 //
+// # Архив вложений
+//
+//   - [NewArchive] — архив файлов вложений
+//   - [NewSignedArchive] — архив файлов вложений с отсоединенными подписями УКЭП (.sig)
+//
+// # Услуги
+//
+//   - [Service] — услуга: метаданные и архив заявления
+//   - [ServiceRegistry], [DefaultServices] — реестр услуг по кодам serviceCode и targetCode
+//
 // # Получение маркера доступа (токена) ЕСИА
 //
 //   - [github.com/ofstudio/go-api-epgu/esia/aas] — OAuth2-клиент для работы с согласиями ЕСИА
 //   - [github.com/ofstudio/go-api-epgu/esia/signature] — Электронная подпись запросов к ЕСИА
 //
+// # Сертификаты НУЦ Минцифры России
+//
+//   - [github.com/ofstudio/go-api-epgu/rootca] — http-клиент с сертификатами Russian Trusted Root CA / Sub CA
+//     для [Client.WithHTTPClient]
+//
+// # Тестирование
+//
+//   - [github.com/ofstudio/go-api-epgu/epgutest] — фейковый сервер API ЕПГУ для интеграционных тестов
+//   - [github.com/ofstudio/go-api-epgu/esia/esiatest] — фейковый сервер авторизации ЕСИА
+//   - [github.com/ofstudio/go-api-epgu/cassette] — запись и воспроизведение HTTP-запросов к ЕПГУ и ЕСИА
+//
 // # Услуги API ЕПГУ
 //
 //   - [github.com/ofstudio/go-api-epgu/services/sfr/10000000109-zdp] — Доставка пенсии и социальных выплат ПФР
@@ -0,0 +1,157 @@
+package environment
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+// Config - конфигурация клиентов API ЕПГУ и ЕСИА: готовая среда и заменяющие ее значения.
+// Пустые значения не заменяют значения среды.
+type Config struct {
+	Environment  string        `yaml:"environment"`    // Готовая среда, см [Preset]; по умолчанию - svcdev
+	EPGUBaseURI  string        `yaml:"epgu_base_uri"`  // Адрес API ЕПГУ
+	ESIABaseURI  string        `yaml:"esia_base_uri"`  // Адрес ЕСИА
+	ESIAClientId string        `yaml:"esia_client_id"` // Мнемоника ИС в ЕСИА
+	RootCAFile   string        `yaml:"root_ca"`        // Дополнительные корневые сертификаты (PEM)
+	ChunkSize    int           `yaml:"chunk_size"`     // Размер части архива
+	Timeout      time.Duration `yaml:"timeout"`        // Таймаут HTTP-запроса, например "30s"
+	RateLimit    float64       `yaml:"rate_limit"`     // Ограничение частоты запросов к API ЕПГУ в секунду
+}
+
+// LoadConfig - читает конфигурацию из файла path (YAML или JSON) и заменяет значения
+// переменными окружения, см [Config.FromEnv]. Если path пустой, конфигурация читается
+// только из переменных окружения.
+//
+// В случае ошибки возвращает цепочку из [ErrConfig] и описания ошибки.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+		}
+		// JSON - подмножество YAML
+		if err = yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrConfig, path, err)
+		}
+	}
+	if err := cfg.FromEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// FromEnv - заменяет значения конфигурации непустыми переменными окружения:
+//   - EPGU_ENV - готовая среда
+//   - EPGU_BASE_URI - адрес API ЕПГУ
+//   - ESIA_BASE_URI - адрес ЕСИА
+//   - ESIA_CLIENT_ID - мнемоника ИС в ЕСИА
+//   - EPGU_ROOT_CA - файл дополнительных корневых сертификатов
+//   - EPGU_CHUNK_SIZE - размер части архива
+//   - EPGU_TIMEOUT - таймаут HTTP-запроса, например "30s"
+//   - EPGU_RATE_LIMIT - ограничение частоты запросов к API ЕПГУ в секунду
+//
+// В случае ошибки возвращает цепочку из [ErrConfig] и описания ошибки.
+func (c *Config) FromEnv() error {
+	for name, value := range map[string]*string{
+		"EPGU_ENV":       &c.Environment,
+		"EPGU_BASE_URI":  &c.EPGUBaseURI,
+		"ESIA_BASE_URI":  &c.ESIABaseURI,
+		"ESIA_CLIENT_ID": &c.ESIAClientId,
+		"EPGU_ROOT_CA":   &c.RootCAFile,
+	} {
+		if v := os.Getenv(name); v != "" {
+			*value = v
+		}
+	}
+
+	var err error
+	if v := os.Getenv("EPGU_CHUNK_SIZE"); v != "" {
+		if c.ChunkSize, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("%w: EPGU_CHUNK_SIZE: %w", ErrConfig, err)
+		}
+	}
+	if v := os.Getenv("EPGU_TIMEOUT"); v != "" {
+		if c.Timeout, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("%w: EPGU_TIMEOUT: %w", ErrConfig, err)
+		}
+	}
+	if v := os.Getenv("EPGU_RATE_LIMIT"); v != "" {
+		if c.RateLimit, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("%w: EPGU_RATE_LIMIT: %w", ErrConfig, err)
+		}
+	}
+	return nil
+}
+
+// Env - среда конфигурации: готовая среда с замененными значениями.
+//
+// В случае ошибки возвращает [ErrUnknownEnvironment] или ошибки [Environment.Validate].
+func (c *Config) Env() (Environment, error) {
+	name := c.Environment
+	if name == "" {
+		name = SVCDEV.Name
+	}
+	env, err := Preset(name)
+	if err != nil {
+		return env, err
+	}
+
+	if c.EPGUBaseURI != "" {
+		env.EPGUBaseURI = c.EPGUBaseURI
+	}
+	if c.ESIABaseURI != "" {
+		env.ESIABaseURI = c.ESIABaseURI
+	}
+	if c.RootCAFile != "" {
+		env.RootCAFile = c.RootCAFile
+	}
+	if c.ChunkSize > 0 {
+		env.ChunkSize = c.ChunkSize
+	}
+	if c.Timeout > 0 {
+		env.Timeout = c.Timeout
+	}
+	if c.RateLimit > 0 {
+		env.RateLimit = c.RateLimit
+	}
+	env.EPGUBaseURI = strings.TrimSuffix(env.EPGUBaseURI, "/")
+	env.ESIABaseURI = strings.TrimSuffix(env.ESIABaseURI, "/")
+
+	if err = env.Validate(); err != nil {
+		return env, err
+	}
+	return env, nil
+}
+
+// Clients - клиенты API ЕПГУ и ЕСИА среды конфигурации, см [Environment.EPGUClient]
+// и [Environment.ESIAClient]. Провайдер подписи signer используется клиентом ЕСИА.
+//
+// В случае ошибки возвращает ошибки [Config.Env] или [ErrConfig], если не задан esia_client_id.
+func (c *Config) Clients(signer signature.Provider) (*apipgu.Client, *aas.Client, error) {
+	env, err := c.Env()
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.ESIAClientId == "" {
+		return nil, nil, fmt.Errorf("%w: не задан esia_client_id", ErrConfig)
+	}
+	epgu, err := env.EPGUClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	esia, err := env.ESIAClient(c.ESIAClientId, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return epgu, esia, nil
+}
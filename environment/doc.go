@@ -0,0 +1,39 @@
+// Пакет environment - среды (контуры) API ЕПГУ и ЕСИА и загрузка конфигурации клиентов.
+//
+// [Environment] объединяет адреса ЕПГУ и ЕСИА одного контура, признак ГОСТ-TLS и лимиты по умолчанию.
+// Готовые среды: [SVCDEV], [SVCDEVGOST], [Production] и [Sandbox] (песочница cmd/epgu-sandbox).
+//
+// [LoadConfig] читает конфигурацию из файла YAML или JSON и переменных окружения,
+// [Config.Clients] создает [apipgu.Client] и [aas.Client] выбранной среды.
+//
+// Маркер доступа тестового контура ЕСИА не принимается продуктовым ЕПГУ (и наоборот), а ошибка
+// обнаруживается только по ответу сервера. Клиент ЕПГУ, созданный [Environment.EPGUClient], проверяет
+// издателя маркера доступа до отправки запроса и возвращает ошибку [ErrContourMismatch].
+//
+// Пример:
+//
+//	cfg, err := environment.LoadConfig("epgu.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	apiClient, esiaClient, err := cfg.Clients(signer)
+//
+// Файл конфигурации:
+//
+//	environment: svcdev            # готовая среда
+//	esia_client_id: MNEMONIC       # мнемоника ИС в ЕСИА
+//	timeout: 30s                   # значения, заменяющие значения среды
+//
+// Переменные окружения заменяют значения из файла, см [Config.FromEnv].
+//
+// Стандартная библиотека Go не поддерживает ГОСТ-TLS: для сред с [Environment].GOST адрес ЕПГУ
+// должен указывать на локальный ГОСТ-TLS-прокси (например, stunnel из КриптоПро CSP),
+// а контур задается параметром environment. Без адреса прокси [Environment.EPGUClient]
+// возвращает ошибку [ErrConfig]:
+//
+//	environment: svcdev-gost
+//	epgu_base_uri: http://127.0.0.1:8443   # stunnel -> svcdev-gostapi.test.gosuslugi.ru:443
+//
+// [apipgu.Client]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#Client
+// [aas.Client]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/aas#Client
+package environment
@@ -0,0 +1,268 @@
+package environment
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+	"github.com/ofstudio/go-api-epgu/rootca"
+)
+
+// Лимиты по умолчанию.
+const (
+	DefaultTimeout   = 60 * time.Second // Таймаут HTTP-запроса
+	DefaultRateLimit = 5                // Запросов к API ЕПГУ в секунду на один экземпляр приложения
+)
+
+// Environment - среда (контур) API ЕПГУ и ЕСИА.
+type Environment struct {
+	Name        string        // Название среды
+	EPGUBaseURI string        // Адрес API ЕПГУ
+	ESIABaseURI string        // Адрес ЕСИА
+	Test        bool          // Тестовый контур: маркеры доступа выдает тестовая ЕСИА
+	GOST        bool          // Подключение к ЕПГУ по ГОСТ-TLS: EPGUBaseURI - адрес ГОСТ-TLS-прокси, см [Environment.EPGUClient]
+	RootCA      bool          // Доверять сертификатам НУЦ Минцифры России, см пакет rootca
+	RootCAFile  string        // Дополнительные корневые сертификаты (PEM), см [rootca.Config.WithRoots]
+	ChunkSize   int           // Размер части архива, см [apipgu.Client.WithChunkSize]
+	Timeout     time.Duration // Таймаут HTTP-запроса
+	RateLimit   float64       // Ограничение частоты запросов к API ЕПГУ в секунду; 0 - без ограничения
+}
+
+// Готовые среды.
+var (
+	// SVCDEV - тестовый контур.
+	SVCDEV = Environment{
+		Name:        "svcdev",
+		EPGUBaseURI: "https://svcdev-beta.test.gosuslugi.ru",
+		ESIABaseURI: "https://esia-portal1.test.gosuslugi.ru",
+		Test:        true,
+		ChunkSize:   apipgu.DefaultChunkSize,
+		Timeout:     DefaultTimeout,
+		RateLimit:   DefaultRateLimit,
+	}
+
+	// SVCDEVGOST - тестовый контур с подключением по ГОСТ-TLS.
+	// Go не поддерживает ГОСТ-TLS: адрес ЕПГУ необходимо заменить адресом ГОСТ-TLS-прокси
+	// к svcdev-gostapi.test.gosuslugi.ru, см [Environment.EPGUClient].
+	SVCDEVGOST = Environment{
+		Name:        "svcdev-gost",
+		EPGUBaseURI: "https://svcdev-gostapi.test.gosuslugi.ru",
+		ESIABaseURI: "https://esia-portal1.test.gosuslugi.ru",
+		Test:        true,
+		GOST:        true,
+		ChunkSize:   apipgu.DefaultChunkSize,
+		Timeout:     DefaultTimeout,
+		RateLimit:   DefaultRateLimit,
+	}
+
+	// Production - продуктовый контур.
+	Production = Environment{
+		Name:        "prod",
+		EPGUBaseURI: "https://www.gosuslugi.ru",
+		ESIABaseURI: "https://esia.gosuslugi.ru",
+		RootCA:      true,
+		ChunkSize:   apipgu.DefaultChunkSize,
+		Timeout:     DefaultTimeout,
+		RateLimit:   DefaultRateLimit,
+	}
+
+	// Sandbox - песочница cmd/epgu-sandbox на локальном адресе по умолчанию.
+	Sandbox = Environment{
+		Name:        "sandbox",
+		EPGUBaseURI: "http://127.0.0.1:8090",
+		ESIABaseURI: "http://127.0.0.1:8090",
+		Test:        true,
+		ChunkSize:   apipgu.DefaultChunkSize,
+		Timeout:     DefaultTimeout,
+		RateLimit:   DefaultRateLimit,
+	}
+)
+
+var presets = map[string]Environment{
+	SVCDEV.Name:     SVCDEV,
+	SVCDEVGOST.Name: SVCDEVGOST,
+	Production.Name: Production,
+	Sandbox.Name:    Sandbox,
+}
+
+// Preset - возвращает готовую среду по названию либо [ErrUnknownEnvironment].
+func Preset(name string) (Environment, error) {
+	env, ok := presets[name]
+	if !ok {
+		return Environment{}, fmt.Errorf("%w: '%s', доступны: %s", ErrUnknownEnvironment, name, strings.Join(Names(), ", "))
+	}
+	return env, nil
+}
+
+// Names - названия готовых сред.
+func Names() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate - проверяет, что адреса ЕПГУ и ЕСИА относятся к контуру среды.
+// Адреса вне доменов Госуслуг (песочница, ГОСТ-TLS-прокси) не проверяются.
+//
+// В случае ошибки возвращает [ErrConfig] или [ErrContourMismatch].
+func (e Environment) Validate() error {
+	if e.EPGUBaseURI == "" || e.ESIABaseURI == "" {
+		return fmt.Errorf("%w: среда '%s': не заданы адреса ЕПГУ и ЕСИА", ErrConfig, e.Name)
+	}
+	for _, uri := range []string{e.EPGUBaseURI, e.ESIABaseURI} {
+		if c := contourOf(uri); c != contourUnknown && c != e.contour() {
+			return fmt.Errorf("%w: среда '%s' (%s), адрес %s", ErrContourMismatch, e.Name, e.contour(), uri)
+		}
+	}
+	return nil
+}
+
+// CheckToken - проверяет, что маркер доступа token выдан ЕСИА контура среды.
+// Маркеры, издателя которых определить не удалось, не проверяются.
+//
+// В случае несовпадения контуров возвращает [ErrContourMismatch].
+func (e Environment) CheckToken(token string) error {
+	claims, err := aas.ParseTokenClaims(token)
+	if err != nil {
+		return nil
+	}
+	if c := contourOf(claims.Issuer); c != contourUnknown && c != e.contour() {
+		return fmt.Errorf("%w: маркер доступа выдан ЕСИА %s, среда '%s' (%s)", ErrContourMismatch, c, e.Name, e.contour())
+	}
+	return nil
+}
+
+// HTTPClient - HTTP-клиент среды: таймаут и, для сред с RootCA, сертификаты НУЦ Минцифры России.
+//
+// В случае ошибки возвращает цепочку из [ErrConfig] и ошибок [rootca.Config.CertPool].
+func (e Environment) HTTPClient() (*http.Client, error) {
+	client := &http.Client{Timeout: e.Timeout}
+	if !e.RootCA && e.RootCAFile == "" {
+		client.Transport = http.DefaultTransport.(*http.Transport).Clone()
+		return client, nil
+	}
+
+	cfg := rootca.NewConfig()
+	if e.RootCAFile != "" {
+		data, err := os.ReadFile(e.RootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+		}
+		cfg.WithRoots(data)
+	}
+	rootClient, err := cfg.HTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("%w: root_ca: %w", ErrConfig, err)
+	}
+	client.Transport = rootClient.Transport
+	return client, nil
+}
+
+// EPGUClient - клиент API ЕПГУ среды с размером части архива ChunkSize, ограничением частоты
+// запросов RateLimit и проверкой маркера доступа [Environment.CheckToken] перед каждым запросом.
+// Ограничение действует на все запросы клиента, в том числе из разных горутин.
+// Замена HTTP-клиента методом [apipgu.Client.WithHTTPClient] отключает проверку маркера и ограничение.
+//
+// Стандартная библиотека Go не поддерживает ГОСТ-TLS, поэтому для сред с GOST адрес ЕПГУ должен
+// указывать на прокси, завершающий ГОСТ-TLS (например, stunnel из КриптоПро CSP), а не на домен Госуслуг.
+//
+// В случае ошибки возвращает ошибки [Environment.Validate] и [Environment.HTTPClient]
+// либо [ErrConfig], если для среды с GOST не задан адрес ГОСТ-TLS-прокси.
+func (e Environment) EPGUClient() (*apipgu.Client, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	if e.GOST && contourOf(e.EPGUBaseURI) != contourUnknown {
+		return nil, fmt.Errorf(
+			"%w: среда '%s': Go не поддерживает ГОСТ-TLS, укажите в epgu_base_uri адрес ГОСТ-TLS-прокси к %s",
+			ErrConfig, e.Name, e.EPGUBaseURI,
+		)
+	}
+	httpClient, err := e.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Transport = &guard{next: httpClient.Transport, env: e}
+	if e.RateLimit > 0 {
+		httpClient.Transport = newLimiter(httpClient.Transport, e.RateLimit)
+	}
+	client := apipgu.NewClient(e.EPGUBaseURI).WithHTTPClient(httpClient)
+	if e.ChunkSize > 0 {
+		client.WithChunkSize(e.ChunkSize)
+	}
+	return client, nil
+}
+
+// ESIAClient - клиент ЕСИА среды для ИС clientId с провайдером подписи signer.
+//
+// В случае ошибки возвращает ошибки [Environment.Validate] и [Environment.HTTPClient].
+func (e Environment) ESIAClient(clientId string, signer signature.Provider) (*aas.Client, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	httpClient, err := e.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return aas.NewClient(e.ESIABaseURI, clientId, signer).WithHTTPClient(httpClient), nil
+}
+
+// guard - проверяет маркер доступа запроса к API ЕПГУ, см [Environment.CheckToken].
+type guard struct {
+	next http.RoundTripper
+	env  Environment
+}
+
+func (g *guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		if err := g.env.CheckToken(token); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return g.next.RoundTrip(req)
+}
+
+// contour - контур Госуслуг.
+type contour string
+
+const (
+	contourUnknown contour = ""
+	contourTest    contour = "тестовый контур"
+	contourProd    contour = "продуктовый контур"
+)
+
+func (e Environment) contour() contour {
+	if e.Test {
+		return contourTest
+	}
+	return contourProd
+}
+
+// contourOf - контур по адресу: *.test.gosuslugi.ru - тестовый, остальные *.gosuslugi.ru - продуктовый.
+func contourOf(uri string) contour {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return contourUnknown
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.HasSuffix(host, ".test.gosuslugi.ru"):
+		return contourTest
+	case host == "gosuslugi.ru" || strings.HasSuffix(host, ".gosuslugi.ru"):
+		return contourProd
+	}
+	return contourUnknown
+}
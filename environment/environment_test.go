@@ -0,0 +1,206 @@
+package environment
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+func TestEnvironment(t *testing.T) {
+	suite.Run(t, new(suiteEnvironment))
+}
+
+type suiteEnvironment struct {
+	suite.Suite
+}
+
+// token - маркер доступа ЕСИА с издателем issuer (без подписи).
+func token(issuer string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		enc.EncodeToString([]byte(`{"iss":"`+issuer+`","urn:esia:sbj_id":1000572618}`)) + ".sig"
+}
+
+// writeFile - записывает файл во временный каталог теста.
+func (suite *suiteEnvironment) writeFile(name, data string) string {
+	path := filepath.Join(suite.T().TempDir(), name)
+	suite.Require().NoError(os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func (suite *suiteEnvironment) TestPreset() {
+	env, err := Preset("svcdev-gost")
+	suite.NoError(err)
+	suite.Equal(SVCDEVGOST, env)
+	suite.True(env.GOST)
+	suite.NoError(env.Validate())
+
+	for _, name := range Names() {
+		env, err = Preset(name)
+		suite.NoError(err)
+		suite.NoError(env.Validate(), name)
+	}
+
+	_, err = Preset("test")
+	suite.ErrorIs(err, ErrUnknownEnvironment)
+}
+
+func (suite *suiteEnvironment) TestLoadConfig() {
+	suite.Run("yaml", func() {
+		path := suite.writeFile("epgu.yaml", "environment: svcdev\nesia_client_id: MNEMONIC\ntimeout: 30s\nchunk_size: 1000\n")
+		cfg, err := LoadConfig(path)
+		suite.Require().NoError(err)
+		suite.Equal("MNEMONIC", cfg.ESIAClientId)
+
+		env, err := cfg.Env()
+		suite.Require().NoError(err)
+		suite.Equal(SVCDEV.EPGUBaseURI, env.EPGUBaseURI)
+		suite.Equal(30*time.Second, env.Timeout)
+		suite.Equal(1000, env.ChunkSize)
+		suite.Equal(float64(DefaultRateLimit), env.RateLimit)
+	})
+
+	suite.Run("json with env", func() {
+		path := suite.writeFile("epgu.json", `{"environment": "prod", "esia_client_id": "MNEMONIC"}`)
+		suite.T().Setenv("EPGU_ENV", "sandbox")
+		suite.T().Setenv("EPGU_BASE_URI", "http://localhost:8091/")
+		suite.T().Setenv("EPGU_TIMEOUT", "5s")
+		cfg, err := LoadConfig(path)
+		suite.Require().NoError(err)
+
+		env, err := cfg.Env()
+		suite.Require().NoError(err)
+		suite.Equal("sandbox", env.Name)
+		suite.Equal("http://localhost:8091", env.EPGUBaseURI)
+		suite.Equal(Sandbox.ESIABaseURI, env.ESIABaseURI)
+		suite.Equal(5*time.Second, env.Timeout)
+	})
+
+	suite.Run("bad env", func() {
+		suite.T().Setenv("EPGU_CHUNK_SIZE", "5MB")
+		_, err := LoadConfig("")
+		suite.ErrorIs(err, ErrConfig)
+	})
+
+	suite.Run("missing file", func() {
+		_, err := LoadConfig(filepath.Join(suite.T().TempDir(), "missing.yaml"))
+		suite.ErrorIs(err, ErrConfig)
+	})
+
+	suite.Run("unknown environment", func() {
+		_, err := (&Config{Environment: "stage"}).Env()
+		suite.ErrorIs(err, ErrUnknownEnvironment)
+	})
+
+	suite.Run("contour mismatch", func() {
+		_, err := (&Config{Environment: "prod", ESIABaseURI: SVCDEV.ESIABaseURI}).Env()
+		suite.ErrorIs(err, ErrContourMismatch)
+	})
+}
+
+func (suite *suiteEnvironment) TestCheckToken() {
+	suite.NoError(SVCDEV.CheckToken(token("http://esia-portal1.test.gosuslugi.ru/")))
+	suite.NoError(Production.CheckToken(token("http://esia.gosuslugi.ru/")))
+	suite.ErrorIs(Production.CheckToken(token("http://esia-portal1.test.gosuslugi.ru/")), ErrContourMismatch)
+	suite.ErrorIs(SVCDEV.CheckToken(token("http://esia.gosuslugi.ru/")), ErrContourMismatch)
+	suite.NoError(Production.CheckToken(token("http://127.0.0.1:8090/")))
+	suite.NoError(Production.CheckToken("not-a-jwt"))
+}
+
+func (suite *suiteEnvironment) TestProductionClients() {
+	httpClient, err := Production.HTTPClient()
+	suite.Require().NoError(err, "см rootca/certs/README.md")
+	suite.NotNil(httpClient.Transport)
+
+	epgu, err := Production.EPGUClient()
+	suite.NoError(err)
+	suite.NotNil(epgu)
+
+	esia, err := Production.ESIAClient("MNEMONIC", signature.NewNop("test-signature", "test-certificate"))
+	suite.NoError(err)
+	suite.NotNil(esia)
+}
+
+func (suite *suiteEnvironment) TestEPGUClient() {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orderId":1230254874}`))
+	}))
+	defer server.Close()
+
+	// продуктовый контур за локальным адресом (например, прокси)
+	prod := Environment{Name: "prod-proxy", EPGUBaseURI: server.URL, ESIABaseURI: server.URL}
+	client, err := prod.EPGUClient()
+	suite.Require().NoError(err)
+
+	_, err = client.OrderCreate(token("http://esia-portal1.test.gosuslugi.ru/"), apipgu.OrderMeta{})
+	suite.ErrorIs(err, ErrContourMismatch)
+	suite.ErrorIs(err, apipgu.ErrRequest)
+	suite.Zero(requests)
+
+	orderId, err := client.OrderCreate(token("http://esia.gosuslugi.ru/"), apipgu.OrderMeta{})
+	suite.NoError(err)
+	suite.Equal(1230254874, orderId)
+	suite.Equal(1, requests)
+}
+
+func (suite *suiteEnvironment) TestEPGUClientGOST() {
+	_, err := SVCDEVGOST.EPGUClient()
+	suite.ErrorIs(err, ErrConfig)
+	suite.ErrorContains(err, "ГОСТ-TLS-прокси")
+
+	proxy := SVCDEVGOST
+	proxy.EPGUBaseURI = "http://127.0.0.1:8443"
+	client, err := proxy.EPGUClient()
+	suite.NoError(err)
+	suite.NotNil(client)
+
+	_, err = SVCDEVGOST.ESIAClient("MNEMONIC", signature.NewNop("test-signature", "test-certificate"))
+	suite.NoError(err)
+}
+
+func (suite *suiteEnvironment) TestEPGUClientRateLimit() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orderId":1230254874}`))
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "local", EPGUBaseURI: server.URL, ESIABaseURI: server.URL, RateLimit: 20}
+	client, err := env.EPGUClient()
+	suite.Require().NoError(err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err = client.OrderCreate("token", apipgu.OrderMeta{})
+		suite.Require().NoError(err)
+	}
+	// первый запрос без ожидания, следующие - через 50ms
+	suite.GreaterOrEqual(time.Since(start), 100*time.Millisecond)
+}
+
+func (suite *suiteEnvironment) TestClients() {
+	signer := signature.NewNop("test-signature", "test-certificate")
+
+	epgu, esia, err := (&Config{Environment: "sandbox", ESIAClientId: "MNEMONIC"}).Clients(signer)
+	suite.NoError(err)
+	suite.NotNil(epgu)
+	suite.NotNil(esia)
+
+	_, _, err = (&Config{Environment: "sandbox"}).Clients(signer)
+	suite.ErrorIs(err, ErrConfig)
+
+	_, _, err = (&Config{Environment: "sandbox", ESIAClientId: "MNEMONIC", RootCAFile: "missing.pem"}).Clients(signer)
+	suite.ErrorIs(err, ErrConfig)
+}
@@ -0,0 +1,10 @@
+package environment
+
+import "errors"
+
+// Ошибки пакета environment
+var (
+	ErrConfig             = errors.New("ошибка конфигурации")
+	ErrUnknownEnvironment = errors.New("неизвестная среда")
+	ErrContourMismatch    = errors.New("смешение тестового и продуктового контуров")
+)
@@ -0,0 +1,49 @@
+package environment
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limiter - ограничивает частоту запросов: не более rate запросов в секунду, без накопления.
+// Запрос ожидает своей очереди либо отмены контекста запроса.
+type limiter struct {
+	next     http.RoundTripper
+	interval time.Duration
+	mu       sync.Mutex
+	slot     time.Time // Время, начиная с которого можно отправить следующий запрос
+}
+
+func newLimiter(next http.RoundTripper, rate float64) *limiter {
+	return &limiter{next: next, interval: time.Duration(float64(time.Second) / rate)}
+}
+
+func (l *limiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := l.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, req.Context().Err()
+		}
+	}
+	return l.next.RoundTrip(req)
+}
+
+// reserve - занимает очередное время отправки и возвращает время ожидания до него.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.slot.Before(now) {
+		l.slot = now
+	}
+	wait := l.slot.Sub(now)
+	l.slot = l.slot.Add(l.interval)
+	return wait
+}
@@ -0,0 +1,100 @@
+package epgutest
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// dtoDictRequest - запрос справочника.
+//
+// Подробнее см. "Спецификация API ЕПГУ версия 1.12",
+// раздел "3. Получение справочных данных".
+type dtoDictRequest struct {
+	TreeFiltering      string `json:"treeFiltering"`
+	ParentRefItemValue string `json:"parentRefItemValue"`
+	PageNum            int    `json:"pageNum"`
+	PageSize           int    `json:"pageSize"`
+}
+
+// dtoDictResponse - ответ на запрос справочника.
+type dtoDictResponse struct {
+	Error       dtoDictResponseError `json:"error"`
+	FieldErrors []map[string]any     `json:"fieldErrors"`
+	Total       int                  `json:"total"`
+	Items       []apipgu.DictItem    `json:"items"`
+}
+
+// dtoDictResponseError - результат выполнения операции.
+type dtoDictResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// dict - POST /api/nsi/v1/dictionary/{code}
+//
+// Плоский справочник [apipgu.DictFilterOneLevel] возвращает элементы первого уровня
+// (или непосредственно подчиненные элементу parentRefItemValue), иерархический
+// [apipgu.DictFilterSubTree] - все элементы (или все элементы, подчиненные parentRefItemValue).
+// Номер страницы pageNum начинается с 1.
+func (s *Server) dict(r *http.Request) response {
+	req := dtoDictRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return badRequest(err.Error())
+	}
+	if req.TreeFiltering != apipgu.DictFilterOneLevel && req.TreeFiltering != apipgu.DictFilterSubTree {
+		return badRequest("некорректный параметр treeFiltering")
+	}
+
+	all, ok := s.dicts[path.Base(r.URL.Path)]
+	if !ok {
+		return jsonResponse(http.StatusOK, dtoDictResponse{
+			Error:       dtoDictResponseError{Code: 7, Message: "Entity not found"},
+			FieldErrors: []map[string]any{},
+			Items:       []apipgu.DictItem{},
+		})
+	}
+
+	var items []apipgu.DictItem
+	if req.TreeFiltering == apipgu.DictFilterOneLevel {
+		for _, item := range all {
+			if item.ParentValue == req.ParentRefItemValue {
+				items = append(items, item)
+			}
+		}
+	} else {
+		items = subtree(all, req.ParentRefItemValue)
+	}
+
+	pageNum := req.PageNum - 1
+	if pageNum < 0 {
+		pageNum = 0
+	}
+	page := paginate(items, pageNum, req.PageSize)
+	if page == nil {
+		page = []apipgu.DictItem{}
+	}
+	return jsonResponse(http.StatusOK, dtoDictResponse{
+		Error:       dtoDictResponseError{Code: 0, Message: "operation completed"},
+		FieldErrors: []map[string]any{},
+		Total:       len(items),
+		Items:       page,
+	})
+}
+
+// subtree - возвращает все элементы, подчиненные элементу parent; parent = "" - все элементы.
+func subtree(all []apipgu.DictItem, parent string) []apipgu.DictItem {
+	if parent == "" {
+		return all
+	}
+	var items []apipgu.DictItem
+	for _, item := range all {
+		if item.ParentValue == parent {
+			items = append(items, item)
+			items = append(items, subtree(all, item.Value)...)
+		}
+	}
+	return items
+}
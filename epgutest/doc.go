@@ -0,0 +1,44 @@
+// Пакет epgutest - фейковый сервер API ЕПГУ для интеграционных тестов.
+//
+// Сервер хранит состояние заявлений в памяти и реализует методы, используемые [apipgu.Client]:
+//
+//   - POST /api/gusmev/order — создание заявления
+//   - POST /api/gusmev/push — формирование заявления единым методом
+//   - POST /api/gusmev/push/chunked — загрузка архива по частям со сборкой чанков
+//   - POST /api/gusmev/order/{orderId} — детальная информация по заявлению
+//   - POST /api/gusmev/order/{orderId}/cancel — отмена заявления
+//   - GET /api/gusmev/order/getOrdersStatus — статусы заявлений по списку
+//   - GET /api/gusmev/order/getUpdatedAfter — статусы заявлений с даты обновления
+//   - POST /api/nsi/v1/dictionary/{code} — справочные данные
+//   - GET /api/storage/v2/files/{objectId}/{objectType}/download — скачивание файлов
+//
+// Поведение сервера соответствует документу "Спецификация API ЕПГУ версия 1.12":
+// маркер доступа проверяется для всех методов, кроме справочников;
+// при загрузке по частям первый чанк должен быть отправлен первым, последний - последним,
+// а все чанки - в течение 5 минут; детали заявления возвращаются в поле order
+// в виде экранированного JSON-объекта.
+//
+// Статусы заявлений задаются сценарием услуги [Scenario] и переключаются методом [Server.Advance]
+// или автоматически: по истечении задержки [Step].Delay либо при каждом запросе деталей заявления,
+// см [Server.WithAutoAdvance].
+// Ошибки HTTP и ошибки ЕПГУ задаются методом [Server.WithFault].
+//
+// Пример:
+//
+//	server := epgutest.NewServer().
+//		WithTokens("test-token").
+//		WithScenario("10000000109", epgutest.Scenario{
+//			Steps: []epgutest.Step{
+//				{StatusId: 2, Title: "Заявление получено ведомством"},
+//				{StatusId: 3, Title: "Исполнено", Final: true},
+//			},
+//		})
+//	defer server.Close()
+//
+//	client := apipgu.NewClient(server.URL)
+//	orderId, err := client.OrderCreate("test-token", meta)
+//	...
+//	err = server.Advance(orderId)
+//
+// [apipgu.Client]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#Client
+package epgutest
@@ -0,0 +1,10 @@
+package epgutest
+
+import "errors"
+
+// Ошибки пакета epgutest
+var (
+	ErrOrderNotFound  = errors.New("заявление не найдено")
+	ErrOrderNotPushed = errors.New("архив заявления не загружен")
+	ErrNoSteps        = errors.New("в сценарии нет следующего статуса")
+)
@@ -0,0 +1,633 @@
+package epgutest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+// Коды состояния заявления в ответе метода [apipgu.Client.OrderInfo].
+//
+// Подробнее см. "Спецификация API ЕПГУ версия 1.12",
+// "Приложение 1. Статусы заявления в процессе обработки в gu-smev".
+const (
+	CodeOK                    = "OK"                        // Заявление создано на портале и отправлено в ведомство
+	CodeNew                   = "NEW"                       // Заявление создано, архив не загружен
+	CodeInvalidFilesStructure = "INVALID_FILES_STRUCTURE"   // Архив не является zip-архивом или содержит папки
+	CodeValidationError       = "VALIDATION_ERROR"          // Вложения не прошли валидацию
+	CodeReqNotFound           = "REQ_NOT_FOUND"             // Нет транспортного xml-файла
+	CodeMPCNotFound           = "MPC_NOT_FOUND"             // Нет xml-файла с бизнес-данными
+	CodeReqVerifyFailed       = "REQ_VERIFY_FAILED"         // Данные xml-файлов не соответствуют получателю услуги
+	CodeFilesVerifyFailed     = "FILES_VERIFICATION_FAILED" // Подписи вложений отсутствуют или не прошли проверку
+	CodeLimitationException   = "LIMITATION_EXCEPTION"      // Превышены ограничения Приложения 3 Спецификации
+	CodeInternalError         = "INTERNAL_ERROR"            // Техническая ошибка
+)
+
+// ChunkTimeout - время, в течение которого должны быть отправлены все чанки архива,
+// начиная с отправки первого чанка.
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.1.3 Отправка заявления (загрузка архива по частям)".
+const ChunkTimeout = 5 * time.Minute
+
+// Scenario - сценарий обработки заявлений услуги, см [Server.WithScenario].
+//
+// После загрузки архива заявление получает код Code. Если код равен [CodeOK] (по умолчанию),
+// заявлению устанавливаются статусы [PushSteps], а статусы Steps устанавливаются
+// последовательно методом [Server.Advance].
+// Иначе обработка заявления завершается с кодом Code и сообщением Message (например, [CodeValidationError]).
+type Scenario struct {
+	Code    string // Код обработки заявления в gu-smev
+	Message string // Сообщение к коду обработки
+	Sender  string // Наименование ведомства - отправителя статусов
+	Steps   []Step // Статусы заявления после отправки в ведомство
+	Cancel  *Step  // Статус после отмены заявления; по умолчанию [StepCancelled]
+}
+
+// Step - статус заявления на портале.
+type Step struct {
+	StatusId      int    // Код статуса
+	Title         string // Наименование статуса
+	Comment       string // Комментарий к статусу
+	Final         bool   // Финальный статус
+	CancelAllowed bool   // В статусе возможна отмена заявления
+	Files         []File // Файлы ответа ведомства
+
+	// Delay - задержка автоматической установки статуса после предыдущего статуса заявления.
+	// Если 0 - статус устанавливается методом [Server.Advance].
+	Delay time.Duration
+}
+
+// File - файл ответа ведомства.
+type File struct {
+	Name                string // Имя файла
+	MimeType            string // MIME-тип; по умолчанию определяется по расширению имени файла
+	Data                []byte // Содержимое файла
+	HasDigitalSignature bool   // Флаг наличия ЭП к файлу
+}
+
+// PushSteps - статусы, которые устанавливаются заявлению после загрузки архива.
+var PushSteps = []Step{
+	{StatusId: 0, Title: "Черновик заявления"},
+	{StatusId: 17, Title: "Зарегистрировано на портале"},
+	{StatusId: 21, Title: "Заявление отправлено в ведомство"},
+}
+
+// StepCancelled - статус после отмены заявления по умолчанию.
+// Код статуса условный и может быть изменен в [Scenario].Cancel.
+var StepCancelled = Step{StatusId: 10, Title: "Заявление отменено", Final: true}
+
+// order - заявление.
+type order struct {
+	id          int
+	meta        apipgu.OrderMeta
+	code        string
+	message     string
+	created     time.Time
+	pushed      time.Time
+	updated     time.Time
+	archive     []byte
+	upload      *upload
+	scenario    Scenario
+	steps       []Step
+	statuses    []apipgu.OrderStatus
+	attachments []apipgu.OrderAttachmentFile
+	responses   []apipgu.OrderResponseFile
+	smevTx      string
+}
+
+// upload - загрузка архива по частям.
+type upload struct {
+	total   int
+	parts   map[int][]byte
+	started time.Time
+}
+
+// storedFile - файл в хранилище.
+type storedFile struct {
+	mimeType string
+	data     []byte
+}
+
+// Advance - устанавливает заявлению следующий статус сценария услуги.
+//
+// Возвращает ошибки:
+//   - [ErrOrderNotFound] - заявление не найдено
+//   - [ErrOrderNotPushed] - архив заявления не загружен или обработка завершилась ошибкой
+//   - [ErrNoSteps] - в сценарии нет следующего статуса
+func (s *Server) Advance(orderId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.pushedOrder(orderId)
+	if err != nil {
+		return err
+	}
+	if len(o.steps) == 0 {
+		return fmt.Errorf("%w: заявление %d", ErrNoSteps, orderId)
+	}
+	s.apply(o, o.steps[0])
+	o.steps = o.steps[1:]
+	return nil
+}
+
+// SetStatus - устанавливает заявлению статус step вне сценария.
+//
+// Возвращает ошибки [ErrOrderNotFound] и [ErrOrderNotPushed].
+func (s *Server) SetStatus(orderId int, step Step) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.pushedOrder(orderId)
+	if err != nil {
+		return err
+	}
+	s.apply(o, step)
+	return nil
+}
+
+// Archive - возвращает загруженный архив заявления (собранный из чанков) или nil.
+func (s *Server) Archive(orderId int) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o, ok := s.orders[orderId]; ok {
+		return o.archive
+	}
+	return nil
+}
+
+// Order - возвращает детали заявления в том виде, в котором они возвращаются методом
+// [apipgu.Client.OrderInfo], или nil, если заявление не найдено или не отправлено в ведомство.
+func (s *Server) Order(orderId int) *apipgu.OrderDetails {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceDue()
+	if o, ok := s.orders[orderId]; ok && len(o.statuses) > 0 {
+		return o.details()
+	}
+	return nil
+}
+
+// Orders - возвращает номера всех заявлений в порядке создания.
+func (s *Server) Orders() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int, 0, len(s.orders))
+	for id := range s.orders {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// advanceDue - устанавливает заявлениям статусы сценария, задержка [Step].Delay которых истекла.
+// Дата статуса - время истечения задержки.
+func (s *Server) advanceDue() {
+	now := s.now()
+	for _, o := range s.orders {
+		for len(o.steps) > 0 && o.steps[0].Delay > 0 {
+			due := o.updated.Add(o.steps[0].Delay)
+			if now.Before(due) {
+				break
+			}
+			s.applyAt(o, o.steps[0], due)
+			o.steps = o.steps[1:]
+		}
+	}
+}
+
+// pushedOrder - возвращает заявление, отправленное в ведомство.
+func (s *Server) pushedOrder(orderId int) (*order, error) {
+	o, ok := s.orders[orderId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrOrderNotFound, orderId)
+	}
+	if len(o.statuses) == 0 {
+		return nil, fmt.Errorf("%w: заявление %d [code='%s']", ErrOrderNotPushed, orderId, o.code)
+	}
+	return o, nil
+}
+
+// newOrder - создает заявление.
+func (s *Server) newOrder(meta apipgu.OrderMeta) *order {
+	s.lastOrderId++
+	smevTx, _ := utils.GUID()
+	now := s.now()
+	o := &order{
+		id:       s.lastOrderId,
+		meta:     meta,
+		code:     CodeNew,
+		created:  now,
+		updated:  now,
+		scenario: s.scenarios[meta.ServiceCode],
+		smevTx:   smevTx,
+	}
+	s.orders[o.id] = o
+	return o
+}
+
+// complete - обрабатывает загруженный архив заявления.
+func (s *Server) complete(o *order, archive []byte) {
+	o.archive = archive
+	o.pushed = s.now()
+	o.updated = o.pushed
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		o.code, o.message = CodeInvalidFilesStructure, "архив не является zip-архивом"
+		return
+	}
+	names := make(map[string]struct{}, len(zr.File))
+	for _, f := range zr.File {
+		if strings.Contains(f.Name, "/") {
+			o.code, o.message = CodeInvalidFilesStructure, "архив содержит папки"
+			return
+		}
+		names[f.Name] = struct{}{}
+	}
+
+	if o.scenario.Code != "" && o.scenario.Code != CodeOK {
+		o.code, o.message = o.scenario.Code, o.scenario.Message
+		return
+	}
+
+	for _, f := range zr.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			o.code, o.message = CodeInvalidFilesStructure, err.Error()
+			return
+		}
+		link := s.store(o.id, f.Name, "2", "", data)
+		fileType := "ATTACHMENT"
+		if strings.HasPrefix(f.Name, "req") {
+			fileType = "REQUEST"
+		}
+		_, signed := names[f.Name+apipgu.SignatureExt]
+		o.attachments = append(o.attachments, apipgu.OrderAttachmentFile{
+			Id:                  fileId(o.id, f.Name),
+			FileName:            f.Name,
+			MimeType:            mimeType(f.Name),
+			Link:                link,
+			HasDigitalSignature: signed,
+			FileSize:            len(data),
+			Type:                fileType,
+		})
+	}
+
+	o.code = CodeOK
+	o.steps = append([]Step{}, o.scenario.Steps...)
+	for _, step := range PushSteps {
+		s.apply(o, step)
+	}
+}
+
+// apply - устанавливает заявлению статус step.
+func (s *Server) apply(o *order, step Step) {
+	s.applyAt(o, step, s.now())
+}
+
+// applyAt - устанавливает заявлению статус step с датой now.
+func (s *Server) applyAt(o *order, step Step, now time.Time) {
+	if !now.After(o.updated) {
+		// даты статусов должны возрастать, даже если часы остановлены
+		now = o.updated.Add(time.Millisecond)
+	}
+	o.updated = now
+
+	hasResult := "N"
+	for _, f := range step.Files {
+		link := s.store(o.id, f.Name, "1", f.MimeType, f.Data)
+		o.responses = append(o.responses, apipgu.OrderResponseFile{
+			Id:                  fileId(o.id, f.Name),
+			FileName:            f.Name,
+			MimeType:            s.files[fileKey(o.id, f.Name, "1")].mimeType,
+			Link:                link,
+			HasDigitalSignature: f.HasDigitalSignature,
+			EdsStatus:           "EDS_NOT_SUPPORTED",
+			FileSize:            len(f.Data),
+		})
+		hasResult = "Y"
+	}
+
+	colorCode := "in_progress"
+	switch {
+	case step.StatusId == 0:
+		colorCode = "edit"
+	case step.Final:
+		colorCode = ""
+	}
+
+	s.lastStatusId++
+	o.statuses = append(o.statuses, apipgu.OrderStatus{
+		Id:              s.lastStatusId,
+		StatusId:        step.StatusId,
+		Title:           step.Title,
+		Date:            apipgu.DateTime{Time: now},
+		OrderId:         o.id,
+		FinalStatus:     step.Final,
+		HasResult:       hasResult,
+		CancelAllowed:   step.CancelAllowed,
+		Sender:          o.scenario.Sender,
+		Comment:         step.Comment,
+		UnreadEvent:     true,
+		StatusColorCode: colorCode,
+	})
+}
+
+// details - детали заявления.
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.4. Получение деталей по заявлению".
+func (o *order) details() *apipgu.OrderDetails {
+	current := o.statuses[len(o.statuses)-1]
+	attachments := o.attachments
+	if attachments == nil {
+		attachments = []apipgu.OrderAttachmentFile{}
+	}
+	responses := o.responses
+	if responses == nil {
+		responses = []apipgu.OrderResponseFile{}
+	}
+	return &apipgu.OrderDetails{
+		Id:            o.id,
+		OrderStatusId: current.StatusId,
+		Statuses:      o.statuses,
+		CurrentStatusHistory: apipgu.OrderStatusHistory{
+			Id:              current.Id,
+			StatusId:        current.StatusId,
+			Title:           current.Title,
+			Date:            current.Date,
+			OrderId:         o.id,
+			FinalStatus:     current.FinalStatus,
+			HasResult:       current.HasResult,
+			CancelAllowed:   current.CancelAllowed,
+			Sender:          current.Sender,
+			Comment:         current.Comment,
+			StatusColorCode: current.StatusColorCode,
+			UnreadEvent:     true,
+		},
+		Updated:              apipgu.DateTime{Time: o.updated},
+		Closed:               current.FinalStatus,
+		HasResult:            len(o.responses) > 0,
+		OrderAttachmentFiles: attachments,
+		OrderResponseFiles:   responses,
+
+		HasNewStatus:           true,
+		CurrentStatusHistoryId: current.Id,
+		OrderStatusName:        current.Title,
+		StateStructureName:     o.scenario.Sender,
+
+		SourceSystem:       "epgutest",
+		CreationMode:       "api",
+		PersonType:         "PERSON",
+		UserSelectedRegion: "00000000000",
+		Location:           o.meta.Region,
+
+		OrderType:        "ORDER",
+		EserviceId:       o.meta.ServiceCode,
+		ServiceTargetId:  o.meta.TargetCode,
+		AdmLevelCode:     "FEDERAL",
+		ServiceEpguId:    "1",
+		FormVersion:      "1",
+		PossibleServices: map[string]any{},
+
+		OrderDate:            apipgu.DateTime{Time: o.created.Truncate(time.Second)},
+		RequestDate:          apipgu.DateTime{Time: o.pushed},
+		OrderAttributeEvents: []apipgu.OrderAttributeEvent{},
+		EQueueEvents:         []map[string]any{},
+
+		SmevTx:        o.smevTx,
+		SmevMessageId: "WAIT_RESPONSE",
+
+		NoPaidPaymentCount:  -1,
+		PaymentStatusEvents: []map[string]any{},
+		OrderPayments:       []map[string]any{},
+
+		EdsStatus: "EDS_NOT_SUPPORTED",
+
+		TextMessages: []map[string]any{},
+		InfoMessages: []map[string]any{},
+		Steps:        []any{},
+	}
+}
+
+// dtoOrderInfoResponse - ответ метода POST /api/gusmev/order/{orderId}.
+type dtoOrderInfoResponse struct {
+	Code      string  `json:"code"`
+	Message   *string `json:"message"`
+	MessageId string  `json:"messageId"`
+	Order     *string `json:"order"`
+}
+
+// dtoOrderIdResponse - ответ с номером заявления.
+type dtoOrderIdResponse struct {
+	OrderId int `json:"orderId"`
+}
+
+// orderCreate - POST /api/gusmev/order
+func (s *Server) orderCreate(r *http.Request) response {
+	meta, err := parseMeta(r.Body)
+	if err != nil {
+		return badRequest(err.Error())
+	}
+	o := s.newOrder(meta)
+	return jsonResponse(http.StatusOK, dtoOrderIdResponse{OrderId: o.id})
+}
+
+// push - POST /api/gusmev/push
+func (s *Server) push(r *http.Request) response {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return badRequest(err.Error())
+	}
+	meta, err := parseMeta(strings.NewReader(r.FormValue("meta")))
+	if err != nil {
+		return badRequest(err.Error())
+	}
+	data, err := formFile(r)
+	if err != nil {
+		return badRequest(err.Error())
+	}
+	o := s.newOrder(meta)
+	s.complete(o, data)
+	return jsonResponse(http.StatusOK, dtoOrderIdResponse{OrderId: o.id})
+}
+
+// pushChunked - POST /api/gusmev/push/chunked
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.1.3 Отправка заявления (загрузка архива по частям)".
+func (s *Server) pushChunked(r *http.Request) response {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return badRequest(err.Error())
+	}
+	orderId, err := strconv.Atoi(r.FormValue("orderId"))
+	if err != nil {
+		return badRequest("некорректный параметр orderId")
+	}
+	o, ok := s.orders[orderId]
+	if !ok {
+		return errorResponse(http.StatusNoContent, "", "")
+	}
+	if o.archive != nil {
+		return badRequest("архив заявления уже загружен")
+	}
+	data, err := formFile(r)
+	if err != nil {
+		return badRequest(err.Error())
+	}
+
+	total, err1 := intParam(r, "chunks", 1)
+	chunk, err2 := intParam(r, "chunk", 0)
+	if err1 != nil || err2 != nil || total < 1 || chunk < 0 || chunk >= total {
+		return badRequest("некорректные параметры chunk и chunks")
+	}
+
+	now := s.now()
+	u := o.upload
+	switch {
+	case u != nil && now.Sub(u.started) > ChunkTimeout:
+		o.upload = nil
+		return badRequest("истекло время загрузки чанков")
+	case chunk == 0 && u != nil:
+		return badRequest("первый чанк уже загружен")
+	case chunk == 0:
+		u = &upload{total: total, parts: make(map[int][]byte, total), started: now}
+		o.upload = u
+	case u == nil:
+		return badRequest("первым должен быть отправлен первый чанк")
+	case total != u.total:
+		return badRequest("количество чанков не совпадает с первым чанком")
+	case u.parts[chunk] != nil:
+		return badRequest(fmt.Sprintf("чанк %d уже загружен", chunk))
+	case chunk == total-1 && len(u.parts) != total-1:
+		return badRequest("последним должен быть отправлен последний чанк")
+	}
+	u.parts[chunk] = data
+
+	if len(u.parts) < u.total {
+		return jsonResponse(http.StatusPartialContent, dtoOrderIdResponse{OrderId: o.id})
+	}
+
+	archive := make([]byte, 0)
+	for i := 0; i < u.total; i++ {
+		archive = append(archive, u.parts[i]...)
+	}
+	o.upload = nil
+	s.complete(o, archive)
+	return jsonResponse(http.StatusOK, dtoOrderIdResponse{OrderId: o.id})
+}
+
+// orderInfo - POST /api/gusmev/order/{orderId}
+func (s *Server) orderInfo(r *http.Request) response {
+	o, res := s.pathOrder(r, "")
+	if o == nil {
+		return res
+	}
+	if s.autoAdvance && len(o.statuses) > 0 && len(o.steps) > 0 {
+		s.apply(o, o.steps[0])
+		o.steps = o.steps[1:]
+	}
+
+	messageId, _ := utils.GUID()
+	info := dtoOrderInfoResponse{Code: o.code, MessageId: messageId}
+	if o.message != "" {
+		info.Message = &o.message
+	}
+	if o.code == CodeOK {
+		details, err := json.Marshal(o.details())
+		if err != nil {
+			return errorResponse(http.StatusInternalServerError, "internal_error", err.Error())
+		}
+		order := string(details)
+		info.Order = &order
+	}
+	return jsonResponse(http.StatusOK, info)
+}
+
+// orderCancel - POST /api/gusmev/order/{orderId}/cancel
+func (s *Server) orderCancel(r *http.Request) response {
+	o, res := s.pathOrder(r, "/cancel")
+	if o == nil {
+		return res
+	}
+	if len(o.statuses) == 0 || !o.statuses[len(o.statuses)-1].CancelAllowed {
+		return errorResponse(
+			http.StatusConflict, "cancel_not_allowed", "Отмена заявления в текущем статусе невозможна",
+		)
+	}
+	step := StepCancelled
+	if o.scenario.Cancel != nil {
+		step = *o.scenario.Cancel
+	}
+	s.apply(o, step)
+	o.steps = nil
+	return response{status: http.StatusOK}
+}
+
+// pathOrder - возвращает заявление по номеру из пути запроса
+// либо ответ с ошибкой, если заявление не найдено.
+func (s *Server) pathOrder(r *http.Request, suffix string) (*order, response) {
+	value := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), suffix)
+	orderId, err := strconv.Atoi(path.Base(value))
+	if err != nil {
+		return nil, badRequest("некорректный номер заявления")
+	}
+	o, ok := s.orders[orderId]
+	if !ok {
+		return nil, errorResponse(http.StatusNoContent, "", "")
+	}
+	return o, response{}
+}
+
+// parseMeta - разбирает метаданные заявления.
+func parseMeta(r io.Reader) (apipgu.OrderMeta, error) {
+	dto := struct {
+		Region      string `json:"region"`
+		ServiceCode string `json:"serviceCode"`
+		TargetCode  string `json:"targetCode"`
+	}{}
+	if err := json.NewDecoder(r).Decode(&dto); err != nil {
+		return apipgu.OrderMeta{}, fmt.Errorf("некорректные метаданные заявления: %w", err)
+	}
+	if dto.Region == "" || dto.ServiceCode == "" || dto.TargetCode == "" {
+		return apipgu.OrderMeta{}, fmt.Errorf("не указаны region, serviceCode или targetCode")
+	}
+	return apipgu.OrderMeta{Region: dto.Region, ServiceCode: dto.ServiceCode, TargetCode: dto.TargetCode}, nil
+}
+
+// formFile - возвращает содержимое поля file multipart-запроса.
+func formFile(r *http.Request) ([]byte, error) {
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("не передан файл: %w", err)
+	}
+	//goland:noinspection ALL
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// readZipFile - возвращает содержимое файла zip-архива.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection ALL
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// fileId - идентификатор файла заявления, например "1230254874/files/cmVxLnhtbA".
+func fileId(orderId int, name string) string {
+	return fmt.Sprintf("%d/files/%s", orderId, base64.RawURLEncoding.EncodeToString([]byte(name)))
+}
@@ -0,0 +1,340 @@
+package epgutest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// Op - метод API ЕПГУ, используется в [Server.WithFault] и [Server.Requests].
+type Op string
+
+// Методы API ЕПГУ.
+const (
+	OpOrderCreate  Op = "OrderCreate"        // POST /api/gusmev/order
+	OpPush         Op = "OrderPush"          // POST /api/gusmev/push
+	OpPushChunked  Op = "OrderPushChunked"   // POST /api/gusmev/push/chunked
+	OpOrderInfo    Op = "OrderInfo"          // POST /api/gusmev/order/{orderId}
+	OpOrderCancel  Op = "OrderCancel"        // POST /api/gusmev/order/{orderId}/cancel
+	OpOrdersStatus Op = "OrdersStatus"       // GET /api/gusmev/order/getOrdersStatus
+	OpUpdatedAfter Op = "UpdatedAfter"       // GET /api/gusmev/order/getUpdatedAfter
+	OpDict         Op = "Dict"               // POST /api/nsi/v1/dictionary/{code}
+	OpDownload     Op = "AttachmentDownload" // GET /api/storage/v2/files/{objectId}/{objectType}/download
+)
+
+// Fault - ошибка, которую сервер возвращает вместо обработки запроса.
+//
+// Пример: 3 ответа HTTP 429, затем обычная обработка запросов:
+//
+//	server.WithFault(epgutest.OpPushChunked, epgutest.Fault{Status: 429, Times: 3})
+//
+// Пример: недоступность сервиса (HTTP 502) в течение минуты с момента первого запроса:
+//
+//	server.WithFault(epgutest.OpOrderInfo, epgutest.Fault{Status: 502, Duration: time.Minute})
+//
+// Пример: ошибка ЕПГУ limitation_exception на каждый запрос:
+//
+//	server.WithFault(epgutest.OpOrderCreate, epgutest.Fault{Status: 409, Code: "limitation_exception"})
+type Fault struct {
+	Status  int           // HTTP-код ответа; 0 - только задержка Delay, затем обычная обработка запроса
+	Code    string        // Код ошибки ЕПГУ в JSON-ответе (например, "limitation_exception"); пусто - ответ без тела
+	Message string        // Текст ошибки ЕПГУ в JSON-ответе
+	Delay   time.Duration // Задержка перед ответом
+	Times   int           // Количество срабатываний; 0 - на каждый запрос
+
+	// Duration - длительность действия ошибки с момента первого срабатывания; 0 - без ограничения.
+	Duration time.Duration
+
+	started time.Time
+}
+
+// Server - фейковый сервер API ЕПГУ.
+// Реализует [http.Handler], поэтому может использоваться без [httptest.Server], см [New].
+type Server struct {
+	URL string // Базовый URL запущенного сервера для [apipgu.NewClient], см [NewServer]
+
+	httpServer   *httptest.Server
+	mu           sync.Mutex
+	now          func() time.Time
+	tokens       map[string]struct{}
+	revoked      map[string]struct{}
+	scenarios    map[string]Scenario
+	autoAdvance  bool
+	faults       map[Op][]*Fault
+	requests     map[Op]int
+	orders       map[int]*order
+	files        map[string]storedFile
+	dicts        map[string][]apipgu.DictItem
+	lastOrderId  int
+	lastStatusId int
+}
+
+// New - конструктор [Server] без запуска HTTP-сервера.
+func New() *Server {
+	return &Server{
+		now:          time.Now,
+		tokens:       make(map[string]struct{}),
+		revoked:      make(map[string]struct{}),
+		scenarios:    make(map[string]Scenario),
+		faults:       make(map[Op][]*Fault),
+		requests:     make(map[Op]int),
+		orders:       make(map[int]*order),
+		files:        make(map[string]storedFile),
+		dicts:        make(map[string][]apipgu.DictItem),
+		lastOrderId:  1230254873,
+		lastStatusId: 12300710520,
+	}
+}
+
+// NewServer - конструктор [Server], запускает HTTP-сервер [httptest.Server].
+// Адрес сервера: [Server.URL]. После использования сервер необходимо остановить: [Server.Close].
+func NewServer() *Server {
+	s := New()
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close - останавливает HTTP-сервер, запущенный [NewServer].
+func (s *Server) Close() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// WithTokens - задает допустимые маркеры доступа.
+// Если маркеры не заданы, допустимым считается любой непустой маркер.
+// На запрос с недопустимым маркером сервер отвечает HTTP 401.
+func (s *Server) WithTokens(tokens ...string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range tokens {
+		s.tokens[token] = struct{}{}
+	}
+	return s
+}
+
+// RevokeToken - отзывает маркер доступа (например, для проверки обновления истекшего маркера).
+func (s *Server) RevokeToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[token] = struct{}{}
+}
+
+// WithScenario - задает сценарий обработки заявлений услуги с кодом serviceCode
+// (поле serviceCode метаданных заявления [apipgu.OrderMeta]).
+func (s *Server) WithScenario(serviceCode string, scenario Scenario) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[serviceCode] = scenario
+	return s
+}
+
+// WithAutoAdvance - включает переключение заявления на следующий статус сценария
+// при каждом запросе деталей заявления [apipgu.Client.OrderInfo].
+func (s *Server) WithAutoAdvance() *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoAdvance = true
+	return s
+}
+
+// WithFault - добавляет ошибку, которую сервер возвращает на запросы метода op.
+// Ошибки срабатывают в порядке добавления.
+func (s *Server) WithFault(op Op, fault Fault) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[op] = append(s.faults[op], &fault)
+	return s
+}
+
+// ClearFaults - удаляет все ошибки, добавленные [Server.WithFault].
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[Op][]*Fault)
+}
+
+// WithDict - добавляет элементы справочника с кодом code.
+// Иерархия элементов задается полем [apipgu.DictItem].ParentValue.
+func (s *Server) WithDict(code string, items ...apipgu.DictItem) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dicts[code] = append(s.dicts[code], items...)
+	return s
+}
+
+// WithClock - задает функцию текущего времени (по умолчанию [time.Now]).
+// Используется для дат статусов и проверки времени загрузки чанков.
+func (s *Server) WithClock(now func() time.Time) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+	return s
+}
+
+// Requests - возвращает количество запросов к методу op, включая запросы, завершившиеся ошибкой.
+func (s *Server) Requests(op Op) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[op]
+}
+
+// ServeHTTP - реализация [http.Handler].
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	post, get := r.Method == http.MethodPost, r.Method == http.MethodGet
+
+	switch {
+	case post && path == "/api/gusmev/order":
+		s.handle(w, r, OpOrderCreate, true, s.orderCreate)
+	case post && path == "/api/gusmev/push":
+		s.handle(w, r, OpPush, true, s.push)
+	case post && path == "/api/gusmev/push/chunked":
+		s.handle(w, r, OpPushChunked, true, s.pushChunked)
+	case get && path == "/api/gusmev/order/getOrdersStatus":
+		s.handle(w, r, OpOrdersStatus, true, s.ordersStatus)
+	case get && path == "/api/gusmev/order/getUpdatedAfter":
+		s.handle(w, r, OpUpdatedAfter, true, s.updatedAfter)
+	case post && strings.HasPrefix(path, "/api/gusmev/order/") && strings.HasSuffix(path, "/cancel"):
+		s.handle(w, r, OpOrderCancel, true, s.orderCancel)
+	case post && strings.HasPrefix(path, "/api/gusmev/order/"):
+		s.handle(w, r, OpOrderInfo, true, s.orderInfo)
+	case post && strings.HasPrefix(path, "/api/nsi/v1/dictionary/"):
+		s.handle(w, r, OpDict, false, s.dict)
+	case get && strings.HasPrefix(path, "/api/storage/v2/files/") && strings.HasSuffix(path, "/download"):
+		s.handle(w, r, OpDownload, true, s.download)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// response - ответ сервера.
+type response struct {
+	status      int
+	body        any    // JSON-содержимое ответа
+	raw         []byte // Двоичное содержимое ответа
+	contentType string // Тип двоичного содержимого
+}
+
+// jsonResponse - ответ с JSON-содержимым.
+func jsonResponse(status int, body any) response {
+	return response{status: status, body: body}
+}
+
+// errorResponse - ответ с ошибкой ЕПГУ.
+//
+// Подробнее см. "Спецификация API ЕПГУ версия 1.12",
+// "Приложение 4. Ошибки, возвращаемые при запросах к API ЕПГУ"
+func errorResponse(status int, code, message string) response {
+	if code == "" {
+		return response{status: status}
+	}
+	return jsonResponse(status, map[string]string{"code": code, "message": message})
+}
+
+func (res response) write(w http.ResponseWriter) {
+	switch {
+	case res.raw != nil:
+		w.Header().Set("Content-Type", res.contentType)
+		w.WriteHeader(res.status)
+		_, _ = w.Write(res.raw)
+	case res.body != nil:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(res.status)
+		_ = json.NewEncoder(w).Encode(res.body)
+	default:
+		w.WriteHeader(res.status)
+	}
+}
+
+// handle - проверяет ошибки [Fault] и маркер доступа, затем обрабатывает запрос.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, op Op, auth bool, h func(*http.Request) response) {
+	s.mu.Lock()
+	s.requests[op]++
+	fault := s.fault(op)
+	s.mu.Unlock()
+
+	if fault != nil {
+		time.Sleep(fault.Delay)
+		if fault.Status != 0 {
+			errorResponse(fault.Status, fault.Code, fault.Message).write(w)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceDue()
+	if auth && !s.authorized(r) {
+		errorResponse(http.StatusUnauthorized, "", "").write(w)
+		return
+	}
+	h(r).write(w)
+}
+
+// fault - возвращает очередную ошибку метода op или nil.
+// Ошибки с истекшим сроком действия [Fault].Duration удаляются.
+func (s *Server) fault(op Op) *Fault {
+	now := s.now()
+	faults := s.faults[op][:0]
+	for _, f := range s.faults[op] {
+		if f.Duration > 0 && !f.started.IsZero() && now.Sub(f.started) >= f.Duration {
+			continue
+		}
+		faults = append(faults, f)
+	}
+	s.faults[op] = faults
+
+	for i, f := range s.faults[op] {
+		if f.Duration > 0 && f.started.IsZero() {
+			f.started = now
+		}
+		if f.Times == 0 {
+			return f
+		}
+		if f.Times == 1 {
+			s.faults[op] = append(s.faults[op][:i:i], s.faults[op][i+1:]...)
+		}
+		f.Times--
+		return f
+	}
+	return nil
+}
+
+// authorized - проверяет маркер доступа в заголовке Authorization.
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header || token == "" {
+		return false
+	}
+	if _, ok := s.revoked[token]; ok {
+		return false
+	}
+	if len(s.tokens) == 0 {
+		return true
+	}
+	_, ok := s.tokens[token]
+	return ok
+}
+
+// badRequest - ответ HTTP 400 с ошибкой ЕПГУ bad_request.
+func badRequest(message string) response {
+	return errorResponse(http.StatusBadRequest, "bad_request", message)
+}
+
+// intParam - возвращает целочисленный параметр запроса или def, если параметр не передан.
+func intParam(r *http.Request, name string, def int) (int, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
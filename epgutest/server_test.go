@@ -0,0 +1,482 @@
+package epgutest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+const testToken = "test-token"
+
+var testMeta = apipgu.OrderMeta{Region: "45000000000", ServiceCode: "10000000109", TargetCode: "-10000000109"}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(suiteServer))
+}
+
+type suiteServer struct {
+	suite.Suite
+	server *Server
+	client *apipgu.Client
+}
+
+func (suite *suiteServer) SetupTest() {
+	suite.server = NewServer().WithTokens(testToken)
+	suite.client = apipgu.NewClient(suite.server.URL)
+}
+
+func (suite *suiteServer) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *suiteServer) TestOrderPushChunked() {
+	file := apipgu.ArchiveFile{Filename: "req_test.xml", Data: make([]byte, 1000)}
+	_, err := rand.Read(file.Data)
+	suite.Require().NoError(err)
+	archive, err := apipgu.NewArchive("test-archive", file)
+	suite.Require().NoError(err)
+
+	orderId, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+	suite.NoError(suite.client.WithChunkSize(300).OrderPushChunked(testToken, orderId, archive))
+	suite.Equal(1+(len(archive.Data)-1)/300, suite.server.Requests(OpPushChunked))
+	suite.Equal(archive.Data, suite.server.Archive(orderId))
+
+	info, err := suite.client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Equal(CodeOK, info.Code)
+	suite.Require().NotNil(info.Order)
+	suite.Equal(orderId, info.Order.Id)
+	suite.Equal(21, info.Order.OrderStatusId)
+	suite.Len(info.Order.Statuses, 3)
+	suite.Equal("10000000109", info.Order.EserviceId)
+	suite.Require().Len(info.Order.OrderAttachmentFiles, 1)
+	attachment := info.Order.OrderAttachmentFiles[0]
+	suite.Equal("req_test.xml", attachment.FileName)
+	suite.Equal("REQUEST", attachment.Type)
+	suite.Equal(fmt.Sprintf("terrabyte://00/%d/req_test.xml/2", orderId), attachment.Link)
+
+	data, err := suite.client.AttachmentDownload(testToken, attachment.Link)
+	suite.NoError(err)
+	suite.Equal(file.Data, data)
+}
+
+func (suite *suiteServer) TestOrderPushChunkedOrder() {
+	orderId, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+
+	suite.Run("first chunk must be sent first", func() {
+		status, body := suite.pushChunk(orderId, 1, 3, []byte("b"))
+		suite.Equal(http.StatusBadRequest, status)
+		suite.Contains(body, "bad_request")
+	})
+
+	suite.Run("last chunk must be sent last", func() {
+		status, _ := suite.pushChunk(orderId, 0, 3, []byte("a"))
+		suite.Equal(http.StatusPartialContent, status)
+		status, body := suite.pushChunk(orderId, 2, 3, []byte("c"))
+		suite.Equal(http.StatusBadRequest, status)
+		suite.Contains(body, "последним должен быть отправлен последний чанк")
+	})
+
+	suite.Run("middle chunk", func() {
+		status, body := suite.pushChunk(orderId, 1, 3, []byte("b"))
+		suite.Equal(http.StatusPartialContent, status)
+		suite.JSONEq(fmt.Sprintf(`{"orderId":%d}`, orderId), body)
+		status, _ = suite.pushChunk(orderId, 1, 3, []byte("b"))
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("last chunk", func() {
+		status, _ := suite.pushChunk(orderId, 2, 3, []byte("c"))
+		suite.Equal(http.StatusOK, status)
+		suite.Equal([]byte("abc"), suite.server.Archive(orderId))
+
+		info, err := suite.client.OrderInfo(testToken, orderId)
+		suite.Require().NoError(err)
+		suite.Equal(CodeInvalidFilesStructure, info.Code)
+		suite.Nil(info.Order)
+	})
+
+	suite.Run("already pushed", func() {
+		status, _ := suite.pushChunk(orderId, 0, 1, []byte("a"))
+		suite.Equal(http.StatusBadRequest, status)
+	})
+
+	suite.Run("unknown order", func() {
+		err := suite.client.OrderPushChunked(testToken, 1, &apipgu.Archive{Data: []byte("a")})
+		suite.ErrorIs(err, apipgu.ErrStatusOrderNotFound)
+	})
+}
+
+func (suite *suiteServer) TestOrderPushChunkedTimeout() {
+	now := time.Date(2023, 11, 2, 7, 27, 22, 0, time.Local)
+	suite.server.WithClock(func() time.Time { return now })
+	orderId, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+
+	status, _ := suite.pushChunk(orderId, 0, 2, []byte("a"))
+	suite.Equal(http.StatusPartialContent, status)
+	now = now.Add(ChunkTimeout + time.Second)
+	status, body := suite.pushChunk(orderId, 1, 2, []byte("b"))
+	suite.Equal(http.StatusBadRequest, status)
+	suite.Contains(body, "истекло время загрузки чанков")
+}
+
+func (suite *suiteServer) TestOrderPushScenario() {
+	suite.server.WithScenario(testMeta.ServiceCode, Scenario{
+		Sender: "СФР",
+		Steps: []Step{
+			{StatusId: 2, Title: "Заявление получено ведомством", Comment: "Сообщение доставлено", CancelAllowed: true},
+			{StatusId: 3, Title: "Исполнено", Final: true, Files: []File{
+				{Name: "resp.xml", Data: []byte("<resp/>")},
+				{Name: "resp.xml.sig", Data: []byte("signature")},
+			}},
+		},
+	})
+	archive, err := apipgu.NewArchive("test", apipgu.ArchiveFile{Filename: "req.xml", Data: []byte("<req/>")})
+	suite.Require().NoError(err)
+
+	orderId, err := suite.client.OrderPush(testToken, testMeta, archive)
+	suite.Require().NoError(err)
+	suite.Equal(archive.Data, suite.server.Archive(orderId))
+
+	suite.Require().NoError(suite.server.Advance(orderId))
+	info, err := suite.client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Equal(2, info.Order.OrderStatusId)
+	suite.Equal("Сообщение доставлено", info.Order.CurrentStatusHistory.Comment)
+	suite.Equal("СФР", info.Order.CurrentStatusHistory.Sender)
+	suite.False(info.Order.Closed)
+
+	suite.Require().NoError(suite.server.Advance(orderId))
+	info, err = suite.client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Equal(3, info.Order.OrderStatusId)
+	suite.True(info.Order.Closed)
+	suite.True(info.Order.HasResult)
+	suite.Require().Len(info.Order.OrderResponseFiles, 2)
+
+	files := apipgu.SignedResponseFiles(info.Order.OrderResponseFiles)
+	suite.Require().Len(files, 1)
+	data, err := suite.client.AttachmentDownload(testToken, files[0].SignatureLink)
+	suite.NoError(err)
+	suite.Equal([]byte("signature"), data)
+
+	suite.ErrorIs(suite.server.Advance(orderId), ErrNoSteps)
+	suite.ErrorIs(suite.server.Advance(1), ErrOrderNotFound)
+}
+
+func (suite *suiteServer) TestOrderInfo() {
+	suite.Run("escaped order json", func() {
+		orderId := suite.pushOrder()
+		res := suite.request(http.MethodPost, fmt.Sprintf("/api/gusmev/order/%d", orderId), testToken)
+		suite.Equal(http.StatusOK, res.StatusCode)
+		dto := struct {
+			Code  string `json:"code"`
+			Order string `json:"order"`
+		}{}
+		suite.Require().NoError(json.NewDecoder(res.Body).Decode(&dto))
+		suite.Equal("OK", dto.Code)
+		details := apipgu.OrderDetails{}
+		suite.Require().NoError(json.Unmarshal([]byte(dto.Order), &details))
+		suite.Equal(orderId, details.Id)
+		suite.Equal("Заявление отправлено в ведомство", details.OrderStatusName)
+	})
+
+	suite.Run("order not pushed", func() {
+		orderId, err := suite.client.OrderCreate(testToken, testMeta)
+		suite.Require().NoError(err)
+		info, err := suite.client.OrderInfo(testToken, orderId)
+		suite.Require().NoError(err)
+		suite.Equal(CodeNew, info.Code)
+		suite.Nil(info.Order)
+		suite.ErrorIs(suite.server.Advance(orderId), ErrOrderNotPushed)
+	})
+
+	suite.Run("order not found", func() {
+		_, err := suite.client.OrderInfo(testToken, 1)
+		suite.ErrorIs(err, apipgu.ErrStatusOrderNotFound)
+	})
+
+	suite.Run("processing error", func() {
+		suite.server.WithScenario("error-service", Scenario{Code: CodeValidationError, Message: "ошибка валидации"})
+		archive, err := apipgu.NewArchive("test", apipgu.ArchiveFile{Filename: "req.xml", Data: []byte("<req/>")})
+		suite.Require().NoError(err)
+		meta := testMeta
+		meta.ServiceCode = "error-service"
+		orderId, err := suite.client.OrderPush(testToken, meta, archive)
+		suite.Require().NoError(err)
+		info, err := suite.client.OrderInfo(testToken, orderId)
+		suite.Require().NoError(err)
+		suite.Equal(CodeValidationError, info.Code)
+		suite.Equal("ошибка валидации", info.Message)
+		suite.Nil(info.Order)
+	})
+}
+
+func (suite *suiteServer) TestAutoAdvance() {
+	suite.server.WithAutoAdvance().WithScenario(testMeta.ServiceCode, Scenario{
+		Steps: []Step{{StatusId: 2, Title: "Заявление получено ведомством"}, {StatusId: 3, Title: "Исполнено", Final: true}},
+	})
+	orderId := suite.pushOrder()
+	for _, want := range []int{2, 3, 3} {
+		info, err := suite.client.OrderInfo(testToken, orderId)
+		suite.Require().NoError(err)
+		suite.Equal(want, info.Order.OrderStatusId)
+	}
+}
+
+func (suite *suiteServer) TestStepDelay() {
+	now := time.Date(2023, 11, 2, 7, 27, 22, 0, time.Local)
+	suite.server.WithClock(func() time.Time { return now }).WithScenario(testMeta.ServiceCode, Scenario{
+		Steps: []Step{
+			{StatusId: 2, Title: "Заявление получено ведомством", Delay: time.Minute},
+			{StatusId: 3, Title: "Исполнено", Final: true, Delay: time.Minute},
+		},
+	})
+	orderId := suite.pushOrder()
+	pushed := suite.server.Order(orderId).Updated.Time
+
+	now = now.Add(59 * time.Second)
+	info, err := suite.client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Equal(21, info.Order.OrderStatusId)
+
+	now = now.Add(2 * time.Minute)
+	info, err = suite.client.OrderInfo(testToken, orderId)
+	suite.Require().NoError(err)
+	suite.Equal(3, info.Order.OrderStatusId)
+	suite.Equal(pushed.Add(2*time.Minute), info.Order.Updated.Time)
+	suite.ErrorIs(suite.server.Advance(orderId), ErrNoSteps)
+}
+
+func (suite *suiteServer) TestOrderCancel() {
+	suite.server.WithScenario(testMeta.ServiceCode, Scenario{
+		Steps: []Step{{StatusId: 2, Title: "Заявление получено ведомством", CancelAllowed: true}},
+	})
+	orderId := suite.pushOrder()
+
+	err := suite.client.OrderCancel(testToken, orderId)
+	suite.ErrorIs(err, apipgu.ErrCodeCancelNotAllowed)
+
+	suite.Require().NoError(suite.server.Advance(orderId))
+	suite.NoError(suite.client.OrderCancel(testToken, orderId))
+	details := suite.server.Order(orderId)
+	suite.Require().NotNil(details)
+	suite.Equal(StepCancelled.StatusId, details.OrderStatusId)
+	suite.True(details.Closed)
+}
+
+func (suite *suiteServer) TestTokens() {
+	suite.Run("invalid token", func() {
+		_, err := suite.client.OrderCreate("wrong-token", testMeta)
+		suite.ErrorIs(err, apipgu.ErrStatusUnauthorized)
+	})
+
+	suite.Run("no token", func() {
+		res := suite.request(http.MethodPost, "/api/gusmev/order/1", "")
+		suite.Equal(http.StatusUnauthorized, res.StatusCode)
+	})
+
+	suite.Run("revoked token", func() {
+		_, err := suite.client.OrderCreate(testToken, testMeta)
+		suite.NoError(err)
+		suite.server.RevokeToken(testToken)
+		_, err = suite.client.OrderCreate(testToken, testMeta)
+		suite.ErrorIs(err, apipgu.ErrStatusUnauthorized)
+	})
+}
+
+func (suite *suiteServer) TestFaults() {
+	suite.server.
+		WithFault(OpOrderCreate, Fault{Status: http.StatusTooManyRequests, Times: 2}).
+		WithFault(OpOrderCreate, Fault{Status: http.StatusConflict, Code: "limitation_exception", Times: 1})
+
+	_, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.ErrorIs(err, apipgu.ErrStatusTooManyRequests)
+	_, err = suite.client.OrderCreate(testToken, testMeta)
+	suite.ErrorIs(err, apipgu.ErrStatusTooManyRequests)
+	_, err = suite.client.OrderCreate(testToken, testMeta)
+	suite.ErrorIs(err, apipgu.ErrCodeLimitationException)
+	_, err = suite.client.OrderCreate(testToken, testMeta)
+	suite.NoError(err)
+	suite.Equal(4, suite.server.Requests(OpOrderCreate))
+}
+
+func (suite *suiteServer) TestFaultDuration() {
+	now := time.Date(2023, 11, 2, 7, 27, 22, 0, time.Local)
+	suite.server.
+		WithClock(func() time.Time { return now }).
+		WithFault(OpOrderCreate, Fault{Status: http.StatusBadGateway, Duration: time.Minute})
+
+	_, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.ErrorIs(err, apipgu.ErrStatusBadGateway)
+	now = now.Add(59 * time.Second)
+	_, err = suite.client.OrderCreate(testToken, testMeta)
+	suite.ErrorIs(err, apipgu.ErrStatusBadGateway)
+	now = now.Add(time.Second)
+	_, err = suite.client.OrderCreate(testToken, testMeta)
+	suite.NoError(err)
+
+	suite.server.WithFault(OpOrderCreate, Fault{Status: http.StatusBadGateway})
+	suite.server.ClearFaults()
+	_, err = suite.client.OrderCreate(testToken, testMeta)
+	suite.NoError(err)
+}
+
+func (suite *suiteServer) TestOrdersStatus() {
+	first := suite.pushOrder()
+	second := suite.pushOrder()
+	notPushed, err := suite.client.OrderCreate(testToken, testMeta)
+	suite.Require().NoError(err)
+
+	suite.Run("getOrdersStatus", func() {
+		res := suite.request(
+			http.MethodGet,
+			fmt.Sprintf("/api/gusmev/order/getOrdersStatus?orderIds=%d,%d,%d,1&pageNum=0&pageSize=3", first, second, notPushed),
+			testToken,
+		)
+		suite.Equal(http.StatusOK, res.StatusCode)
+		dto := dtoOrdersStatusResponse{}
+		suite.Require().NoError(json.NewDecoder(res.Body).Decode(&dto))
+		suite.Equal(3, dto.Count)
+		suite.Equal(4, dto.TotalCount)
+		suite.Equal("FOUND", dto.Content[0].OrderSearchStatus)
+		suite.Require().NotNil(dto.Content[0].Status)
+		suite.Equal(21, dto.Content[0].Status.StatusId)
+		suite.Equal("Заявление отправлено в ведомство", dto.Content[0].Status.StatusName)
+		suite.Nil(dto.Content[2].Status)
+	})
+
+	suite.Run("getUpdatedAfter", func() {
+		after := time.Now().Add(-time.Minute).In(apipgu.Moscow).Format(statusLayout)
+		res := suite.request(http.MethodGet, "/api/gusmev/order/getUpdatedAfter?pageNum=0&pageSize=5&updatedAfter="+after, testToken)
+		suite.Equal(http.StatusOK, res.StatusCode)
+		dto := dtoOrdersStatusResponse{}
+		suite.Require().NoError(json.NewDecoder(res.Body).Decode(&dto))
+		suite.Equal(2, dto.TotalCount)
+		suite.Equal(second, dto.Content[0].OrderId)
+		suite.Equal(first, dto.Content[1].OrderId)
+	})
+
+	suite.Run("client", func() {
+		status, err := suite.client.OrdersStatus(testToken, []int{first, 1}, 0, 0)
+		suite.Require().NoError(err)
+		suite.Equal(2, status.TotalCount)
+		suite.Equal(apipgu.OrderSearchFound, status.Content[0].OrderSearchStatus)
+		suite.Require().NotNil(status.Content[0].Status)
+		suite.WithinDuration(time.Now(), status.Content[0].Status.Updated.Time, time.Minute)
+		suite.Equal(apipgu.OrderSearchNotFound, status.Content[1].OrderSearchStatus)
+
+		updated, err := suite.client.OrdersUpdatedAfter(testToken, time.Now().Add(-time.Minute), 1, 1)
+		suite.Require().NoError(err)
+		suite.Equal(2, updated.TotalCount)
+		suite.Require().Len(updated.Content, 1)
+		suite.Equal(first, updated.Content[0].OrderId)
+	})
+
+	suite.Run("getUpdatedAfter bad timestamp", func() {
+		res := suite.request(http.MethodGet, "/api/gusmev/order/getUpdatedAfter?updatedAfter=yesterday", testToken)
+		suite.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func (suite *suiteServer) TestDict() {
+	suite.server.WithDict("TEST_DICT",
+		apipgu.DictItem{Value: "1", Title: "Первый"},
+		apipgu.DictItem{Value: "1.1", ParentValue: "1", Title: "Первый - первый"},
+		apipgu.DictItem{Value: "1.1.1", ParentValue: "1.1", Title: "Первый - первый - первый"},
+		apipgu.DictItem{Value: "2", Title: "Второй"},
+	)
+
+	suite.Run("one level", func() {
+		items, total, err := suite.client.Dict("TEST_DICT", apipgu.DictFilterOneLevel, "", 0, 0)
+		suite.NoError(err)
+		suite.Equal(2, total)
+		suite.Len(items, 2)
+	})
+
+	suite.Run("subtree with parent", func() {
+		items, total, err := suite.client.Dict("TEST_DICT", apipgu.DictFilterSubTree, "1", 0, 0)
+		suite.NoError(err)
+		suite.Equal(2, total)
+		suite.Equal("1.1.1", items[1].Value)
+	})
+
+	suite.Run("pagination", func() {
+		items, total, err := suite.client.Dict("TEST_DICT", apipgu.DictFilterSubTree, "", 2, 3)
+		suite.NoError(err)
+		suite.Equal(4, total)
+		suite.Require().Len(items, 1)
+		suite.Equal("2", items[0].Value)
+	})
+
+	suite.Run("not found", func() {
+		_, _, err := suite.client.Dict("UNKNOWN", apipgu.DictFilterOneLevel, "", 0, 0)
+		suite.ErrorIs(err, apipgu.ErrDictResponse)
+	})
+}
+
+func (suite *suiteServer) TestDownloadNotFound() {
+	_, err := suite.client.AttachmentDownload(testToken, "terrabyte://00/1/req.xml/2")
+	suite.ErrorIs(err, apipgu.ErrStatusURLNotFound)
+}
+
+// pushOrder - создает заявление и загружает архив.
+func (suite *suiteServer) pushOrder() int {
+	archive, err := apipgu.NewArchive("test", apipgu.ArchiveFile{Filename: "req.xml", Data: []byte("<req/>")})
+	suite.Require().NoError(err)
+	orderId, err := suite.client.OrderPush(testToken, testMeta, archive)
+	suite.Require().NoError(err)
+	return orderId
+}
+
+// pushChunk - отправляет чанк архива и возвращает HTTP-код и тело ответа.
+func (suite *suiteServer) pushChunk(orderId, chunk, chunks int, data []byte) (int, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	suite.Require().NoError(w.WriteField("orderId", fmt.Sprintf("%d", orderId)))
+	suite.Require().NoError(w.WriteField("chunk", fmt.Sprintf("%d", chunk)))
+	suite.Require().NoError(w.WriteField("chunks", fmt.Sprintf("%d", chunks)))
+	fw, err := w.CreateFormFile("file", fmt.Sprintf("test.z%03d", chunk+1))
+	suite.Require().NoError(err)
+	_, err = fw.Write(data)
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Close())
+
+	req, err := http.NewRequest(http.MethodPost, suite.server.URL+"/api/gusmev/push/chunked", body)
+	suite.Require().NoError(err)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	res, err := http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+	//goland:noinspection ALL
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	suite.Require().NoError(err)
+	return res.StatusCode, string(resBody)
+}
+
+// request - выполняет запрос к серверу.
+func (suite *suiteServer) request(method, uri, token string) *http.Response {
+	req, err := http.NewRequest(method, suite.server.URL+uri, nil)
+	suite.Require().NoError(err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { _ = res.Body.Close() })
+	return res
+}
@@ -0,0 +1,141 @@
+package epgutest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+// statusLayout - формат даты и времени методов получения статусов заявлений
+// (московское время, см [apipgu.Moscow]):
+//
+//	2022-12-21T20:49:37.672
+const statusLayout = "2006-01-02T15:04:05.000"
+
+// dtoOrdersStatusResponse - ответ методов getOrdersStatus и getUpdatedAfter.
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.3. Получение статусов заявлений".
+type dtoOrdersStatusResponse struct {
+	Count      int                    `json:"count"`      // Количество записей в массиве content
+	TotalCount int                    `json:"totalCount"` // Количество найденных записей
+	Content    []dtoOrderStatusRecord `json:"content"`    // Записи
+}
+
+// dtoOrderStatusRecord - запись ответа методов getOrdersStatus и getUpdatedAfter.
+type dtoOrderStatusRecord struct {
+	OrderId           int             `json:"orderId"`           // Номер заявления
+	OrderSearchStatus string          `json:"orderSearchStatus"` // FOUND / NOT_FOUND
+	Status            *dtoOrderStatus `json:"status"`            // Текущий статус заявления
+}
+
+// dtoOrderStatus - текущий статус заявления.
+type dtoOrderStatus struct {
+	StatusId   int    `json:"statusId"`   // Код статуса
+	StatusName string `json:"statusName"` // Наименование статуса
+	Updated    string `json:"updated"`    // Дата и время обновления статуса
+}
+
+// ordersStatus - GET /api/gusmev/order/getOrdersStatus?pageNum={n}&pageSize={m}&orderIds={array[integer]}
+func (s *Server) ordersStatus(r *http.Request) response {
+	var ids []int
+	for _, param := range r.URL.Query()["orderIds"] {
+		for _, value := range strings.Split(param, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return badRequest("некорректный параметр orderIds")
+			}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return badRequest("не указан параметр orderIds")
+	}
+
+	records := make([]dtoOrderStatusRecord, 0, len(ids))
+	for _, id := range ids {
+		records = append(records, s.statusRecord(id))
+	}
+	return s.statusPage(r, records)
+}
+
+// updatedAfter - GET /api/gusmev/order/getUpdatedAfter?pageNum={n}&pageSize={m}&updatedAfter={timestamp}
+func (s *Server) updatedAfter(r *http.Request) response {
+	after, err := time.ParseInLocation(statusLayout, r.URL.Query().Get("updatedAfter"), apipgu.Moscow)
+	if err != nil {
+		return badRequest("некорректный параметр updatedAfter")
+	}
+
+	var orders []*order
+	for _, o := range s.orders {
+		if len(o.statuses) > 0 && o.updated.After(after) {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].updated.Equal(orders[j].updated) {
+			return orders[i].id > orders[j].id
+		}
+		return orders[i].updated.After(orders[j].updated)
+	})
+
+	records := make([]dtoOrderStatusRecord, 0, len(orders))
+	for _, o := range orders {
+		records = append(records, s.statusRecord(o.id))
+	}
+	return s.statusPage(r, records)
+}
+
+// statusRecord - запись о текущем статусе заявления.
+func (s *Server) statusRecord(orderId int) dtoOrderStatusRecord {
+	o, ok := s.orders[orderId]
+	if !ok {
+		return dtoOrderStatusRecord{OrderId: orderId, OrderSearchStatus: "NOT_FOUND"}
+	}
+	record := dtoOrderStatusRecord{OrderId: orderId, OrderSearchStatus: "FOUND"}
+	if len(o.statuses) > 0 {
+		current := o.statuses[len(o.statuses)-1]
+		record.Status = &dtoOrderStatus{
+			StatusId:   current.StatusId,
+			StatusName: current.Title,
+			Updated:    o.updated.In(apipgu.Moscow).Format(statusLayout),
+		}
+	}
+	return record
+}
+
+// statusPage - ответ со страницей pageNum (начиная с 0) размером pageSize.
+// Если pageSize не указан, возвращаются все записи.
+func (s *Server) statusPage(r *http.Request, records []dtoOrderStatusRecord) response {
+	pageNum, err1 := intParam(r, "pageNum", 0)
+	pageSize, err2 := intParam(r, "pageSize", 0)
+	if err1 != nil || err2 != nil || pageNum < 0 || pageSize < 0 {
+		return badRequest("некорректные параметры pageNum и pageSize")
+	}
+	page := paginate(records, pageNum, pageSize)
+	return jsonResponse(http.StatusOK, dtoOrdersStatusResponse{
+		Count:      len(page),
+		TotalCount: len(records),
+		Content:    page,
+	})
+}
+
+// paginate - возвращает страницу pageNum (начиная с 0) размером pageSize; pageSize = 0 - все элементы.
+func paginate[T any](items []T, pageNum, pageSize int) []T {
+	if pageSize == 0 {
+		return items
+	}
+	start := pageNum * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
@@ -0,0 +1,68 @@
+package epgutest
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// store - сохраняет файл заявления в хранилище и возвращает ссылку на него, например:
+//
+//	terrabyte://00/1230254874/req_8d8567db-d445-4759-a122-6b4cefeca22c.xml/2
+//
+// Файлам заявления соответствует тип объекта "2", файлам ответа ведомства - "1".
+func (s *Server) store(orderId int, name, objectType, contentType string, data []byte) string {
+	if contentType == "" {
+		contentType = mimeType(name)
+	}
+	s.files[fileKey(orderId, name, objectType)] = storedFile{mimeType: contentType, data: data}
+	return fmt.Sprintf("terrabyte://00/%d/%s/%s", orderId, name, objectType)
+}
+
+// download - GET /api/storage/v2/files/{objectId}/{objectType}/download?mnemonic={mnemonic}
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "4. Скачивание файла".
+func (s *Server) download(r *http.Request) response {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/storage/v2/files/"), "/")
+	if len(parts) != 3 {
+		return badRequest("некорректный путь запроса")
+	}
+	key := parts[0] + "/" + r.URL.Query().Get("mnemonic") + "/" + parts[1]
+	f, ok := s.files[key]
+	if !ok {
+		return errorResponse(http.StatusNotFound, "", "")
+	}
+	return response{status: http.StatusOK, raw: f.data, contentType: f.mimeType}
+}
+
+// fileKey - ключ файла в хранилище: "{objectId}/{mnemonic}/{objectType}".
+func fileKey(orderId int, name, objectType string) string {
+	return fmt.Sprintf("%d/%s/%s", orderId, name, objectType)
+}
+
+// mimeType - возвращает MIME-тип файла по расширению.
+// Не использует [mime.TypeByExtension], результат которого зависит от настроек системы.
+func mimeType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".xml":
+		return "application/xml"
+	case ".pdf":
+		return "application/pdf"
+	case ".zip":
+		return "application/zip"
+	case ".sig", ".p7s":
+		return "application/pkcs7-signature"
+	case ".p7m":
+		return "application/pkcs7-mime"
+	case ".txt":
+		return "text/plain"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -16,9 +16,14 @@ var (
 	ErrPush               = errors.New("ошибка OrderPush")
 	ErrOrderInfo          = errors.New("ошибка OrderInfo")
 	ErrOrderCancel        = errors.New("ошибка OrderCancel")
+	ErrOrdersStatus       = errors.New("ошибка OrdersStatus")
+	ErrOrdersUpdatedAfter = errors.New("ошибка OrdersUpdatedAfter")
 	ErrAttachmentDownload = errors.New("ошибка AttachmentDownload")
 	ErrDict               = errors.New("ошибка Dict")
 	ErrService            = errors.New("ошибка услуги")
+	ErrAttachmentVerify   = errors.New("ошибка AttachmentDownloadVerified")
+	ErrPushService        = errors.New("ошибка OrderPushService")
+	ErrServiceRegistry    = errors.New("ошибка реестра услуг")
 )
 
 // Ошибки второго уровня.
@@ -29,12 +34,16 @@ var (
 	ErrJSONUnmarshal         = errors.New("ошибка чтения JSON")
 	ErrNoFiles               = errors.New("нет файлов во вложении")
 	ErrZip                   = errors.New("ошибка создания zip-архива")
+	ErrFileSign              = errors.New("ошибка подписания файла вложения")
+	ErrNoSignature           = errors.New("не найдена электронная подпись файла")
 	ErrGUID                  = errors.New("не удалось сгенерировать GUID")
 	ErrXMLMarshal            = errors.New("ошибка создания XML")
 	ErrNilArchive            = errors.New("не передан архив")
 	ErrWrongOrderID          = errors.New("некорректный ID заявления")
 	ErrInvalidFileLink       = errors.New("некорректная ссылка на файл")
 	ErrDictResponse          = errors.New("ошибка получения справочных данных")
+	ErrServiceNotFound       = errors.New("услуга не найдена")
+	ErrServiceData           = errors.New("некорректные данные заявления")
 )
 
 // HTTP-ошибки.
@@ -15,8 +15,9 @@ import (
 const tsLayout = "2006.01.02 15:04:05 -0700"
 
 const (
-	UserEndpoint  = "/aas/oauth2/v2/ac" // URI страницы ЕСИА для предоставления пользователем запрошенных прав
-	TokenEndpoint = "/aas/oauth2/v3/te" // Эндпоинт для обмена кода авторизации на маркер доступа
+	UserEndpoint   = "/aas/oauth2/v2/ac" // URI страницы ЕСИА для предоставления пользователем запрошенных прав
+	TokenEndpoint  = "/aas/oauth2/v3/te" // Эндпоинт для обмена кода авторизации на маркер доступа
+	LogoutEndpoint = "/idp/ext/Logout"   // URI страницы ЕСИА для выхода пользователя из учетной записи
 )
 
 // ErrorResponse - ответ от ЕСИА при ошибке
@@ -38,12 +39,14 @@ type TokenExchangeResponse struct {
 // Client - OAuth2-клиент для запроса согласия и маркера доступа ЕСИА
 // для получателей услуг ЕПГУ - физических лиц.
 type Client struct {
-	baseURI    string
-	clientId   string
-	signer     signature.Provider
-	httpClient *http.Client
-	logger     utils.Logger
-	debug      bool
+	baseURI      string
+	clientId     string
+	signer       signature.Provider
+	httpClient   *http.Client
+	logger       utils.Logger
+	debug        bool
+	har          *utils.HAR
+	redirectURIs map[string]struct{}
 }
 
 // NewClient - конструктор для Client.
@@ -77,6 +80,14 @@ func (c *Client) WithDebug(logger utils.Logger) *Client {
 	return c
 }
 
+// WithHAR - включает запись HTTP-запросов и ответов к ЕСИА в журнал har в формате HAR 1.2.
+// Комментарий к записи журнала содержит наименование метода клиента, например "TokenExchange".
+// Маркеры доступа, client_secret и код авторизации в журнал не записываются, см [utils.HAR].
+func (c *Client) WithHAR(har *utils.HAR) *Client {
+	c.har = har
+	return c
+}
+
 // WithHTTPClient - устанавливает http-клиент для запросов к ЕСИА
 func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
 	if httpClient != nil {
@@ -85,22 +96,84 @@ func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
 	return c
 }
 
+// WithRedirectURIs - устанавливает список разрешенных redirect_uri, зарегистрированных для ИС в ЕСИА.
+// Если список задан, то [Client.AuthURI], [Client.TokenExchange], [Client.TokenUpdate]
+// и [Client.LogoutURI] проверяют redirectURI по списку до подписания запроса
+// и возвращают [ErrRedirectURI] вместо ошибки ЕСИА [ErrESIA_007023].
+// Сравнение выполняется побайтно. Вызов без аргументов отменяет проверку.
+func (c *Client) WithRedirectURIs(uris ...string) *Client {
+	if len(uris) == 0 {
+		c.redirectURIs = nil
+		return c
+	}
+	c.redirectURIs = make(map[string]struct{}, len(uris))
+	for _, uri := range uris {
+		c.redirectURIs[uri] = struct{}{}
+	}
+	return c
+}
+
+// ValidateRedirectURI - проверяет redirectURI по списку разрешенных, см [Client.WithRedirectURIs].
+// Если список не задан, проверка не выполняется.
+//
+// В случае ошибки возвращает цепочку из [ErrRedirectURI] и [ErrESIA_007023].
+func (c *Client) ValidateRedirectURI(redirectURI string) error {
+	if c.redirectURIs == nil {
+		return nil
+	}
+	if _, ok := c.redirectURIs[redirectURI]; !ok {
+		return fmt.Errorf("%w: %w [redirect_uri='%s']", ErrRedirectURI, ErrESIA_007023, redirectURI)
+	}
+	return nil
+}
+
+// LogoutURI - формирует URI на страницу ЕСИА для выхода пользователя из учетной записи Госуслуг.
+// После выхода ЕСИА перенаправляет пользователя на redirectURI.
+// Если redirectURI пустой, пользователь остается на странице ЕСИА.
+//
+// Возвращает URI на страницу ЕСИА либо цепочку ошибок из [ErrLogoutURI] и [ErrRedirectURI].
+//
+// Подробнее см "Методические рекомендации по использованию ЕСИА",
+// раздел "Выход из учетной записи (logout)".
+func (c *Client) LogoutURI(redirectURI string) (string, error) {
+	params := &url.Values{}
+	params.Add("client_id", c.clientId)
+	if redirectURI != "" {
+		if err := c.ValidateRedirectURI(redirectURI); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrLogoutURI, err)
+		}
+		params.Add("redirect_url", redirectURI)
+	}
+	return c.baseURI + LogoutEndpoint + "?" + params.Encode(), nil
+}
+
 // AuthURI - формирует URI на страницу ЕСИА для предоставления пользователем запрошенных прав.
-// Тк используется параметр [Permissions], то в scope необходимо указывать "openid".
+// Тк используется параметр [Permissions], то в scope необходимо указывать [ScopeOpenID].
+//
+// Перед формированием ссылки права доступа проверяются с помощью [Permissions.Validate],
+// а redirectURI — с помощью [Client.ValidateRedirectURI].
 //
 // Возвращает URI на страницу ЕСИА либо цепочку ошибок из [ErrAuthURI] и других:
+//   - [ErrPermissions] - некорректный параметр permissions (с ошибкой ЕСИА ErrESIA_0367xx)
+//   - [ErrRedirectURI] - redirectURI отсутствует среди разрешенных
 //   - [ErrSign] - ошибка подписи ссылки
 //   - [ErrGUID] - при невозможности сформировать GUID
 //
 // Подробнее см "Методические рекомендации по использованию ЕСИА",
 // раздел "Получение авторизационного кода (v2/ac)".
-func (c *Client) AuthURI(scope, redirectURI string, permissions Permissions) (string, error) {
+func (c *Client) AuthURI(scope Scopes, redirectURI string, permissions Permissions) (string, error) {
+	if err := permissions.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAuthURI, err)
+	}
+	if err := c.ValidateRedirectURI(redirectURI); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAuthURI, err)
+	}
 	timestamp := time.Now().UTC().Format(tsLayout)
 	state, err := guid()
 	if err != nil {
 		return "", fmt.Errorf("%w: %w: %w", ErrAuthURI, ErrGUID, err)
 	}
-	clientSecret, err := c.sign(c.clientId, scope, timestamp, state, redirectURI)
+	clientSecret, certHash, err := c.sign(c.clientId, scope.String(), timestamp, state, redirectURI)
 	if err != nil {
 		return "", fmt.Errorf("%w: %w", ErrAuthURI, err)
 	}
@@ -108,11 +181,11 @@ func (c *Client) AuthURI(scope, redirectURI string, permissions Permissions) (st
 	params := &url.Values{}
 	params.Add("client_id", c.clientId)
 	params.Add("client_secret", clientSecret)
-	params.Add("scope", scope)
+	params.Add("scope", scope.String())
 	params.Add("timestamp", timestamp)
 	params.Add("state", state)
 	params.Add("redirect_uri", redirectURI)
-	params.Add("client_certificate_hash", c.signer.CertHash())
+	params.Add("client_certificate_hash", certHash)
 	params.Add("response_type", "code")
 	params.Add("access_type", "online")
 	params.Add("permissions", permissions.Base64String())
@@ -150,11 +223,14 @@ func (c *Client) ParseCallback(query url.Values) (string, string, error) {
 
 // TokenExchange обменивает код авторизации на маркер доступа.
 // Параметры scope и redirectURI должны быть такими же, как и при вызове [Client.AuthURI].
+// Строковое представление scope всегда каноническое (см [Scopes.String]),
+// поэтому порядок и повторы скоупов на совпадение подписи не влияют.
 //
 // Подробнее см "Методические рекомендации по использованию ЕСИА",
 // раздел "Получение маркера доступа в обмен на авторизационный код (v3/te)".
 //
 // Возвращает ответ от ЕСИА [TokenExchangeResponse] либо цепочку ошибок из [ErrTokenExchange] и других:
+//   - [ErrRedirectURI] - redirectURI отсутствует среди разрешенных
 //   - [ErrSign] - ошибка подписи запроса
 //   - [ErrGUID] - при невозможности сформировать GUID
 //   - [ErrRequest] - ошибка HTTP-запроса
@@ -165,13 +241,16 @@ func (c *Client) ParseCallback(query url.Values) (string, string, error) {
 // Пример сообщения об ошибке:
 //
 //	HTTP 400 Bad request: ESIA-007014: Запрос не содержит обязательного параметра [error='invalid_request', error_description='ESIA-007014: The request does not contain the mandatory parameter' state='48d1a8dc-0b7d-418a-b4ef-2c7797f77dc9']'
-func (c *Client) TokenExchange(code, scope, redirectURI string) (*TokenExchangeResponse, error) {
+func (c *Client) TokenExchange(code string, scope Scopes, redirectURI string) (*TokenExchangeResponse, error) {
+	if err := c.ValidateRedirectURI(redirectURI); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
 	timestamp := time.Now().UTC().Format(tsLayout)
 	state, err := guid()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w: %w", ErrTokenExchange, ErrGUID, err)
 	}
-	clientSecret, err := c.sign(c.clientId, scope, timestamp, state, redirectURI, code)
+	clientSecret, certHash, err := c.sign(c.clientId, scope.String(), timestamp, state, redirectURI, code)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
 	}
@@ -179,11 +258,11 @@ func (c *Client) TokenExchange(code, scope, redirectURI string) (*TokenExchangeR
 	reqBody := url.Values{}
 	reqBody.Set("client_id", c.clientId)
 	reqBody.Set("client_secret", clientSecret)
-	reqBody.Set("scope", scope)
+	reqBody.Set("scope", scope.String())
 	reqBody.Set("timestamp", timestamp)
 	reqBody.Set("state", state)
 	reqBody.Set("redirect_uri", redirectURI)
-	reqBody.Set("client_certificate_hash", c.signer.CertHash())
+	reqBody.Set("client_certificate_hash", certHash)
 	reqBody.Set("code", code)
 	reqBody.Set("grant_type", "authorization_code")
 	reqBody.Set("token_type", "Bearer")
@@ -191,6 +270,7 @@ func (c *Client) TokenExchange(code, scope, redirectURI string) (*TokenExchangeR
 	result := &TokenExchangeResponse{}
 
 	if err = c.request(
+		"TokenExchange",
 		http.MethodPost,
 		TokenEndpoint,
 		"application/x-www-form-urlencoded",
@@ -212,13 +292,16 @@ func (c *Client) TokenExchange(code, scope, redirectURI string) (*TokenExchangeR
 // Возвращает ответ от ЕСИА [TokenExchangeResponse] либо цепочку ошибок из [ErrTokenUpdate] и
 // ошибок аналогичных TokenExchange.
 func (c *Client) TokenUpdate(oid, redirectURI string) (*TokenExchangeResponse, error) {
+	if err := c.ValidateRedirectURI(redirectURI); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenUpdate, err)
+	}
 	timestamp := time.Now().UTC().Format(tsLayout)
-	scope := "prm_chg?oid=" + oid
+	scope := string(scopePrmChg) + "?oid=" + oid
 	state, err := guid()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w: %w", ErrTokenUpdate, ErrGUID, err)
 	}
-	clientSecret, err := c.sign(c.clientId, scope, timestamp, state, redirectURI)
+	clientSecret, certHash, err := c.sign(c.clientId, scope, timestamp, state, redirectURI)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrTokenUpdate, err)
 	}
@@ -230,12 +313,13 @@ func (c *Client) TokenUpdate(oid, redirectURI string) (*TokenExchangeResponse, e
 	reqBody.Set("timestamp", timestamp)
 	reqBody.Set("state", state)
 	reqBody.Set("redirect_uri", redirectURI)
-	reqBody.Set("client_certificate_hash", c.signer.CertHash())
+	reqBody.Set("client_certificate_hash", certHash)
 	reqBody.Set("grant_type", "client_credentials")
 	reqBody.Set("token_type", "Bearer")
 
 	result := &TokenExchangeResponse{}
 	if err = c.request(
+		"TokenUpdate",
 		http.MethodPost,
 		TokenEndpoint,
 		"application/x-www-form-urlencoded",
@@ -247,16 +331,26 @@ func (c *Client) TokenUpdate(oid, redirectURI string) (*TokenExchangeResponse, e
 	return result, nil
 }
 
-func (c *Client) sign(args ...string) (string, error) {
+// sign - возвращает client_secret и хэш сертификата, соответствующий подписи.
+// Если провайдер реализует [signature.CertHashSigner], хэш сертификата возвращается вместе с подписью.
+func (c *Client) sign(args ...string) (string, string, error) {
 	if c.signer == nil {
-		return "", fmt.Errorf("%w: signer not specified", ErrSign)
+		return "", "", fmt.Errorf("%w: signer not specified", ErrSign)
 	}
 	data := []byte(strings.Join(args, ""))
-	sign, err := c.signer.Sign(data)
+	var sign []byte
+	var certHash string
+	var err error
+	if s, ok := c.signer.(signature.CertHashSigner); ok {
+		sign, certHash, err = s.SignWithCertHash(data)
+	} else {
+		sign, err = c.signer.Sign(data)
+		certHash = c.signer.CertHash()
+	}
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrSign, err)
+		return "", "", fmt.Errorf("%w: %w", ErrSign, err)
 	}
-	return base64.URLEncoding.EncodeToString(sign), nil
+	return base64.URLEncoding.EncodeToString(sign), certHash, nil
 }
 
 func (c *Client) logReq(req *http.Request) {
@@ -50,7 +50,7 @@ func (suite *suiteTestClient) TestAuthURI() {
 			},
 		}
 
-		uriStr, err := client.AuthURI("test-scope", "test-redirect", permissions)
+		uriStr, err := client.AuthURI(NewScopes("test-scope"), "test-redirect", permissions)
 		suite.NoError(err)
 		u, err := url.Parse(uriStr)
 		suite.NoError(err)
@@ -77,15 +77,44 @@ func (suite *suiteTestClient) TestAuthURI() {
 		guid = func() (string, error) {
 			return "", ErrGUID
 		}
-		uriStr, err := client.AuthURI("test-scope", "test-redirect", Permissions{})
+		uriStr, err := client.AuthURI(NewScopes("test-scope"), "test-redirect", Permissions{})
 		suite.ErrorIs(err, ErrAuthURI)
 		suite.ErrorIs(err, ErrGUID)
 		suite.Empty(uriStr)
 	})
 
+	suite.Run("error permissions", func() {
+		client := NewClient("", "test-client", signature.NewNop(testSignature, testCertHash))
+		uriStr, err := client.AuthURI(NewScopes(ScopeOpenID), "test-redirect", Permissions{{Sysname: "test"}})
+		suite.ErrorIs(err, ErrAuthURI)
+		suite.ErrorIs(err, ErrPermissions)
+		suite.ErrorIs(err, ErrESIA_036705)
+		suite.Empty(uriStr)
+	})
+
+	suite.Run("error redirect uri", func() {
+		signer := &recordSigner{}
+		client := NewClient("", "test-client", signer).WithRedirectURIs("https://example.com/callback")
+		uriStr, err := client.AuthURI(NewScopes(ScopeOpenID), "https://example.com/other", Permissions{})
+		suite.ErrorIs(err, ErrAuthURI)
+		suite.ErrorIs(err, ErrRedirectURI)
+		suite.ErrorIs(err, ErrESIA_007023)
+		suite.Empty(uriStr)
+		suite.Empty(signer.data)
+	})
+
+	suite.Run("cert hash signer", func() {
+		client := NewClient("", "test", &certHashSigner{})
+		uriStr, err := client.AuthURI(NewScopes(ScopeOpenID), "test", Permissions{})
+		suite.Require().NoError(err)
+		u, err := url.Parse(uriStr)
+		suite.Require().NoError(err)
+		suite.Equal("signed_hash", u.Query().Get("client_certificate_hash"))
+	})
+
 	suite.Run("error sign", func() {
 		client := NewClient("", "test", signature.NewNop("", ""))
-		uriStr, err := client.AuthURI("openid", "test", Permissions{})
+		uriStr, err := client.AuthURI(NewScopes(ScopeOpenID), "test", Permissions{})
 		suite.ErrorIs(err, ErrAuthURI)
 		suite.ErrorIs(err, ErrSign)
 		suite.Empty(uriStr)
@@ -93,7 +122,7 @@ func (suite *suiteTestClient) TestAuthURI() {
 
 	suite.Run("error signer is nil", func() {
 		client := NewClient("", "test", nil)
-		uriStr, err := client.AuthURI("openid", "test", Permissions{})
+		uriStr, err := client.AuthURI(NewScopes(ScopeOpenID), "test", Permissions{})
 		suite.ErrorIs(err, ErrAuthURI)
 		suite.ErrorIs(err, ErrSign)
 		suite.Empty(uriStr)
@@ -101,6 +130,61 @@ func (suite *suiteTestClient) TestAuthURI() {
 
 }
 
+func (suite *suiteTestClient) TestLogoutURI() {
+	suite.Run("success", func() {
+		client := NewClient("https://esia.example.com", "test-client", nil)
+		uriStr, err := client.LogoutURI("https://example.com/logout")
+		suite.Require().NoError(err)
+		u, err := url.Parse(uriStr)
+		suite.Require().NoError(err)
+		suite.Equal("esia.example.com", u.Host)
+		suite.Equal(LogoutEndpoint, u.Path)
+		suite.Equal("test-client", u.Query().Get("client_id"))
+		suite.Equal("https://example.com/logout", u.Query().Get("redirect_url"))
+	})
+
+	suite.Run("success without redirect", func() {
+		client := NewClient("https://esia.example.com", "test-client", nil).
+			WithRedirectURIs("https://example.com/callback")
+		uriStr, err := client.LogoutURI("")
+		suite.Require().NoError(err)
+		suite.Equal("https://esia.example.com/idp/ext/Logout?client_id=test-client", uriStr)
+	})
+
+	suite.Run("error redirect uri", func() {
+		client := NewClient("https://esia.example.com", "test-client", nil).
+			WithRedirectURIs("https://example.com/callback")
+		uriStr, err := client.LogoutURI("https://evil.example.com/")
+		suite.ErrorIs(err, ErrLogoutURI)
+		suite.ErrorIs(err, ErrRedirectURI)
+		suite.ErrorIs(err, ErrESIA_007023)
+		suite.Empty(uriStr)
+	})
+}
+
+func (suite *suiteTestClient) TestValidateRedirectURI() {
+	client := NewClient("", "test-client", nil)
+	suite.NoError(client.ValidateRedirectURI("anything"))
+
+	client.WithRedirectURIs("https://example.com/callback", "https://example.com/logout")
+	suite.NoError(client.ValidateRedirectURI("https://example.com/callback"))
+	suite.NoError(client.ValidateRedirectURI("https://example.com/logout"))
+	suite.ErrorIs(client.ValidateRedirectURI("https://example.com/callback/"), ErrRedirectURI)
+	suite.ErrorIs(client.ValidateRedirectURI(""), ErrESIA_007023)
+
+	_, err := client.TokenExchange("code", NewScopes(ScopeOpenID), "https://example.com/other")
+	suite.ErrorIs(err, ErrTokenExchange)
+	suite.ErrorIs(err, ErrRedirectURI)
+
+	_, err = client.TokenUpdate("1", "https://example.com/other")
+	suite.ErrorIs(err, ErrTokenUpdate)
+	suite.ErrorIs(err, ErrRedirectURI)
+
+	client.WithRedirectURIs()
+	suite.NoError(client.ValidateRedirectURI("https://example.com/other"))
+	suite.NoError(client.ValidateRedirectURI(""))
+}
+
 func (suite *suiteTestClient) TestParseCallback() {
 	suite.Run("success", func() {
 		client := NewClient("", "test", signature.NewNop(testSignature, testCertHash))
@@ -184,7 +268,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 		defer server.Close()
 
 		client := NewClient(server.URL, "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test-code", "test-scope", "test-uri")
+		token, err := client.TokenExchange("test-code", NewScopes("test-scope"), "test-uri")
 		suite.NoError(err)
 		suite.Require().NotNil(token)
 		suite.Equal("test", token.AccessToken)
@@ -199,7 +283,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 		defer server.Close()
 
 		client := NewClient(server.URL, "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrUnexpectedContentType)
 		suite.Equal(
@@ -218,7 +302,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 		defer server.Close()
 
 		client := NewClient(server.URL, "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrESIA_007004)
 		suite.Equal(
@@ -237,7 +321,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 		defer server.Close()
 
 		client := NewClient(server.URL, "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrJSONUnmarshal)
 		suite.Equal(
@@ -256,7 +340,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 		defer server.Close()
 
 		client := NewClient(server.URL, "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrJSONUnmarshal)
 		suite.Equal(
@@ -272,7 +356,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 		}
 
 		client := NewClient("", "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrGUID)
 		suite.Nil(token)
@@ -280,7 +364,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 
 	suite.Run("error request call", func() {
 		client := NewClient("", "test", signature.NewNop(testSignature, testCertHash))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrRequest)
 		suite.Nil(token)
@@ -288,7 +372,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 
 	suite.Run("error sign", func() {
 		client := NewClient("", "test", signature.NewNop("", ""))
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrSign)
 		suite.Nil(token)
@@ -296,7 +380,7 @@ func (suite *suiteTestClient) TestTokenExchange() {
 
 	suite.Run("error signer is nil", func() {
 		client := NewClient("", "test", nil)
-		token, err := client.TokenExchange("test", "test", "test")
+		token, err := client.TokenExchange("test", NewScopes("test"), "test")
 		suite.ErrorIs(err, ErrTokenExchange)
 		suite.ErrorIs(err, ErrSign)
 		suite.Nil(token)
@@ -421,3 +505,41 @@ func (suite *suiteTestClient) TestTokenUpdate() {
 		suite.Nil(token)
 	})
 }
+
+func (suite *suiteTestClient) TestWithHAR() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"access_token":"test-token","id_token":"test","state":"test","token_type":"Bearer","expires_in":0}`))
+	}))
+	defer server.Close()
+
+	har := utils.NewHAR()
+	client := NewClient(server.URL, "test", signature.NewNop(testSignature, testCertHash)).WithHAR(har)
+	token, err := client.TokenExchange("test-code", NewScopes("test-scope"), "test-uri")
+	suite.Require().NoError(err)
+	suite.Equal("test-token", token.AccessToken)
+
+	entries := har.Entries()
+	suite.Require().Len(entries, 1)
+	suite.Equal("TokenExchange", entries[0].Comment)
+	suite.Require().NotNil(entries[0].Request.PostData)
+	suite.NotContains(entries[0].Request.PostData.Text, "test-code")
+	suite.Contains(entries[0].Response.Content.Text, `"access_token":"***"`)
+}
+
+// certHashSigner - провайдер подписи, возвращающий хэш сертификата вместе с подписью.
+type certHashSigner struct{}
+
+func (s *certHashSigner) Sign(data []byte) ([]byte, error) {
+	sig, _, err := s.SignWithCertHash(data)
+	return sig, err
+}
+
+func (s *certHashSigner) SignWithCertHash(_ []byte) ([]byte, string, error) {
+	return []byte(testSignature), "signed_hash", nil
+}
+
+func (s *certHashSigner) CertHash() string {
+	return "other_hash"
+}
@@ -0,0 +1,197 @@
+package aas
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConsentStatus - состояние согласия пользователя.
+type ConsentStatus string
+
+// Состояния согласия пользователя.
+const (
+	ConsentActive  ConsentStatus = "active"  // Согласие действует (по данным последнего обращения к ЕСИА)
+	ConsentExpired ConsentStatus = "expired" // Истек срок действия согласия
+	ConsentRevoked ConsentStatus = "revoked" // Согласие отозвано пользователем на Госуслугах
+)
+
+// Consent - сведения о согласии пользователя, предоставленном по запросу [Client.AuthURI].
+//
+// Согласие выдается на срок, указанный в [Permission.Expire]. Пока согласие действует,
+// маркер доступа можно обновлять с помощью [Client.TokenUpdate]. Пользователь может
+// отозвать согласие на Госуслугах в любой момент — об этом становится известно
+// только при очередном обновлении маркера.
+type Consent struct {
+	GrantedAt time.Time `json:"granted_at"` // Время предоставления согласия
+	ExpiresAt time.Time `json:"expires_at"` // Расчетное окончание срока согласия. Нулевое значение — срок неизвестен
+	RevokedAt time.Time `json:"revoked_at"` // Время обнаружения отзыва согласия. Нулевое значение — согласие не отозвано
+}
+
+// NewConsent - конструктор [Consent] для согласия, предоставленного в момент grantedAt.
+// Срок действия рассчитывается по наименьшему сроку [Permission.Expire] из permissions.
+// Если сроки не указаны, срок действия согласия считается неизвестным.
+func NewConsent(permissions Permissions, grantedAt time.Time) Consent {
+	consent := Consent{GrantedAt: grantedAt}
+	minutes := 0
+	for _, p := range permissions {
+		if p.Expire > 0 && (minutes == 0 || p.Expire < minutes) {
+			minutes = p.Expire
+		}
+	}
+	if minutes > 0 {
+		consent.ExpiresAt = grantedAt.Add(time.Duration(minutes) * time.Minute)
+	}
+	return consent
+}
+
+// Status - возвращает состояние согласия на текущий момент.
+func (c Consent) Status() ConsentStatus {
+	switch {
+	case !c.RevokedAt.IsZero():
+		return ConsentRevoked
+	case !c.ExpiresAt.IsZero() && !nowFunc().Before(c.ExpiresAt):
+		return ConsentExpired
+	default:
+		return ConsentActive
+	}
+}
+
+// ExpiresIn - возвращает оставшийся срок действия согласия.
+// Возвращает 0, если срок истек или неизвестен.
+func (c Consent) ExpiresIn() time.Duration {
+	if c.ExpiresAt.IsZero() {
+		return 0
+	}
+	if d := c.ExpiresAt.Sub(nowFunc()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// classify - определяет причину ошибки обновления маркера доступа.
+// Ошибки ЕСИА [ErrESIA_007004] и [ErrESIA_007019] означают, что согласия больше нет:
+// если расчетный срок согласия истек — [ErrConsentExpired], иначе — [ErrConsentRevoked].
+// Прочие ошибки возвращаются без изменений.
+func (c Consent) classify(err error) error {
+	if !errors.Is(err, ErrESIA_007004) && !errors.Is(err, ErrESIA_007019) {
+		return err
+	}
+	if !c.ExpiresAt.IsZero() && !nowFunc().Before(c.ExpiresAt) {
+		return fmt.Errorf("%w: %w", ErrConsentExpired, err)
+	}
+	return fmt.Errorf("%w: %w", ErrConsentRevoked, err)
+}
+
+// ConsentTracker - отслеживает согласия пользователей и обновляет маркеры доступа.
+//
+// Сведения о согласии хранятся в записи [TokenRecord] хранилища [TokenStore] вместе с маркером доступа.
+// Если согласие истекло или отозвано, [ConsentTracker.Refresh] возвращает [ErrConsentExpired]
+// или [ErrConsentRevoked], а [ConsentTracker.ReconsentURI] формирует ссылку для повторного
+// запроса согласия с теми же параметрами.
+//
+// Пример:
+//
+//	tracker := aas.NewConsentTracker(client, store)
+//	// После callback от ЕСИА
+//	res, err := client.TokenExchange(code, scope, redirectURI)
+//	record, err := tracker.Grant(res, scope, redirectURI, permissions)
+//	// Позже, при подаче заявления
+//	record, err = tracker.Refresh(oid)
+//	if errors.Is(err, aas.ErrConsentRevoked) || errors.Is(err, aas.ErrConsentExpired) {
+//		uri, err := tracker.ReconsentURI(oid)
+//		// предложить пользователю перейти по ссылке uri
+//	}
+type ConsentTracker struct {
+	client *Client
+	store  TokenStore
+}
+
+// NewConsentTracker - конструктор [ConsentTracker].
+func NewConsentTracker(client *Client, store TokenStore) *ConsentTracker {
+	return &ConsentTracker{client: client, store: store}
+}
+
+// Grant - сохраняет маркер доступа и сведения о согласии по ответу ЕСИА [Client.TokenExchange].
+// Параметры scope, redirectURI и permissions должны быть такими же, как и при вызове [Client.AuthURI].
+//
+// В случае ошибки возвращает цепочку из [ErrConsent] и ошибок [NewTokenRecord] или [TokenStore].
+func (t *ConsentTracker) Grant(res *TokenExchangeResponse, scope Scopes, redirectURI string, permissions Permissions) (*TokenRecord, error) {
+	record, err := NewTokenRecord(res, scope, redirectURI, permissions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	if err = t.store.Save(record); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	return record, nil
+}
+
+// Status - возвращает состояние согласия пользователя с идентификатором oid.
+//
+// В случае ошибки возвращает цепочку из [ErrConsent] и ошибки [TokenStore].
+func (t *ConsentTracker) Status(oid string) (ConsentStatus, error) {
+	record, err := t.store.Load(oid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	return record.Consent.Status(), nil
+}
+
+// Refresh - обновляет маркер доступа пользователя с помощью [Client.TokenUpdate] и сохраняет его.
+// Если согласие уже известно как истекшее или отозванное, запрос к ЕСИА не выполняется.
+// При обнаружении отзыва согласия отметка об этом сохраняется в хранилище.
+//
+// В случае ошибки возвращает цепочку из [ErrConsent] и следующих возможных ошибок:
+//   - [ErrConsentExpired] - истек срок действия согласия
+//   - [ErrConsentRevoked] - согласие отозвано пользователем
+//   - ошибки [Client.TokenUpdate], [TokenRecord.Update] и [TokenStore]
+func (t *ConsentTracker) Refresh(oid string) (*TokenRecord, error) {
+	record, err := t.store.Load(oid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+
+	switch record.Consent.Status() {
+	case ConsentRevoked:
+		return nil, fmt.Errorf("%w: %w [oid='%s']", ErrConsent, ErrConsentRevoked, oid)
+	case ConsentExpired:
+		return nil, fmt.Errorf("%w: %w [oid='%s']", ErrConsent, ErrConsentExpired, oid)
+	}
+
+	res, err := t.client.TokenUpdate(oid, record.RedirectURI)
+	if err != nil {
+		err = record.Consent.classify(err)
+		if errors.Is(err, ErrConsentRevoked) {
+			record.Consent.RevokedAt = nowFunc()
+			if saveErr := t.store.Save(record); saveErr != nil {
+				return nil, fmt.Errorf("%w: %w: %w", ErrConsent, err, saveErr)
+			}
+		}
+		return nil, fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+
+	if err = record.Update(res); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	if err = t.store.Save(record); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	return record, nil
+}
+
+// ReconsentURI - формирует ссылку на страницу ЕСИА для повторного предоставления согласия
+// с теми же scope, redirectURI и permissions, что и при первоначальном запросе.
+//
+// В случае ошибки возвращает цепочку из [ErrConsent] и ошибок [TokenStore] или [Client.AuthURI].
+func (t *ConsentTracker) ReconsentURI(oid string) (string, error) {
+	record, err := t.store.Load(oid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	uri, err := t.client.AuthURI(record.Scope, record.RedirectURI, record.Permissions)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrConsent, err)
+	}
+	return uri, nil
+}
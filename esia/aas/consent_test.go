@@ -0,0 +1,217 @@
+package aas
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTestConsent struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestConsent(t *testing.T) {
+	suite.Run(t, new(suiteTestConsent))
+}
+
+func (suite *suiteTestConsent) SetupTest() {
+	suite.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return suite.now }
+}
+
+func (suite *suiteTestConsent) TearDownTest() {
+	nowFunc = time.Now
+}
+
+func (suite *suiteTestConsent) TestNewConsent() {
+	suite.Run("min expire", func() {
+		permissions := NewPermissions(
+			NewPermission("A").WithExpire(120),
+			NewPermission("B").WithExpire(60),
+			NewPermission("C"),
+		)
+		consent := NewConsent(permissions, suite.now)
+		suite.Equal(suite.now, consent.GrantedAt)
+		suite.Equal(suite.now.Add(time.Hour), consent.ExpiresAt)
+		suite.Equal(ConsentActive, consent.Status())
+		suite.Equal(time.Hour, consent.ExpiresIn())
+	})
+
+	suite.Run("unknown expire", func() {
+		consent := NewConsent(NewPermissions(NewPermission("A")), suite.now)
+		suite.True(consent.ExpiresAt.IsZero())
+		suite.Equal(ConsentActive, consent.Status())
+		suite.Zero(consent.ExpiresIn())
+	})
+}
+
+func (suite *suiteTestConsent) TestJSON() {
+	suite.Run("unknown expire", func() {
+		consent := NewConsent(NewPermissions(NewPermission("A")), suite.now)
+		data, err := json.Marshal(consent)
+		suite.Require().NoError(err)
+		suite.JSONEq(`{
+			"granted_at": "2024-01-01T12:00:00Z",
+			"expires_at": "0001-01-01T00:00:00Z",
+			"revoked_at": "0001-01-01T00:00:00Z"
+		}`, string(data))
+
+		loaded := Consent{}
+		suite.Require().NoError(json.Unmarshal(data, &loaded))
+		suite.Equal(consent, loaded)
+		suite.True(loaded.ExpiresAt.IsZero())
+		suite.Equal(ConsentActive, loaded.Status())
+	})
+
+	suite.Run("revoked", func() {
+		consent := NewConsent(NewPermissions(NewPermission("A").WithExpire(60)), suite.now)
+		consent.RevokedAt = suite.now.Add(time.Minute)
+		data, err := json.Marshal(consent)
+		suite.Require().NoError(err)
+
+		loaded := Consent{}
+		suite.Require().NoError(json.Unmarshal(data, &loaded))
+		suite.Equal(consent, loaded)
+		suite.Equal(ConsentRevoked, loaded.Status())
+	})
+
+	suite.Run("missing fields", func() {
+		loaded := Consent{}
+		suite.Require().NoError(json.Unmarshal([]byte(`{"granted_at": "2024-01-01T12:00:00Z"}`), &loaded))
+		suite.True(loaded.ExpiresAt.IsZero())
+		suite.True(loaded.RevokedAt.IsZero())
+		suite.Equal(ConsentActive, loaded.Status())
+	})
+}
+
+func (suite *suiteTestConsent) TestStatus() {
+	consent := NewConsent(NewPermissions(NewPermission("A").WithExpire(60)), suite.now)
+	suite.now = suite.now.Add(time.Hour)
+	suite.Equal(ConsentExpired, consent.Status())
+	suite.Zero(consent.ExpiresIn())
+	consent.RevokedAt = suite.now
+	suite.Equal(ConsentRevoked, consent.Status())
+}
+
+func (suite *suiteTestConsent) TestClassify() {
+	consent := NewConsent(NewPermissions(NewPermission("A").WithExpire(60)), suite.now)
+	suite.ErrorIs(consent.classify(ErrESIA_007004), ErrConsentRevoked)
+	suite.ErrorIs(consent.classify(ErrESIA_007019), ErrConsentRevoked)
+	suite.Equal(ErrESIA_007014, consent.classify(ErrESIA_007014))
+	suite.now = suite.now.Add(time.Hour)
+	suite.ErrorIs(consent.classify(ErrESIA_007004), ErrConsentExpired)
+}
+
+func (suite *suiteTestConsent) TestRefresh() {
+	suite.Run("success", func() {
+		server := suite.esiaServer(http.StatusOK, `{"access_token":"`+testToken(`{"urn:esia:sbj_id":1}`)+`","expires_in":3600}`)
+		defer server.Close()
+		tracker, store := suite.tracker(server.URL, 60)
+
+		suite.now = suite.now.Add(30 * time.Minute)
+		record, err := tracker.Refresh("1")
+		suite.Require().NoError(err)
+		suite.Equal(suite.now.Add(time.Hour), record.ExpiresAt)
+		stored, err := store.Load("1")
+		suite.Require().NoError(err)
+		suite.Equal(record.AccessToken, stored.AccessToken)
+	})
+
+	suite.Run("revoked", func() {
+		server := suite.esiaServer(http.StatusBadRequest, `{"error":"access_denied","error_description":"ESIA-007004: Access denied"}`)
+		defer server.Close()
+		tracker, _ := suite.tracker(server.URL, 60)
+
+		suite.now = suite.now.Add(30 * time.Minute)
+		_, err := tracker.Refresh("1")
+		suite.ErrorIs(err, ErrConsent)
+		suite.ErrorIs(err, ErrConsentRevoked)
+		suite.ErrorIs(err, ErrESIA_007004)
+
+		status, err := tracker.Status("1")
+		suite.Require().NoError(err)
+		suite.Equal(ConsentRevoked, status)
+
+		_, err = tracker.Refresh("1")
+		suite.ErrorIs(err, ErrConsentRevoked)
+	})
+
+	suite.Run("revoked without expire", func() {
+		server := suite.esiaServer(http.StatusBadRequest, `{"error":"access_denied","error_description":"ESIA-007019: No permission"}`)
+		defer server.Close()
+		tracker, _ := suite.tracker(server.URL, 0)
+
+		_, err := tracker.Refresh("1")
+		suite.ErrorIs(err, ErrConsentRevoked)
+		suite.ErrorIs(err, ErrESIA_007019)
+	})
+
+	suite.Run("expired", func() {
+		server := suite.esiaServer(http.StatusBadRequest, `{"error":"access_denied","error_description":"ESIA-007019: No permission"}`)
+		defer server.Close()
+		tracker, store := suite.tracker(server.URL, 60)
+
+		record, err := store.Load("1")
+		suite.Require().NoError(err)
+		suite.Equal(ConsentActive, record.Consent.Status())
+		suite.now = suite.now.Add(2 * time.Hour)
+
+		_, err = tracker.Refresh("1")
+		suite.ErrorIs(err, ErrConsent)
+		suite.ErrorIs(err, ErrConsentExpired)
+		suite.NotErrorIs(err, ErrESIA_007019)
+	})
+
+	suite.Run("other error", func() {
+		server := suite.esiaServer(http.StatusBadRequest, `{"error":"invalid_request","error_description":"ESIA-007014: Bad request"}`)
+		defer server.Close()
+		tracker, _ := suite.tracker(server.URL, 60)
+
+		_, err := tracker.Refresh("1")
+		suite.ErrorIs(err, ErrConsent)
+		suite.ErrorIs(err, ErrESIA_007014)
+		suite.NotErrorIs(err, ErrConsentRevoked)
+		suite.NotErrorIs(err, ErrConsentExpired)
+	})
+
+	suite.Run("not found", func() {
+		tracker := NewConsentTracker(NewClient("", "test", &recordSigner{}), NewMemoryTokenStore())
+		_, err := tracker.Refresh("1")
+		suite.ErrorIs(err, ErrConsent)
+		suite.ErrorIs(err, ErrTokenNotFound)
+	})
+}
+
+func (suite *suiteTestConsent) TestReconsentURI() {
+	tracker, _ := suite.tracker("https://esia.example.com", 60)
+	uri, err := tracker.ReconsentURI("1")
+	suite.Require().NoError(err)
+	suite.Contains(uri, "https://esia.example.com"+UserEndpoint)
+	suite.Contains(uri, "redirect_uri=https%3A%2F%2Fexample.com%2Fcallback")
+}
+
+func (suite *suiteTestConsent) tracker(baseURI string, expire int) (*ConsentTracker, TokenStore) {
+	store := NewMemoryTokenStore()
+	tracker := NewConsentTracker(NewClient(baseURI, "test", &recordSigner{}), store)
+	_, err := tracker.Grant(
+		&TokenExchangeResponse{AccessToken: testToken(`{"urn:esia:sbj_id":1}`), ExpiresIn: 3600},
+		NewScopes(ScopeOpenID, ScopeAPIOrder),
+		"https://example.com/callback",
+		NewPermissions(NewAPIPGUPermission("Тест").WithExpire(expire)),
+	)
+	suite.Require().NoError(err)
+	return tracker, store
+}
+
+func (suite *suiteTestConsent) esiaServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
@@ -16,6 +16,9 @@ var (
 	ErrParseCallback = errors.New("ошибка обратного вызова")
 	ErrTokenExchange = errors.New("ошибка запроса токена")
 	ErrTokenUpdate   = errors.New("ошибка обновления токена")
+	ErrTokenStore    = errors.New("ошибка хранилища токенов")
+	ErrConsent       = errors.New("ошибка согласия пользователя")
+	ErrLogoutURI     = errors.New("ошибка при создании ссылки для выхода")
 )
 
 // Ошибки второго уровня.
@@ -26,6 +29,15 @@ var (
 	ErrRequest               = errors.New("ошибка HTTP-запроса")
 	ErrJSONUnmarshal         = errors.New("ошибка чтения JSON")
 	ErrUnexpectedContentType = errors.New("неожиданный тип содержимого")
+	ErrPermissions           = errors.New("некорректный параметр permissions")
+	ErrTokenParse            = errors.New("некорректный маркер доступа")
+	ErrTokenNotFound         = errors.New("маркер доступа не найден")
+	ErrInvalidOID            = errors.New("некорректный OID пользователя")
+	ErrCrypto                = errors.New("ошибка шифрования")
+	ErrFileIO                = errors.New("ошибка файловой операции")
+	ErrConsentExpired        = errors.New("истек срок действия согласия пользователя")
+	ErrConsentRevoked        = errors.New("согласие отозвано пользователем")
+	ErrRedirectURI           = errors.New("некорректный параметр redirect_uri")
 )
 
 // Ошибки ЕСИА.
@@ -3,8 +3,28 @@ package aas
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 )
 
+// Мнемоника типа согласия на подачу заявлений через API ЕПГУ.
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12", "Приложение 4. Ошибки, возвращаемые при запросах к API ЕПГУ",
+// код ошибки access_denied_person_permissions.
+const PermissionSysnameAPIPGU = "APIPGU"
+
+// Мнемоники действий с данными объекта [Permission].
+const (
+	PermissionActionAllActionsToData = "ALL_ACTIONS_TO_DATA" // Все действия с данными
+)
+
+// Мнемоники целей согласия объекта [Permission].
+const (
+	PermissionPurposeAPIPGU = "APIPGU" // Подача заявлений через API ЕПГУ
+)
+
+// PermissionExpireAPIPGUMax - максимальный срок согласия типа [PermissionSysnameAPIPGU] в минутах (1 год).
+const PermissionExpireAPIPGUMax = 525600
+
 // Permissions - список запрашиваемых прав доступа.
 //
 // Подробнее см "Методические рекомендации по интеграции с REST API Цифрового профиля",
@@ -44,3 +64,181 @@ func (p Permissions) Base64String() string {
 	j, _ := json.Marshal(p)
 	return base64.RawURLEncoding.EncodeToString(j)
 }
+
+// NewPermissions - конструктор [Permissions].
+func NewPermissions(permissions ...*Permission) Permissions {
+	result := make(Permissions, 0, len(permissions))
+	for _, p := range permissions {
+		if p != nil {
+			result = append(result, *p)
+		}
+	}
+	return result
+}
+
+// NewPermission - конструктор [Permission] с мнемоникой типа согласия sysname.
+func NewPermission(sysname string) *Permission {
+	return &Permission{Sysname: sysname}
+}
+
+// NewAPIPGUPermission - конструктор [Permission] для согласия на подачу заявлений через API ЕПГУ:
+//   - тип согласия [PermissionSysnameAPIPGU]
+//   - действие [PermissionActionAllActionsToData]
+//   - цель [PermissionPurposeAPIPGU]
+//   - срок [PermissionExpireAPIPGUMax]
+//   - скоуп [ScopeAPIOrder] и дополнительные скоупы scopes
+//
+// Параметр responsibleObject - название организации в ЕСИА.
+func NewAPIPGUPermission(responsibleObject string, scopes ...Scope) *Permission {
+	return NewPermission(PermissionSysnameAPIPGU).
+		WithResponsibleObject(responsibleObject).
+		WithExpire(PermissionExpireAPIPGUMax).
+		WithActions(PermissionActionAllActionsToData).
+		WithPurposes(PermissionPurposeAPIPGU).
+		WithScopes(ScopeAPIOrder).
+		WithScopes(scopes...)
+}
+
+// WithResponsibleObject - ответственный объект (название организации).
+func (p *Permission) WithResponsibleObject(responsibleObject string) *Permission {
+	p.ResponsibleObject = responsibleObject
+	return p
+}
+
+// WithExpire - срок, на который будет выдано согласие после утверждения (в минутах).
+func (p *Permission) WithExpire(minutes int) *Permission {
+	p.Expire = minutes
+	return p
+}
+
+// WithActions - добавляет мнемоники действий.
+func (p *Permission) WithActions(sysnames ...string) *Permission {
+	for _, sysname := range sysnames {
+		p.Actions = append(p.Actions, PermissionAction{Sysname: sysname})
+	}
+	return p
+}
+
+// WithPurposes - добавляет мнемоники целей согласия.
+func (p *Permission) WithPurposes(sysnames ...string) *Permission {
+	for _, sysname := range sysnames {
+		p.Purposes = append(p.Purposes, PermissionPurpose{Sysname: sysname})
+	}
+	return p
+}
+
+// WithScopes - добавляет мнемоники областей доступа.
+func (p *Permission) WithScopes(scopes ...Scope) *Permission {
+	for _, scope := range scopes {
+		p.Scopes = append(p.Scopes, PermissionScope{Sysname: string(scope)})
+	}
+	return p
+}
+
+// Validate - проверяет список запрашиваемых прав доступа по правилам ЕСИА
+// до перехода пользователя на страницу ЕСИА. Пустой список считается корректным.
+//
+// В случае ошибки возвращает цепочку из [ErrPermissions] и ошибки ЕСИА,
+// которую вернула бы ЕСИА на такой запрос:
+//   - [ErrESIA_036700] - не указана мнемоника типа согласия
+//   - [ErrESIA_036702] - не указан обязательный скоуп для типа согласия
+//   - [ErrESIA_036703] - скоупы выходят за рамки разрешенных для типа согласия
+//   - [ErrESIA_036705] - не указано ни одного действия
+//   - [ErrESIA_036706] - не указана мнемоника действия
+//   - [ErrESIA_036707] - не указано ни одной цели
+//   - [ErrESIA_036716] - некорректный срок действия согласия
+//   - [ErrESIA_036726] - не указана мнемоника цели
+//   - [ErrESIA_036727] - указано более одной цели
+//
+// Обязательные и разрешенные скоупы, а также максимальный срок проверяются
+// только для известных типов согласия (напр. [PermissionSysnameAPIPGU]).
+//
+// Пример сообщения об ошибке:
+//
+//	некорректный параметр permissions: ESIA-036727: Необходимо указать одну цель согласия [index=0, sysname='APIPGU']
+func (p Permissions) Validate() error {
+	for i, permission := range p {
+		if err := permission.validate(); err != nil {
+			return fmt.Errorf("%w: %w [index=%d, sysname='%s']", ErrPermissions, err, i, permission.Sysname)
+		}
+	}
+	return nil
+}
+
+func (p Permission) validate() error {
+	if p.Sysname == "" {
+		return ErrESIA_036700
+	}
+
+	if len(p.Actions) == 0 {
+		return ErrESIA_036705
+	}
+	for _, action := range p.Actions {
+		if action.Sysname == "" {
+			return ErrESIA_036706
+		}
+	}
+
+	switch {
+	case len(p.Purposes) == 0:
+		return ErrESIA_036707
+	case len(p.Purposes) > 1:
+		return ErrESIA_036727
+	case p.Purposes[0].Sysname == "":
+		return ErrESIA_036726
+	}
+
+	if p.Expire < 0 {
+		return ErrESIA_036716
+	}
+
+	rules, ok := permissionRules[p.Sysname]
+	if !ok {
+		return nil
+	}
+
+	if rules.maxExpire > 0 && p.Expire > rules.maxExpire {
+		return ErrESIA_036716
+	}
+
+	scopes := make(Scopes, 0, len(p.Scopes))
+	for _, scope := range p.Scopes {
+		scopes = append(scopes, Scope(scope.Sysname))
+	}
+	for _, required := range rules.required {
+		if !scopes.Has(required) {
+			return ErrESIA_036702
+		}
+	}
+	allowed := NewScopes(rules.required...).With(rules.allowed...)
+	for _, scope := range scopes {
+		if !allowed.Has(scope) {
+			return ErrESIA_036703
+		}
+	}
+
+	return nil
+}
+
+// permissionRule - правила ЕСИА для типа согласия.
+type permissionRule struct {
+	required  []Scope // Обязательные скоупы
+	allowed   []Scope // Дополнительные скоупы, которые разрешено запрашивать
+	maxExpire int     // Максимальный срок согласия в минутах
+}
+
+// permissionRules - правила известных типов согласия.
+var permissionRules = map[string]permissionRule{
+	PermissionSysnameAPIPGU: {
+		required: []Scope{ScopeAPIOrder},
+		allowed: []Scope{
+			ScopeSNILS,
+			ScopeIdDoc,
+			ScopeGender,
+			ScopeFullname,
+			ScopeBirthdate,
+			ScopeAddresses,
+		},
+		maxExpire: PermissionExpireAPIPGUMax,
+	},
+}
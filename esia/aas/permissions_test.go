@@ -28,3 +28,62 @@ func TestPermissions_Base64String(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, p, pGot)
 }
+
+func TestNewAPIPGUPermission(t *testing.T) {
+	p := NewPermissions(NewAPIPGUPermission("test", ScopeSNILS), nil)
+	require.Equal(t, Permissions{
+		Permission{
+			ResponsibleObject: "test",
+			Sysname:           PermissionSysnameAPIPGU,
+			Expire:            PermissionExpireAPIPGUMax,
+			Actions:           []PermissionAction{{Sysname: PermissionActionAllActionsToData}},
+			Purposes:          []PermissionPurpose{{Sysname: PermissionPurposeAPIPGU}},
+			Scopes:            []PermissionScope{{Sysname: string(ScopeAPIOrder)}, {Sysname: string(ScopeSNILS)}},
+		},
+	}, p)
+	require.NoError(t, p.Validate())
+}
+
+func TestPermissions_Validate(t *testing.T) {
+	valid := func() *Permission {
+		return NewPermission("TEST").WithActions("ACTION").WithPurposes("PURPOSE").WithScopes("test")
+	}
+	tests := []struct {
+		name string
+		p    Permissions
+		want error
+	}{
+		{name: "empty", p: Permissions{}},
+		{name: "unknown sysname", p: NewPermissions(valid())},
+		{name: "apipgu", p: NewPermissions(NewAPIPGUPermission("test", ScopeFullname, ScopeBirthdate))},
+		{name: "no sysname", p: NewPermissions(valid(), valid().WithResponsibleObject("x"), &Permission{}), want: ErrESIA_036700},
+		{name: "no actions", p: NewPermissions(NewPermission("TEST").WithPurposes("PURPOSE")), want: ErrESIA_036705},
+		{name: "empty action", p: NewPermissions(valid().WithActions("")), want: ErrESIA_036706},
+		{name: "no purposes", p: NewPermissions(NewPermission("TEST").WithActions("ACTION")), want: ErrESIA_036707},
+		{name: "two purposes", p: NewPermissions(valid().WithPurposes("OTHER")), want: ErrESIA_036727},
+		{name: "empty purpose", p: NewPermissions(NewPermission("TEST").WithActions("ACTION").WithPurposes("")), want: ErrESIA_036726},
+		{name: "negative expire", p: NewPermissions(valid().WithExpire(-1)), want: ErrESIA_036716},
+		{name: "apipgu expire", p: NewPermissions(NewAPIPGUPermission("test").WithExpire(PermissionExpireAPIPGUMax + 1)), want: ErrESIA_036716},
+		{name: "apipgu no required scope", p: NewPermissions(NewPermission(PermissionSysnameAPIPGU).
+			WithActions(PermissionActionAllActionsToData).
+			WithPurposes(PermissionPurposeAPIPGU).
+			WithScopes(ScopeSNILS)), want: ErrESIA_036702},
+		{name: "apipgu extra scope", p: NewPermissions(NewAPIPGUPermission("test", ScopeEmail)), want: ErrESIA_036703},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if tt.want == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrPermissions)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := NewPermissions(valid(), NewAPIPGUPermission("test").WithPurposes("OTHER")).Validate()
+		require.EqualError(t, err, "некорректный параметр permissions: ESIA-036727: Необходимо указать одну цель согласия [index=1, sysname='APIPGU']")
+	})
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 func (c *Client) request(
+	op,
 	method,
 	endpoint,
 	contentType string,
@@ -25,7 +27,11 @@ func (c *Client) request(
 
 	c.logReq(req)
 
+	started := time.Now()
 	res, err := c.httpClient.Do(req)
+	if c.har != nil {
+		c.har.Record(req, res, err, started, op)
+	}
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrRequest, err)
 	}
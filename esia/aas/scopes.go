@@ -0,0 +1,131 @@
+package aas
+
+import (
+	"sort"
+	"strings"
+)
+
+// Scope - область доступа (скоуп) ЕСИА.
+//
+// Подробнее см "Методические рекомендации по использованию ЕСИА",
+// раздел "Перечень областей доступа (scope)".
+type Scope string
+
+// Скоупы ЕСИА для получения данных физического лица.
+const (
+	ScopeOpenID    Scope = "openid"    // Идентификатор пользователя (OID). Обязателен при запросе согласия с параметром permissions
+	ScopeFullname  Scope = "fullname"  // ФИО
+	ScopeBirthdate Scope = "birthdate" // Дата рождения
+	ScopeGender    Scope = "gender"    // Пол
+	ScopeSNILS     Scope = "snils"     // СНИЛС
+	ScopeINN       Scope = "inn"       // ИНН
+	ScopeIdDoc     Scope = "id_doc"    // Документ, удостоверяющий личность
+	ScopeMobile    Scope = "mobile"    // Номер мобильного телефона
+	ScopeEmail     Scope = "email"     // Адрес электронной почты
+	ScopeAddresses Scope = "addresses" // Адреса регистрации и проживания
+)
+
+// ScopeAPIOrder - скоуп ЕПГУ для подачи заявлений с использованием API ЕПГУ.
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12", раздел "1.1 Технические условия", таблица 1.
+const ScopeAPIOrder Scope = "http://lk.gosuslugi.ru/api-order"
+
+// Скоупы ЕСИА для получения данных организации.
+// Запрашиваются только с указанием OID организации: см. [OrgScope].
+const (
+	ScopeOrgShortName Scope = "org_shortname" // Сокращенное наименование организации
+	ScopeOrgFullName  Scope = "org_fullname"  // Полное наименование организации
+	ScopeOrgType      Scope = "org_type"      // Тип организации
+	ScopeOrgOGRN      Scope = "org_ogrn"      // ОГРН организации
+	ScopeOrgINN       Scope = "org_inn"       // ИНН организации
+	ScopeOrgLeg       Scope = "org_leg"       // Организационно-правовая форма
+	ScopeOrgKPP       Scope = "org_kpp"       // КПП организации
+	ScopeOrgCtts      Scope = "org_ctts"      // Контактные данные организации
+	ScopeOrgAddrs     Scope = "org_addrs"     // Адреса организации
+	ScopeOrgEmps      Scope = "org_emps"      // Сотрудники организации
+)
+
+// scopeOrgPrefix - префикс скоупов организации.
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12", раздел "1.1 Технические условия".
+const scopeOrgPrefix = "http://esia.gosuslugi.ru/"
+
+// scopePrmChg - скоуп обновления маркера доступа по OID пользователя, см [Client.TokenUpdate].
+const scopePrmChg Scope = "prm_chg"
+
+// OrgScope - возвращает скоуп организации с OID организации orgOid.
+//
+// Пример:
+//
+//	OrgScope(ScopeOrgOGRN, "1000000000") // "http://esia.gosuslugi.ru/org_ogrn?org_oid=1000000000"
+func OrgScope(scope Scope, orgOid string) Scope {
+	s := string(scope)
+	if !strings.HasPrefix(s, scopeOrgPrefix) {
+		s = scopeOrgPrefix + s
+	}
+	return Scope(s + "?org_oid=" + orgOid)
+}
+
+// Scopes - набор скоупов, запрашиваемых у ЕСИА.
+//
+// ЕСИА проверяет подпись client_secret, в которую скоупы входят в виде строки,
+// поэтому строка скоупов при вызовах [Client.AuthURI] и [Client.TokenExchange]
+// должна совпадать побайтно. [Scopes.String] всегда возвращает каноническое
+// представление набора: без повторов, в лексикографическом порядке, через один пробел.
+type Scopes []Scope
+
+// NewScopes - конструктор [Scopes].
+func NewScopes(scopes ...Scope) Scopes {
+	return Scopes{}.With(scopes...)
+}
+
+// ParseScopes - разбирает строку скоупов, разделенных пробельными символами.
+func ParseScopes(s string) Scopes {
+	fields := strings.Fields(s)
+	scopes := make(Scopes, 0, len(fields))
+	for _, f := range fields {
+		scopes = append(scopes, Scope(f))
+	}
+	return scopes
+}
+
+// With - возвращает новый набор с добавленными скоупами.
+// Исходный набор не изменяется.
+func (s Scopes) With(scopes ...Scope) Scopes {
+	result := make(Scopes, 0, len(s)+len(scopes))
+	result = append(result, s...)
+	return append(result, scopes...)
+}
+
+// Has - возвращает true, если набор содержит скоуп scope.
+func (s Scopes) Has(scope Scope) bool {
+	scope = Scope(strings.TrimSpace(string(scope)))
+	for _, item := range s {
+		if Scope(strings.TrimSpace(string(item))) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// String - возвращает каноническое строковое представление набора скоупов:
+// без пустых значений и повторов, в лексикографическом порядке, через один пробел.
+//
+// Пример:
+//
+//	NewScopes(ScopeOpenID, ScopeAPIOrder, ScopeOpenID).String() // "http://lk.gosuslugi.ru/api-order openid"
+func (s Scopes) String() string {
+	seen := make(map[string]struct{}, len(s))
+	items := make([]string, 0, len(s))
+	for _, scope := range s {
+		for _, item := range strings.Fields(string(scope)) {
+			if _, ok := seen[item]; ok {
+				continue
+			}
+			seen[item] = struct{}{}
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, " ")
+}
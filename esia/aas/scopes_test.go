@@ -0,0 +1,116 @@
+package aas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTestScopes struct {
+	suite.Suite
+}
+
+func TestScopes(t *testing.T) {
+	suite.Run(t, new(suiteTestScopes))
+}
+
+func (suite *suiteTestScopes) TestString() {
+	suite.Run("canonical", func() {
+		suite.Equal(
+			"fullname http://lk.gosuslugi.ru/api-order openid",
+			NewScopes(ScopeOpenID, ScopeAPIOrder, ScopeFullname, ScopeOpenID).String(),
+		)
+	})
+
+	suite.Run("order independent", func() {
+		a := NewScopes(ScopeOpenID, ScopeSNILS, ScopeEmail)
+		b := NewScopes(ScopeEmail, ScopeOpenID).With(ScopeSNILS)
+		suite.Equal(a.String(), b.String())
+	})
+
+	suite.Run("whitespace", func() {
+		suite.Equal("email openid", Scopes{"  openid ", "email\topenid", ""}.String())
+	})
+
+	suite.Run("empty", func() {
+		suite.Equal("", NewScopes().String())
+		suite.Equal("", Scopes(nil).String())
+	})
+}
+
+func (suite *suiteTestScopes) TestParseScopes() {
+	scopes := ParseScopes("  openid\nhttp://lk.gosuslugi.ru/api-order   openid ")
+	suite.Equal(Scopes{ScopeOpenID, ScopeAPIOrder, ScopeOpenID}, scopes)
+	suite.Equal("http://lk.gosuslugi.ru/api-order openid", scopes.String())
+	suite.Empty(ParseScopes(" "))
+}
+
+func (suite *suiteTestScopes) TestWith() {
+	a := NewScopes(ScopeOpenID)
+	b := a.With(ScopeEmail)
+	suite.Equal(Scopes{ScopeOpenID}, a)
+	suite.Equal(Scopes{ScopeOpenID, ScopeEmail}, b)
+}
+
+func (suite *suiteTestScopes) TestHas() {
+	scopes := NewScopes(ScopeOpenID, " email ")
+	suite.True(scopes.Has(ScopeOpenID))
+	suite.True(scopes.Has(ScopeEmail))
+	suite.False(scopes.Has(ScopeSNILS))
+}
+
+func (suite *suiteTestScopes) TestOrgScope() {
+	suite.Equal(
+		Scope("http://esia.gosuslugi.ru/org_ogrn?org_oid=1000000000"),
+		OrgScope(ScopeOrgOGRN, "1000000000"),
+	)
+	suite.Equal(
+		Scope("http://esia.gosuslugi.ru/org_inn?org_oid=1"),
+		OrgScope("http://esia.gosuslugi.ru/org_inn", "1"),
+	)
+}
+
+func (suite *suiteTestScopes) TestSameSignedScope() {
+	signer := &recordSigner{}
+	var tokenScope string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenScope = r.FormValue("scope")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"access_token":"test"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test", signer)
+
+	uriStr, err := client.AuthURI(NewScopes(ScopeOpenID, ScopeAPIOrder), "test-redirect", Permissions{})
+	suite.Require().NoError(err)
+	u, err := url.Parse(uriStr)
+	suite.Require().NoError(err)
+	authScope := u.Query().Get("scope")
+
+	_, err = client.TokenExchange("code", NewScopes(ScopeAPIOrder, ScopeOpenID, ScopeAPIOrder), "test-redirect")
+	suite.Require().NoError(err)
+
+	suite.Equal("http://lk.gosuslugi.ru/api-order openid", authScope)
+	suite.Equal(authScope, tokenScope)
+	suite.Require().Len(signer.data, 2)
+	suite.Contains(signer.data[0], authScope)
+	suite.Contains(signer.data[1], authScope)
+}
+
+// recordSigner - провайдер подписи, сохраняющий подписываемые данные.
+type recordSigner struct {
+	data []string
+}
+
+func (s *recordSigner) Sign(data []byte) ([]byte, error) {
+	s.data = append(s.data, string(data))
+	return []byte("signature"), nil
+}
+
+func (s *recordSigner) CertHash() string {
+	return "hash"
+}
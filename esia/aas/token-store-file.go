@@ -0,0 +1,152 @@
+package aas
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileTokenStore - реализация [TokenStore] в зашифрованных файлах.
+//
+// Каждая запись хранится в отдельном файле {dir}/{oid}.token, зашифрованном
+// AES-GCM на ключе, переданном в конструктор. OID используется как дополнительные
+// аутентифицируемые данные: запись, перенесенная в файл другого пользователя, не расшифруется.
+// Файлы записываются атомарно (через временный файл) с правами 0600.
+//
+// Каталог может находиться на общем сетевом ресурсе, если несколько
+// экземпляров приложения должны использовать одни и те же маркеры.
+type FileTokenStore struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// fileTokenExt - расширение файлов [FileTokenStore].
+const fileTokenExt = ".token"
+
+// NewFileTokenStore - конструктор [FileTokenStore].
+// Параметры:
+//   - dir - каталог для хранения файлов, создается при необходимости
+//   - key - ключ AES длиной 16, 24 или 32 байта (рекомендуется 32 байта: AES-256)
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и следующих возможных ошибок:
+//   - [ErrCrypto] - некорректный ключ
+//   - [ErrFileIO] - ошибка создания каталога
+func NewFileTokenStore(dir string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrCrypto, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrCrypto, err)
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+	return &FileTokenStore{dir: dir, aead: aead}, nil
+}
+
+// Save - шифрует и сохраняет запись.
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и следующих возможных ошибок:
+//   - [ErrInvalidOID] - некорректный OID
+//   - [ErrCrypto] - ошибка шифрования
+//   - [ErrFileIO] - ошибка записи файла
+func (s *FileTokenStore) Save(record *TokenRecord) error {
+	if record == nil {
+		return fmt.Errorf("%w: %w: пустая запись", ErrTokenStore, ErrInvalidOID)
+	}
+	if err := checkOID(record.OID); err != nil {
+		return fmt.Errorf("%w: %w", ErrTokenStore, err)
+	}
+
+	plaintext, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrCrypto, err)
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrCrypto, err)
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, []byte(record.OID))
+
+	tmp, err := os.CreateTemp(s.dir, record.OID+".tmp*")
+	if err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+	//goland:noinspection ALL
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+	if err = os.Rename(tmp.Name(), s.path(record.OID)); err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+	return nil
+}
+
+// Load - читает и расшифровывает запись по OID.
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и следующих возможных ошибок:
+//   - [ErrInvalidOID] - некорректный OID
+//   - [ErrTokenNotFound] - запись не найдена
+//   - [ErrCrypto] - ошибка расшифровки: неверный ключ или файл поврежден
+//   - [ErrFileIO] - ошибка чтения файла
+func (s *FileTokenStore) Load(oid string) (*TokenRecord, error) {
+	if err := checkOID(oid); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenStore, err)
+	}
+	data, err := os.ReadFile(s.path(oid))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w: '%s'", ErrTokenStore, ErrTokenNotFound, oid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("%w: %w: файл поврежден", ErrTokenStore, ErrCrypto)
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(oid))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrCrypto, err)
+	}
+
+	record := &TokenRecord{}
+	if err = json.Unmarshal(plaintext, record); err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrJSONUnmarshal, err)
+	}
+	return record, nil
+}
+
+// Delete - удаляет файл записи по OID.
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и следующих возможных ошибок:
+//   - [ErrInvalidOID] - некорректный OID
+//   - [ErrFileIO] - ошибка удаления файла
+func (s *FileTokenStore) Delete(oid string) error {
+	if err := checkOID(oid); err != nil {
+		return fmt.Errorf("%w: %w", ErrTokenStore, err)
+	}
+	err := os.Remove(s.path(oid))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w: %w", ErrTokenStore, ErrFileIO, err)
+	}
+	return nil
+}
+
+func (s *FileTokenStore) path(oid string) string {
+	return filepath.Join(s.dir, oid+fileTokenExt)
+}
@@ -0,0 +1,56 @@
+package aas
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MemoryTokenStore - реализация [TokenStore] в памяти процесса.
+// Безопасна для конкурентного использования.
+// Подходит для тестов и приложений из одного экземпляра.
+type MemoryTokenStore struct {
+	mu      sync.RWMutex
+	records map[string]TokenRecord
+}
+
+// NewMemoryTokenStore - конструктор [MemoryTokenStore].
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{records: make(map[string]TokenRecord)}
+}
+
+// Save - сохраняет копию записи.
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и [ErrInvalidOID].
+func (s *MemoryTokenStore) Save(record *TokenRecord) error {
+	if record == nil {
+		return fmt.Errorf("%w: %w: пустая запись", ErrTokenStore, ErrInvalidOID)
+	}
+	if err := checkOID(record.OID); err != nil {
+		return fmt.Errorf("%w: %w", ErrTokenStore, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.OID] = *record
+	return nil
+}
+
+// Load - возвращает копию записи по OID.
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и [ErrTokenNotFound].
+func (s *MemoryTokenStore) Load(oid string) (*TokenRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[oid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %w: '%s'", ErrTokenStore, ErrTokenNotFound, oid)
+	}
+	return &record, nil
+}
+
+// Delete - удаляет запись по OID.
+func (s *MemoryTokenStore) Delete(oid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, oid)
+	return nil
+}
@@ -0,0 +1,108 @@
+package aas
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// TokenStore - интерфейс хранилища маркеров доступа ЕСИА по идентификатору пользователя (OID).
+//
+// Позволяет получить маркер доступа на одном экземпляре приложения (callback от ЕСИА),
+// а использовать и обновлять его на другом.
+//
+// Реализации:
+//   - [MemoryTokenStore] — хранение в памяти процесса
+//   - [FileTokenStore] — хранение в зашифрованных файлах (AES-GCM)
+type TokenStore interface {
+	// Save - сохраняет запись, заменяя существующую запись с тем же OID.
+	Save(record *TokenRecord) error
+	// Load - возвращает запись по OID либо [ErrTokenNotFound].
+	Load(oid string) (*TokenRecord, error)
+	// Delete - удаляет запись по OID. Удаление отсутствующей записи не является ошибкой.
+	Delete(oid string) error
+}
+
+// TokenRecord - запись хранилища маркеров доступа [TokenStore].
+type TokenRecord struct {
+	OID         string      `json:"oid"`                   // Идентификатор пользователя
+	AccessToken string      `json:"access_token"`          // Маркер доступа
+	IdToken     string      `json:"id_token,omitempty"`    // Маркер идентификации
+	ExpiresAt   time.Time   `json:"expires_at"`            // Окончание срока действия маркера доступа
+	Scope       Scopes      `json:"scope"`                 // Скоупы, на которые выдан маркер
+	Permissions Permissions `json:"permissions,omitempty"` // Запрошенные права доступа
+	RedirectURI string      `json:"redirect_uri"`          // redirect_uri, использованный при получении маркера: нужен для [Client.TokenUpdate]
+	UpdatedAt   time.Time   `json:"updated_at"`            // Время получения или обновления маркера
+	Consent     Consent     `json:"consent"`               // Сведения о согласии пользователя
+}
+
+// NewTokenRecord - создает запись [TokenRecord] из ответа ЕСИА [Client.TokenExchange].
+// OID пользователя и срок действия берутся из маркера доступа.
+// Сведения о согласии рассчитываются по permissions на момент вызова, см [NewConsent].
+// Параметры scope, redirectURI и permissions должны быть такими же, как и при вызове [Client.AuthURI].
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и [ErrTokenParse].
+func NewTokenRecord(res *TokenExchangeResponse, scope Scopes, redirectURI string, permissions Permissions) (*TokenRecord, error) {
+	record := &TokenRecord{
+		Scope:       scope,
+		Permissions: permissions,
+		RedirectURI: redirectURI,
+	}
+	if err := record.Update(res); err != nil {
+		return nil, err
+	}
+	record.Consent = NewConsent(permissions, record.UpdatedAt)
+	return record, nil
+}
+
+// Update - обновляет маркер доступа в записи по ответу ЕСИА [Client.TokenUpdate].
+//
+// В случае ошибки возвращает цепочку из [ErrTokenStore] и следующих возможных ошибок:
+//   - [ErrTokenParse] - некорректный маркер доступа
+//   - [ErrInvalidOID] - OID из маркера не совпадает с OID записи
+func (r *TokenRecord) Update(res *TokenExchangeResponse) error {
+	if res == nil {
+		return fmt.Errorf("%w: %w: пустой ответ", ErrTokenStore, ErrTokenParse)
+	}
+	claims, err := ParseTokenClaims(res.AccessToken)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTokenStore, err)
+	}
+	oid := claims.OID()
+	if !reOID.MatchString(oid) || (r.OID != "" && r.OID != oid) {
+		return fmt.Errorf("%w: %w: '%s'", ErrTokenStore, ErrInvalidOID, oid)
+	}
+
+	now := nowFunc()
+	expiresAt := claims.Expiry()
+	if res.ExpiresIn > 0 {
+		expiresAt = now.Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+
+	r.OID = oid
+	r.AccessToken = res.AccessToken
+	if res.IdToken != "" {
+		r.IdToken = res.IdToken
+	}
+	r.ExpiresAt = expiresAt
+	r.UpdatedAt = now
+	return nil
+}
+
+// Expired - возвращает true, если срок действия маркера доступа истек
+// или истечет в течение leeway.
+func (r *TokenRecord) Expired(leeway time.Duration) bool {
+	return !r.ExpiresAt.IsZero() && !nowFunc().Add(leeway).Before(r.ExpiresAt)
+}
+
+// reOID - формат OID пользователя ЕСИА.
+var reOID = regexp.MustCompile(`^\d{1,20}$`)
+
+func checkOID(oid string) error {
+	if !reOID.MatchString(oid) {
+		return fmt.Errorf("%w: '%s'", ErrInvalidOID, oid)
+	}
+	return nil
+}
+
+var nowFunc = time.Now
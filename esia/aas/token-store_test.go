@@ -0,0 +1,236 @@
+package aas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTestTokenStore struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestTokenStore(t *testing.T) {
+	suite.Run(t, new(suiteTestTokenStore))
+}
+
+func (suite *suiteTestTokenStore) SetupTest() {
+	suite.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return suite.now }
+}
+
+func (suite *suiteTestTokenStore) TearDownTest() {
+	nowFunc = time.Now
+}
+
+func (suite *suiteTestTokenStore) TestParseTokenClaims() {
+	suite.Run("success", func() {
+		claims, err := ParseTokenClaims(testToken(`{"urn:esia:sbj_id":1000572618,"exp":1704153600,"client_id":"MNEMONIC"}`))
+		suite.Require().NoError(err)
+		suite.Equal("1000572618", claims.OID())
+		suite.Equal("MNEMONIC", claims.ClientId)
+		suite.Equal(time.Unix(1704153600, 0), claims.Expiry())
+	})
+
+	suite.Run("error parts", func() {
+		_, err := ParseTokenClaims("invalid")
+		suite.ErrorIs(err, ErrTokenParse)
+	})
+
+	suite.Run("error payload", func() {
+		_, err := ParseTokenClaims("a.!!!.c")
+		suite.ErrorIs(err, ErrTokenParse)
+	})
+}
+
+func (suite *suiteTestTokenStore) TestNewTokenRecord() {
+	suite.Run("success", func() {
+		res := &TokenExchangeResponse{
+			AccessToken: testToken(`{"urn:esia:sbj_id":1000572618}`),
+			IdToken:     "id-token",
+			ExpiresIn:   3600,
+		}
+		record, err := NewTokenRecord(res, NewScopes(ScopeOpenID), "redirect", Permissions{})
+		suite.Require().NoError(err)
+		suite.Equal("1000572618", record.OID)
+		suite.Equal(res.AccessToken, record.AccessToken)
+		suite.Equal("id-token", record.IdToken)
+		suite.Equal(suite.now.Add(time.Hour), record.ExpiresAt)
+		suite.Equal(suite.now, record.UpdatedAt)
+		suite.Equal("redirect", record.RedirectURI)
+		suite.False(record.Expired(time.Minute))
+		suite.True(record.Expired(time.Hour))
+	})
+
+	suite.Run("error token", func() {
+		_, err := NewTokenRecord(&TokenExchangeResponse{AccessToken: "invalid"}, nil, "", nil)
+		suite.ErrorIs(err, ErrTokenStore)
+		suite.ErrorIs(err, ErrTokenParse)
+	})
+
+	suite.Run("error oid", func() {
+		_, err := NewTokenRecord(&TokenExchangeResponse{AccessToken: testToken(`{}`)}, nil, "", nil)
+		suite.ErrorIs(err, ErrTokenStore)
+		suite.ErrorIs(err, ErrInvalidOID)
+	})
+}
+
+func (suite *suiteTestTokenStore) TestUpdate() {
+	record, err := NewTokenRecord(
+		&TokenExchangeResponse{AccessToken: testToken(`{"urn:esia:sbj_id":1}`), IdToken: "id-token", ExpiresIn: 60},
+		nil, "redirect", nil,
+	)
+	suite.Require().NoError(err)
+
+	suite.Run("success", func() {
+		suite.now = suite.now.Add(time.Minute)
+		token := testToken(`{"urn:esia:sbj_id":1,"iat":1}`)
+		suite.Require().NoError(record.Update(&TokenExchangeResponse{AccessToken: token, ExpiresIn: 60}))
+		suite.Equal(token, record.AccessToken)
+		suite.Equal("id-token", record.IdToken)
+		suite.Equal(suite.now.Add(time.Minute), record.ExpiresAt)
+	})
+
+	suite.Run("error other oid", func() {
+		err := record.Update(&TokenExchangeResponse{AccessToken: testToken(`{"urn:esia:sbj_id":2}`)})
+		suite.ErrorIs(err, ErrInvalidOID)
+		suite.Equal("1", record.OID)
+	})
+}
+
+func (suite *suiteTestTokenStore) TestMemoryTokenStore() {
+	suite.testStore(NewMemoryTokenStore())
+}
+
+func (suite *suiteTestTokenStore) TestFileTokenStore() {
+	dir := suite.T().TempDir()
+	store, err := NewFileTokenStore(dir, testKey())
+	suite.Require().NoError(err)
+	suite.testStore(store)
+
+	suite.Run("encrypted", func() {
+		suite.Require().NoError(store.Save(testRecord("1000572618")))
+		data, err := os.ReadFile(filepath.Join(dir, "1000572618.token"))
+		suite.Require().NoError(err)
+		suite.NotContains(string(data), "access-token")
+		info, err := os.Stat(filepath.Join(dir, "1000572618.token"))
+		suite.Require().NoError(err)
+		suite.Equal(os.FileMode(0600), info.Mode().Perm())
+	})
+
+	suite.Run("error wrong key", func() {
+		suite.Require().NoError(store.Save(testRecord("1000572618")))
+		key := testKey()
+		key[0] ^= 0xff
+		other, err := NewFileTokenStore(dir, key)
+		suite.Require().NoError(err)
+		_, err = other.Load("1000572618")
+		suite.ErrorIs(err, ErrTokenStore)
+		suite.ErrorIs(err, ErrCrypto)
+	})
+
+	suite.Run("error tampered", func() {
+		suite.Require().NoError(store.Save(testRecord("1000572618")))
+		path := filepath.Join(dir, "1000572618.token")
+		data, err := os.ReadFile(path)
+		suite.Require().NoError(err)
+		data[len(data)-1] ^= 0xff
+		suite.Require().NoError(os.WriteFile(path, data, 0600))
+		_, err = store.Load("1000572618")
+		suite.ErrorIs(err, ErrCrypto)
+	})
+
+	suite.Run("error moved", func() {
+		suite.Require().NoError(store.Save(testRecord("1000572618")))
+		suite.Require().NoError(os.Rename(
+			filepath.Join(dir, "1000572618.token"),
+			filepath.Join(dir, "1000000001.token"),
+		))
+		_, err = store.Load("1000000001")
+		suite.ErrorIs(err, ErrCrypto)
+	})
+
+	suite.Run("error key", func() {
+		_, err := NewFileTokenStore(dir, []byte("short"))
+		suite.ErrorIs(err, ErrTokenStore)
+		suite.ErrorIs(err, ErrCrypto)
+	})
+
+	suite.Run("error path traversal", func() {
+		_, err := store.Load("../1000572618")
+		suite.ErrorIs(err, ErrInvalidOID)
+	})
+}
+
+func (suite *suiteTestTokenStore) testStore(store TokenStore) {
+	suite.Run("save and load", func() {
+		record := testRecord("1000572618")
+		suite.Require().NoError(store.Save(record))
+		loaded, err := store.Load("1000572618")
+		suite.Require().NoError(err)
+		suite.Equal(record.AccessToken, loaded.AccessToken)
+		suite.Equal(record.IdToken, loaded.IdToken)
+		suite.True(record.ExpiresAt.Equal(loaded.ExpiresAt))
+		suite.Equal(record.Scope, loaded.Scope)
+		suite.Equal(record.Permissions, loaded.Permissions)
+		suite.Equal(record.RedirectURI, loaded.RedirectURI)
+	})
+
+	suite.Run("replace", func() {
+		record := testRecord("1000572618")
+		record.AccessToken = "new-access-token"
+		suite.Require().NoError(store.Save(record))
+		loaded, err := store.Load("1000572618")
+		suite.Require().NoError(err)
+		suite.Equal("new-access-token", loaded.AccessToken)
+	})
+
+	suite.Run("delete", func() {
+		suite.Require().NoError(store.Delete("1000572618"))
+		_, err := store.Load("1000572618")
+		suite.ErrorIs(err, ErrTokenStore)
+		suite.ErrorIs(err, ErrTokenNotFound)
+		suite.NoError(store.Delete("1000572618"))
+	})
+
+	suite.Run("error oid", func() {
+		err := store.Save(testRecord("invalid"))
+		suite.ErrorIs(err, ErrTokenStore)
+		suite.ErrorIs(err, ErrInvalidOID)
+	})
+}
+
+func testToken(payload string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"GOST3410_2012_256","typ":"JWT"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+}
+
+func testKey() []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+func testRecord(oid string) *TokenRecord {
+	permissions := NewPermissions(NewAPIPGUPermission("Тест"))
+	// Приводим к виду после JSON-сериализации
+	data, _ := json.Marshal(permissions)
+	_ = json.Unmarshal(data, &permissions)
+	return &TokenRecord{
+		OID:         oid,
+		AccessToken: "access-token",
+		IdToken:     "id-token",
+		ExpiresAt:   time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+		Scope:       NewScopes(ScopeOpenID, ScopeAPIOrder),
+		Permissions: permissions,
+		RedirectURI: "https://example.com/callback",
+	}
+}
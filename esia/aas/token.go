@@ -0,0 +1,70 @@
+package aas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenClaims - утверждения (claims) маркера доступа ЕСИА.
+//
+// Пример содержимого маркера доступа:
+//
+//	{
+//	  "nbf": 1700000000,
+//	  "scope": "http://lk.gosuslugi.ru/api-order?oid=1000572618 openid",
+//	  "iss": "http://esia-portal1.test.gosuslugi.ru/",
+//	  "urn:esia:sid": "2b1c6a7d-...",
+//	  "urn:esia:sbj_id": 1000572618,
+//	  "exp": 1700086400,
+//	  "iat": 1700000000,
+//	  "client_id": "MNEMONIC"
+//	}
+type TokenClaims struct {
+	Issuer    string      `json:"iss"`             // Организация, выпустившая маркер (адрес ЕСИА)
+	ClientId  string      `json:"client_id"`       // Мнемоника ИС-потребителя
+	Scope     string      `json:"scope"`           // Скоупы, на которые выдан маркер
+	SbjId     json.Number `json:"urn:esia:sbj_id"` // Идентификатор пользователя (OID)
+	SessionId string      `json:"urn:esia:sid"`    // Идентификатор сессии
+	NotBefore int64       `json:"nbf"`             // Начало срока действия (Unix time)
+	ExpiresAt int64       `json:"exp"`             // Окончание срока действия (Unix time)
+	IssuedAt  int64       `json:"iat"`             // Время выдачи (Unix time)
+}
+
+// ParseTokenClaims - возвращает утверждения маркера доступа ЕСИА.
+//
+// ВАЖНО: подпись маркера не проверяется. Используйте только для маркеров,
+// полученных непосредственно от ЕСИА.
+//
+// В случае ошибки возвращает [ErrTokenParse].
+func ParseTokenClaims(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: ожидается 3 части, получено %d", ErrTokenParse, len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenParse, err)
+	}
+	claims := &TokenClaims{}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenParse, err)
+	}
+	return claims, nil
+}
+
+// OID - возвращает идентификатор пользователя (OID) из маркера доступа.
+func (c *TokenClaims) OID() string {
+	return c.SbjId.String()
+}
+
+// Expiry - возвращает время окончания срока действия маркера доступа.
+// Если срок не указан, возвращает нулевое время.
+func (c *TokenClaims) Expiry() time.Time {
+	if c.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(c.ExpiresAt, 0)
+}
@@ -0,0 +1,39 @@
+// Пакет esiatest - фейковый сервер авторизации ЕСИА для тестирования входа пользователя
+// и получения маркера доступа с помощью [aas.Client].
+//
+// Сервер реализует:
+//
+//   - GET /aas/oauth2/v2/ac — страница предоставления пользователем запрошенных прав:
+//     вместо пользователя решение принимает функция [Server.WithDecision],
+//     результат передается перенаправлением на redirect_uri с параметрами code и state
+//     либо с ошибкой ЕСИА (error, error_description)
+//   - POST /aas/oauth2/v3/te — обмен кода авторизации на маркер доступа (grant_type=authorization_code)
+//     и обновление маркера доступа по OID пользователя (grant_type=client_credentials, scope=prm_chg?oid=...)
+//
+// Для каждого запроса проверяются обязательные параметры, время запроса timestamp
+// (см [Server.WithTimestampWindow]), разрешенные redirect_uri (см [Server.WithClient])
+// и подпись client_secret с помощью [SecretVerifier]. По умолчанию используется [NopVerifier],
+// который принимает подпись тестового провайдера:
+//
+//	signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash)
+//
+// Маркеры доступа имеют формат JWT и разбираются функцией [aas.ParseTokenClaims];
+// подпись маркеров не является подписью ГОСТ и не может быть проверена.
+//
+// Пример:
+//
+//	server := esiatest.NewServer().WithClient("TEST_IS", "http://localhost/callback")
+//	defer server.Close()
+//
+//	client := aas.NewClient(server.URL, "TEST_IS", signature.NewNop(esiatest.TestSignature, esiatest.TestCertHash))
+//	authURI, err := client.AuthURI(scopes, "http://localhost/callback", permissions)
+//	...
+//	query, err := server.Authorize(authURI)
+//	...
+//	code, state, err := client.ParseCallback(query)
+//	...
+//	res, err := client.TokenExchange(code, scopes, "http://localhost/callback")
+//
+// [aas.Client]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/aas#Client
+// [aas.ParseTokenClaims]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/aas#ParseTokenClaims
+package esiatest
@@ -0,0 +1,8 @@
+package esiatest
+
+import "errors"
+
+// Ошибки пакета esiatest
+var (
+	ErrSecret = errors.New("некорректная подпись client_secret")
+)
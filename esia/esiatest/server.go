@@ -0,0 +1,521 @@
+package esiatest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+// tsLayout - формат параметра timestamp запросов к ЕСИА.
+const tsLayout = "2006.01.02 15:04:05 -0700"
+
+// Значения по умолчанию.
+const (
+	DefaultOID             = "1000572618"       // OID пользователя, предоставляющего права
+	DefaultTokenTTL        = time.Hour          // Срок действия маркера доступа
+	DefaultTimestampWindow = 5 * time.Minute    // Допустимое отклонение timestamp от времени сервера
+	CodeTTL                = 5 * time.Minute    // Срок действия кода авторизации
+	TestSignature          = "test-signature"   // Подпись тестового провайдера, см [NopVerifier]
+	TestCertHash           = "test-certificate" // Хэш сертификата тестового провайдера, см [NopVerifier]
+)
+
+// AuthRequest - запрос на предоставление пользователем прав, передается в функцию [Server.WithDecision].
+type AuthRequest struct {
+	ClientId    string // Мнемоника ИС-потребителя
+	Scope       aas.Scopes
+	State       string
+	RedirectURI string
+	Permissions string // Параметр permissions в base64
+	AccessType  string
+}
+
+// Decision - решение пользователя на странице предоставления прав.
+type Decision struct {
+	OID string // OID пользователя, предоставившего права; по умолчанию [DefaultOID]
+
+	// Deny - пользователь отказал в предоставлении прав: ошибка access_denied (ESIA-007004)
+	Deny bool
+
+	// Error и ErrorDescription - произвольная ошибка ЕСИА, например:
+	//	Decision{Error: "invalid_scope", ErrorDescription: "ESIA-007006: ..."}
+	Error            string
+	ErrorDescription string
+}
+
+// Server - фейковый сервер авторизации ЕСИА.
+// Реализует [http.Handler], поэтому может использоваться без [httptest.Server], см [New].
+type Server struct {
+	URL string // Базовый URL запущенного сервера для [aas.NewClient], см [NewServer]
+
+	httpServer *httptest.Server
+	mu         sync.Mutex
+	now        func() time.Time
+	verifier   SecretVerifier
+	clients    map[string]map[string]struct{}
+	decision   func(AuthRequest) Decision
+	tokenTTL   time.Duration
+	window     time.Duration
+	codes      map[string]authCode
+	consents   map[string]aas.Scopes // Предоставленные права: "{clientId}/{oid}" -> scope
+	tokens     map[string]time.Time  // Выданные маркеры доступа и срок их действия
+}
+
+// authCode - выданный код авторизации.
+type authCode struct {
+	clientId    string
+	scope       string
+	redirectURI string
+	oid         string
+	expires     time.Time
+}
+
+// New - конструктор [Server] без запуска HTTP-сервера.
+func New() *Server {
+	return &Server{
+		now:      time.Now,
+		verifier: NewNopVerifier(TestSignature, TestCertHash),
+		clients:  make(map[string]map[string]struct{}),
+		decision: func(AuthRequest) Decision { return Decision{} },
+		tokenTTL: DefaultTokenTTL,
+		window:   DefaultTimestampWindow,
+		codes:    make(map[string]authCode),
+		consents: make(map[string]aas.Scopes),
+		tokens:   make(map[string]time.Time),
+	}
+}
+
+// NewServer - конструктор [Server], запускает HTTP-сервер [httptest.Server].
+// Адрес сервера: [Server.URL]. После использования сервер необходимо остановить: [Server.Close].
+func NewServer() *Server {
+	s := New()
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close - останавливает HTTP-сервер, запущенный [NewServer].
+func (s *Server) Close() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// WithClient - регистрирует ИС с мнемоникой clientId и разрешенными redirect_uri.
+// Если ни одна ИС не зарегистрирована, принимаются запросы от любой ИС с любым redirect_uri.
+func (s *Server) WithClient(clientId string, redirectURIs ...string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uris := make(map[string]struct{}, len(redirectURIs))
+	for _, uri := range redirectURIs {
+		uris[uri] = struct{}{}
+	}
+	s.clients[clientId] = uris
+	return s
+}
+
+// WithVerifier - задает проверку подписи client_secret (по умолчанию [NopVerifier]).
+func (s *Server) WithVerifier(verifier SecretVerifier) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verifier = verifier
+	return s
+}
+
+// WithDecision - задает решение пользователя на странице предоставления прав.
+// По умолчанию пользователь с OID [DefaultOID] предоставляет запрошенные права.
+func (s *Server) WithDecision(decision func(AuthRequest) Decision) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decision = decision
+	return s
+}
+
+// WithTokenTTL - задает срок действия маркера доступа (по умолчанию [DefaultTokenTTL]).
+func (s *Server) WithTokenTTL(ttl time.Duration) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenTTL = ttl
+	return s
+}
+
+// WithTimestampWindow - задает допустимое отклонение параметра timestamp от времени сервера
+// (по умолчанию [DefaultTimestampWindow]). При превышении возвращается ошибка ESIA-007015.
+func (s *Server) WithTimestampWindow(window time.Duration) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.window = window
+	return s
+}
+
+// WithClock - задает функцию текущего времени (по умолчанию [time.Now]).
+func (s *Server) WithClock(now func() time.Time) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+	return s
+}
+
+// Revoke - отзывает права, предоставленные пользователем oid информационной системе clientId:
+// обновление маркера доступа ([aas.Client.TokenUpdate]) завершится ошибкой ESIA-007019.
+func (s *Server) Revoke(clientId, oid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.consents, clientId+"/"+oid)
+}
+
+// Valid - проверяет, что маркер доступа выдан сервером и не истек.
+// Используется для проверки маркера в фейковых ресурсных серверах.
+func (s *Server) Valid(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.tokens[token]
+	return ok && s.now().Before(expires)
+}
+
+// Authorize - выполняет запрос к странице предоставления прав authURI (результат [aas.Client.AuthURI])
+// и возвращает query-параметры перенаправления на redirect_uri для [aas.Client.ParseCallback].
+func (s *Server) Authorize(authURI string) (url.Values, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	res, err := client.Get(authURI)
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection ALL
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		e := aas.ErrorResponse{}
+		_ = json.NewDecoder(res.Body).Decode(&e)
+		return nil, fmt.Errorf("HTTP %s: %s", res.Status, e.ErrorDescription)
+	}
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	return location.Query(), nil
+}
+
+// ServeHTTP - реализация [http.Handler].
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == aas.UserEndpoint:
+		s.authorize(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == aas.TokenEndpoint:
+		s.token(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// esiaError - ошибка ЕСИА.
+type esiaError struct {
+	error       string
+	description string
+}
+
+// Ошибки ЕСИА.
+var (
+	errMissingParam = esiaError{"invalid_request", "ESIA-007014: The request does not contain the mandatory parameter"}
+	errClient       = esiaError{"invalid_client", "ESIA-007002: Certificate does not match the system mnemonic"}
+	errRedirectURI  = esiaError{"invalid_request", "ESIA-007023: The redirect_uri is not allowed for the system"}
+	errTimestamp    = esiaError{"invalid_request", "ESIA-007015: Invalid request time"}
+	errSecret       = esiaError{"invalid_client", "ESIA-007053: Client_secret is invalid"}
+	errDenied       = esiaError{"access_denied", "ESIA-007004: The resource owner or authorization server denied the request"}
+	errGrant        = esiaError{"invalid_grant", "ESIA-007011: The authorization code is invalid, expired or revoked"}
+	errGrantType    = esiaError{"unsupported_grant_type", "ESIA-007009: The authorization server does not support this grant type"}
+	errScope        = esiaError{"invalid_scope", "ESIA-007006: The requested scope is invalid"}
+	errNoConsent    = esiaError{"access_denied", "ESIA-007019: No permission to access"}
+)
+
+// authorize - GET /aas/oauth2/v2/ac
+//
+// Подробнее см "Методические рекомендации по использованию ЕСИА",
+// раздел "Получение авторизационного кода (v2/ac)".
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := AuthRequest{
+		ClientId:    q.Get("client_id"),
+		Scope:       aas.ParseScopes(q.Get("scope")),
+		State:       q.Get("state"),
+		RedirectURI: q.Get("redirect_uri"),
+		Permissions: q.Get("permissions"),
+		AccessType:  q.Get("access_type"),
+	}
+
+	// ошибки до проверки redirect_uri возвращаются без перенаправления
+	if req.ClientId == "" || req.RedirectURI == "" {
+		s.writeError(w, errMissingParam, req.State)
+		return
+	}
+	if e := s.checkClient(req.ClientId, req.RedirectURI); e != nil {
+		s.writeError(w, *e, req.State)
+		return
+	}
+
+	e := s.checkRequest(q, req.ClientId, q.Get("scope"), q.Get("timestamp"), req.State, req.RedirectURI)
+	if e == nil && q.Get("response_type") != "code" {
+		e = &errMissingParam
+	}
+	if e != nil {
+		redirect(w, r, req.RedirectURI, url.Values{
+			"error": {e.error}, "error_description": {e.description}, "state": {req.State},
+		})
+		return
+	}
+
+	decision := s.decision(req)
+	switch {
+	case decision.Deny:
+		redirect(w, r, req.RedirectURI, url.Values{
+			"error": {errDenied.error}, "error_description": {errDenied.description}, "state": {req.State},
+		})
+		return
+	case decision.Error != "":
+		redirect(w, r, req.RedirectURI, url.Values{
+			"error": {decision.Error}, "error_description": {decision.ErrorDescription}, "state": {req.State},
+		})
+		return
+	}
+
+	oid := decision.OID
+	if oid == "" {
+		oid = DefaultOID
+	}
+	code, err := s.jwt(map[string]any{
+		"client_id":       req.ClientId,
+		"urn:esia:sbj_id": json.Number(oid),
+		"iat":             s.now().Unix(),
+		"exp":             s.now().Add(CodeTTL).Unix(),
+	})
+	if err != nil {
+		s.writeError(w, esiaError{"server_error", "ESIA-007007: " + err.Error()}, req.State)
+		return
+	}
+	s.codes[code] = authCode{
+		clientId:    req.ClientId,
+		scope:       q.Get("scope"),
+		redirectURI: req.RedirectURI,
+		oid:         oid,
+		expires:     s.now().Add(CodeTTL),
+	}
+	redirect(w, r, req.RedirectURI, url.Values{"code": {code}, "state": {req.State}})
+}
+
+// token - POST /aas/oauth2/v3/te
+//
+// Подробнее см "Методические рекомендации по использованию ЕСИА",
+// раздел "Получение маркера доступа в обмен на авторизационный код (v3/te)".
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, errMissingParam, "")
+		return
+	}
+	form := r.PostForm
+	clientId, scope, state, redirectURI := form.Get("client_id"), form.Get("scope"), form.Get("state"), form.Get("redirect_uri")
+
+	if clientId == "" || redirectURI == "" {
+		s.writeError(w, errMissingParam, state)
+		return
+	}
+	if e := s.checkClient(clientId, redirectURI); e != nil {
+		s.writeError(w, *e, state)
+		return
+	}
+
+	var oid string
+	var granted aas.Scopes
+	switch form.Get("grant_type") {
+	case "authorization_code":
+		code := form.Get("code")
+		if e := s.checkRequest(form, clientId, scope, form.Get("timestamp"), state, redirectURI, code); e != nil {
+			s.writeError(w, *e, state)
+			return
+		}
+		c, ok := s.codes[code]
+		delete(s.codes, code)
+		if !ok || c.clientId != clientId || c.redirectURI != redirectURI || s.now().After(c.expires) {
+			s.writeError(w, errGrant, state)
+			return
+		}
+		if c.scope != scope {
+			s.writeError(w, errScope, state)
+			return
+		}
+		oid, granted = c.oid, aas.ParseScopes(scope)
+		s.consents[clientId+"/"+oid] = granted
+
+	case "client_credentials":
+		if e := s.checkRequest(form, clientId, scope, form.Get("timestamp"), state, redirectURI); e != nil {
+			s.writeError(w, *e, state)
+			return
+		}
+		var ok bool
+		if oid, ok = strings.CutPrefix(scope, "prm_chg?oid="); !ok || oid == "" {
+			s.writeError(w, errScope, state)
+			return
+		}
+		if granted, ok = s.consents[clientId+"/"+oid]; !ok {
+			s.writeError(w, errNoConsent, state)
+			return
+		}
+
+	default:
+		s.writeError(w, errGrantType, state)
+		return
+	}
+
+	accessToken, idToken, err := s.issue(clientId, oid, granted)
+	if err != nil {
+		s.writeError(w, esiaError{"server_error", "ESIA-007007: " + err.Error()}, state)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	_ = json.NewEncoder(w).Encode(aas.TokenExchangeResponse{
+		AccessToken: accessToken,
+		IdToken:     idToken,
+		State:       state,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.tokenTTL.Seconds()),
+	})
+}
+
+// checkClient - проверяет мнемонику ИС и redirect_uri.
+func (s *Server) checkClient(clientId, redirectURI string) *esiaError {
+	if len(s.clients) == 0 {
+		return nil
+	}
+	uris, ok := s.clients[clientId]
+	if !ok {
+		return &errClient
+	}
+	if _, ok = uris[redirectURI]; !ok && len(uris) > 0 {
+		return &errRedirectURI
+	}
+	return nil
+}
+
+// checkRequest - проверяет обязательные параметры, timestamp и подпись client_secret.
+// Подписанные данные - конкатенация значений signed.
+func (s *Server) checkRequest(params url.Values, clientId string, signed ...string) *esiaError {
+	for _, name := range []string{"client_secret", "scope", "timestamp", "state", "client_certificate_hash"} {
+		if params.Get(name) == "" {
+			return &errMissingParam
+		}
+	}
+
+	ts, err := time.Parse(tsLayout, params.Get("timestamp"))
+	if err != nil {
+		return &errTimestamp
+	}
+	if d := s.now().Sub(ts); d > s.window || d < -s.window {
+		return &errTimestamp
+	}
+
+	secret, err := base64.URLEncoding.DecodeString(params.Get("client_secret"))
+	if err != nil {
+		return &errSecret
+	}
+	data := []byte(clientId + strings.Join(signed, ""))
+	if err = s.verifier.Verify(data, secret, params.Get("client_certificate_hash")); err != nil {
+		return &errSecret
+	}
+	return nil
+}
+
+// issue - выпускает маркер доступа и маркер идентификации.
+func (s *Server) issue(clientId, oid string, granted aas.Scopes) (string, string, error) {
+	now := s.now()
+	sid, err := utils.GUID()
+	if err != nil {
+		return "", "", err
+	}
+
+	// скоупы в маркере доступа указываются с OID пользователя, кроме openid
+	scopes := make([]string, 0, len(granted))
+	for _, scope := range granted {
+		if scope == aas.ScopeOpenID {
+			scopes = append(scopes, string(scope))
+			continue
+		}
+		scopes = append(scopes, string(scope)+"?oid="+oid)
+	}
+
+	accessToken, err := s.jwt(aas.TokenClaims{
+		Issuer:    s.URL + "/",
+		ClientId:  clientId,
+		Scope:     strings.Join(scopes, " "),
+		SbjId:     json.Number(oid),
+		SessionId: sid,
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(s.tokenTTL).Unix(),
+		IssuedAt:  now.Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	idToken, err := s.jwt(map[string]any{
+		"iss":           s.URL + "/",
+		"sub":           json.Number(oid),
+		"aud":           clientId,
+		"auth_time":     now.Unix(),
+		"iat":           now.Unix(),
+		"exp":           now.Add(s.tokenTTL).Unix(),
+		"amr":           "DS",
+		"urn:esia:sid":  sid,
+		"urn:esia:sbj":  map[string]any{"urn:esia:sbj:typ": "P", "urn:esia:sbj:oid": json.Number(oid)},
+		"urn:esia:amd":  "PWD",
+		"urn:esia:sbjt": "P",
+	})
+	if err != nil {
+		return "", "", err
+	}
+	s.tokens[accessToken] = now.Add(s.tokenTTL)
+	return accessToken, idToken, nil
+}
+
+// jwt - формирует маркер в формате JWT с заголовком ЕСИА и случайной подписью.
+func (s *Server) jwt(claims any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "GOST3410_2012_256", "typ": "JWT", "sbt": "access", "ver": "1"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	sig, err := utils.GUID()
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(header) + "." + enc.EncodeToString(payload) + "." + enc.EncodeToString([]byte(sig)), nil
+}
+
+// writeError - ответ с ошибкой ЕСИА.
+func (s *Server) writeError(w http.ResponseWriter, e esiaError, state string) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(aas.ErrorResponse{Error: e.error, ErrorDescription: e.description, State: state})
+}
+
+// redirect - перенаправление на redirectURI с параметрами params.
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	sep := "?"
+	if strings.Contains(redirectURI, "?") {
+		sep = "&"
+	}
+	http.Redirect(w, r, redirectURI+sep+params.Encode(), http.StatusFound)
+}
@@ -0,0 +1,272 @@
+package esiatest
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/esia/aas"
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+const (
+	testClientId    = "TEST_IS"
+	testRedirectURI = "http://localhost/callback"
+)
+
+type suiteServer struct {
+	suite.Suite
+	server *Server
+	client *aas.Client
+	scopes aas.Scopes
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(suiteServer))
+}
+
+func (suite *suiteServer) SetupTest() {
+	suite.server = NewServer().WithClient(testClientId, testRedirectURI)
+	suite.client = aas.NewClient(suite.server.URL, testClientId, signature.NewNop(TestSignature, TestCertHash))
+	suite.scopes = aas.NewScopes(aas.ScopeOpenID, aas.ScopeAPIOrder)
+}
+
+func (suite *suiteServer) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *suiteServer) SetupSubTest() {
+	suite.TearDownTest()
+	suite.SetupTest()
+}
+
+// login - вход пользователя и обмен кода авторизации на маркер доступа.
+func (suite *suiteServer) login() *aas.TokenExchangeResponse {
+	authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+	suite.Require().NoError(err)
+	query, err := suite.server.Authorize(authURI)
+	suite.Require().NoError(err)
+	code, _, err := suite.client.ParseCallback(query)
+	suite.Require().NoError(err)
+	res, err := suite.client.TokenExchange(code, suite.scopes, testRedirectURI)
+	suite.Require().NoError(err)
+	return res
+}
+
+func (suite *suiteServer) TestLogin() {
+	suite.Run("success", func() {
+		res := suite.login()
+		suite.Equal("Bearer", res.TokenType)
+		suite.Equal(3600, res.ExpiresIn)
+		suite.True(suite.server.Valid(res.AccessToken))
+		suite.False(suite.server.Valid(res.IdToken))
+
+		claims, err := aas.ParseTokenClaims(res.AccessToken)
+		suite.Require().NoError(err)
+		suite.Equal(DefaultOID, claims.OID())
+		suite.Equal(testClientId, claims.ClientId)
+		suite.Equal(suite.server.URL+"/", claims.Issuer)
+		suite.Equal("http://lk.gosuslugi.ru/api-order?oid="+DefaultOID+" openid", claims.Scope)
+		suite.NotEmpty(claims.SessionId)
+		suite.Equal(time.Hour, claims.Expiry().Sub(time.Unix(claims.IssuedAt, 0)))
+	})
+
+	suite.Run("custom OID and token TTL", func() {
+		suite.server.
+			WithDecision(func(AuthRequest) Decision { return Decision{OID: "1000000001"} }).
+			WithTokenTTL(10 * time.Minute)
+		res := suite.login()
+		suite.Equal(600, res.ExpiresIn)
+		claims, err := aas.ParseTokenClaims(res.AccessToken)
+		suite.Require().NoError(err)
+		suite.Equal("1000000001", claims.OID())
+	})
+
+	suite.Run("decision receives request", func() {
+		var req AuthRequest
+		suite.server.WithDecision(func(r AuthRequest) Decision {
+			req = r
+			return Decision{}
+		})
+		suite.login()
+		suite.Equal(testClientId, req.ClientId)
+		suite.Equal(testRedirectURI, req.RedirectURI)
+		suite.True(req.Scope.Has(aas.ScopeAPIOrder))
+		suite.Equal("online", req.AccessType)
+		suite.NotEmpty(req.State)
+	})
+}
+
+func (suite *suiteServer) TestAuthorizeErrors() {
+	suite.Run("denied", func() {
+		suite.server.WithDecision(func(AuthRequest) Decision { return Decision{Deny: true} })
+		authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		query, err := suite.server.Authorize(authURI)
+		suite.Require().NoError(err)
+		_, state, err := suite.client.ParseCallback(query)
+		suite.ErrorIs(err, aas.ErrESIA_007004)
+		suite.NotEmpty(state)
+	})
+
+	suite.Run("custom error", func() {
+		suite.server.WithDecision(func(AuthRequest) Decision {
+			return Decision{Error: "invalid_scope", ErrorDescription: "ESIA-007006: invalid scope"}
+		})
+		authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		query, err := suite.server.Authorize(authURI)
+		suite.Require().NoError(err)
+		_, _, err = suite.client.ParseCallback(query)
+		suite.ErrorIs(err, aas.ErrESIA_007006)
+	})
+
+	suite.Run("bad secret", func() {
+		client := aas.NewClient(suite.server.URL, testClientId, signature.NewNop("wrong", TestCertHash))
+		authURI, err := client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		query, err := suite.server.Authorize(authURI)
+		suite.Require().NoError(err)
+		_, _, err = client.ParseCallback(query)
+		suite.ErrorIs(err, aas.ErrESIA_007053)
+	})
+
+	suite.Run("bad timestamp", func() {
+		suite.server.WithClock(func() time.Time { return time.Now().Add(10 * time.Minute) })
+		authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		query, err := suite.server.Authorize(authURI)
+		suite.Require().NoError(err)
+		_, _, err = suite.client.ParseCallback(query)
+		suite.ErrorIs(err, aas.ErrESIA_007015)
+	})
+
+	suite.Run("timestamp window", func() {
+		suite.server.
+			WithClock(func() time.Time { return time.Now().Add(10 * time.Minute) }).
+			WithTimestampWindow(15 * time.Minute)
+		suite.login()
+	})
+
+	suite.Run("redirect_uri not allowed", func() {
+		authURI, err := suite.client.AuthURI(suite.scopes, "http://evil/callback", nil)
+		suite.Require().NoError(err)
+		_, err = suite.server.Authorize(authURI)
+		suite.ErrorContains(err, "ESIA-007023")
+	})
+
+	suite.Run("unknown client", func() {
+		client := aas.NewClient(suite.server.URL, "OTHER_IS", signature.NewNop(TestSignature, TestCertHash))
+		authURI, err := client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		_, err = suite.server.Authorize(authURI)
+		suite.ErrorContains(err, "ESIA-007002")
+	})
+
+	suite.Run("missing parameter", func() {
+		authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		u, err := url.Parse(authURI)
+		suite.Require().NoError(err)
+		q := u.Query()
+		q.Del("timestamp")
+		u.RawQuery = q.Encode()
+		query, err := suite.server.Authorize(u.String())
+		suite.Require().NoError(err)
+		_, _, err = suite.client.ParseCallback(query)
+		suite.ErrorIs(err, aas.ErrESIA_007014)
+	})
+}
+
+func (suite *suiteServer) TestTokenExchangeErrors() {
+	authorize := func() string {
+		authURI, err := suite.client.AuthURI(suite.scopes, testRedirectURI, nil)
+		suite.Require().NoError(err)
+		query, err := suite.server.Authorize(authURI)
+		suite.Require().NoError(err)
+		code, _, err := suite.client.ParseCallback(query)
+		suite.Require().NoError(err)
+		return code
+	}
+
+	suite.Run("code is single-use", func() {
+		code := authorize()
+		_, err := suite.client.TokenExchange(code, suite.scopes, testRedirectURI)
+		suite.Require().NoError(err)
+		_, err = suite.client.TokenExchange(code, suite.scopes, testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007011)
+	})
+
+	suite.Run("unknown code", func() {
+		_, err := suite.client.TokenExchange("unknown", suite.scopes, testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007011)
+	})
+
+	suite.Run("expired code", func() {
+		code := authorize()
+		suite.server.WithClock(func() time.Time { return time.Now().Add(CodeTTL + time.Second) }).
+			WithTimestampWindow(time.Hour)
+		_, err := suite.client.TokenExchange(code, suite.scopes, testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007011)
+	})
+
+	suite.Run("scope mismatch", func() {
+		code := authorize()
+		_, err := suite.client.TokenExchange(code, aas.NewScopes(aas.ScopeOpenID), testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007006)
+	})
+
+	suite.Run("bad secret", func() {
+		code := authorize()
+		suite.server.WithVerifier(VerifierFunc(func([]byte, []byte, string) error { return ErrSecret }))
+		_, err := suite.client.TokenExchange(code, suite.scopes, testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007053)
+	})
+
+	suite.Run("unsupported grant type", func() {
+		res, err := http.PostForm(suite.server.URL+aas.TokenEndpoint, url.Values{
+			"client_id":    {testClientId},
+			"redirect_uri": {testRedirectURI},
+			"grant_type":   {"password"},
+		})
+		suite.Require().NoError(err)
+		_ = res.Body.Close()
+		suite.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func (suite *suiteServer) TestTokenUpdate() {
+	suite.Run("success", func() {
+		suite.login()
+		res, err := suite.client.TokenUpdate(DefaultOID, testRedirectURI)
+		suite.Require().NoError(err)
+		suite.True(suite.server.Valid(res.AccessToken))
+		claims, err := aas.ParseTokenClaims(res.AccessToken)
+		suite.Require().NoError(err)
+		suite.Equal(DefaultOID, claims.OID())
+		suite.Contains(claims.Scope, string(aas.ScopeAPIOrder))
+	})
+
+	suite.Run("no consent", func() {
+		_, err := suite.client.TokenUpdate("1000000002", testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007019)
+	})
+
+	suite.Run("revoked", func() {
+		suite.login()
+		suite.server.Revoke(testClientId, DefaultOID)
+		_, err := suite.client.TokenUpdate(DefaultOID, testRedirectURI)
+		suite.ErrorIs(err, aas.ErrESIA_007019)
+	})
+}
+
+func (suite *suiteServer) TestValid() {
+	res := suite.login()
+	suite.True(suite.server.Valid(res.AccessToken))
+	suite.server.WithClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	suite.False(suite.server.Valid(res.AccessToken))
+	suite.False(suite.server.Valid("unknown"))
+}
@@ -0,0 +1,38 @@
+package esiatest
+
+// SecretVerifier - проверка подписи client_secret запросов к ЕСИА.
+type SecretVerifier interface {
+	// Verify - проверяет подпись secret данных data и хэш сертификата certHash.
+	Verify(data, secret []byte, certHash string) error
+}
+
+// NopVerifier - проверка подписи тестового провайдера [signature.Nop]:
+// подпись и хэш сертификата должны совпадать с фиксированными значениями.
+//
+// [signature.Nop]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/signature#Nop
+type NopVerifier struct {
+	signature string
+	certHash  string
+}
+
+// NewNopVerifier - конструктор [NopVerifier].
+// Значения signature и certHash должны совпадать с параметрами конструктора signature.NewNop.
+func NewNopVerifier(signature, certHash string) *NopVerifier {
+	return &NopVerifier{signature: signature, certHash: certHash}
+}
+
+// Verify - реализация [SecretVerifier].
+func (v *NopVerifier) Verify(_, secret []byte, certHash string) error {
+	if string(secret) != v.signature || certHash != v.certHash {
+		return ErrSecret
+	}
+	return nil
+}
+
+// VerifierFunc - адаптер функции к интерфейсу [SecretVerifier].
+type VerifierFunc func(data, secret []byte, certHash string) error
+
+// Verify - реализация [SecretVerifier].
+func (f VerifierFunc) Verify(data, secret []byte, certHash string) error {
+	return f(data, secret, certHash)
+}
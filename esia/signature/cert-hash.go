@@ -0,0 +1,194 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+// CertHash - возвращает хэш сертификата в формате, который ожидает ЕСИА
+// в параметре client_certificate_hash: ГОСТ Р 34.11-2012 (256 бит) от сертификата в DER,
+// в шестнадцатеричном виде прописными буквами.
+// Результат совпадает с выводом утилиты КриптоПро CSP:
+//
+//	cpverify -mk <path/to/cert.cer> -alg GR3411_2012_256
+//
+// Сертификат cert принимается в DER или PEM ("-----BEGIN CERTIFICATE-----").
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] и описания ошибки.
+func CertHash(cert []byte) (string, error) {
+	der, err := certDER(cert)
+	if err != nil {
+		return "", err
+	}
+	return certHashDER(der), nil
+}
+
+// CertHashFromFile - возвращает хэш сертификата из файла certPath, см [CertHash].
+//
+// В случае ошибки возвращает цепочку из [ErrCertRead] или [ErrCertParse] и описания ошибки.
+func CertHashFromFile(certPath string) (string, error) {
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCertRead, err)
+	}
+	return CertHash(cert)
+}
+
+// WithCert - возвращает провайдер подписи provider, у которого хэш сертификата
+// рассчитывается по сертификату cert (DER или PEM), см [CertHash].
+// Позволяет не хранить хэш сертификата отдельно от сертификата
+// и избежать ошибки ЕСИА [aas.ErrESIA_007002] из-за их несоответствия.
+//
+// Для провайдера [GOST] дополнительно проверяется, что открытый ключ сертификата
+// соответствует закрытому ключу провайдера.
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] или [ErrCertMismatch] и описания ошибки.
+//
+// [aas.ErrESIA_007002]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/aas#ErrESIA_007002
+func WithCert(provider Provider, cert []byte) (Provider, error) {
+	der, err := certDER(cert)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := provider.(*GOST); ok {
+		if err = p.checkCert(der); err != nil {
+			return nil, err
+		}
+	}
+	return &certProvider{Provider: provider, certHash: certHashDER(der)}, nil
+}
+
+// WithCertFile - то же, что [WithCert], но сертификат читается из файла certPath.
+//
+// В случае ошибки возвращает цепочку из [ErrCertRead], [ErrCertParse] или [ErrCertMismatch] и описания ошибки.
+func WithCertFile(provider Provider, certPath string) (Provider, error) {
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertRead, err)
+	}
+	return WithCert(provider, cert)
+}
+
+// certProvider - провайдер подписи с хэшем сертификата, рассчитанным по сертификату.
+type certProvider struct {
+	Provider
+	certHash string
+}
+
+func (p *certProvider) CertHash() string {
+	return p.certHash
+}
+
+// certDER - возвращает сертификат в DER из DER или PEM.
+func certDER(cert []byte) ([]byte, error) {
+	der := cert
+	if block, _ := pem.Decode(cert); block != nil {
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("%w: неподдерживаемый тип PEM-блока '%s'", ErrCertParse, block.Type)
+		}
+		der = block.Bytes
+	}
+	if !isCertificate(der) {
+		return nil, fmt.Errorf("%w: некорректная структура сертификата X.509", ErrCertParse)
+	}
+	return der, nil
+}
+
+// certHashDER - возвращает хэш сертификата der в формате ЕСИА.
+func certHashDER(der []byte) string {
+	sum := gost3411.Sum256(der)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// isCertificate - проверяет, что der имеет структуру сертификата X.509:
+// SEQUENCE { SEQUENCE, SEQUENCE, BIT STRING }.
+func isCertificate(der []byte) bool {
+	var cert struct {
+		TBS       asn1.RawValue
+		Algorithm asn1.RawValue
+		Signature asn1.BitString
+	}
+	if unmarshalAll(der, &cert) != nil {
+		return false
+	}
+	return cert.TBS.Tag == asn1.TagSequence && cert.TBS.IsCompound &&
+		cert.Algorithm.Tag == asn1.TagSequence && cert.Algorithm.IsCompound
+}
+
+// tbsCertificate - поля сертификата X.509 (RFC 5280), используемые пакетом.
+type tbsCertificate struct {
+	Version   int `asn1:"optional,explicit,default:0,tag:0"`
+	Serial    asn1.RawValue
+	Algorithm asn1.RawValue
+	Issuer    asn1.RawValue
+	Validity  struct {
+		NotBefore time.Time
+		NotAfter  time.Time
+	}
+	Subject   asn1.RawValue
+	PublicKey struct {
+		Algorithm struct {
+			Algorithm asn1.ObjectIdentifier
+			Params    asn1.RawValue `asn1:"optional"`
+		}
+		Key asn1.BitString
+	}
+	IssuerUID  asn1.BitString  `asn1:"optional,tag:1"`
+	SubjectUID asn1.BitString  `asn1:"optional,tag:2"`
+	Extensions []certExtension `asn1:"optional,explicit,tag:3"`
+}
+
+// certExtension - расширение сертификата X.509.
+type certExtension struct {
+	ID       asn1.ObjectIdentifier
+	Critical bool `asn1:"optional"`
+	Value    []byte
+}
+
+// parseTBS - разбирает поля сертификата der.
+func parseTBS(der []byte) (*tbsCertificate, error) {
+	var cert struct {
+		TBS tbsCertificate
+	}
+	if _, err := asn1.Unmarshal(der, &cert); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	return &cert.TBS, nil
+}
+
+// certPublicKey - возвращает открытый ключ ГОСТ Р 34.10 из сертификата der в виде LE(x) || LE(y).
+func certPublicKey(der []byte) ([]byte, error) {
+	tbs, err := parseTBS(der)
+	if err != nil {
+		return nil, err
+	}
+	alg := tbs.PublicKey.Algorithm.Algorithm
+	if !isGOSTKeyAlgorithm(alg) {
+		return nil, fmt.Errorf("%w: неподдерживаемый алгоритм открытого ключа '%s'", ErrCertMismatch, alg)
+	}
+	var raw []byte
+	if err = unmarshalAll(tbs.PublicKey.Key.Bytes, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	return raw, nil
+}
+
+// checkCert - проверяет, что открытый ключ сертификата der соответствует закрытому ключу.
+func (p *GOST) checkCert(der []byte) error {
+	raw, err := certPublicKey(der)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(raw, p.key.PublicKey().Raw()) {
+		return fmt.Errorf("%w: открытый ключ сертификата не соответствует закрытому ключу", ErrCertMismatch)
+	}
+	return nil
+}
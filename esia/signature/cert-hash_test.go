@@ -0,0 +1,183 @@
+package signature
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+)
+
+type suiteCertHash struct {
+	suite.Suite
+}
+
+func TestCertHash(t *testing.T) {
+	suite.Run(t, new(suiteCertHash))
+}
+
+func (suite *suiteCertHash) TestCertHash() {
+	der, _ := hex.DecodeString(testContainerCert)
+
+	suite.Run("der", func() {
+		hash, err := CertHash(der)
+		suite.Require().NoError(err)
+		suite.Equal(testContainerCertHash, hash)
+	})
+
+	suite.Run("pem", func() {
+		hash, err := CertHash(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+		suite.Require().NoError(err)
+		suite.Equal(testContainerCertHash, hash)
+	})
+
+	suite.Run("file", func() {
+		path := filepath.Join(suite.T().TempDir(), "cert.cer")
+		suite.Require().NoError(os.WriteFile(path, der, 0600))
+		hash, err := CertHashFromFile(path)
+		suite.Require().NoError(err)
+		suite.Equal(testContainerCertHash, hash)
+
+		_, err = CertHashFromFile(filepath.Join(suite.T().TempDir(), "none.cer"))
+		suite.ErrorIs(err, ErrCertRead)
+	})
+
+	suite.Run("error", func() {
+		for _, cert := range [][]byte{
+			nil,
+			[]byte("test"),
+			[]byte(testGOSTKeyPEM),
+			{0x30, 0x00},
+		} {
+			hash, err := CertHash(cert)
+			suite.ErrorIs(err, ErrCertParse)
+			suite.Empty(hash)
+		}
+	})
+}
+
+func (suite *suiteCertHash) TestWithCert() {
+	signer, err := NewGOST([]byte(testGOSTKeyPEM), "")
+	suite.Require().NoError(err)
+
+	suite.Run("gost", func() {
+		cert := suite.cert(oidGOST2012256, testGOSTPublicKey)
+		provider, err := WithCert(signer, cert)
+		suite.Require().NoError(err)
+		hash, _ := CertHash(cert)
+		suite.Equal(hash, provider.CertHash())
+
+		sig, err := provider.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Len(sig, 64)
+	})
+
+	suite.Run("other provider", func() {
+		der, _ := hex.DecodeString(testContainerCert)
+		provider, err := WithCert(NewNop("signature", "wrong"), der)
+		suite.Require().NoError(err)
+		suite.Equal(testContainerCertHash, provider.CertHash())
+		sig, err := provider.Sign(nil)
+		suite.NoError(err)
+		suite.Equal("signature", string(sig))
+	})
+
+	suite.Run("file", func() {
+		path := filepath.Join(suite.T().TempDir(), "cert.pem")
+		cert := suite.cert(oidGOST2012256, testGOSTPublicKey)
+		suite.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600))
+		provider, err := WithCertFile(signer, path)
+		suite.Require().NoError(err)
+		hash, _ := CertHash(cert)
+		suite.Equal(hash, provider.CertHash())
+
+		_, err = WithCertFile(signer, filepath.Join(suite.T().TempDir(), "none.pem"))
+		suite.ErrorIs(err, ErrCertRead)
+	})
+
+	suite.Run("error other key", func() {
+		pub := "01" + testGOSTPublicKey[2:]
+		provider, err := WithCert(signer, suite.cert(oidGOST2012256, pub))
+		suite.ErrorIs(err, ErrCertMismatch)
+		suite.Nil(provider)
+	})
+
+	suite.Run("error other algorithm", func() {
+		provider, err := WithCert(signer, suite.cert(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}, testGOSTPublicKey))
+		suite.ErrorIs(err, ErrCertMismatch)
+		suite.Nil(provider)
+	})
+
+	suite.Run("error parse", func() {
+		der, _ := hex.DecodeString(testContainerCert)
+		provider, err := WithCert(signer, der)
+		suite.ErrorIs(err, ErrCertParse)
+		suite.Nil(provider)
+	})
+}
+
+// cert - возвращает тестовый сертификат с открытым ключом pubHex алгоритма alg.
+func (suite *suiteCertHash) cert(alg asn1.ObjectIdentifier, pubHex string) []byte {
+	return testCertificate(suite.T(), alg, pubHex, testNotBefore, testNotAfter)
+}
+
+// Срок действия тестового сертификата.
+var (
+	testNotBefore = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNotAfter  = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// testCertificate - возвращает сертификат X.509 в DER с открытым ключом pubHex (LE(x) || LE(y)) алгоритма alg
+// и сроком действия [notBefore, notAfter]. Подпись сертификата не формируется.
+func testCertificate(t *testing.T, alg asn1.ObjectIdentifier, pubHex string, notBefore, notAfter time.Time) []byte {
+	type algorithm struct {
+		Algorithm asn1.ObjectIdentifier
+		Params    gostKeyParams `asn1:"optional"`
+	}
+	pub, _ := hex.DecodeString(pubHex)
+	key, err := asn1.Marshal(pub)
+	require.NoError(t, err)
+	name := []byte{0x30, 0x00}
+
+	sigAlg := algorithm{Algorithm: asn1.ObjectIdentifier{1, 2, 643, 7, 1, 1, 3, 2}}
+	cert := struct {
+		TBS struct {
+			Version   int `asn1:"explicit,tag:0"`
+			Serial    int
+			Algorithm algorithm
+			Issuer    asn1.RawValue
+			Validity  struct{ NotBefore, NotAfter time.Time }
+			Subject   asn1.RawValue
+			PublicKey struct {
+				Algorithm algorithm
+				Key       asn1.BitString
+			}
+		}
+		Algorithm algorithm
+		Signature asn1.BitString
+	}{}
+	cert.TBS.Version = 2
+	cert.TBS.Serial = 1
+	cert.TBS.Algorithm = sigAlg
+	cert.TBS.Issuer = asn1.RawValue{FullBytes: name}
+	cert.TBS.Validity.NotBefore, cert.TBS.Validity.NotAfter = notBefore, notAfter
+	cert.TBS.Subject = asn1.RawValue{FullBytes: name}
+	cert.TBS.PublicKey.Algorithm = algorithm{
+		Algorithm: alg,
+		Params:    gostKeyParams{PublicKeyParamSet: gost3410.OIDCryptoProA},
+	}
+	cert.TBS.PublicKey.Key = asn1.BitString{Bytes: key, BitLength: len(key) * 8}
+	cert.Algorithm = sigAlg
+	cert.Signature = asn1.BitString{Bytes: make([]byte, 64), BitLength: 512}
+
+	der, err := asn1.Marshal(cert)
+	require.NoError(t, err)
+	return der
+}
@@ -0,0 +1,217 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+var (
+	oidCMSData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidCMSSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidCMSContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidCMSMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidCMSSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidGOST3411256      = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 1, 2, 2}
+)
+
+// CMS - формирование отсоединенной электронной подписи файлов в формате CMS (PKCS#7)
+// по алгоритмам ГОСТ Р 34.10-2012 и ГОСТ Р 34.11-2012 (256 бит).
+// Используется для подписания файлов вложений к заявлению усиленной квалифицированной
+// электронной подписью (УКЭП), см [apipgu.NewSignedArchive].
+//
+// Подпись содержит сертификат подписанта и подписанные атрибуты contentType, signingTime и messageDigest.
+// Непосредственно подпись выполняет провайдер provider: он должен подписывать данные
+// по ГОСТ Р 34.10-2012 (256 бит) с хэш-функцией ГОСТ Р 34.11-2012 (256 бит)
+// и возвращать подпись в виде BE(s) || BE(r), как [GOST], [LocalOpenSSL] и [Remote].
+//
+// [apipgu.NewSignedArchive]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#NewSignedArchive
+type CMS struct {
+	provider Provider
+	cert     []byte
+	tbs      *tbsCertificate
+}
+
+// NewCMS - конструктор [CMS].
+// Параметры:
+//   - provider - провайдер подписи
+//   - cert - сертификат подписанта в DER или PEM с открытым ключом ГОСТ Р 34.10-2012 (256 бит)
+//
+// Для провайдера [GOST] дополнительно проверяется, что открытый ключ сертификата
+// соответствует закрытому ключу провайдера.
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] или [ErrCertMismatch] и описания ошибки.
+func NewCMS(provider Provider, cert []byte) (*CMS, error) {
+	der, err := certDER(cert)
+	if err != nil {
+		return nil, err
+	}
+	tbs, err := parseTBS(der)
+	if err != nil {
+		return nil, err
+	}
+	if alg := tbs.PublicKey.Algorithm.Algorithm; !alg.Equal(oidGOST2012256) {
+		return nil, fmt.Errorf(
+			"%w: алгоритм открытого ключа '%s' не поддерживается для подписи CMS", ErrCertMismatch, alg,
+		)
+	}
+	if p, ok := provider.(*GOST); ok {
+		if err = p.checkCert(der); err != nil {
+			return nil, err
+		}
+	}
+	return &CMS{provider: provider, cert: der, tbs: tbs}, nil
+}
+
+// NewCMSFromFile - то же, что [NewCMS], но сертификат читается из файла certPath.
+//
+// В случае ошибки возвращает цепочку из [ErrCertRead], [ErrCertParse] или [ErrCertMismatch] и описания ошибки.
+func NewCMSFromFile(provider Provider, certPath string) (*CMS, error) {
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertRead, err)
+	}
+	return NewCMS(provider, cert)
+}
+
+// Certificate - возвращает сертификат подписанта в DER.
+func (c *CMS) Certificate() []byte {
+	return c.cert
+}
+
+// SignDetached - возвращает отсоединенную подпись данных в формате CMS (DER).
+//
+// В случае ошибки возвращает цепочку из [ErrCMSSign] и описания ошибки.
+func (c *CMS) SignDetached(data []byte) ([]byte, error) {
+	digest := gost3411.Sum256(data)
+	attrs, err := c.signedAttrs(digest[:], nowFunc())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSSign, err)
+	}
+
+	// Подписываются атрибуты в DER с тегом SET (RFC 5652, п. 5.4)
+	sig, err := c.provider.Sign(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSSign, err)
+	}
+
+	// В SignerInfo атрибуты передаются с тегом [0] IMPLICIT
+	signedAttrs := append([]byte{0xa0}, attrs[1:]...)
+	digestAlg := cmsAlgorithm{Algorithm: oidGOST3411256}
+	sid, err := asn1.Marshal(cmsIssuerAndSerial{
+		Issuer: asn1.RawValue{FullBytes: c.tbs.Issuer.FullBytes},
+		Serial: asn1.RawValue{FullBytes: c.tbs.Serial.FullBytes},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSSign, err)
+	}
+
+	signedData := cmsSignedData{
+		Version:          1,
+		DigestAlgorithms: []cmsAlgorithm{digestAlg},
+		ContentInfo:      cmsEncapContentInfo{ContentType: oidCMSData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: c.cert},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    digestAlg,
+			SignedAttrs:        asn1.RawValue{FullBytes: signedAttrs},
+			SignatureAlgorithm: cmsAlgorithm{Algorithm: oidGOST2012256},
+			Signature:          sig,
+		}},
+	}
+	content, err := asn1.Marshal(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSSign, err)
+	}
+	info, err := asn1.Marshal(cmsContentInfo{
+		ContentType: oidCMSSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSSign, err)
+	}
+	return info, nil
+}
+
+// signedAttrs - возвращает подписываемые атрибуты в DER с тегом SET.
+func (c *CMS) signedAttrs(digest []byte, signingTime time.Time) ([]byte, error) {
+	values := []struct {
+		oid   asn1.ObjectIdentifier
+		value any
+	}{
+		{oidCMSContentType, oidCMSData},
+		{oidCMSSigningTime, signingTime.UTC()},
+		{oidCMSMessageDigest, digest},
+	}
+	attrs := make([][]byte, 0, len(values))
+	for _, v := range values {
+		value, err := asn1.Marshal(v.value)
+		if err != nil {
+			return nil, err
+		}
+		attr, err := asn1.Marshal(cmsAttribute{
+			Type:   v.oid,
+			Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value},
+		})
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+
+	// Элементы SET OF в DER упорядочиваются по возрастанию кодировки (X.690, п. 11.6)
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+	return asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassUniversal,
+		Tag:        asn1.TagSet,
+		IsCompound: true,
+		Bytes:      bytes.Join(attrs, nil),
+	})
+}
+
+// Структуры CMS (RFC 5652), используемые пакетом.
+type (
+	cmsContentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}
+	cmsSignedData struct {
+		Version          int
+		DigestAlgorithms []cmsAlgorithm `asn1:"set"`
+		ContentInfo      cmsEncapContentInfo
+		Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+		CRLs             asn1.RawValue   `asn1:"optional,tag:1"`
+		SignerInfos      []cmsSignerInfo `asn1:"set"`
+	}
+	cmsEncapContentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	}
+	cmsSignerInfo struct {
+		Version            int
+		SID                asn1.RawValue // IssuerAndSerialNumber или [0] SubjectKeyIdentifier
+		DigestAlgorithm    cmsAlgorithm
+		SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+		SignatureAlgorithm cmsAlgorithm
+		Signature          []byte
+		UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+	}
+	cmsIssuerAndSerial struct {
+		Issuer asn1.RawValue
+		Serial asn1.RawValue
+	}
+	cmsAlgorithm struct {
+		Algorithm asn1.ObjectIdentifier
+		Params    asn1.RawValue `asn1:"optional"`
+	}
+	cmsAttribute struct {
+		Type   asn1.ObjectIdentifier
+		Values asn1.RawValue
+	}
+)
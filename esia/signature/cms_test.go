@@ -0,0 +1,144 @@
+package signature
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+type suiteCMS struct {
+	suite.Suite
+	signer *GOST
+	cert   []byte
+	now    time.Time
+}
+
+func TestCMS(t *testing.T) {
+	suite.Run(t, new(suiteCMS))
+}
+
+func (suite *suiteCMS) SetupTest() {
+	var err error
+	suite.signer, err = NewGOST([]byte(testGOSTKeyPEM), "")
+	suite.Require().NoError(err)
+	suite.cert = testCertificate(suite.T(), oidGOST2012256, testGOSTPublicKey, testNotBefore, testNotAfter)
+	suite.now = time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return suite.now }
+}
+
+func (suite *suiteCMS) TearDownTest() {
+	nowFunc = time.Now
+}
+
+func (suite *suiteCMS) TestSignDetached() {
+	c, err := NewCMS(suite.signer, suite.cert)
+	suite.Require().NoError(err)
+	suite.Equal(suite.cert, c.Certificate())
+
+	sig, err := c.SignDetached([]byte(testDataToSign))
+	suite.Require().NoError(err)
+
+	var info cmsContentInfo
+	suite.Require().NoError(unmarshalAll(sig, &info))
+	suite.True(info.ContentType.Equal(oidCMSSignedData))
+
+	var sd cmsSignedData
+	suite.Require().NoError(unmarshalAll(info.Content.Bytes, &sd))
+	suite.Equal(1, sd.Version)
+	suite.Require().Len(sd.DigestAlgorithms, 1)
+	suite.True(sd.DigestAlgorithms[0].Algorithm.Equal(oidGOST3411256))
+	suite.True(sd.ContentInfo.ContentType.Equal(oidCMSData))
+	suite.Empty(sd.ContentInfo.Content.Bytes, "отсоединенная подпись не содержит данных")
+	suite.Equal(suite.cert, sd.Certificates.Bytes)
+
+	suite.Require().Len(sd.SignerInfos, 1)
+	si := sd.SignerInfos[0]
+	var sid cmsIssuerAndSerial
+	suite.Require().NoError(unmarshalAll(si.SID.FullBytes, &sid))
+	suite.Equal([]byte{0x02, 0x01, 0x01}, sid.Serial.FullBytes)
+	suite.Equal([]byte{0x30, 0x00}, sid.Issuer.FullBytes)
+	suite.True(si.SignatureAlgorithm.Algorithm.Equal(oidGOST2012256))
+
+	// Подписанные атрибуты: [0] IMPLICIT в SignerInfo, SET при подписании
+	suite.Equal(byte(0xa0), si.SignedAttrs.FullBytes[0])
+	signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	var attrs []cmsAttribute
+	_, err = asn1.UnmarshalWithParams(signed, &attrs, "set")
+	suite.Require().NoError(err)
+	values := map[string][]byte{}
+	for _, attr := range attrs {
+		values[attr.Type.String()] = attr.Values.Bytes
+	}
+	suite.Require().Len(values, 3)
+
+	var contentType asn1.ObjectIdentifier
+	suite.Require().NoError(unmarshalAll(values[oidCMSContentType.String()], &contentType))
+	suite.True(contentType.Equal(oidCMSData))
+
+	var signingTime time.Time
+	suite.Require().NoError(unmarshalAll(values[oidCMSSigningTime.String()], &signingTime))
+	suite.True(suite.now.Equal(signingTime))
+
+	var messageDigest []byte
+	suite.Require().NoError(unmarshalAll(values[oidCMSMessageDigest.String()], &messageDigest))
+	digest := gost3411.Sum256([]byte(testDataToSign))
+	suite.Equal(digest[:], messageDigest)
+
+	// Подпись атрибутов
+	raw, _ := hex.DecodeString(testGOSTPublicKey)
+	pub, err := gost3410.NewPublicKeyRaw(gost3410.CurveCryptoProA, raw)
+	suite.Require().NoError(err)
+	digest = gost3411.Sum256(signed)
+	suite.NoError(pub.VerifyDigest(digest[:], si.Signature))
+}
+
+func (suite *suiteCMS) TestNewCMS() {
+	suite.Run("file", func() {
+		path := filepath.Join(suite.T().TempDir(), "cert.cer")
+		suite.Require().NoError(os.WriteFile(path, suite.cert, 0600))
+		c, err := NewCMSFromFile(suite.signer, path)
+		suite.Require().NoError(err)
+		suite.Equal(suite.cert, c.Certificate())
+
+		_, err = NewCMSFromFile(suite.signer, filepath.Join(suite.T().TempDir(), "none.cer"))
+		suite.ErrorIs(err, ErrCertRead)
+	})
+
+	suite.Run("error parse", func() {
+		c, err := NewCMS(suite.signer, []byte("test"))
+		suite.ErrorIs(err, ErrCertParse)
+		suite.Nil(c)
+	})
+
+	suite.Run("error other key", func() {
+		cert := testCertificate(suite.T(), oidGOST2012256, "01"+testGOSTPublicKey[2:], testNotBefore, testNotAfter)
+		c, err := NewCMS(suite.signer, cert)
+		suite.ErrorIs(err, ErrCertMismatch)
+		suite.Nil(c)
+	})
+
+	suite.Run("error other algorithm", func() {
+		cert := testCertificate(suite.T(), oidGOST2001, testGOSTPublicKey, testNotBefore, testNotAfter)
+		c, err := NewCMS(NewNop("signature", ""), cert)
+		suite.ErrorIs(err, ErrCertMismatch)
+		suite.Nil(c)
+	})
+
+	suite.Run("error sign", func() {
+		provider := newTestProvider("failed")
+		provider.err.Store(true)
+		c, err := NewCMS(provider, suite.cert)
+		suite.Require().NoError(err)
+		sig, err := c.SignDetached([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrCMSSign)
+		suite.Nil(sig)
+	})
+}
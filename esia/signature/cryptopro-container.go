@@ -0,0 +1,338 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/asn1"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost28147"
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+	"github.com/ofstudio/go-api-epgu/internal/gost341194"
+)
+
+// Файлы контейнера КриптоПро.
+const (
+	containerHeaderFile  = "header.key"
+	containerPrimaryFile = "primary.key"
+	containerMasksFile   = "masks.key"
+	containerNameFile    = "name.key"
+)
+
+// containerPINSeed - начальное значение выработки ключа из PIN-кода.
+var containerPINSeed = []byte("DENEFH028.760246785.IUEFHWUIO.EF")
+
+// Container - контейнер закрытого ключа КриптоПро на съемном носителе:
+// каталог (обычно с именем вида "xxxxxxxx.000") с файлами
+// header.key, primary.key, masks.key, primary2.key, masks2.key и name.key.
+//
+// Контейнер читается без КриптоПро CSP. Формат контейнера не опубликован производителем:
+// реализация следует общедоступным описаниям формата и поддерживает
+// ключи ГОСТ Р 34.10-2001 и ГОСТ Р 34.10-2012 (256 бит) без аппаратного носителя.
+type Container struct {
+	name string
+	key  *gost3410.PrivateKey
+	cert []byte
+}
+
+// OpenContainer - читает контейнер КриптоПро из каталога dir и расшифровывает
+// закрытый ключ с помощью PIN-кода pin (пустая строка, если PIN-код не установлен).
+//
+// Правильность PIN-кода проверяется по отпечатку открытого ключа из header.key.
+//
+// В случае ошибки возвращает цепочку из одной из ошибок и описания ошибки:
+//   - [ErrContainerRead] - ошибка чтения файлов контейнера
+//   - [ErrContainerParse] - некорректный формат файлов контейнера
+//   - [ErrContainerPIN] - неверный PIN-код
+func OpenContainer(dir, pin string) (*Container, error) {
+	header, err := readContainerFile(dir, containerHeaderFile)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := readContainerFile(dir, containerPrimaryFile)
+	if err != nil {
+		return nil, err
+	}
+	masks, err := readContainerFile(dir, containerMasksFile)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := parseContainerHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var primaryKey struct {
+		Key []byte
+	}
+	if err = unmarshalAll(primary, &primaryKey); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrContainerParse, containerPrimaryFile, err)
+	}
+	var masksKey struct {
+		Mask []byte
+		Salt []byte
+		HMAC []byte
+	}
+	if err = unmarshalAll(masks, &masksKey); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrContainerParse, containerMasksFile, err)
+	}
+	size := info.curve.PointSize()
+	if len(primaryKey.Key) != size || len(masksKey.Mask) != size {
+		return nil, fmt.Errorf("%w: некорректная длина ключа", ErrContainerParse)
+	}
+
+	key, err := unmaskContainerKey(info, primaryKey.Key, masksKey.Mask, masksKey.Salt, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Container{
+		name: readContainerName(dir),
+		key:  key,
+		cert: info.cert,
+	}, nil
+}
+
+// Name - возвращает имя контейнера из name.key либо имя каталога контейнера.
+func (c *Container) Name() string {
+	return c.name
+}
+
+// Certificate - возвращает сертификат в DER, записанный в контейнер, либо nil.
+func (c *Container) Certificate() []byte {
+	return c.cert
+}
+
+// CertHash - возвращает хэш сертификата из контейнера в формате ЕСИА
+// (ГОСТ Р 34.11-2012, 256 бит) либо пустую строку, если сертификат не записан в контейнер.
+func (c *Container) CertHash() string {
+	if c.cert == nil {
+		return ""
+	}
+	return certHashDER(c.cert)
+}
+
+// NewGOSTFromContainer - конструктор [GOST], загружающий закрытый ключ и сертификат
+// из контейнера КриптоПро в каталоге dir, см [OpenContainer].
+// Хэш сертификата рассчитывается по сертификату из контейнера.
+//
+// В случае ошибки возвращает цепочку из одной из ошибок [OpenContainer]
+// или [ErrContainerCert] и описания ошибки.
+func NewGOSTFromContainer(dir, pin string) (*GOST, error) {
+	c, err := OpenContainer(dir, pin)
+	if err != nil {
+		return nil, err
+	}
+	if c.cert == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrContainerCert, dir)
+	}
+	return &GOST{key: c.key, certHash: c.CertHash(), rand: rand.Reader}, nil
+}
+
+// containerInfo - сведения из header.key.
+type containerInfo struct {
+	is2012      bool
+	curve       *gost3410.Curve
+	fingerprint []byte
+	cert        []byte
+}
+
+// parseContainerHeader - извлекает из header.key алгоритм и параметры ключа,
+// отпечаток открытого ключа и сертификат.
+//
+// Структура header.key различается в версиях КриптоПро CSP,
+// поэтому нужные значения ищутся обходом всего дерева DER.
+func parseContainerHeader(der []byte) (*containerInfo, error) {
+	info := &containerInfo{}
+	var alg asn1.ObjectIdentifier
+	err := walkDER(der, func(v asn1.RawValue) bool {
+		if info.cert == nil && isCertificate(v.FullBytes) {
+			info.cert = bytes.Clone(v.FullBytes)
+			return false
+		}
+		switch {
+		case v.Class == asn1.ClassUniversal && v.Tag == asn1.TagOID:
+			var oid asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(v.FullBytes, &oid); err != nil {
+				return true
+			}
+			if alg == nil && isGOSTKeyAlgorithm(oid) {
+				alg = oid
+			} else if info.curve == nil {
+				info.curve = gost3410.CurveByOID(oid)
+			}
+		case v.Class == asn1.ClassUniversal && v.Tag == asn1.TagOctetString && len(v.Bytes) == 8:
+			if info.fingerprint == nil {
+				info.fingerprint = v.Bytes
+			}
+		case !v.IsCompound && v.Class == asn1.ClassContextSpecific && isCertificate(v.Bytes):
+			if info.cert == nil {
+				info.cert = bytes.Clone(v.Bytes)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrContainerParse, containerHeaderFile, err)
+	}
+	if alg == nil {
+		return nil, fmt.Errorf("%w: %s: алгоритм ключа не найден", ErrContainerParse, containerHeaderFile)
+	}
+	if info.curve == nil {
+		return nil, fmt.Errorf("%w: %s: набор параметров ключа не найден", ErrContainerParse, containerHeaderFile)
+	}
+	if info.fingerprint == nil {
+		return nil, fmt.Errorf("%w: %s: отпечаток открытого ключа не найден", ErrContainerParse, containerHeaderFile)
+	}
+	info.is2012 = alg.Equal(oidGOST2012256) || alg.Equal(oidGOST2012256DH)
+	return info, nil
+}
+
+// unmaskContainerKey - расшифровывает закрытый ключ из primary.key и снимает маску из masks.key.
+//
+// Ключ шифрования вырабатывается из PIN-кода и соли: для ключей ГОСТ Р 34.10-2001
+// с хэш-функцией ГОСТ Р 34.11-94 и таблицей замен CryptoPro-A,
+// для ключей ГОСТ Р 34.10-2012 — с хэш-функцией ГОСТ Р 34.11-2012 (256 бит) и таблицей замен TC26-Z.
+// Закрытый ключ равен расшифрованному значению, умноженному на число, обратное маске, по модулю q.
+func unmaskContainerKey(info *containerInfo, encrypted, mask, salt []byte, pin string) (*gost3410.PrivateKey, error) {
+	newHash, sbox := containerHash2001, gost28147.SboxCryptoProA
+	if info.is2012 {
+		newHash, sbox = gost3411.New256, gost28147.SboxTC26Z
+	}
+
+	cipher, err := gost28147.NewCipher(containerPINKey(newHash, salt, pin), sbox)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrContainerParse, err)
+	}
+	masked := make([]byte, len(encrypted))
+	for i := 0; i < len(encrypted); i += gost28147.BlockSize {
+		cipher.Decrypt(masked[i:], encrypted[i:])
+	}
+
+	q := info.curve.Q
+	m := new(big.Int).SetBytes(reverse(mask))
+	mInv := new(big.Int).ModInverse(m, q)
+	if mInv == nil {
+		return nil, fmt.Errorf("%w: некорректная маска ключа", ErrContainerParse)
+	}
+	d := new(big.Int).SetBytes(reverse(masked))
+	d.Mul(d, mInv).Mod(d, q)
+
+	key, err := gost3410.NewPrivateKeyInt(info.curve, d)
+	if err != nil {
+		return nil, ErrContainerPIN
+	}
+	x := key.PublicKey().Raw()[:len(info.fingerprint)]
+	if !bytes.Equal(x, info.fingerprint) {
+		return nil, ErrContainerPIN
+	}
+	return key, nil
+}
+
+// containerPINKey - вырабатывает ключ шифрования закрытого ключа из PIN-кода и соли.
+func containerPINKey(newHash func() hash.Hash, salt []byte, pin string) []byte {
+	pin4 := make([]byte, len(pin)*4)
+	for i := 0; i < len(pin); i++ {
+		pin4[i*4] = pin[i]
+	}
+
+	h := newHash()
+	h.Write(salt)
+	h.Write(pin4)
+	pinHash := h.Sum(nil)
+
+	rounds := 2
+	if pin != "" {
+		rounds = 2000
+	}
+	current := bytes.Clone(containerPINSeed)
+	m36 := make([]byte, len(current))
+	m5c := make([]byte, len(current))
+	xorPad := func() {
+		for i, b := range current {
+			m36[i] = b ^ 0x36
+			m5c[i] = b ^ 0x5c
+		}
+	}
+	for i := 0; i < rounds; i++ {
+		xorPad()
+		h.Reset()
+		h.Write(m36)
+		h.Write(pinHash)
+		h.Write(m5c)
+		h.Write(pinHash)
+		current = h.Sum(current[:0])
+	}
+
+	xorPad()
+	h.Reset()
+	h.Write(m36)
+	h.Write(salt)
+	h.Write(m5c)
+	h.Write(pin4)
+	current = h.Sum(current[:0])
+
+	h.Reset()
+	h.Write(current)
+	return h.Sum(nil)
+}
+
+func containerHash2001() hash.Hash {
+	return gost341194.New(gost28147.SboxCryptoPro3411)
+}
+
+// walkDER - обходит дерево DER и вызывает fn для каждого значения.
+// Если fn возвращает false, вложенные значения не обходятся.
+func walkDER(der []byte, fn func(v asn1.RawValue) bool) error {
+	for len(der) > 0 {
+		var v asn1.RawValue
+		rest, err := asn1.Unmarshal(der, &v)
+		if err != nil {
+			return err
+		}
+		if fn(v) && v.IsCompound {
+			if err = walkDER(v.Bytes, fn); err != nil {
+				return err
+			}
+		}
+		der = rest
+	}
+	return nil
+}
+
+func readContainerFile(dir, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrContainerRead, err)
+	}
+	return data, nil
+}
+
+// readContainerName - возвращает имя контейнера из name.key: SEQUENCE { IA5String }.
+func readContainerName(dir string) string {
+	var name struct {
+		Name string `asn1:"ia5"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, containerNameFile))
+	if err == nil {
+		if _, err = asn1.Unmarshal(data, &name); err == nil && name.Name != "" {
+			return name.Name
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(dir), filepath.Ext(dir))
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
@@ -0,0 +1,251 @@
+package signature
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost28147"
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+type suiteContainer struct {
+	suite.Suite
+}
+
+func TestContainer(t *testing.T) {
+	suite.Run(t, new(suiteContainer))
+}
+
+// Тестовый сертификат: структура X.509 без содержимого.
+const testContainerCert = "3015300302010130" + "0a06082a8503070101030203020001"
+
+// Хэш testContainerCert, рассчитан независимой реализацией ГОСТ Р 34.11-2012 (nettle).
+const testContainerCertHash = "6547C424F2695F87753899F9067AD4A3769E16282028F0564B68602278D2338B"
+
+func (suite *suiteContainer) TestOpenContainer() {
+	tests := []struct {
+		name string
+		alg  asn1.ObjectIdentifier
+		pin  string
+	}{
+		{"gost 2012 with pin", oidGOST2012256, "12345678"},
+		{"gost 2012 without pin", oidGOST2012256, ""},
+		{"gost 2001 with pin", oidGOST2001, "12345678"},
+		{"gost 2001 without pin", oidGOST2001, ""},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			dir := suite.writeContainer(tt.alg, tt.pin, true)
+			c, err := OpenContainer(dir, tt.pin)
+			suite.Require().NoError(err)
+			suite.Equal("test-container", c.Name())
+			suite.Equal(testContainerCert, hex.EncodeToString(c.Certificate()))
+			suite.Equal(testContainerCertHash, c.CertHash())
+			suite.Equal(testGOSTPublicKey, hex.EncodeToString(c.key.PublicKey().Raw()))
+
+			_, err = OpenContainer(dir, tt.pin+"0")
+			suite.ErrorIs(err, ErrContainerPIN)
+		})
+	}
+}
+
+func (suite *suiteContainer) TestNewGOSTFromContainer() {
+	suite.Run("success", func() {
+		dir := suite.writeContainer(oidGOST2012256, "0000", true)
+		signer, err := NewGOSTFromContainer(dir, "0000")
+		suite.Require().NoError(err)
+		suite.Equal(testContainerCertHash, signer.CertHash())
+
+		sig, err := signer.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		raw, _ := hex.DecodeString(testGOSTPublicKey)
+		pub, err := gost3410.NewPublicKeyRaw(gost3410.CurveCryptoProA, raw)
+		suite.Require().NoError(err)
+		digest := gost3411.Sum256([]byte(testDataToSign))
+		suite.NoError(pub.VerifyDigest(digest[:], sig))
+	})
+
+	suite.Run("error no certificate", func() {
+		dir := suite.writeContainer(oidGOST2012256, "0000", false)
+		c, err := OpenContainer(dir, "0000")
+		suite.Require().NoError(err)
+		suite.Nil(c.Certificate())
+		suite.Empty(c.CertHash())
+
+		signer, err := NewGOSTFromContainer(dir, "0000")
+		suite.ErrorIs(err, ErrContainerCert)
+		suite.Nil(signer)
+	})
+
+	suite.Run("error wrong pin", func() {
+		dir := suite.writeContainer(oidGOST2012256, "0000", true)
+		signer, err := NewGOSTFromContainer(dir, "1111")
+		suite.ErrorIs(err, ErrContainerPIN)
+		suite.Nil(signer)
+	})
+}
+
+func (suite *suiteContainer) TestOpenContainerErrors() {
+	suite.Run("missing file", func() {
+		dir := suite.writeContainer(oidGOST2012256, "", true)
+		suite.Require().NoError(os.Remove(filepath.Join(dir, containerMasksFile)))
+		_, err := OpenContainer(dir, "")
+		suite.ErrorIs(err, ErrContainerRead)
+	})
+
+	suite.Run("name from directory", func() {
+		dir := suite.writeContainer(oidGOST2012256, "", true)
+		suite.Require().NoError(os.Remove(filepath.Join(dir, containerNameFile)))
+		c, err := OpenContainer(dir, "")
+		suite.Require().NoError(err)
+		suite.Equal("abcdefgh", c.Name())
+	})
+
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{"invalid header", containerHeaderFile, "test"},
+		{"header without algorithm", containerHeaderFile, "3000"},
+		{"invalid primary", containerPrimaryFile, "3000"},
+		{"invalid masks", containerMasksFile, "test"},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			dir := suite.writeContainer(oidGOST2012256, "", true)
+			data := []byte(tt.data)
+			if b, err := hex.DecodeString(tt.data); err == nil {
+				data = b
+			}
+			suite.Require().NoError(os.WriteFile(filepath.Join(dir, tt.file), data, 0600))
+			_, err := OpenContainer(dir, "")
+			suite.ErrorIs(err, ErrContainerParse)
+		})
+	}
+}
+
+// testContainerVector - описание контейнера КриптоПро из testdata/cryptopro, см testdata/cryptopro/README.md.
+type testContainerVector struct {
+	Source    string `json:"source"`     // Как и чем создан контейнер
+	PIN       string `json:"pin"`        // PIN-код контейнера
+	Name      string `json:"name"`       // Имя контейнера из name.key
+	PublicKey string `json:"public_key"` // Открытый ключ LE(x) || LE(y) в hex, если в контейнере нет сертификата
+	CertHash  string `json:"cert_hash"`  // Хэш сертификата контейнера, если он есть
+}
+
+// TestRealContainers - контейнеры, созданные КриптоПро CSP: в отличие от [suiteContainer.writeContainer]
+// проверяют чтение контейнера независимо от кода записи. Открытый ключ, вычисленный
+// по расшифрованному закрытому ключу, сравнивается с ключом из сертификата контейнера или из vector.json.
+func (suite *suiteContainer) TestRealContainers() {
+	vectors, err := filepath.Glob(filepath.Join("testdata", "cryptopro", "*", "vector.json"))
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(vectors, "нет контейнеров КриптоПро в testdata/cryptopro, см testdata/cryptopro/README.md")
+
+	for _, path := range vectors {
+		dir := filepath.Dir(path)
+		suite.Run(filepath.Base(dir), func() {
+			data, err := os.ReadFile(path)
+			suite.Require().NoError(err)
+			v := testContainerVector{}
+			suite.Require().NoError(json.Unmarshal(data, &v))
+			suite.Require().NotEmpty(v.Source, "не указан источник контейнера")
+
+			c, err := OpenContainer(dir, v.PIN)
+			suite.Require().NoError(err)
+			if v.Name != "" {
+				suite.Equal(v.Name, c.Name())
+			}
+
+			publicKey := c.key.PublicKey().Raw()
+			if v.PublicKey != "" {
+				suite.Equal(strings.ToLower(v.PublicKey), hex.EncodeToString(publicKey))
+			}
+			if c.Certificate() != nil {
+				certKey, err := certPublicKey(c.Certificate())
+				suite.Require().NoError(err)
+				suite.Equal(hex.EncodeToString(certKey), hex.EncodeToString(publicKey))
+				suite.Equal(strings.ToUpper(v.CertHash), c.CertHash())
+			} else {
+				suite.Require().NotEmpty(v.PublicKey, "в контейнере нет сертификата: укажите public_key")
+			}
+
+			_, err = OpenContainer(dir, v.PIN+"0")
+			suite.ErrorIs(err, ErrContainerPIN)
+		})
+	}
+}
+
+// writeContainer - создает контейнер с ключом testGOSTKeyPEM на кривой CryptoPro-A,
+// зашифрованным на PIN-коде pin.
+// Контейнер записывается тем же кодом выработки ключа из PIN-кода, что и читается:
+// совместимость с КриптоПро CSP проверяет [suiteContainer.TestRealContainers].
+func (suite *suiteContainer) writeContainer(alg asn1.ObjectIdentifier, pin string, withCert bool) string {
+	dir := filepath.Join(suite.T().TempDir(), "abcdefgh.000")
+	suite.Require().NoError(os.Mkdir(dir, 0700))
+
+	key, err := parsePrivateKeyPEM([]byte(testGOSTKeyPEM))
+	suite.Require().NoError(err)
+	curve := key.Curve
+
+	mask := big.NewInt(0x1234567)
+	masked := new(big.Int).Mul(key.D, mask)
+	masked.Mod(masked, curve.Q)
+	salt := []byte("saltsaltsalt")
+
+	info := &containerInfo{is2012: alg.Equal(oidGOST2012256), curve: curve}
+	newHash, sbox := containerHash2001, gost28147.SboxCryptoProA
+	if info.is2012 {
+		newHash, sbox = gost3411.New256, gost28147.SboxTC26Z
+	}
+	cipher, err := gost28147.NewCipher(containerPINKey(newHash, salt, pin), sbox)
+	suite.Require().NoError(err)
+	plain := reverse(masked.FillBytes(make([]byte, 32)))
+	encrypted := make([]byte, len(plain))
+	for i := 0; i < len(plain); i += gost28147.BlockSize {
+		cipher.Encrypt(encrypted[i:], plain[i:])
+	}
+
+	header := struct {
+		Params struct {
+			Algorithm asn1.ObjectIdentifier
+			Params    gostKeyParams
+		}
+		Fingerprint []byte
+		Cert        asn1.RawValue `asn1:"optional,explicit,tag:5"`
+	}{}
+	header.Params.Algorithm = alg
+	header.Params.Params.PublicKeyParamSet = gost3410.OIDCryptoProA
+	header.Params.Params.DigestParamSet = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 1, 2, 2}
+	header.Fingerprint = key.PublicKey().Raw()[:8]
+	if withCert {
+		header.Cert.FullBytes, _ = hex.DecodeString(testContainerCert)
+	}
+
+	primary := struct{ Key []byte }{encrypted}
+	masks := struct{ Mask, Salt, HMAC []byte }{reverse(mask.FillBytes(make([]byte, 32))), salt, []byte{0, 0, 0, 0}}
+	name := struct {
+		Name string `asn1:"ia5"`
+	}{"test-container"}
+
+	for file, v := range map[string]any{
+		containerHeaderFile:  header,
+		containerPrimaryFile: primary,
+		containerMasksFile:   masks,
+		containerNameFile:    name,
+	} {
+		data, err := asn1.Marshal(v)
+		suite.Require().NoError(err)
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, file), data, 0600))
+	}
+	return dir
+}
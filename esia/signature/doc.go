@@ -4,6 +4,30 @@
 //  1. [LocalCryptoPro] — электронная подпись с использованием алгоритма ГОСТ Р 34.10-2012 (256 бит) и
 //     инсталляции КриптоПро CSP 5 для рабочих станций. Может быть использована для отладки взаимодействия
 //     с ЕСИА. Не подходит в качестве серверного решения.
-//  2. [Nop] — тестовый провайдер электронной подписи: возвращает фиксированное значение подписи.
+//  2. [GOST] — электронная подпись с использованием алгоритмов ГОСТ Р 34.10-2012 и ГОСТ Р 34.11-2012 (256 бит)
+//     на чистом Go. Закрытый ключ загружается из PEM в формате PKCS#8 или из контейнера КриптоПро
+//     на съемном носителе, см [OpenContainer]. Подходит в качестве серверного решения.
+//  3. [LocalOpenSSL] — электронная подпись с использованием алгоритма ГОСТ Р 34.10-2012 (256 бит) и
+//     утилиты openssl с модулем ГОСТ (gost-engine или провайдер gostprov). Не требует КриптоПро CSP.
+//  4. [Remote] — электронная подпись во внешнем сервисе подписи по HTTP (например, с ключами в HSM).
+//     Эталонный сервис подписи: [NewRemoteHandler] и программа cmd/esia-signer.
+//  5. [Nop] — тестовый провайдер электронной подписи: возвращает фиксированное значение подписи.
 //     Используется для юнит-тестов.
+//
+// # Обертки над провайдерами
+//   - [WithCert] — хэш сертификата рассчитывается по сертификату
+//   - [Pool] — ограничение количества одновременных подписей, очередь с таймаутом, метрики
+//     и переключение на резервный провайдер
+//   - [Rotating] — плановая замена сертификата ИС: выбор провайдера по сроку действия сертификата
+//
+// # Подпись файлов и проверка подписи
+//
+// [CMS] — отсоединенная подпись CMS (PKCS#7) по ГОСТ Р 34.10-2012 с сертификатом подписанта
+// для файлов вложений к заявлению, см [apipgu.NewSignedArchive].
+//
+// [Verifier] — проверка подписи CMS файлов ответа ведомства и цепочки сертификатов подписанта
+// по хранилищу доверенных сертификатов [TrustStore], см [apipgu.Client.AttachmentDownloadVerified].
+//
+// [apipgu.NewSignedArchive]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#NewSignedArchive
+// [apipgu.Client.AttachmentDownloadVerified]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#Client.AttachmentDownloadVerified
 package signature
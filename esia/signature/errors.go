@@ -9,3 +9,60 @@ var (
 	ErrTempFileRead   = errors.New("ошибка чтения временного файла")
 	ErrCPTestExec     = errors.New("ошибка запуска cptest")
 )
+
+// Ошибки провайдера [LocalOpenSSL]
+var (
+	ErrOpenSSLExec = errors.New("ошибка запуска openssl")
+)
+
+// Ошибки провайдера [GOST]
+var (
+	ErrKeyRead  = errors.New("ошибка чтения файла закрытого ключа")
+	ErrKeyParse = errors.New("ошибка разбора закрытого ключа")
+	ErrGOSTSign = errors.New("ошибка подписи ГОСТ Р 34.10-2012")
+)
+
+// Ошибки провайдера [Remote]
+var (
+	ErrRemoteRequest      = errors.New("ошибка запроса к сервису подписи")
+	ErrRemoteResponse     = errors.New("ошибка ответа сервиса подписи")
+	ErrDigestNotSupported = errors.New("провайдер не поддерживает подпись хэш-кода")
+)
+
+// Ошибки провайдера [Pool]
+var (
+	ErrPoolTimeout = errors.New("превышено время ожидания очереди подписи")
+	ErrPoolSign    = errors.New("ошибка подписи всеми провайдерами")
+)
+
+// Ошибки провайдера [Rotating]
+var (
+	ErrNoActiveCert = errors.New("нет действующего сертификата ИС")
+)
+
+// Ошибки формирования подписи [CMS]
+var (
+	ErrCMSSign = errors.New("ошибка формирования подписи CMS")
+)
+
+// Ошибки проверки подписи [Verifier]
+var (
+	ErrCMSParse         = errors.New("ошибка разбора подписи CMS")
+	ErrSignatureInvalid = errors.New("электронная подпись недействительна")
+	ErrCertUntrusted    = errors.New("сертификат подписанта не прошел проверку")
+)
+
+// Ошибки чтения контейнера КриптоПро [OpenContainer]
+var (
+	ErrContainerRead  = errors.New("ошибка чтения контейнера КриптоПро")
+	ErrContainerParse = errors.New("ошибка разбора контейнера КриптоПро")
+	ErrContainerPIN   = errors.New("неверный PIN-код контейнера КриптоПро")
+	ErrContainerCert  = errors.New("сертификат не найден в контейнере КриптоПро")
+)
+
+// Ошибки расчета хэша сертификата [CertHash] и [WithCert]
+var (
+	ErrCertRead     = errors.New("ошибка чтения файла сертификата")
+	ErrCertParse    = errors.New("ошибка разбора сертификата")
+	ErrCertMismatch = errors.New("сертификат не соответствует закрытому ключу")
+)
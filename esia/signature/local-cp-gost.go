@@ -1,9 +1,11 @@
 package signature
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 )
 
 // LocalCryptoPro реализация [signature.Provider] с использованием
@@ -54,6 +56,9 @@ type LocalCryptoPro struct {
 // Команда выведет хеш сертификата:
 //
 //	1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF0
+//
+// Хеш сертификата также можно рассчитать без КриптоПро CSP с помощью [CertHashFromFile]
+// либо получать из сертификата автоматически, см [WithCertFile].
 func NewLocalCryptoPro(cspTestPath, cspContainer, certHash string) *LocalCryptoPro {
 	return &LocalCryptoPro{
 		cspTestPath:  cspTestPath,
@@ -126,8 +131,30 @@ type cmdInterface interface {
 	Run(path string, args ...string) error
 }
 
+// pipeCmdInterface - запуск команды с передачей данных через stdin и чтением результата из stdout.
+type pipeCmdInterface interface {
+	Pipe(stdin []byte, path string, args ...string) ([]byte, error)
+}
+
 type osExec struct{}
 
 func (o osExec) Run(path string, args ...string) error {
 	return exec.Command(path, args...).Run()
 }
+
+// Pipe - запускает команду, передает stdin и возвращает stdout.
+// В случае ошибки к ней добавляется вывод stderr.
+func (o osExec) Pipe(stdin []byte, path string, args ...string) ([]byte, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
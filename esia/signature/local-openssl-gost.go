@@ -0,0 +1,97 @@
+package signature
+
+import "fmt"
+
+// LocalOpenSSL - реализация [signature.Provider] с использованием утилиты openssl
+// с подключенным модулем ГОСТ (gost-engine для OpenSSL 1.1 и 3, либо провайдер gostprov для OpenSSL 3).
+// Для подписания используются алгоритмы ГОСТ Р 34.11-2012 и ГОСТ Р 34.10-2012 (256 бит).
+//
+// Подписываемые данные передаются через stdin, подпись читается из stdout: временные файлы не создаются.
+//
+// OpenSSL возвращает подпись в порядке байтов, который ожидает ЕСИА: BE(s) || BE(r),
+// т.е. так же, как [LocalCryptoPro] после реверса байтов, поэтому подпись возвращается без изменений.
+type LocalOpenSSL struct {
+	opensslPath string
+	keyPath     string
+	certHash    string
+	engine      string
+	provider    string
+	cmd         pipeCmdInterface
+}
+
+// NewLocalOpenSSL - конструктор [LocalOpenSSL].
+//
+// # opensslPath
+//
+// Полный путь к утилите openssl, например "/usr/bin/openssl".
+//
+// # keyPath
+//
+// Путь к закрытому ключу ГОСТ Р 34.10-2012 (256 бит) в PEM-формате PKCS#8 без шифрования.
+//
+// # certHash
+//
+// Хеш сертификата, см [NewLocalCryptoPro] и [CertHashFromFile].
+//
+// По умолчанию подключается модуль gost-engine: "-engine gost". Для OpenSSL 3
+// с провайдером ГОСТ используйте [LocalOpenSSL.WithProvider].
+// Если модуль ГОСТ подключен в конфигурации openssl.cnf, используйте [LocalOpenSSL.WithEngine] с пустой строкой.
+func NewLocalOpenSSL(opensslPath, keyPath, certHash string) *LocalOpenSSL {
+	return &LocalOpenSSL{
+		opensslPath: opensslPath,
+		keyPath:     keyPath,
+		certHash:    certHash,
+		engine:      "gost",
+		cmd:         osExec{},
+	}
+}
+
+// WithEngine - устанавливает имя модуля (engine) OpenSSL, реализующего алгоритмы ГОСТ.
+// Пустая строка - модуль не указывается в командной строке.
+func (p *LocalOpenSSL) WithEngine(engine string) *LocalOpenSSL {
+	p.engine = engine
+	p.provider = ""
+	return p
+}
+
+// WithProvider - устанавливает имя провайдера OpenSSL 3, реализующего алгоритмы ГОСТ (обычно "gostprov"),
+// вместо модуля (engine).
+func (p *LocalOpenSSL) WithProvider(provider string) *LocalOpenSSL {
+	p.provider = provider
+	p.engine = ""
+	return p
+}
+
+// CertHash - возвращает хэш сертификата.
+func (p *LocalOpenSSL) CertHash() string {
+	return p.certHash
+}
+
+// Sign - возвращает подпись для данных c использованием алгоритма ГОСТ Р 34.10-2012 (256 бит)
+// и хэш-функции ГОСТ Р 34.11-2012 (256 бит).
+//
+// В случае ошибки возвращает цепочку из [ErrOpenSSLExec] и описания ошибки.
+func (p *LocalOpenSSL) Sign(data []byte) ([]byte, error) {
+	sig, err := p.cmd.Pipe(data, p.opensslPath, p.args()...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOpenSSLExec, err)
+	}
+	if len(sig) != 64 {
+		return nil, fmt.Errorf("%w: некорректная длина подписи: %d", ErrOpenSSLExec, len(sig))
+	}
+	return sig, nil
+}
+
+// args - аргументы командной строки openssl:
+//
+//	openssl dgst -md_gost12_256 [-engine gost | -provider gostprov -provider default] -sign key.pem
+func (p *LocalOpenSSL) args() []string {
+	args := []string{"dgst", "-md_gost12_256"}
+	switch {
+	case p.provider != "":
+		args = append(args, "-provider", p.provider, "-provider", "default")
+	case p.engine != "":
+		args = append(args, "-engine", p.engine)
+	}
+	return append(args, "-sign", p.keyPath)
+}
@@ -0,0 +1,109 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteLocalOpenSSL struct {
+	suite.Suite
+	signer *LocalOpenSSL
+	cmd    *testPipeCmd
+}
+
+func TestLocalOpenSSL(t *testing.T) {
+	suite.Run(t, new(suiteLocalOpenSSL))
+}
+
+func (suite *suiteLocalOpenSSL) SetupTest() {
+	suite.cmd = newTestPipeCmd(suite.T())
+	suite.signer = NewLocalOpenSSL("openssl", "key.pem", "test_hash")
+	suite.signer.cmd = suite.cmd
+}
+
+func (suite *suiteLocalOpenSSL) TestSign() {
+	suite.Run("success", func() {
+		sig, err := suite.signer.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal(testGOSTSignature, hex.EncodeToString(sig))
+		suite.Equal([]string{"dgst", "-md_gost12_256", "-engine", "gost", "-sign", "key.pem"}, suite.cmd.args)
+	})
+
+	suite.Run("provider", func() {
+		_, err := suite.signer.WithProvider("gostprov").Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal([]string{"dgst", "-md_gost12_256", "-provider", "gostprov", "-provider", "default", "-sign", "key.pem"}, suite.cmd.args)
+	})
+
+	suite.Run("no engine", func() {
+		_, err := suite.signer.WithEngine("").Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal([]string{"dgst", "-md_gost12_256", "-sign", "key.pem"}, suite.cmd.args)
+	})
+
+	suite.Run("error", func() {
+		sig, err := suite.signer.Sign([]byte{})
+		suite.ErrorIs(err, ErrOpenSSLExec)
+		suite.Nil(sig)
+	})
+
+	suite.Run("error signature length", func() {
+		sig, err := suite.signer.Sign([]byte("short"))
+		suite.ErrorIs(err, ErrOpenSSLExec)
+		suite.Nil(sig)
+	})
+}
+
+func (suite *suiteLocalOpenSSL) TestCertHash() {
+	suite.Equal("test_hash", suite.signer.CertHash())
+}
+
+func (suite *suiteLocalOpenSSL) TestOsExecPipe() {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		suite.T().Skip("sh not found")
+	}
+
+	out, err := osExec{}.Pipe([]byte(testDataToSign), sh, "-c", "cat")
+	suite.Require().NoError(err)
+	suite.Equal(testDataToSign, string(out))
+
+	out, err = osExec{}.Pipe(nil, sh, "-c", "echo test error >&2; exit 1")
+	suite.ErrorContains(err, "test error")
+	suite.Nil(out)
+}
+
+// testPipeCmd - имитация openssl с gost-engine: подписывает данные провайдером [GOST]
+// с фиксированным k, т.е. возвращает подпись в том же порядке байтов, что и openssl.
+type testPipeCmd struct {
+	t    *testing.T
+	args []string
+}
+
+func newTestPipeCmd(t *testing.T) *testPipeCmd {
+	return &testPipeCmd{t: t}
+}
+
+func (c *testPipeCmd) Pipe(stdin []byte, path string, args ...string) ([]byte, error) {
+	require.Equal(c.t, "openssl", path)
+	c.args = args
+
+	// если входные данные пустые, то возвращаем ошибку
+	if len(stdin) == 0 {
+		return nil, errors.New("some error")
+	}
+	if string(stdin) == "short" {
+		return []byte("short"), nil
+	}
+
+	signer, err := NewGOST([]byte(testGOSTKeyPEM), "")
+	require.NoError(c.t, err)
+	signer.rand = bytes.NewReader(bytes.Repeat([]byte{0x11}, 32))
+	return signer.Sign(stdin)
+}
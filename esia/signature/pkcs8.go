@@ -0,0 +1,116 @@
+package signature
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+)
+
+// OID алгоритмов открытого ключа ГОСТ Р 34.10.
+var (
+	oidGOST2001    = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 19}
+	oidGOST2012256 = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 1, 1, 1}
+)
+
+// OID алгоритмов ключа обмена ГОСТ Р 34.10: встречаются в контейнерах КриптоПро и сертификатах.
+var (
+	oidGOST2001DH    = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 98}
+	oidGOST2012256DH = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 1, 6, 1}
+)
+
+// pkcs8 - структура PrivateKeyInfo (RFC 5208) / OneAsymmetricKey (RFC 5958).
+type pkcs8 struct {
+	Version    int
+	Algorithm  pkcs8Algorithm
+	PrivateKey []byte
+	Attributes asn1.RawValue `asn1:"optional,tag:0"`
+	PublicKey  asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type pkcs8Algorithm struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters gostKeyParams
+}
+
+// gostKeyParams - параметры ключа ГОСТ Р 34.10 (RFC 4491, раздел 2.3.1).
+type gostKeyParams struct {
+	PublicKeyParamSet  asn1.ObjectIdentifier
+	DigestParamSet     asn1.ObjectIdentifier `asn1:"optional"`
+	EncryptionParamSet asn1.ObjectIdentifier `asn1:"optional"`
+}
+
+// parsePrivateKeyPEM - читает закрытый ключ ГОСТ Р 34.10-2012 (256 бит) из PEM в формате PKCS#8.
+func parsePrivateKeyPEM(data []byte) (*gost3410.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: PEM-блок не найден", ErrKeyParse)
+	}
+	if block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%w: неподдерживаемый тип PEM-блока '%s'", ErrKeyParse, block.Type)
+	}
+	return parsePKCS8(block.Bytes)
+}
+
+// parsePKCS8 - читает закрытый ключ ГОСТ Р 34.10-2012 (256 бит) из DER в формате PKCS#8.
+//
+// Поддерживаемые форматы значения ключа:
+//   - OCTET STRING с ключом, записанным начиная с младшего байта (OpenSSL gost-engine)
+//   - INTEGER (устаревший формат)
+//   - ключ без обертки, записанный начиная с младшего байта
+func parsePKCS8(der []byte) (*gost3410.PrivateKey, error) {
+	info := pkcs8{}
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyParse, err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: лишние данные после ключа", ErrKeyParse)
+	}
+
+	alg := info.Algorithm.Algorithm
+	if !alg.Equal(oidGOST2012256) && !alg.Equal(oidGOST2001) {
+		return nil, fmt.Errorf("%w: неподдерживаемый алгоритм '%s'", ErrKeyParse, alg)
+	}
+	curveOID := info.Algorithm.Parameters.PublicKeyParamSet
+	curve := gost3410.CurveByOID(curveOID)
+	if curve == nil {
+		return nil, fmt.Errorf("%w: неподдерживаемый набор параметров '%s'", ErrKeyParse, curveOID)
+	}
+
+	var key *gost3410.PrivateKey
+	var raw []byte
+	var d *big.Int
+	switch {
+	case unmarshalAll(info.PrivateKey, &raw) == nil:
+		key, err = gost3410.NewPrivateKey(curve, raw)
+	case unmarshalAll(info.PrivateKey, &d) == nil:
+		key, err = gost3410.NewPrivateKeyInt(curve, d)
+	default:
+		key, err = gost3410.NewPrivateKey(curve, info.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyParse, err)
+	}
+	return key, nil
+}
+
+// unmarshalAll - asn1.Unmarshal без лишних данных в конце.
+func unmarshalAll(der []byte, v any) error {
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return asn1.SyntaxError{Msg: "trailing data"}
+	}
+	return nil
+}
+
+// isGOSTKeyAlgorithm - проверяет, что oid - алгоритм ключа ГОСТ Р 34.10-2001 или ГОСТ Р 34.10-2012 (256 бит).
+func isGOSTKeyAlgorithm(oid asn1.ObjectIdentifier) bool {
+	return oid.Equal(oidGOST2001) || oid.Equal(oidGOST2001DH) ||
+		oid.Equal(oidGOST2012256) || oid.Equal(oidGOST2012256DH)
+}
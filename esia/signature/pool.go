@@ -0,0 +1,219 @@
+package signature
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertHashSigner - провайдер подписи, возвращающий хэш сертификата вместе с подписью.
+// Нужен, если провайдер может подписывать разными сертификатами (например, [Pool] при переключении
+// на резервный провайдер): хэш сертификата в запросе к ЕСИА должен соответствовать подписи.
+// Клиент ЕСИА использует этот интерфейс, если провайдер его реализует.
+type CertHashSigner interface {
+	SignWithCertHash(data []byte) (signature []byte, certHash string, err error)
+}
+
+// PoolEvent - сведения об одной попытке подписи в [Pool], передаются в [Pool.WithObserver].
+type PoolEvent struct {
+	Provider int           // Номер провайдера: 0 - основной, 1 и далее - резервные
+	Latency  time.Duration // Время подписи (без ожидания в очереди)
+	Wait     time.Duration // Время ожидания в очереди
+	Err      error         // Ошибка подписи
+}
+
+// PoolStats - счетчики [Pool], см [Pool.Stats].
+type PoolStats struct {
+	Signed    uint64        // Успешных подписей
+	Failed    uint64        // Ошибок подписи (по всем провайдерам)
+	Failovers uint64        // Переключений между провайдерами
+	Rejected  uint64        // Запросов, не дождавшихся очереди
+	InFlight  int64         // Подписей, выполняемых в данный момент
+	Latency   time.Duration // Суммарное время успешных подписей
+}
+
+// Pool - реализация [signature.Provider], ограничивающая количество одновременных подписей
+// и переключающаяся между основным и резервными провайдерами при ошибке.
+//
+// Запросы сверх лимита ожидают в очереди не дольше таймаута, см [Pool.WithQueueTimeout] и [Pool.SignContext].
+//
+// При ошибке основного провайдера подпись выполняется резервным, и он становится активным.
+// Через [Pool.WithFailback] выполняется попытка вернуться к основному провайдеру.
+// Хэш сертификата [Pool.CertHash] соответствует активному провайдеру;
+// для точного соответствия подписи и хэша используйте [Pool.SignWithCertHash].
+type Pool struct {
+	providers    []Provider
+	sem          chan struct{}
+	queueTimeout time.Duration
+	failback     time.Duration
+	observer     func(PoolEvent)
+
+	mu         sync.RWMutex
+	active     int
+	failoverAt time.Time
+
+	signed, failed, failovers, rejected atomic.Uint64
+	inFlight                            atomic.Int64
+	latency                             atomic.Int64
+}
+
+// NewPool - конструктор [Pool] с основным провайдером primary и резервными провайдерами backups.
+//
+// По умолчанию: количество одновременных подписей равно runtime.NumCPU(),
+// таймаут очереди 30 секунд, возврат к основному провайдеру через 1 минуту.
+func NewPool(primary Provider, backups ...Provider) *Pool {
+	return &Pool{
+		providers:    append([]Provider{primary}, backups...),
+		sem:          make(chan struct{}, runtime.NumCPU()),
+		queueTimeout: 30 * time.Second,
+		failback:     time.Minute,
+	}
+}
+
+// WithConcurrency - устанавливает максимальное количество одновременных подписей.
+// Например, 1 для [LocalCryptoPro].
+func (p *Pool) WithConcurrency(n int) *Pool {
+	if n < 1 {
+		n = 1
+	}
+	p.sem = make(chan struct{}, n)
+	return p
+}
+
+// WithQueueTimeout - устанавливает максимальное время ожидания в очереди для [Pool.Sign].
+func (p *Pool) WithQueueTimeout(timeout time.Duration) *Pool {
+	p.queueTimeout = timeout
+	return p
+}
+
+// WithFailback - устанавливает время, через которое после переключения на резервный провайдер
+// выполняется попытка вернуться к основному.
+func (p *Pool) WithFailback(d time.Duration) *Pool {
+	p.failback = d
+	return p
+}
+
+// WithObserver - устанавливает функцию, вызываемую после каждой попытки подписи.
+// Используется для сбора метрик (задержки, ошибки). Функция не должна блокировать выполнение.
+func (p *Pool) WithObserver(observer func(PoolEvent)) *Pool {
+	p.observer = observer
+	return p
+}
+
+// CertHash - возвращает хэш сертификата активного провайдера.
+func (p *Pool) CertHash() string {
+	return p.providers[p.current()].CertHash()
+}
+
+// Sign - возвращает подпись данных, см [Pool.SignContext].
+func (p *Pool) Sign(data []byte) ([]byte, error) {
+	sig, _, err := p.SignWithCertHash(data)
+	return sig, err
+}
+
+// SignWithCertHash - возвращает подпись данных и хэш сертификата провайдера, выполнившего подпись.
+func (p *Pool) SignWithCertHash(data []byte) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.queueTimeout)
+	defer cancel()
+	return p.SignContext(ctx, data)
+}
+
+// SignContext - ожидает очереди не дольше, чем позволяет ctx, и возвращает подпись данных
+// и хэш сертификата провайдера, выполнившего подпись.
+//
+// В случае ошибки возвращает цепочку из одной из ошибок и описания ошибки:
+//   - [ErrPoolTimeout] - истекло время ожидания в очереди
+//   - [ErrPoolSign] - ошибка подписи всеми провайдерами (с ошибкой последнего провайдера)
+func (p *Pool) SignContext(ctx context.Context, data []byte) ([]byte, string, error) {
+	start := time.Now()
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		p.rejected.Add(1)
+		return nil, "", fmt.Errorf("%w: %w", ErrPoolTimeout, ctx.Err())
+	}
+	defer func() { <-p.sem }()
+	wait := time.Since(start)
+
+	p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+
+	first := p.current()
+	var err error
+	for i := 0; i < len(p.providers); i++ {
+		n := (first + i) % len(p.providers)
+		provider := p.providers[n]
+		signStart := time.Now()
+		var (
+			sig      []byte
+			certHash string
+		)
+		sig, certHash, err = signWithCertHash(provider, data)
+		p.observe(PoolEvent{Provider: n, Latency: time.Since(signStart), Wait: wait, Err: err})
+		if err == nil {
+			p.signed.Add(1)
+			p.latency.Add(int64(time.Since(signStart)))
+			p.setActive(first, n)
+			return sig, certHash, nil
+		}
+		p.failed.Add(1)
+	}
+	return nil, "", fmt.Errorf("%w: %w", ErrPoolSign, err)
+}
+
+// Stats - возвращает текущие значения счетчиков.
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Signed:    p.signed.Load(),
+		Failed:    p.failed.Load(),
+		Failovers: p.failovers.Load(),
+		Rejected:  p.rejected.Load(),
+		InFlight:  p.inFlight.Load(),
+		Latency:   time.Duration(p.latency.Load()),
+	}
+}
+
+// current - возвращает номер активного провайдера с учетом возврата к основному.
+func (p *Pool) current() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.active != 0 && time.Since(p.failoverAt) >= p.failback {
+		return 0
+	}
+	return p.active
+}
+
+// setActive - делает активным провайдер n, выполнивший подпись.
+// Если подпись выполнена не первым опрошенным провайдером first, фиксируется переключение.
+func (p *Pool) setActive(first, n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n != first {
+		p.failovers.Add(1)
+		p.failoverAt = time.Now()
+	}
+	p.active = n
+}
+
+// signWithCertHash - возвращает подпись данных и хэш сертификата провайдера.
+// Если провайдер реализует [CertHashSigner] (например, [Rotating]), хэш берется из того же вызова:
+// между подписью и отдельным вызовом [Provider.CertHash] сертификат провайдера может смениться.
+func signWithCertHash(provider Provider, data []byte) ([]byte, string, error) {
+	if s, ok := provider.(CertHashSigner); ok {
+		return s.SignWithCertHash(data)
+	}
+	sig, err := provider.Sign(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return sig, provider.CertHash(), nil
+}
+
+func (p *Pool) observe(event PoolEvent) {
+	if p.observer != nil {
+		p.observer(event)
+	}
+}
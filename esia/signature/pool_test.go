@@ -0,0 +1,226 @@
+package signature
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suitePool struct {
+	suite.Suite
+}
+
+func TestPool(t *testing.T) {
+	suite.Run(t, new(suitePool))
+}
+
+func (suite *suitePool) TestSign() {
+	suite.Run("primary", func() {
+		pool := NewPool(newTestProvider("primary"), newTestProvider("backup"))
+		sig, certHash, err := pool.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("primary", string(sig))
+		suite.Equal("primary", certHash)
+		suite.Equal("primary", pool.CertHash())
+		suite.Equal(PoolStats{Signed: 1, Latency: pool.Stats().Latency}, pool.Stats())
+	})
+
+	suite.Run("failover", func() {
+		primary := newTestProvider("primary")
+		primary.err.Store(true)
+		var events []PoolEvent
+		pool := NewPool(primary, newTestProvider("backup")).
+			WithObserver(func(e PoolEvent) { events = append(events, e) })
+
+		sig, certHash, err := pool.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("backup", string(sig))
+		suite.Equal("backup", certHash)
+		suite.Equal("backup", pool.CertHash())
+
+		// активен резервный провайдер: основной не опрашивается
+		sig, err = pool.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("backup", string(sig))
+		suite.Equal(int32(1), primary.calls.Load())
+
+		stats := pool.Stats()
+		suite.Equal(uint64(2), stats.Signed)
+		suite.Equal(uint64(1), stats.Failed)
+		suite.Equal(uint64(1), stats.Failovers)
+		suite.Require().Len(events, 3)
+		suite.Equal(0, events[0].Provider)
+		suite.Error(events[0].Err)
+		suite.Equal(1, events[1].Provider)
+		suite.NoError(events[1].Err)
+	})
+
+	suite.Run("failback", func() {
+		primary := newTestProvider("primary")
+		primary.err.Store(true)
+		pool := NewPool(primary, newTestProvider("backup")).WithFailback(time.Millisecond)
+
+		sig, err := pool.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("backup", string(sig))
+
+		primary.err.Store(false)
+		time.Sleep(2 * time.Millisecond)
+		suite.Equal("primary", pool.CertHash())
+		sig, err = pool.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("primary", string(sig))
+		suite.Equal(uint64(1), pool.Stats().Failovers)
+	})
+
+	suite.Run("error all providers", func() {
+		primary, backup := newTestProvider("primary"), newTestProvider("backup")
+		primary.err.Store(true)
+		backup.err.Store(true)
+		sig, err := NewPool(primary, backup).Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrPoolSign)
+		suite.ErrorContains(err, "backup")
+		suite.Nil(sig)
+	})
+}
+
+func (suite *suitePool) TestConcurrency() {
+	provider := newTestProvider("primary")
+	provider.delay = 5 * time.Millisecond
+	pool := NewPool(provider).WithConcurrency(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pool.Sign([]byte(testDataToSign))
+			suite.NoError(err)
+		}()
+	}
+	wg.Wait()
+	suite.Equal(int32(2), provider.maxActive.Load())
+	suite.Equal(uint64(10), pool.Stats().Signed)
+	suite.Equal(int64(0), pool.Stats().InFlight)
+}
+
+func (suite *suitePool) TestRotating() {
+	// старый и новый сертификаты действуют одновременно (период перехода)
+	newRotating := func() *Rotating {
+		now := time.Now()
+		oldProvider, newProvider := newTestProvider("old"), newTestProvider("new")
+		oldProvider.delay, newProvider.delay = time.Millisecond, time.Millisecond
+		return NewRotating(
+			RotationEntry{Provider: oldProvider, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+			RotationEntry{Provider: newProvider, NotBefore: now.Add(-time.Minute), NotAfter: now.Add(2 * time.Hour)},
+		)
+	}
+
+	suite.Run("certificate changed after signing", func() {
+		rotating := newRotating()
+		var once sync.Once
+		pool := NewPool(rotating).WithObserver(func(PoolEvent) {
+			// параллельная подпись новым сертификатом сразу после подписи пулом
+			once.Do(func() {
+				rotating.PreferNew(true)
+				_, err := rotating.Sign([]byte(testDataToSign))
+				suite.NoError(err)
+			})
+		})
+		sig, certHash, err := pool.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("old", string(sig))
+		suite.Equal("old", certHash)
+	})
+
+	suite.Run("concurrent", func() {
+		rotating := newRotating()
+		pool := NewPool(rotating).WithConcurrency(8)
+
+		var wg sync.WaitGroup
+		var mismatches atomic.Int32
+		for i := 0; i < 64; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				rotating.PreferNew(i%2 == 0)
+				sig, certHash, err := pool.SignWithCertHash([]byte(testDataToSign))
+				suite.NoError(err)
+				if string(sig) != certHash {
+					mismatches.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		suite.Equal(int32(0), mismatches.Load())
+		suite.Equal(uint64(64), pool.Stats().Signed)
+	})
+}
+
+func (suite *suitePool) TestQueueTimeout() {
+	provider := newTestProvider("primary")
+	provider.delay = 50 * time.Millisecond
+	pool := NewPool(provider).WithConcurrency(1).WithQueueTimeout(time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = pool.Sign([]byte(testDataToSign))
+	}()
+	for provider.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	sig, err := pool.Sign([]byte(testDataToSign))
+	suite.ErrorIs(err, ErrPoolTimeout)
+	suite.Nil(sig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = pool.SignContext(ctx, []byte(testDataToSign))
+	suite.ErrorIs(err, ErrPoolTimeout)
+	suite.ErrorIs(err, context.Canceled)
+
+	<-done
+	suite.Equal(uint64(2), pool.Stats().Rejected)
+}
+
+// testProvider - провайдер подписи, возвращающий свое имя в качестве подписи и хэша сертификата.
+type testProvider struct {
+	name      string
+	delay     time.Duration
+	err       atomic.Bool
+	calls     atomic.Int32
+	active    atomic.Int32
+	maxActive atomic.Int32
+}
+
+func newTestProvider(name string) *testProvider {
+	return &testProvider{name: name}
+}
+
+func (p *testProvider) Sign(_ []byte) ([]byte, error) {
+	p.calls.Add(1)
+	active := p.active.Add(1)
+	defer p.active.Add(-1)
+	for {
+		max := p.maxActive.Load()
+		if active <= max || p.maxActive.CompareAndSwap(max, active) {
+			break
+		}
+	}
+	time.Sleep(p.delay)
+	if p.err.Load() {
+		return nil, errors.New(p.name + " error")
+	}
+	return []byte(p.name), nil
+}
+
+func (p *testProvider) CertHash() string {
+	return p.name
+}
@@ -0,0 +1,136 @@
+package signature
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DigestSigner - провайдер подписи, поддерживающий подписание готового хэш-кода
+// ГОСТ Р 34.11-2012 (256 бит). Используется сервисом подписи [NewRemoteHandler]
+// для запросов [Remote] в режиме [Remote.WithDigest].
+type DigestSigner interface {
+	SignDigest(digest []byte) ([]byte, error)
+}
+
+// SignDigest - возвращает подпись хэш-кода ГОСТ Р 34.11-2012 (256 бит).
+//
+// В случае ошибки возвращает цепочку из [ErrGOSTSign] и описания ошибки.
+func (p *GOST) SignDigest(digest []byte) ([]byte, error) {
+	sig, err := p.key.SignDigest(digest, p.rand)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGOSTSign, err)
+	}
+	return sig, nil
+}
+
+// SignDigest - подписывает хэш-код, если исходный провайдер реализует [DigestSigner].
+func (p *certProvider) SignDigest(digest []byte) ([]byte, error) {
+	if s, ok := p.Provider.(DigestSigner); ok {
+		return s.SignDigest(digest)
+	}
+	return nil, ErrDigestNotSupported
+}
+
+// NewRemoteHandler - возвращает эталонный HTTP-обработчик сервиса подписи для [Remote]
+// с подписью провайдером provider. Если token не пустой, запросы должны содержать
+// заголовок "Authorization: Bearer {token}".
+//
+// Подпись хэш-кода ([RemoteSignRequest.Digest]) поддерживается,
+// если provider реализует [DigestSigner], например [GOST].
+// Хэш сертификата возвращается вместе с подписью; если provider реализует [CertHashSigner]
+// (например, [Rotating] или [Pool]), хэш берется из того же вызова, что и подпись.
+//
+// Используется программой cmd/esia-signer и для тестирования взаимодействия с ЕСИА на одной машине.
+func NewRemoteHandler(provider Provider, token string) http.Handler {
+	h := &remoteHandler{provider: provider, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc(RemoteSignEndpoint, h.sign)
+	mux.HandleFunc(RemoteCertHashEndpoint, h.certHash)
+	return h.auth(mux)
+}
+
+type remoteHandler struct {
+	provider Provider
+	token    string
+}
+
+func (h *remoteHandler) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.token != "" && !constantTimeEqual(r.Header.Get("Authorization"), "Bearer "+h.token) {
+			h.error(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *remoteHandler) sign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.error(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	req := &RemoteSignRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, remoteMaxBody)).Decode(req); err != nil {
+		h.error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var (
+		sig      []byte
+		certHash string
+		err      error
+	)
+	switch {
+	case len(req.Digest) > 0:
+		s, ok := h.provider.(DigestSigner)
+		if !ok {
+			h.error(w, http.StatusBadRequest, ErrDigestNotSupported)
+			return
+		}
+		if sig, err = s.SignDigest(req.Digest); err == nil {
+			certHash = h.provider.CertHash()
+		}
+	case len(req.Data) > 0:
+		sig, certHash, err = signWithCertHash(h.provider, req.Data)
+	default:
+		h.error(w, http.StatusBadRequest, errors.New("empty data"))
+		return
+	}
+	switch {
+	case errors.Is(err, ErrDigestNotSupported):
+		h.error(w, http.StatusBadRequest, err)
+	case err != nil:
+		h.error(w, http.StatusInternalServerError, err)
+	default:
+		h.json(w, http.StatusOK, &RemoteSignResponse{Signature: sig, CertHash: certHash})
+	}
+}
+
+func (h *remoteHandler) certHash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.error(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	h.json(w, http.StatusOK, &RemoteCertHashResponse{CertHash: h.provider.CertHash()})
+}
+
+func (h *remoteHandler) error(w http.ResponseWriter, status int, err error) {
+	h.json(w, status, &RemoteErrorResponse{Error: err.Error()})
+}
+
+func (h *remoteHandler) json(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// remoteMaxBody - максимальный размер запроса к сервису подписи.
+const remoteMaxBody = 1 << 20
+
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
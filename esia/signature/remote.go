@@ -0,0 +1,296 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+// Эндпоинты сервиса подписи [Remote], см [NewRemoteHandler].
+const (
+	RemoteSignEndpoint     = "/sign"      // Подписание данных или хэш-кода
+	RemoteCertHashEndpoint = "/cert-hash" // Хэш сертификата
+)
+
+// RemoteSignRequest - запрос к сервису подписи [RemoteSignEndpoint].
+// Заполняется одно из полей: Data или Digest.
+type RemoteSignRequest struct {
+	Data   []byte `json:"data,omitempty"`   // Подписываемые данные (base64)
+	Digest []byte `json:"digest,omitempty"` // Хэш-код ГОСТ Р 34.11-2012 (256 бит) подписываемых данных (base64)
+}
+
+// RemoteSignResponse - ответ сервиса подписи [RemoteSignEndpoint].
+type RemoteSignResponse struct {
+	Signature []byte `json:"signature"`           // Подпись в порядке байтов ЕСИА: BE(s) || BE(r) (base64)
+	CertHash  string `json:"cert_hash,omitempty"` // Хэш сертификата, которым выполнена подпись; пусто у сервисов прежних версий
+}
+
+// RemoteCertHashResponse - ответ сервиса подписи [RemoteCertHashEndpoint].
+type RemoteCertHashResponse struct {
+	CertHash string `json:"cert_hash"` // Хэш сертификата
+}
+
+// RemoteErrorResponse - ответ сервиса подписи при ошибке.
+type RemoteErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Remote - реализация [signature.Provider], передающая данные на подпись
+// во внешний сервис подписи по HTTP (например, сервис с ключами в HSM).
+//
+// Протокол сервиса:
+//   - POST [RemoteSignEndpoint]: [RemoteSignRequest] => [RemoteSignResponse]
+//   - GET [RemoteCertHashEndpoint]: => [RemoteCertHashResponse]
+//   - при ошибке: HTTP 4xx/5xx и [RemoteErrorResponse]
+//
+// Эталонная реализация сервиса: [NewRemoteHandler] и программа cmd/esia-signer.
+//
+// Хэш сертификата сервис возвращает вместе с подписью ([RemoteSignResponse.CertHash]):
+// если сервис сменил сертификат (например, [Rotating] или [Pool] с резервным провайдером),
+// [Remote.SignWithCertHash] возвращает хэш нового сертификата, а кэш хэша обновляется.
+// Для сервисов прежних версий, не возвращающих хэш вместе с подписью, хэш сертификата запрашивается
+// отдельно при первой подписи или первом вызове [Remote.CertHash] и кэшируется,
+// либо задается явно с помощью [Remote.WithCertHash].
+// Если хэш сертификата получить не удалось, [Remote.Sign] возвращает ошибку.
+type Remote struct {
+	baseURI    string
+	httpClient *http.Client
+	token      string
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+	digest     bool
+	logger     utils.Logger
+	debug      bool
+
+	mu       sync.Mutex
+	certHash string
+}
+
+// NewRemote - конструктор [Remote].
+// Параметр baseURI - адрес сервиса подписи, например "https://signer.local:8443".
+//
+// По умолчанию: таймаут запроса 10 секунд, без повторов, на подпись передаются данные целиком.
+func NewRemote(baseURI string) *Remote {
+	return &Remote{
+		baseURI:    strings.TrimRight(baseURI, "/"),
+		httpClient: &http.Client{},
+		timeout:    10 * time.Second,
+	}
+}
+
+// WithHTTPClient - устанавливает http-клиент для запросов к сервису подписи.
+func (p *Remote) WithHTTPClient(httpClient *http.Client) *Remote {
+	if httpClient != nil {
+		p.httpClient = httpClient
+	}
+	return p
+}
+
+// WithTLSConfig - устанавливает параметры TLS для запросов к сервису подписи,
+// в т.ч. клиентский сертификат для взаимной аутентификации (mTLS).
+func (p *Remote) WithTLSConfig(config *tls.Config) *Remote {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	p.httpClient = &http.Client{Transport: transport}
+	return p
+}
+
+// WithBearerToken - устанавливает токен для заголовка "Authorization: Bearer {token}".
+func (p *Remote) WithBearerToken(token string) *Remote {
+	p.token = token
+	return p
+}
+
+// WithTimeout - устанавливает таймаут одного запроса к сервису подписи.
+func (p *Remote) WithTimeout(timeout time.Duration) *Remote {
+	p.timeout = timeout
+	return p
+}
+
+// WithRetries - устанавливает количество повторов запроса и паузу между ними.
+// Повторяются запросы, завершившиеся сетевой ошибкой, HTTP 429 или HTTP 5xx.
+func (p *Remote) WithRetries(retries int, delay time.Duration) *Remote {
+	p.retries = retries
+	p.retryDelay = delay
+	return p
+}
+
+// WithDigest - включает передачу на подпись хэш-кода ГОСТ Р 34.11-2012 (256 бит) вместо данных.
+// Хэш-код рассчитывается локально: подписываемые данные не покидают приложение.
+func (p *Remote) WithDigest() *Remote {
+	p.digest = true
+	return p
+}
+
+// WithCertHash - устанавливает хэш сертификата: запрос к сервису подписи не выполняется.
+func (p *Remote) WithCertHash(certHash string) *Remote {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.certHash = certHash
+	return p
+}
+
+// WithDebug - включает логирование запросов и ответов, см [utils.LogReq].
+func (p *Remote) WithDebug(logger utils.Logger) *Remote {
+	p.logger = logger
+	p.debug = logger != nil
+	return p
+}
+
+// CertHash - возвращает хэш сертификата последней подписи либо полученный по [Remote.LoadCertHash].
+// При ошибке запроса к сервису подписи возвращает пустую строку.
+// После успешной подписи [Remote.Sign] хэш сертификата уже получен и ошибка невозможна.
+func (p *Remote) CertHash() string {
+	certHash, _ := p.LoadCertHash()
+	return certHash
+}
+
+// LoadCertHash - возвращает хэш сертификата, при необходимости запрашивая его у сервиса подписи.
+// Может использоваться для проверки доступности сервиса при запуске приложения.
+//
+// В случае ошибки возвращает цепочку из [ErrRemoteRequest] или [ErrRemoteResponse] и описания ошибки.
+func (p *Remote) LoadCertHash() (string, error) {
+	p.mu.Lock()
+	certHash := p.certHash
+	p.mu.Unlock()
+	if certHash != "" {
+		return certHash, nil
+	}
+
+	// запрос выполняется без блокировки: параллельные вызовы могут запросить хэш повторно
+	res := &RemoteCertHashResponse{}
+	if err := p.request(http.MethodGet, RemoteCertHashEndpoint, nil, res); err != nil {
+		return "", err
+	}
+	if res.CertHash == "" {
+		return "", fmt.Errorf("%w: пустой хэш сертификата", ErrRemoteResponse)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.certHash == "" {
+		p.certHash = res.CertHash
+	}
+	return p.certHash, nil
+}
+
+// Sign - возвращает подпись данных, полученную от сервиса подписи, см [Remote.SignWithCertHash].
+//
+// В случае ошибки возвращает цепочку из [ErrRemoteRequest] или [ErrRemoteResponse] и описания ошибки.
+func (p *Remote) Sign(data []byte) ([]byte, error) {
+	sig, _, err := p.SignWithCertHash(data)
+	return sig, err
+}
+
+// SignWithCertHash - возвращает подпись данных, полученную от сервиса подписи, и хэш сертификата,
+// которым выполнена подпись. Реализует [CertHashSigner].
+// Если сервис не вернул хэш сертификата вместе с подписью, используется [Remote.LoadCertHash].
+//
+// В случае ошибки возвращает цепочку из [ErrRemoteRequest] или [ErrRemoteResponse] и описания ошибки.
+func (p *Remote) SignWithCertHash(data []byte) ([]byte, string, error) {
+	req := &RemoteSignRequest{Data: data}
+	if p.digest {
+		digest := gost3411.Sum256(data)
+		req = &RemoteSignRequest{Digest: digest[:]}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrRemoteRequest, err)
+	}
+	res := &RemoteSignResponse{}
+	if err = p.request(http.MethodPost, RemoteSignEndpoint, body, res); err != nil {
+		return nil, "", err
+	}
+	if len(res.Signature) == 0 {
+		return nil, "", fmt.Errorf("%w: пустая подпись", ErrRemoteResponse)
+	}
+
+	if res.CertHash != "" {
+		p.mu.Lock()
+		p.certHash = res.CertHash
+		p.mu.Unlock()
+		return res.Signature, res.CertHash, nil
+	}
+	certHash, err := p.LoadCertHash()
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Signature, certHash, nil
+}
+
+// request - выполняет запрос к сервису подписи с повторами.
+func (p *Remote) request(method, endpoint string, body []byte, result any) error {
+	var err error
+	for attempt := 0; attempt <= p.retries; attempt++ {
+		if attempt > 0 && p.retryDelay > 0 {
+			time.Sleep(p.retryDelay)
+		}
+		var retry bool
+		if retry, err = p.do(method, endpoint, body, result); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// do - выполняет один запрос к сервису подписи.
+// Возвращает true, если запрос может быть повторен.
+func (p *Remote) do(method, endpoint string, body []byte, result any) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURI+endpoint, reqBody)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrRemoteRequest, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	if p.debug {
+		utils.LogReq(req, p.logger)
+	}
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", ErrRemoteRequest, err)
+	}
+	//goland:noinspection ALL
+	defer res.Body.Close()
+	if p.debug {
+		utils.LogRes(res, p.logger)
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", ErrRemoteRequest, err)
+	}
+	if res.StatusCode >= 400 {
+		errRes := &RemoteErrorResponse{}
+		_ = json.Unmarshal(resBody, errRes)
+		retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return retry, fmt.Errorf("%w: HTTP %d: %s", ErrRemoteResponse, res.StatusCode, errRes.Error)
+	}
+	if err = json.Unmarshal(resBody, result); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrRemoteResponse, err)
+	}
+	return false, nil
+}
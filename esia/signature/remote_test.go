@@ -0,0 +1,301 @@
+package signature
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+type suiteRemote struct {
+	suite.Suite
+	signer *GOST
+	server *httptest.Server
+	calls  atomic.Int32
+}
+
+func TestRemote(t *testing.T) {
+	suite.Run(t, new(suiteRemote))
+}
+
+func (suite *suiteRemote) SetupTest() {
+	var err error
+	suite.signer, err = NewGOST([]byte(testGOSTKeyPEM), "test_hash")
+	suite.Require().NoError(err)
+	handler := NewRemoteHandler(suite.signer, "test_token")
+	suite.calls.Store(0)
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.calls.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+func (suite *suiteRemote) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *suiteRemote) TestSign() {
+	suite.Run("data", func() {
+		p := NewRemote(suite.server.URL + "/").WithBearerToken("test_token")
+		sig, err := p.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.NoError(suite.verify([]byte(testDataToSign), sig))
+	})
+
+	suite.Run("digest", func() {
+		p := NewRemote(suite.server.URL).WithBearerToken("test_token").WithDigest()
+		sig, err := p.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.NoError(suite.verify([]byte(testDataToSign), sig))
+	})
+
+	suite.Run("error unauthorized", func() {
+		suite.calls.Store(0)
+		p := NewRemote(suite.server.URL).WithBearerToken("wrong").WithRetries(2, 0)
+		sig, err := p.Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrRemoteResponse)
+		suite.ErrorContains(err, "HTTP 401")
+		suite.Nil(sig)
+		suite.Equal(int32(1), suite.calls.Load())
+	})
+
+	suite.Run("error digest not supported", func() {
+		server := httptest.NewServer(NewRemoteHandler(NewNop("signature", "hash"), ""))
+		defer server.Close()
+		sig, err := NewRemote(server.URL).WithDigest().Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrRemoteResponse)
+		suite.ErrorContains(err, ErrDigestNotSupported.Error())
+		suite.Nil(sig)
+	})
+
+	suite.Run("error provider", func() {
+		server := httptest.NewServer(NewRemoteHandler(NewNop("", "hash"), ""))
+		defer server.Close()
+		sig, err := NewRemote(server.URL).Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrRemoteResponse)
+		suite.ErrorContains(err, "HTTP 500")
+		suite.Nil(sig)
+	})
+
+	suite.Run("with cert hash", func() {
+		suite.calls.Store(0)
+		p := NewRemote(suite.server.URL).WithBearerToken("test_token")
+		sig, certHash, err := p.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.NoError(suite.verify([]byte(testDataToSign), sig))
+		suite.Equal("test_hash", certHash)
+		suite.Equal("test_hash", p.CertHash())
+		suite.Equal(int32(1), suite.calls.Load()) // хэш сертификата возвращается вместе с подписью
+	})
+
+	suite.Run("cert hash changed", func() {
+		primary := newTestProvider("primary")
+		server := httptest.NewServer(NewRemoteHandler(NewPool(primary, newTestProvider("backup")), ""))
+		defer server.Close()
+		p := NewRemote(server.URL)
+		sig, certHash, err := p.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("primary", string(sig))
+		suite.Equal("primary", certHash)
+
+		primary.err.Store(true)
+		sig, certHash, err = p.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("backup", string(sig))
+		suite.Equal("backup", certHash)
+		suite.Equal("backup", p.CertHash())
+	})
+
+	suite.Run("legacy service", func() {
+		var certHashCalls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == RemoteCertHashEndpoint {
+				certHashCalls.Add(1)
+				_, _ = w.Write([]byte(`{"cert_hash":"legacy_hash"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"signature":"c2lnbmF0dXJl"}`))
+		}))
+		defer server.Close()
+		p := NewRemote(server.URL)
+		for i := 0; i < 2; i++ {
+			sig, certHash, err := p.SignWithCertHash([]byte(testDataToSign))
+			suite.Require().NoError(err)
+			suite.Equal("signature", string(sig))
+			suite.Equal("legacy_hash", certHash)
+		}
+		suite.Equal(int32(1), certHashCalls.Load())
+	})
+
+	suite.Run("error cert hash", func() {
+		var signCalls atomic.Int32
+		handler := NewRemoteHandler(NewNop("signature", ""), "")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == RemoteSignEndpoint {
+				signCalls.Add(1)
+			}
+			handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+		sig, err := NewRemote(server.URL).Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrRemoteResponse)
+		suite.ErrorContains(err, "пустой хэш сертификата")
+		suite.Nil(sig)
+		suite.Equal(int32(1), signCalls.Load())
+	})
+
+	suite.Run("error connection", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		sig, err := NewRemote(server.URL).WithRetries(1, time.Millisecond).Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrRemoteRequest)
+		suite.Nil(sig)
+	})
+}
+
+func (suite *suiteRemote) TestRetries() {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		NewRemoteHandler(suite.signer, "").ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	suite.Run("success", func() {
+		sig, err := NewRemote(server.URL).WithCertHash("test_hash").WithRetries(2, time.Millisecond).Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Len(sig, 64)
+		suite.Equal(int32(3), calls.Load())
+	})
+
+	suite.Run("exhausted", func() {
+		calls.Store(0)
+		sig, err := NewRemote(server.URL).WithCertHash("test_hash").WithRetries(1, time.Millisecond).Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrRemoteResponse)
+		suite.ErrorContains(err, "HTTP 503")
+		suite.Nil(sig)
+		suite.Equal(int32(2), calls.Load())
+	})
+}
+
+func (suite *suiteRemote) TestTimeout() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+	sig, err := NewRemote(server.URL).WithTimeout(10 * time.Millisecond).Sign([]byte(testDataToSign))
+	suite.ErrorIs(err, ErrRemoteRequest)
+	suite.Nil(sig)
+}
+
+func (suite *suiteRemote) TestCertHash() {
+	suite.Run("cached", func() {
+		suite.calls.Store(0)
+		p := NewRemote(suite.server.URL).WithBearerToken("test_token")
+		suite.Equal("test_hash", p.CertHash())
+		suite.Equal("test_hash", p.CertHash())
+		suite.Equal(int32(1), suite.calls.Load())
+	})
+
+	suite.Run("fixed", func() {
+		suite.calls.Store(0)
+		p := NewRemote(suite.server.URL).WithCertHash("fixed_hash")
+		suite.Equal("fixed_hash", p.CertHash())
+		suite.Equal(int32(0), suite.calls.Load())
+	})
+
+	suite.Run("error", func() {
+		p := NewRemote(suite.server.URL)
+		suite.Empty(p.CertHash())
+		_, err := p.LoadCertHash()
+		suite.ErrorIs(err, ErrRemoteResponse)
+	})
+
+	suite.Run("no lock during request", func() {
+		started, release := make(chan struct{}), make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			NewRemoteHandler(NewNop("signature", "remote_hash"), "").ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		p := NewRemote(server.URL)
+		loaded := make(chan string)
+		go func() {
+			certHash, _ := p.LoadCertHash()
+			loaded <- certHash
+		}()
+		<-started
+
+		// пока запрос к сервису выполняется, хэш сертификата можно задать явно
+		done := make(chan struct{})
+		go func() {
+			p.WithCertHash("fixed_hash")
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			suite.Fail("WithCertHash blocked by LoadCertHash")
+		}
+		close(release)
+		suite.Equal("fixed_hash", <-loaded)
+		suite.Equal("fixed_hash", p.CertHash())
+	})
+
+	suite.Run("error empty", func() {
+		server := httptest.NewServer(NewRemoteHandler(NewNop("signature", ""), ""))
+		defer server.Close()
+		_, err := NewRemote(server.URL).LoadCertHash()
+		suite.ErrorIs(err, ErrRemoteResponse)
+	})
+}
+
+func (suite *suiteRemote) TestHandler() {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"sign method", http.MethodGet, RemoteSignEndpoint, "", http.StatusMethodNotAllowed},
+		{"cert hash method", http.MethodPost, RemoteCertHashEndpoint, "", http.StatusMethodNotAllowed},
+		{"invalid json", http.MethodPost, RemoteSignEndpoint, "test", http.StatusBadRequest},
+		{"empty data", http.MethodPost, RemoteSignEndpoint, "{}", http.StatusBadRequest},
+		{"invalid digest", http.MethodPost, RemoteSignEndpoint, `{"digest":"AAAA"}`, http.StatusInternalServerError},
+	}
+	handler := NewRemoteHandler(suite.signer, "")
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			suite.Equal(tt.status, w.Code)
+			suite.Contains(w.Body.String(), `"error"`)
+		})
+	}
+}
+
+func (suite *suiteRemote) verify(data, sig []byte) error {
+	raw, _ := hex.DecodeString(testGOSTPublicKey)
+	pub, err := gost3410.NewPublicKeyRaw(gost3410.CurveCryptoProA, raw)
+	suite.Require().NoError(err)
+	digest := gost3411.Sum256(data)
+	return pub.VerifyDigest(digest[:], sig)
+}
@@ -0,0 +1,187 @@
+package signature
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+// RotationEntry - провайдер подписи с периодом действия сертификата для [Rotating].
+type RotationEntry struct {
+	Provider  Provider  // Провайдер подписи
+	NotBefore time.Time // Начало периода действия сертификата
+	NotAfter  time.Time // Окончание периода действия сертификата
+}
+
+// NewRotationEntry - создает [RotationEntry] по сертификату cert (DER или PEM):
+// период действия берется из сертификата, а хэш сертификата рассчитывается по нему, см [WithCert].
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] или [ErrCertMismatch] и описания ошибки.
+func NewRotationEntry(provider Provider, cert []byte) (RotationEntry, error) {
+	der, err := certDER(cert)
+	if err != nil {
+		return RotationEntry{}, err
+	}
+	tbs, err := parseTBS(der)
+	if err != nil {
+		return RotationEntry{}, err
+	}
+	p, err := WithCert(provider, der)
+	if err != nil {
+		return RotationEntry{}, err
+	}
+	return RotationEntry{Provider: p, NotBefore: tbs.Validity.NotBefore, NotAfter: tbs.Validity.NotAfter}, nil
+}
+
+// Valid - возвращает true, если сертификат действует в момент t.
+func (e RotationEntry) Valid(t time.Time) bool {
+	return !t.Before(e.NotBefore) && t.Before(e.NotAfter)
+}
+
+// Rotating - реализация [signature.Provider] для плановой замены сертификата ИС.
+// Содержит несколько провайдеров с периодами действия сертификатов
+// и выбирает активный провайдер по текущему времени.
+//
+// Если действуют несколько сертификатов (период перехода), по умолчанию используется
+// ранее выпущенный сертификат, а после вызова [Rotating.PreferNew] — новый.
+// Это позволяет переключиться на новый сертификат в согласованный день,
+// после загрузки его на Технологический портал ЕСИА.
+//
+// [Rotating.CertHash] возвращает хэш сертификата провайдера, выполнившего последнюю подпись,
+// а до первой подписи — активного провайдера.
+type Rotating struct {
+	entries    []RotationEntry
+	warnBefore time.Duration
+	logger     utils.Logger
+
+	mu        sync.Mutex
+	preferNew bool
+	last      *RotationEntry
+	warnedAt  map[int]time.Time
+}
+
+// NewRotating - конструктор [Rotating].
+func NewRotating(entries ...RotationEntry) *Rotating {
+	sorted := make([]RotationEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].NotBefore.Before(sorted[j].NotBefore)
+	})
+	return &Rotating{entries: sorted, warnedAt: map[int]time.Time{}}
+}
+
+// WithExpiryWarning - включает предупреждение в logger об истечении срока действия
+// активного сертификата за days дней. Предупреждение выводится при подписи не чаще раза в сутки.
+func (r *Rotating) WithExpiryWarning(days int, logger utils.Logger) *Rotating {
+	r.warnBefore = time.Duration(days) * 24 * time.Hour
+	r.logger = logger
+	return r
+}
+
+// PreferNew - включает или выключает использование нового сертификата в период,
+// когда действуют несколько сертификатов.
+func (r *Rotating) PreferNew(prefer bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preferNew = prefer
+}
+
+// Active - возвращает активный провайдер на текущий момент.
+//
+// В случае ошибки возвращает [ErrNoActiveCert].
+func (r *Rotating) Active() (RotationEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, entry, err := r.active(nowFunc())
+	if err != nil {
+		return RotationEntry{}, err
+	}
+	return *entry, nil
+}
+
+// CertHash - возвращает хэш сертификата провайдера, выполнившего последнюю подпись,
+// либо активного провайдера, если подпись еще не выполнялась.
+// Если действующих сертификатов нет, возвращает пустую строку.
+func (r *Rotating) CertHash() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last != nil {
+		return r.last.Provider.CertHash()
+	}
+	if _, entry, err := r.active(nowFunc()); err == nil {
+		return entry.Provider.CertHash()
+	}
+	return ""
+}
+
+// Sign - возвращает подпись данных активным провайдером.
+//
+// В случае ошибки возвращает [ErrNoActiveCert] или ошибку провайдера.
+func (r *Rotating) Sign(data []byte) ([]byte, error) {
+	sig, _, err := r.SignWithCertHash(data)
+	return sig, err
+}
+
+// SignWithCertHash - возвращает подпись данных активным провайдером и хэш его сертификата.
+//
+// В случае ошибки возвращает [ErrNoActiveCert] или ошибку провайдера.
+func (r *Rotating) SignWithCertHash(data []byte) ([]byte, string, error) {
+	now := nowFunc()
+	r.mu.Lock()
+	n, entry, err := r.active(now)
+	if err == nil {
+		r.warn(n, entry, now)
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return nil, "", err
+	}
+
+	sig, err := entry.Provider.Sign(data)
+	if err != nil {
+		return nil, "", err
+	}
+	r.mu.Lock()
+	r.last = entry
+	r.mu.Unlock()
+	return sig, entry.Provider.CertHash(), nil
+}
+
+// active - возвращает номер и активный провайдер в момент now.
+func (r *Rotating) active(now time.Time) (int, *RotationEntry, error) {
+	n := -1
+	for i := range r.entries {
+		if !r.entries[i].Valid(now) {
+			continue
+		}
+		if n == -1 || r.preferNew {
+			n = i
+		}
+	}
+	if n == -1 {
+		return 0, nil, fmt.Errorf("%w: %s", ErrNoActiveCert, now.Format(time.RFC3339))
+	}
+	return n, &r.entries[n], nil
+}
+
+// warn - выводит предупреждение об истечении срока действия сертификата.
+func (r *Rotating) warn(n int, entry *RotationEntry, now time.Time) {
+	if r.logger == nil || entry.NotAfter.Sub(now) > r.warnBefore {
+		return
+	}
+	if at, ok := r.warnedAt[n]; ok && now.Sub(at) < 24*time.Hour {
+		return
+	}
+	r.warnedAt[n] = now
+	r.logger.Print(fmt.Sprintf(
+		"ВНИМАНИЕ: срок действия сертификата ИС [cert_hash='%s'] истекает %s (через %d дн.)",
+		entry.Provider.CertHash(),
+		entry.NotAfter.Format(time.DateOnly),
+		int(entry.NotAfter.Sub(now).Hours()/24),
+	))
+}
+
+var nowFunc = time.Now
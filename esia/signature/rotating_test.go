@@ -0,0 +1,159 @@
+package signature
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteRotating struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestRotating(t *testing.T) {
+	suite.Run(t, new(suiteRotating))
+}
+
+func (suite *suiteRotating) SetupTest() {
+	suite.now = time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return suite.now }
+}
+
+func (suite *suiteRotating) TearDownTest() {
+	nowFunc = time.Now
+}
+
+// entries - старый сертификат действует в 2024 году, новый - с 15.12.2024 по 2025 год.
+func (suite *suiteRotating) entries() (RotationEntry, RotationEntry) {
+	return RotationEntry{
+		Provider:  newTestProvider("old"),
+		NotBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, RotationEntry{
+		Provider:  newTestProvider("new"),
+		NotBefore: time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (suite *suiteRotating) TestSign() {
+	oldEntry, newEntry := suite.entries()
+	r := NewRotating(newEntry, oldEntry)
+
+	suite.Run("before cutover", func() {
+		suite.now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		suite.Equal("old", r.CertHash())
+		sig, certHash, err := r.SignWithCertHash([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("old", string(sig))
+		suite.Equal("old", certHash)
+	})
+
+	suite.Run("overlap", func() {
+		suite.now = time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+		sig, err := r.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("old", string(sig))
+		suite.Equal("old", r.CertHash())
+	})
+
+	suite.Run("prefer new", func() {
+		r.PreferNew(true)
+		suite.Equal("old", r.CertHash(), "хэш сертификата последней подписи")
+		active, err := r.Active()
+		suite.Require().NoError(err)
+		suite.Equal("new", active.Provider.CertHash())
+
+		sig, err := r.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("new", string(sig))
+		suite.Equal("new", r.CertHash())
+		r.PreferNew(false)
+	})
+
+	suite.Run("after expiry", func() {
+		suite.now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		sig, err := r.Sign([]byte(testDataToSign))
+		suite.Require().NoError(err)
+		suite.Equal("new", string(sig))
+	})
+
+	suite.Run("error no active", func() {
+		suite.now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		sig, err := r.Sign([]byte(testDataToSign))
+		suite.ErrorIs(err, ErrNoActiveCert)
+		suite.Nil(sig)
+		_, err = r.Active()
+		suite.ErrorIs(err, ErrNoActiveCert)
+		suite.Empty(NewRotating(oldEntry).CertHash())
+	})
+
+	suite.Run("error provider", func() {
+		suite.now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		provider := newTestProvider("failed")
+		provider.err.Store(true)
+		oldEntry.Provider = provider
+		sig, err := NewRotating(oldEntry).Sign([]byte(testDataToSign))
+		suite.ErrorContains(err, "failed error")
+		suite.Nil(sig)
+	})
+}
+
+func (suite *suiteRotating) TestExpiryWarning() {
+	oldEntry, _ := suite.entries()
+	logger := &testLogger{}
+	r := NewRotating(oldEntry).WithExpiryWarning(30, logger)
+
+	suite.now = time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	_, err := r.Sign([]byte(testDataToSign))
+	suite.Require().NoError(err)
+	suite.Empty(logger.messages)
+
+	suite.now = time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	_, err = r.Sign([]byte(testDataToSign))
+	suite.Require().NoError(err)
+	_, err = r.Sign([]byte(testDataToSign))
+	suite.Require().NoError(err)
+	suite.Require().Len(logger.messages, 1)
+	suite.Contains(logger.messages[0], "cert_hash='old'")
+	suite.Contains(logger.messages[0], "2025-01-01")
+	suite.Contains(logger.messages[0], "через 12 дн.")
+
+	suite.now = suite.now.Add(25 * time.Hour)
+	_, err = r.Sign([]byte(testDataToSign))
+	suite.Require().NoError(err)
+	suite.Len(logger.messages, 2)
+}
+
+func (suite *suiteRotating) TestNewRotationEntry() {
+	signer, err := NewGOST([]byte(testGOSTKeyPEM), "")
+	suite.Require().NoError(err)
+	cert := testCertificate(suite.T(), oidGOST2012256, testGOSTPublicKey, testNotBefore, testNotAfter)
+
+	entry, err := NewRotationEntry(signer, cert)
+	suite.Require().NoError(err)
+	suite.Equal(testNotBefore, entry.NotBefore)
+	suite.Equal(testNotAfter, entry.NotAfter)
+	hash, _ := CertHash(cert)
+	suite.Equal(hash, entry.Provider.CertHash())
+	suite.True(entry.Valid(testNotBefore))
+	suite.False(entry.Valid(testNotAfter))
+
+	_, err = NewRotationEntry(signer, []byte("test"))
+	suite.ErrorIs(err, ErrCertParse)
+
+	other := testCertificate(suite.T(), oidGOST2012256, "01"+testGOSTPublicKey[2:], testNotBefore, testNotAfter)
+	_, err = NewRotationEntry(signer, other)
+	suite.ErrorIs(err, ErrCertMismatch)
+}
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Print(v ...any) {
+	l.messages = append(l.messages, fmt.Sprint(v...))
+}
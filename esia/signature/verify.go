@@ -0,0 +1,657 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+// OID алгоритма подписи сертификатов ГОСТ Р 34.10-2012 с ГОСТ Р 34.11-2012 (256 бит).
+var oidGOST2012256Sign = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 1, 3, 2}
+
+// OID атрибутов имени владельца сертификата (Приказ ФСБ России от 27.12.2011 N 795).
+var (
+	oidCommonName   = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidOrganization = asn1.ObjectIdentifier{2, 5, 4, 10}
+	oidOGRN         = asn1.ObjectIdentifier{1, 2, 643, 100, 1}
+	oidSNILS        = asn1.ObjectIdentifier{1, 2, 643, 100, 3}
+	oidINNLE        = asn1.ObjectIdentifier{1, 2, 643, 100, 4}
+	oidOGRNIP       = asn1.ObjectIdentifier{1, 2, 643, 100, 5}
+	oidINN          = asn1.ObjectIdentifier{1, 2, 643, 3, 131, 1, 1}
+)
+
+// OID расширений сертификата (RFC 5280, п. 4.2.1).
+var (
+	oidSubjectKeyID     = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+)
+
+// keyUsageCertSign - номер бита keyCertSign расширения KeyUsage.
+const keyUsageCertSign = 5
+
+// maxChainLength - максимальная длина цепочки сертификатов.
+const maxChainLength = 10
+
+// SignerInfo - сведения о подписанте, возвращаемые [Verifier].
+type SignerInfo struct {
+	Subject      string    // Имя владельца сертификата (все атрибуты)
+	CommonName   string    // Общее имя (CN)
+	Organization string    // Наименование организации (O)
+	OGRN         string    // ОГРН
+	OGRNIP       string    // ОГРНИП
+	INN          string    // ИНН физического лица
+	INNLE        string    // ИНН юридического лица
+	SNILS        string    // СНИЛС
+	SigningTime  time.Time // Время подписания из подписанных атрибутов (указывается подписантом); нулевое, если атрибута нет
+	Certificate  []byte    // Сертификат подписанта в DER
+}
+
+// TrustStore - хранилище доверенных корневых и промежуточных сертификатов для [Verifier].
+// Цепочка сертификатов подписанта должна заканчиваться на одном из корневых сертификатов.
+type TrustStore struct {
+	roots         []*certificate
+	intermediates []*certificate
+}
+
+// NewTrustStore - конструктор [TrustStore].
+func NewTrustStore() *TrustStore {
+	return &TrustStore{}
+}
+
+// AddRoots - добавляет доверенные корневые сертификаты: один сертификат в DER
+// либо один или несколько сертификатов в PEM.
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] и описания ошибки.
+func (s *TrustStore) AddRoots(certs []byte) error {
+	parsed, err := parseCertificates(certs)
+	if err != nil {
+		return err
+	}
+	s.roots = append(s.roots, parsed...)
+	return nil
+}
+
+// AddRootsFile - то же, что [TrustStore.AddRoots], но сертификаты читаются из файла path.
+//
+// В случае ошибки возвращает цепочку из [ErrCertRead] или [ErrCertParse] и описания ошибки.
+func (s *TrustStore) AddRootsFile(path string) error {
+	certs, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCertRead, err)
+	}
+	return s.AddRoots(certs)
+}
+
+// AddIntermediates - добавляет промежуточные сертификаты (например, сертификаты аккредитованных УЦ),
+// если они не передаются в составе подписи. Формат - см [TrustStore.AddRoots].
+// Промежуточные сертификаты сами по себе не являются доверенными:
+// они должны быть подписаны корневым сертификатом.
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] и описания ошибки.
+func (s *TrustStore) AddIntermediates(certs []byte) error {
+	parsed, err := parseCertificates(certs)
+	if err != nil {
+		return err
+	}
+	s.intermediates = append(s.intermediates, parsed...)
+	return nil
+}
+
+// AddIntermediatesFile - то же, что [TrustStore.AddIntermediates], но сертификаты читаются из файла path.
+//
+// В случае ошибки возвращает цепочку из [ErrCertRead] или [ErrCertParse] и описания ошибки.
+func (s *TrustStore) AddIntermediatesFile(path string) error {
+	certs, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCertRead, err)
+	}
+	return s.AddIntermediates(certs)
+}
+
+// verifyChain - проверяет цепочку сертификатов от cert до доверенного корневого сертификата в момент at.
+// Промежуточные сертификаты ищутся в extra и в хранилище. Каждый сертификат издателя, включая корневой,
+// должен быть сертификатом УЦ, см [certificate.checkIssuer].
+func (s *TrustStore) verifyChain(cert *certificate, extra []*certificate, at time.Time) error {
+	candidates := make([]*certificate, 0, len(s.roots)+len(s.intermediates)+len(extra))
+	candidates = append(append(append(candidates, s.roots...), s.intermediates...), extra...)
+
+	current := cert
+	for depth := 0; depth < maxChainLength; depth++ {
+		if !current.valid(at) {
+			return fmt.Errorf(
+				"%w: сертификат '%s' недействителен на %s",
+				ErrCertUntrusted, current.subject(), at.Format(time.RFC3339),
+			)
+		}
+		// Между издателем и сертификатом подписанта depth-1 промежуточных сертификатов
+		if depth > 0 {
+			if err := current.checkIssuer(depth - 1); err != nil {
+				return err
+			}
+		}
+		if s.isRoot(current) {
+			return nil
+		}
+		issuer := current.findIssuer(candidates)
+		if issuer == nil {
+			return fmt.Errorf(
+				"%w: не найден доверенный сертификат издателя сертификата '%s'", ErrCertUntrusted, current.subject(),
+			)
+		}
+		current = issuer
+	}
+	return fmt.Errorf("%w: превышена длина цепочки сертификатов", ErrCertUntrusted)
+}
+
+func (s *TrustStore) isRoot(cert *certificate) bool {
+	for _, root := range s.roots {
+		if bytes.Equal(root.raw, cert.raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier - проверка электронной подписи CMS (PKCS#7) по ГОСТ Р 34.10-2012 (256 бит),
+// например, подписей файлов ответа ведомства, см [apipgu.Client.AttachmentDownloadVerified].
+//
+// Проверяются:
+//   - соответствие хэш-кода данных атрибуту messageDigest
+//   - подпись ГОСТ Р 34.10-2012 каждого подписанта
+//   - цепочка сертификатов подписанта до доверенного корневого сертификата [TrustStore]:
+//     сертификаты издателей должны быть сертификатами УЦ (basicConstraints cA, keyUsage keyCertSign)
+//     с соблюдением ограничения длины цепочки pathLenConstraint
+//   - срок действия сертификатов на текущее время либо на время, заданное [Verifier.WithTime]
+//
+// Время подписания (атрибут signingTime) указывает сам подписант, поэтому оно не используется
+// для проверки срока действия сертификатов и только возвращается в [SignerInfo].
+// Проверка отзыва сертификатов (CRL, OCSP) не выполняется.
+// Поддерживаются подписи в DER, PEM и base64 (как их формирует КриптоПро).
+//
+// [apipgu.Client.AttachmentDownloadVerified]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu#Client.AttachmentDownloadVerified
+type Verifier struct {
+	trust *TrustStore
+	at    time.Time
+}
+
+// NewVerifier - конструктор [Verifier] с хранилищем доверенных сертификатов trust.
+func NewVerifier(trust *TrustStore) *Verifier {
+	return &Verifier{trust: trust}
+}
+
+// WithTime - проверять срок действия сертификатов на время at вместо текущего времени,
+// например на время из доверенной метки времени или время получения файла.
+func (v *Verifier) WithTime(at time.Time) *Verifier {
+	v.at = at
+	return v
+}
+
+// VerifyDetached - проверяет отсоединенную подпись sig данных data
+// и возвращает сведения о подписанте. Если подписантов несколько, проверяются все,
+// а возвращаются сведения о первом.
+//
+// В случае ошибки возвращает цепочку из одной из ошибок и описания ошибки:
+//   - [ErrCMSParse] - ошибка разбора подписи
+//   - [ErrSignatureInvalid] - подпись не соответствует данным
+//   - [ErrCertUntrusted] - сертификат подписанта не прошел проверку
+func (v *Verifier) VerifyDetached(data, sig []byte) (*SignerInfo, error) {
+	sd, err := parseSignedData(sig)
+	if err != nil {
+		return nil, err
+	}
+	return v.verify(sd, data)
+}
+
+// VerifyAttached - проверяет присоединенную подпись sig и возвращает подписанные данные
+// и сведения о подписанте, см [Verifier.VerifyDetached].
+//
+// В случае ошибки возвращает цепочку из [ErrCMSParse], [ErrSignatureInvalid] или [ErrCertUntrusted]
+// и описания ошибки.
+func (v *Verifier) VerifyAttached(sig []byte) ([]byte, *SignerInfo, error) {
+	sd, err := parseSignedData(sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Поле eContent [0] EXPLICIT разбирается без вложенного OCTET STRING
+	if len(sd.ContentInfo.Content.FullBytes) == 0 {
+		return nil, nil, fmt.Errorf("%w: подпись не содержит подписанных данных", ErrCMSParse)
+	}
+	var data []byte
+	if err = unmarshalAll(sd.ContentInfo.Content.Bytes, &data); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrCMSParse, err)
+	}
+	info, err := v.verify(sd, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, info, nil
+}
+
+func (v *Verifier) verify(sd *cmsSignedData, data []byte) (*SignerInfo, error) {
+	certs, err := parseCertificateSet(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(sd.SignerInfos) == 0 {
+		return nil, fmt.Errorf("%w: нет подписантов", ErrCMSParse)
+	}
+
+	var first *SignerInfo
+	for i := range sd.SignerInfos {
+		info, err := v.verifySigner(&sd.SignerInfos[i], sd.ContentInfo.ContentType, data, certs)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = info
+		}
+	}
+	return first, nil
+}
+
+// verifySigner - проверяет подпись одного подписанта si.
+func (v *Verifier) verifySigner(
+	si *cmsSignerInfo,
+	contentType asn1.ObjectIdentifier,
+	data []byte,
+	certs []*certificate,
+) (*SignerInfo, error) {
+	if !si.DigestAlgorithm.Algorithm.Equal(oidGOST3411256) {
+		return nil, fmt.Errorf(
+			"%w: неподдерживаемый алгоритм хэширования '%s'", ErrSignatureInvalid, si.DigestAlgorithm.Algorithm,
+		)
+	}
+	if alg := si.SignatureAlgorithm.Algorithm; !alg.Equal(oidGOST2012256) && !alg.Equal(oidGOST2012256Sign) {
+		return nil, fmt.Errorf("%w: неподдерживаемый алгоритм подписи '%s'", ErrSignatureInvalid, alg)
+	}
+
+	cert := findSigner(si.SID, certs)
+	if cert == nil {
+		return nil, fmt.Errorf("%w: сертификат подписанта не найден в подписи", ErrCertUntrusted)
+	}
+
+	digest := gost3411.Sum256(data)
+	signed := data
+	var signingTime time.Time
+	if len(si.SignedAttrs.FullBytes) > 0 {
+		// Подписываются атрибуты в DER с тегом SET (RFC 5652, п. 5.4)
+		signed = append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+		attrs, err := parseSignedAttrs(signed)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(attrs.messageDigest, digest[:]) {
+			return nil, fmt.Errorf("%w: хэш-код данных не совпадает с атрибутом messageDigest", ErrSignatureInvalid)
+		}
+		if !attrs.contentType.Equal(contentType) {
+			return nil, fmt.Errorf("%w: атрибут contentType не совпадает с типом данных", ErrSignatureInvalid)
+		}
+		signingTime = attrs.signingTime
+	}
+
+	pub, err := cert.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	signedDigest := gost3411.Sum256(signed)
+	if err = pub.VerifyDigest(signedDigest[:], si.Signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	}
+
+	at := v.at
+	if at.IsZero() {
+		at = nowFunc()
+	}
+	if err = v.trust.verifyChain(cert, certs, at); err != nil {
+		return nil, err
+	}
+
+	info := cert.signerInfo()
+	info.SigningTime = signingTime
+	return info, nil
+}
+
+// cmsSignedAttrs - подписанные атрибуты, используемые при проверке подписи.
+type cmsSignedAttrs struct {
+	contentType   asn1.ObjectIdentifier
+	messageDigest []byte
+	signingTime   time.Time
+}
+
+// parseSignedAttrs - разбирает подписанные атрибуты в DER с тегом SET.
+func parseSignedAttrs(der []byte) (*cmsSignedAttrs, error) {
+	var attrs []cmsAttribute
+	if _, err := asn1.UnmarshalWithParams(der, &attrs, "set"); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSParse, err)
+	}
+	result := &cmsSignedAttrs{}
+	for _, attr := range attrs {
+		var err error
+		switch {
+		case attr.Type.Equal(oidCMSContentType):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &result.contentType)
+		case attr.Type.Equal(oidCMSMessageDigest):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &result.messageDigest)
+		case attr.Type.Equal(oidCMSSigningTime):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &result.signingTime)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: атрибут '%s': %w", ErrCMSParse, attr.Type, err)
+		}
+	}
+	if result.contentType == nil || result.messageDigest == nil {
+		return nil, fmt.Errorf("%w: нет обязательных атрибутов contentType и messageDigest", ErrCMSParse)
+	}
+	return result, nil
+}
+
+// parseSignedData - разбирает подпись CMS в DER, PEM или base64.
+func parseSignedData(sig []byte) (*cmsSignedData, error) {
+	der := cmsDER(sig)
+	var info cmsContentInfo
+	if err := unmarshalAll(der, &info); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSParse, err)
+	}
+	if !info.ContentType.Equal(oidCMSSignedData) {
+		return nil, fmt.Errorf("%w: неподдерживаемый тип содержимого '%s'", ErrCMSParse, info.ContentType)
+	}
+	var sd cmsSignedData
+	if err := unmarshalAll(info.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCMSParse, err)
+	}
+	return &sd, nil
+}
+
+// cmsDER - возвращает подпись в DER из DER, PEM или base64.
+func cmsDER(sig []byte) []byte {
+	if len(sig) > 0 && sig[0] == 0x30 {
+		return sig
+	}
+	if block, _ := pem.Decode(sig); block != nil {
+		return block.Bytes
+	}
+	clean := bytes.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, sig)
+	if der, err := base64.StdEncoding.DecodeString(string(clean)); err == nil {
+		return der
+	}
+	return sig
+}
+
+// findSigner - возвращает сертификат подписанта по идентификатору sid.
+func findSigner(sid asn1.RawValue, certs []*certificate) *certificate {
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		for _, cert := range certs {
+			if keyID := cert.subjectKeyID(); keyID != nil && bytes.Equal(keyID, sid.Bytes) {
+				return cert
+			}
+		}
+		return nil
+	}
+	var ias cmsIssuerAndSerial
+	if unmarshalAll(sid.FullBytes, &ias) != nil {
+		return nil
+	}
+	for _, cert := range certs {
+		if bytes.Equal(cert.tbs.Issuer.FullBytes, ias.Issuer.FullBytes) &&
+			bytes.Equal(cert.tbs.Serial.FullBytes, ias.Serial.FullBytes) {
+			return cert
+		}
+	}
+	return nil
+}
+
+// certificate - разобранный сертификат X.509.
+type certificate struct {
+	raw    []byte
+	tbsRaw []byte
+	tbs    *tbsCertificate
+	sigAlg asn1.ObjectIdentifier
+	sig    []byte
+}
+
+// parseCertificate - разбирает сертификат der.
+func parseCertificate(der []byte) (*certificate, error) {
+	var cert struct {
+		TBS       asn1.RawValue
+		Algorithm cmsAlgorithm
+		Signature asn1.BitString
+	}
+	if err := unmarshalAll(der, &cert); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	var tbs tbsCertificate
+	if err := unmarshalAll(cert.TBS.FullBytes, &tbs); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	return &certificate{
+		raw:    der,
+		tbsRaw: cert.TBS.FullBytes,
+		tbs:    &tbs,
+		sigAlg: cert.Algorithm.Algorithm,
+		sig:    cert.Signature.Bytes,
+	}, nil
+}
+
+// parseCertificates - разбирает один сертификат в DER либо один или несколько сертификатов в PEM.
+func parseCertificates(data []byte) ([]*certificate, error) {
+	if block, _ := pem.Decode(data); block == nil {
+		der, err := certDER(data)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := parseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return []*certificate{cert}, nil
+	}
+
+	var certs []*certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := parseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%w: нет PEM-блоков CERTIFICATE", ErrCertParse)
+	}
+	return certs, nil
+}
+
+// parseCertificateSet - разбирает содержимое поля certificates подписи CMS.
+// Элементы, не являющиеся сертификатами X.509, пропускаются.
+func parseCertificateSet(data []byte) ([]*certificate, error) {
+	var certs []*certificate
+	for len(data) > 0 {
+		var raw asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCMSParse, err)
+		}
+		data = rest
+		if raw.Class != asn1.ClassUniversal || raw.Tag != asn1.TagSequence {
+			continue
+		}
+		cert, err := parseCertificate(raw.FullBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCMSParse, err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// valid - проверяет срок действия сертификата в момент at.
+func (c *certificate) valid(at time.Time) bool {
+	return !at.Before(c.tbs.Validity.NotBefore) && !at.After(c.tbs.Validity.NotAfter)
+}
+
+// publicKey - возвращает открытый ключ ГОСТ Р 34.10-2012 (256 бит) сертификата.
+func (c *certificate) publicKey() (*gost3410.PublicKey, error) {
+	alg := c.tbs.PublicKey.Algorithm
+	if !alg.Algorithm.Equal(oidGOST2012256) {
+		return nil, fmt.Errorf(
+			"%w: неподдерживаемый алгоритм открытого ключа '%s' сертификата '%s'",
+			ErrCertUntrusted, alg.Algorithm, c.subject(),
+		)
+	}
+	var params gostKeyParams
+	if _, err := asn1.Unmarshal(alg.Params.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	curve := gost3410.CurveByOID(params.PublicKeyParamSet)
+	if curve == nil {
+		return nil, fmt.Errorf(
+			"%w: неподдерживаемый набор параметров '%s' сертификата '%s'",
+			ErrCertUntrusted, params.PublicKeyParamSet, c.subject(),
+		)
+	}
+	raw, err := certPublicKey(c.raw)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := gost3410.NewPublicKeyRaw(curve, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	return pub, nil
+}
+
+// findIssuer - возвращает сертификат из candidates, которым подписан сертификат, либо nil.
+func (c *certificate) findIssuer(candidates []*certificate) *certificate {
+	if !c.sigAlg.Equal(oidGOST2012256Sign) {
+		return nil
+	}
+	digest := gost3411.Sum256(c.tbsRaw)
+	for _, candidate := range candidates {
+		if bytes.Equal(candidate.raw, c.raw) ||
+			!bytes.Equal(candidate.tbs.Subject.FullBytes, c.tbs.Issuer.FullBytes) {
+			continue
+		}
+		pub, err := candidate.publicKey()
+		if err != nil {
+			continue
+		}
+		if pub.VerifyDigest(digest[:], c.sig) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// checkIssuer - проверяет, что сертификат может быть издателем в цепочке, где за ним следуют
+// intermediates промежуточных сертификатов: расширение BasicConstraints с признаком cA
+// и ограничением длины пути pathLenConstraint, расширение KeyUsage с битом keyCertSign.
+//
+// В случае ошибки возвращает цепочку из [ErrCertUntrusted] и описания ошибки.
+func (c *certificate) checkIssuer(intermediates int) error {
+	var (
+		constraints struct {
+			IsCA       bool `asn1:"optional"`
+			MaxPathLen int  `asn1:"optional,default:-1"`
+		}
+		usage                    asn1.BitString
+		hasConstraints, hasUsage bool
+	)
+	for _, ext := range c.tbs.Extensions {
+		switch {
+		case ext.ID.Equal(oidBasicConstraints):
+			hasConstraints = unmarshalAll(ext.Value, &constraints) == nil
+		case ext.ID.Equal(oidKeyUsage):
+			hasUsage = unmarshalAll(ext.Value, &usage) == nil
+		}
+	}
+	switch {
+	case !hasConstraints || !constraints.IsCA:
+		return fmt.Errorf("%w: сертификат '%s' не является сертификатом УЦ", ErrCertUntrusted, c.subject())
+	case !hasUsage || usage.At(keyUsageCertSign) == 0:
+		return fmt.Errorf("%w: сертификат '%s' не предназначен для подписи сертификатов", ErrCertUntrusted, c.subject())
+	case constraints.MaxPathLen >= 0 && intermediates > constraints.MaxPathLen:
+		return fmt.Errorf(
+			"%w: превышено ограничение длины цепочки (pathLenConstraint=%d) сертификата '%s'",
+			ErrCertUntrusted, constraints.MaxPathLen, c.subject(),
+		)
+	}
+	return nil
+}
+
+// subjectKeyID - возвращает значение расширения SubjectKeyIdentifier либо nil.
+func (c *certificate) subjectKeyID() []byte {
+	for _, ext := range c.tbs.Extensions {
+		if ext.ID.Equal(oidSubjectKeyID) {
+			var keyID []byte
+			if unmarshalAll(ext.Value, &keyID) == nil {
+				return keyID
+			}
+		}
+	}
+	return nil
+}
+
+// subject - возвращает имя владельца сертификата.
+func (c *certificate) subject() string {
+	var rdn pkix.RDNSequence
+	if unmarshalAll(c.tbs.Subject.FullBytes, &rdn) != nil {
+		return ""
+	}
+	return rdn.String()
+}
+
+// signerInfo - возвращает сведения о владельце сертификата.
+func (c *certificate) signerInfo() *SignerInfo {
+	info := &SignerInfo{Subject: c.subject(), Certificate: c.raw}
+	var rdn pkix.RDNSequence
+	if unmarshalAll(c.tbs.Subject.FullBytes, &rdn) != nil {
+		return info
+	}
+	fields := []struct {
+		oid   asn1.ObjectIdentifier
+		value *string
+	}{
+		{oidCommonName, &info.CommonName},
+		{oidOrganization, &info.Organization},
+		{oidOGRN, &info.OGRN},
+		{oidOGRNIP, &info.OGRNIP},
+		{oidINN, &info.INN},
+		{oidINNLE, &info.INNLE},
+		{oidSNILS, &info.SNILS},
+	}
+	for _, set := range rdn {
+		for _, attr := range set {
+			value, ok := attr.Value.(string)
+			if !ok {
+				continue
+			}
+			for _, field := range fields {
+				if attr.Type.Equal(field.oid) {
+					*field.value = value
+				}
+			}
+		}
+	}
+	return info
+}
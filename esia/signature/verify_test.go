@@ -0,0 +1,440 @@
+package signature
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost3410"
+	"github.com/ofstudio/go-api-epgu/internal/gost3411"
+)
+
+type suiteVerifier struct {
+	suite.Suite
+	now     time.Time
+	rootKey *gost3410.PrivateKey
+	root    []byte
+	signer  []byte
+	sig     []byte
+}
+
+func TestVerifier(t *testing.T) {
+	suite.Run(t, new(suiteVerifier))
+}
+
+func (suite *suiteVerifier) SetupTest() {
+	suite.now = time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return suite.now }
+
+	var err error
+	suite.rootKey, err = gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(1234567))
+	suite.Require().NoError(err)
+	rootName := testName(suite.T(), "Тестовый корневой УЦ", "")
+	suite.root = testIssueCertificate(
+		suite.T(), rootName, rootName, suite.rootKey, suite.rootKey.PublicKey().Raw(), 1, testCAExtensions(suite.T(), -1)...,
+	)
+
+	// Сертификат подписанта выпущен корневым УЦ, ключ - testGOSTKeyPEM
+	signerName := testName(suite.T(), "ООО Ведомство", "1027700000000")
+	suite.signer = testIssueCertificate(suite.T(), signerName, rootName, suite.rootKey, suite.pub(), 2)
+	suite.sig = suite.signDetached(suite.signer, []byte(testDataToSign))
+}
+
+func (suite *suiteVerifier) TearDownTest() {
+	nowFunc = time.Now
+}
+
+func (suite *suiteVerifier) TestVerifyDetached() {
+	suite.Run("success", func() {
+		info, err := suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), suite.sig)
+		suite.Require().NoError(err)
+		suite.Equal("ООО Ведомство", info.CommonName)
+		suite.Equal("ООО Ведомство", info.Organization)
+		suite.Equal("1027700000000", info.OGRN)
+		suite.Equal("7700000000", info.INNLE)
+		suite.Contains(info.Subject, "CN=ООО Ведомство")
+		suite.True(suite.now.Equal(info.SigningTime))
+		suite.Equal(suite.signer, info.Certificate)
+	})
+
+	suite.Run("pem and base64", func() {
+		v := suite.verifier(suite.root)
+		_, err := v.VerifyDetached([]byte(testDataToSign), pem.EncodeToMemory(&pem.Block{Type: "CMS", Bytes: suite.sig}))
+		suite.NoError(err)
+		encoded := base64.StdEncoding.EncodeToString(suite.sig)
+		_, err = v.VerifyDetached([]byte(testDataToSign), []byte(encoded[:64]+"\r\n"+encoded[64:]))
+		suite.NoError(err)
+	})
+
+	suite.Run("intermediate", func() {
+		intermediateKey, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(7654321))
+		suite.Require().NoError(err)
+		rootName := testName(suite.T(), "Тестовый корневой УЦ", "")
+		intermediateName := testName(suite.T(), "Тестовый УЦ", "")
+		intermediate := testIssueCertificate(
+			suite.T(), intermediateName, rootName, suite.rootKey, intermediateKey.PublicKey().Raw(), 3,
+			testCAExtensions(suite.T(), 0)...,
+		)
+		signer := testIssueCertificate(
+			suite.T(), testName(suite.T(), "ООО Ведомство", "1027700000000"), intermediateName, intermediateKey, suite.pub(), 4,
+		)
+		sig := suite.signDetached(signer, []byte(testDataToSign))
+
+		_, err = suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+
+		trust := NewTrustStore()
+		suite.Require().NoError(trust.AddRoots(suite.root))
+		suite.Require().NoError(trust.AddIntermediates(intermediate))
+		info, err := NewVerifier(trust).VerifyDetached([]byte(testDataToSign), sig)
+		suite.Require().NoError(err)
+		suite.Equal("1027700000000", info.OGRN)
+
+		// Промежуточный сертификат не является доверенным сам по себе
+		trust = NewTrustStore()
+		suite.Require().NoError(trust.AddRoots(suite.signer))
+		suite.Require().NoError(trust.AddIntermediates(intermediate))
+		_, err = NewVerifier(trust).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+	})
+
+	suite.Run("error leaf as issuer", func() {
+		// Сертификат конечного пользователя, выпущенный доверенным УЦ, выпускает сертификат "УЦ"
+		leafKey, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(1111111))
+		suite.Require().NoError(err)
+		fakeKey, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(2222222))
+		suite.Require().NoError(err)
+		rootName := testName(suite.T(), "Тестовый корневой УЦ", "")
+		leafName := testName(suite.T(), "ООО Пользователь", "1027700000001")
+		fakeName := testName(suite.T(), "Поддельный УЦ", "")
+		leaf := testIssueCertificate(suite.T(), leafName, rootName, suite.rootKey, leafKey.PublicKey().Raw(), 5)
+		fake := testIssueCertificate(
+			suite.T(), fakeName, leafName, leafKey, fakeKey.PublicKey().Raw(), 6, testCAExtensions(suite.T(), -1)...,
+		)
+		signer := testIssueCertificate(
+			suite.T(), testName(suite.T(), "ООО Ведомство", "1027700000000"), fakeName, fakeKey, suite.pub(), 7,
+		)
+		// Сертификаты цепочки передаются в составе подписи
+		sig := suite.modify(suite.signDetached(signer, []byte(testDataToSign)), func(sd *cmsSignedData) {
+			sd.Certificates = asn1.RawValue{
+				Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true,
+				Bytes: append(append(append([]byte{}, sd.Certificates.Bytes...), fake...), leaf...),
+			}
+		})
+		_, err = suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+		suite.ErrorContains(err, "ООО Пользователь")
+		suite.ErrorContains(err, "не является сертификатом УЦ")
+	})
+
+	suite.Run("error issuer without keyCertSign", func() {
+		caKey, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(3333333))
+		suite.Require().NoError(err)
+		rootName := testName(suite.T(), "Тестовый корневой УЦ", "")
+		caName := testName(suite.T(), "Тестовый УЦ", "")
+		constraints := testCAExtensions(suite.T(), -1)[:1]
+		ca := testIssueCertificate(suite.T(), caName, rootName, suite.rootKey, caKey.PublicKey().Raw(), 8, constraints...)
+		signer := testIssueCertificate(
+			suite.T(), testName(suite.T(), "ООО Ведомство", "1027700000000"), caName, caKey, suite.pub(), 9,
+		)
+		trust := NewTrustStore()
+		suite.Require().NoError(trust.AddRoots(suite.root))
+		suite.Require().NoError(trust.AddIntermediates(ca))
+		_, err = NewVerifier(trust).VerifyDetached([]byte(testDataToSign), suite.signDetached(signer, []byte(testDataToSign)))
+		suite.ErrorIs(err, ErrCertUntrusted)
+		suite.ErrorContains(err, "не предназначен для подписи сертификатов")
+	})
+
+	suite.Run("error path length", func() {
+		// Корневой УЦ с pathLenConstraint=0 не может выпускать промежуточные УЦ
+		rootKey, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(4444444))
+		suite.Require().NoError(err)
+		caKey, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(5555555))
+		suite.Require().NoError(err)
+		rootName := testName(suite.T(), "Корневой УЦ без промежуточных", "")
+		caName := testName(suite.T(), "Тестовый УЦ", "")
+		root := testIssueCertificate(
+			suite.T(), rootName, rootName, rootKey, rootKey.PublicKey().Raw(), 10, testCAExtensions(suite.T(), 0)...,
+		)
+		ca := testIssueCertificate(
+			suite.T(), caName, rootName, rootKey, caKey.PublicKey().Raw(), 11, testCAExtensions(suite.T(), -1)...,
+		)
+		signer := testIssueCertificate(
+			suite.T(), testName(suite.T(), "ООО Ведомство", "1027700000000"), caName, caKey, suite.pub(), 12,
+		)
+		sig := suite.signDetached(signer, []byte(testDataToSign))
+
+		trust := NewTrustStore()
+		suite.Require().NoError(trust.AddRoots(root))
+		suite.Require().NoError(trust.AddIntermediates(ca))
+		_, err = NewVerifier(trust).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+		suite.ErrorContains(err, "pathLenConstraint=0")
+
+		// Сертификат, выпущенный корневым УЦ напрямую, принимается
+		direct := testIssueCertificate(
+			suite.T(), testName(suite.T(), "ООО Ведомство", "1027700000000"), rootName, rootKey, suite.pub(), 13,
+		)
+		_, err = NewVerifier(trust).VerifyDetached([]byte(testDataToSign), suite.signDetached(direct, []byte(testDataToSign)))
+		suite.NoError(err)
+	})
+
+	suite.Run("error other data", func() {
+		info, err := suite.verifier(suite.root).VerifyDetached([]byte("other data"), suite.sig)
+		suite.ErrorIs(err, ErrSignatureInvalid)
+		suite.Nil(info)
+	})
+
+	suite.Run("error signature", func() {
+		sig := suite.modify(suite.sig, func(sd *cmsSignedData) {
+			sd.SignerInfos[0].Signature[0] ^= 0xff
+		})
+		_, err := suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrSignatureInvalid)
+	})
+
+	suite.Run("error untrusted", func() {
+		other, err := gost3410.NewPrivateKeyInt(gost3410.CurveCryptoProA, big.NewInt(42))
+		suite.Require().NoError(err)
+		name := testName(suite.T(), "Тестовый корневой УЦ", "")
+		root := testIssueCertificate(suite.T(), name, name, other, other.PublicKey().Raw(), 1, testCAExtensions(suite.T(), -1)...)
+		_, err = suite.verifier(root).VerifyDetached([]byte(testDataToSign), suite.sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+
+		_, err = NewVerifier(NewTrustStore()).VerifyDetached([]byte(testDataToSign), suite.sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+	})
+
+	suite.Run("error expired", func() {
+		suite.now = testNotAfter.Add(time.Hour)
+		sig := suite.signDetached(suite.signer, []byte(testDataToSign))
+		_, err := suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+		suite.ErrorContains(err, "недействителен")
+		suite.now = time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	})
+
+	suite.Run("error backdated signing time", func() {
+		// Подпись сформирована до окончания срока действия сертификата, проверяется после
+		sig := suite.signDetached(suite.signer, []byte(testDataToSign))
+		suite.now = testNotAfter.Add(time.Hour)
+		info, err := suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+		suite.ErrorContains(err, "недействителен")
+		suite.Nil(info)
+
+		signingTime := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+		info, err = suite.verifier(suite.root).WithTime(signingTime).VerifyDetached([]byte(testDataToSign), sig)
+		suite.Require().NoError(err)
+		suite.True(signingTime.Equal(info.SigningTime))
+		suite.now = signingTime
+	})
+
+	suite.Run("error no certificate", func() {
+		sig := suite.modify(suite.sig, func(sd *cmsSignedData) {
+			sd.Certificates = asn1.RawValue{}
+		})
+		_, err := suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+		suite.ErrorIs(err, ErrCertUntrusted)
+	})
+
+	suite.Run("error parse", func() {
+		for _, sig := range [][]byte{nil, []byte("test"), {0x30, 0x00}, suite.root} {
+			_, err := suite.verifier(suite.root).VerifyDetached([]byte(testDataToSign), sig)
+			suite.ErrorIs(err, ErrCMSParse)
+		}
+	})
+}
+
+func (suite *suiteVerifier) TestVerifyAttached() {
+	suite.Run("success", func() {
+		sig := suite.modify(suite.sig, func(sd *cmsSignedData) {
+			content, err := asn1.Marshal([]byte(testDataToSign))
+			suite.Require().NoError(err)
+			sd.ContentInfo.Content = asn1.RawValue{
+				Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content,
+			}
+		})
+		data, info, err := suite.verifier(suite.root).VerifyAttached(sig)
+		suite.Require().NoError(err)
+		suite.Equal(testDataToSign, string(data))
+		suite.Equal("1027700000000", info.OGRN)
+	})
+
+	suite.Run("error detached", func() {
+		data, info, err := suite.verifier(suite.root).VerifyAttached(suite.sig)
+		suite.ErrorIs(err, ErrCMSParse)
+		suite.Nil(data)
+		suite.Nil(info)
+	})
+}
+
+func (suite *suiteVerifier) TestTrustStore() {
+	suite.Run("pem bundle file", func() {
+		bundle := append(
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: suite.root}),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: suite.signer})...,
+		)
+		path := filepath.Join(suite.T().TempDir(), "roots.pem")
+		suite.Require().NoError(os.WriteFile(path, bundle, 0600))
+		trust := NewTrustStore()
+		suite.Require().NoError(trust.AddRootsFile(path))
+		suite.Len(trust.roots, 2)
+		suite.Require().NoError(trust.AddIntermediatesFile(path))
+		suite.Len(trust.intermediates, 2)
+	})
+
+	suite.Run("error", func() {
+		trust := NewTrustStore()
+		suite.ErrorIs(trust.AddRoots([]byte("test")), ErrCertParse)
+		suite.ErrorIs(trust.AddRoots(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY"})), ErrCertParse)
+		suite.ErrorIs(trust.AddIntermediates(nil), ErrCertParse)
+		suite.ErrorIs(trust.AddRootsFile(filepath.Join(suite.T().TempDir(), "none.pem")), ErrCertRead)
+		suite.ErrorIs(trust.AddIntermediatesFile(filepath.Join(suite.T().TempDir(), "none.pem")), ErrCertRead)
+	})
+}
+
+// verifier - возвращает [Verifier] с корневым сертификатом root.
+func (suite *suiteVerifier) verifier(root []byte) *Verifier {
+	trust := NewTrustStore()
+	suite.Require().NoError(trust.AddRoots(root))
+	return NewVerifier(trust)
+}
+
+// signDetached - возвращает подпись data ключом testGOSTKeyPEM с сертификатом cert.
+func (suite *suiteVerifier) signDetached(cert, data []byte) []byte {
+	signer, err := NewGOST([]byte(testGOSTKeyPEM), "")
+	suite.Require().NoError(err)
+	c, err := NewCMS(signer, cert)
+	suite.Require().NoError(err)
+	sig, err := c.SignDetached(data)
+	suite.Require().NoError(err)
+	return sig
+}
+
+// modify - возвращает подпись sig, измененную функцией f.
+func (suite *suiteVerifier) modify(sig []byte, f func(sd *cmsSignedData)) []byte {
+	sd, err := parseSignedData(sig)
+	suite.Require().NoError(err)
+	f(sd)
+	content, err := asn1.Marshal(*sd)
+	suite.Require().NoError(err)
+	info, err := asn1.Marshal(cmsContentInfo{
+		ContentType: oidCMSSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	})
+	suite.Require().NoError(err)
+	return info
+}
+
+func (suite *suiteVerifier) pub() []byte {
+	pub, _ := hex.DecodeString(testGOSTPublicKey)
+	return pub
+}
+
+// testName - возвращает имя в DER с общим именем и наименованием организации name
+// и, если задан, ОГРН ogrn и ИНН ЮЛ.
+func testName(t *testing.T, name, ogrn string) []byte {
+	rdn := pkix.RDNSequence{
+		{{Type: oidCommonName, Value: name}},
+		{{Type: oidOrganization, Value: name}},
+	}
+	if ogrn != "" {
+		numeric := func(s string) asn1.RawValue {
+			return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagNumericString, Bytes: []byte(s)}
+		}
+		rdn = append(rdn,
+			pkix.RelativeDistinguishedNameSET{{Type: oidOGRN, Value: numeric(ogrn)}},
+			pkix.RelativeDistinguishedNameSET{{Type: oidINNLE, Value: numeric("7700000000")}},
+		)
+	}
+	der, err := asn1.Marshal(rdn)
+	require.NoError(t, err)
+	return der
+}
+
+// testCAExtensions - возвращает расширения сертификата УЦ: BasicConstraints с признаком cA
+// и ограничением длины пути pathLen (-1 - без ограничения) и KeyUsage с битами keyCertSign и cRLSign.
+func testCAExtensions(t *testing.T, pathLen int) []certExtension {
+	constraints, err := asn1.Marshal(struct {
+		IsCA       bool
+		MaxPathLen int `asn1:"optional,default:-1"`
+	}{IsCA: true, MaxPathLen: pathLen})
+	require.NoError(t, err)
+	usage, err := asn1.Marshal(asn1.BitString{Bytes: []byte{0x06}, BitLength: 7})
+	require.NoError(t, err)
+	return []certExtension{
+		{ID: oidBasicConstraints, Critical: true, Value: constraints},
+		{ID: oidKeyUsage, Critical: true, Value: usage},
+	}
+}
+
+// testIssueCertificate - возвращает сертификат X.509 в DER с открытым ключом pub (LE(x) || LE(y))
+// и расширениями exts, подписанный ключом issuerKey, со сроком действия [testNotBefore, testNotAfter].
+func testIssueCertificate(
+	t *testing.T,
+	subject, issuer []byte,
+	issuerKey *gost3410.PrivateKey,
+	pub []byte,
+	serial int,
+	exts ...certExtension,
+) []byte {
+	type algorithm struct {
+		Algorithm asn1.ObjectIdentifier
+		Params    gostKeyParams `asn1:"optional"`
+	}
+	key, err := asn1.Marshal(pub)
+	require.NoError(t, err)
+
+	sigAlg := algorithm{Algorithm: oidGOST2012256Sign}
+	tbs := struct {
+		Version   int `asn1:"explicit,tag:0"`
+		Serial    int
+		Algorithm algorithm
+		Issuer    asn1.RawValue
+		Validity  struct{ NotBefore, NotAfter time.Time }
+		Subject   asn1.RawValue
+		PublicKey struct {
+			Algorithm algorithm
+			Key       asn1.BitString
+		}
+		Extensions []certExtension `asn1:"optional,explicit,tag:3"`
+	}{Version: 2, Serial: serial, Algorithm: sigAlg, Extensions: exts}
+	tbs.Issuer = asn1.RawValue{FullBytes: issuer}
+	tbs.Validity.NotBefore, tbs.Validity.NotAfter = testNotBefore, testNotAfter
+	tbs.Subject = asn1.RawValue{FullBytes: subject}
+	tbs.PublicKey.Algorithm = algorithm{
+		Algorithm: oidGOST2012256,
+		Params:    gostKeyParams{PublicKeyParamSet: gost3410.OIDCryptoProA},
+	}
+	tbs.PublicKey.Key = asn1.BitString{Bytes: key, BitLength: len(key) * 8}
+	tbsDER, err := asn1.Marshal(tbs)
+	require.NoError(t, err)
+
+	digest := gost3411.Sum256(tbsDER)
+	sig, err := issuerKey.SignDigest(digest[:], rand.Reader)
+	require.NoError(t, err)
+
+	der, err := asn1.Marshal(struct {
+		TBS       asn1.RawValue
+		Algorithm algorithm
+		Signature asn1.BitString
+	}{
+		TBS:       asn1.RawValue{FullBytes: tbsDER},
+		Algorithm: sigAlg,
+		Signature: asn1.BitString{Bytes: sig, BitLength: len(sig) * 8},
+	})
+	require.NoError(t, err)
+	return der
+}
@@ -7,6 +7,8 @@
 //  3. Получение авторизационного кода из параметров обратного вызова на redirect_uri
 //  4. Обмен авторизационного кода на маркер доступа (/oauth2/v3/te)
 //
+// Те же шаги без изменения кода выполняет команда login утилиты [github.com/ofstudio/go-api-epgu/cmd/esia].
+//
 // # Требования
 //  1. Информационная система должна быть зарегистрирована на
 //     Технологическом портале ЕСИА: продуктовом или тестовом (SVCDEV)
@@ -54,6 +56,10 @@ const (
 	redirectURI = "http://localhost:8000/callback" // Адрес redirect_uri на стороне потребителя
 )
 
+// scope - запрашиваемые скоупы.
+// Должны совпадать при создании ссылки (шаг 1) и при обмене кода на маркер доступа (шаг 4).
+var scope = aas.NewScopes(aas.ScopeOpenID)
+
 // Параметры КриптоПро для signature.LocalCryptoPro.
 //
 // Ссылка на страницу предоставления прав доступа ЕСИА должна содержать параметры
@@ -94,6 +100,7 @@ const (
 	//		cpverify -mk <path/to/cert.cer> -alg GR3411_2012_256
 	// Команда выведет хеш сертификата:
 	//		1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF0
+	// Либо рассчитайте хеш по файлу сертификата: signature.CertHashFromFile("path/to/cert.cer")
 	certHash = "<< хеш сертификата >>"
 )
 
@@ -104,17 +111,21 @@ const (
 // Подробнее см "Методические рекомендации по интеграции с REST API Цифрового профиля",
 // раздел "Структура JSON-объекта параметра permissions".
 //
+// То же самое можно получить с помощью конструктора:
+//
+//	aas.NewPermissions(aas.NewAPIPGUPermission("<< название организации в ЕСИА >>"))
+//
 // ВАЖНО: значения полей вида "<< поле >>" необходимо заполнить актуальными данными вашей ИС.
 var permissions = aas.Permissions{
 	{
-		ResponsibleObject: "<< название организации в ЕСИА >>",                      // Ответственный объект (название организации)
-		Sysname:           "APIPGU",                                                 // Тип согласия
-		Expire:            525600,                                                   // 1 год: макс. срок согласия данного типа
-		Actions:           []aas.PermissionAction{{Sysname: "ALL_ACTIONS_TO_DATA"}}, // Действия с данными
-		Purposes:          []aas.PermissionPurpose{{Sysname: "APIPGU"}},             // Цели согласий
+		ResponsibleObject: "<< название организации в ЕСИА >>",                                     // Ответственный объект (название организации)
+		Sysname:           aas.PermissionSysnameAPIPGU,                                             // Тип согласия
+		Expire:            aas.PermissionExpireAPIPGUMax,                                           // 1 год: макс. срок согласия данного типа
+		Actions:           []aas.PermissionAction{{Sysname: aas.PermissionActionAllActionsToData}}, // Действия с данными
+		Purposes:          []aas.PermissionPurpose{{Sysname: aas.PermissionPurposeAPIPGU}},         // Цели согласий
 		Scopes: []aas.PermissionScope{
 			// Скоуп, необходимый для работы с API Госуслуг
-			{Sysname: "http://lk.gosuslugi.ru/api-order"},
+			{Sysname: string(aas.ScopeAPIOrder)},
 			// Дополнительно, для данного типа согласия можно запросить следующие скоупы
 			//{Sysname: "snils"},
 			//{Sysname: "id_doc"},
@@ -137,7 +148,7 @@ func main() {
 
 	// === ШАГ 1 ===
 	// Создание ссылки на страницу предоставления прав доступа (/oauth2/v2/ac)
-	uri, err := oauthClient.AuthURI("openid", redirectURI, permissions)
+	uri, err := oauthClient.AuthURI(scope, redirectURI, permissions)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -161,7 +172,7 @@ func main() {
 		// === ШАГ 4 ===
 		// Обмен авторизационного кода на маркер доступа (/oauth2/v3/te)
 		message += "\n=== Обмен авторизационного кода на маркер доступа ===\n\n"
-		res, err := oauthClient.TokenExchange(code, "openid", redirectURI)
+		res, err := oauthClient.TokenExchange(code, scope, redirectURI)
 		if err != nil {
 			log.Print(err)
 			http.Error(w, message+err.Error(), http.StatusInternalServerError)
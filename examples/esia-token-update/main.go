@@ -96,6 +96,7 @@ const (
 	//		cpverify -mk <path/to/cert.cer> -alg GR3411_2012_256
 	// Команда выведет хеш сертификата:
 	//		1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF0
+	// Либо рассчитайте хеш по файлу сертификата: signature.CertHashFromFile("path/to/cert.cer")
 	certHash = "<< хеш сертификата >>"
 )
 
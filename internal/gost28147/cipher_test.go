@@ -0,0 +1,52 @@
+package gost28147
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTestGOST28147 struct {
+	suite.Suite
+}
+
+func TestGOST28147(t *testing.T) {
+	suite.Run(t, new(suiteTestGOST28147))
+}
+
+// Контрольные значения получены независимой реализацией (libgcrypt).
+func (suite *suiteTestGOST28147) TestVectors() {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	plain, _ := hex.DecodeString("0011223344556677deadbeefcafebabe")
+	tests := []struct {
+		name   string
+		sbox   *Sbox
+		cipher string
+	}{
+		{"CryptoPro-A", SboxCryptoProA, "76d54d820ed4e06f307f8b634d8263d8"},
+		{"TC26-Z", SboxTC26Z, "3587baac092b445dffa8e3c01639e5bf"},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			c, err := NewCipher(key, tt.sbox)
+			suite.Require().NoError(err)
+			out := make([]byte, len(plain))
+			for i := 0; i < len(plain); i += BlockSize {
+				c.Encrypt(out[i:], plain[i:])
+			}
+			suite.Equal(tt.cipher, hex.EncodeToString(out))
+
+			back := make([]byte, len(out))
+			for i := 0; i < len(out); i += BlockSize {
+				c.Decrypt(back[i:], out[i:])
+			}
+			suite.Equal(plain, back)
+		})
+	}
+}
+
+func (suite *suiteTestGOST28147) TestKeySize() {
+	_, err := NewCipher(make([]byte, 16), SboxTC26Z)
+	suite.ErrorIs(err, ErrKeySize)
+}
@@ -0,0 +1,309 @@
+// Package gost3410 - электронная подпись ГОСТ Р 34.10-2012 на эллиптических кривых.
+//
+// Поддерживаются ключи длиной 256 бит на кривых в форме Вейерштрасса,
+// перечисленных в [CurveByOID].
+//
+// Порядок байтов соответствует принятому в CryptoPro, OpenSSL и ЕСИА:
+//   - хэш-код интерпретируется как число, записанное начиная с младшего байта
+//   - закрытый ключ в PKCS#8 записывается начиная с младшего байта
+//   - подпись записывается как BE(s) || BE(r) (см RFC 4491, раздел 2.2.2)
+package gost3410
+
+import (
+	"encoding/asn1"
+	"math/big"
+)
+
+// Curve - параметры эллиптической кривой y^2 = x^3 + a*x + b (mod p)
+// с базовой точкой (x, y) порядка q.
+type Curve struct {
+	Name string   // Наименование набора параметров
+	P    *big.Int // Модуль
+	A    *big.Int // Коэффициент a
+	B    *big.Int // Коэффициент b
+	Q    *big.Int // Порядок базовой точки
+	X    *big.Int // Координата x базовой точки
+	Y    *big.Int // Координата y базовой точки
+}
+
+// PointSize - размер координаты точки и компоненты подписи в байтах.
+func (c *Curve) PointSize() int {
+	return (c.P.BitLen() + 7) / 8
+}
+
+// Наборы параметров эллиптических кривых.
+var (
+	// CurveTest - тестовый набор параметров из ГОСТ Р 34.10-2012, Приложение А.1.
+	// Не использовать для реальных ключей.
+	CurveTest = &Curve{
+		Name: "id-GostR3410-2001-TestParamSet",
+		P:    hexInt("8000000000000000000000000000000000000000000000000000000000000431"),
+		A:    hexInt("7"),
+		B:    hexInt("5fbff498aa938ce739b8e022fbafef40563f6e6a3472fc2a514c0ce9dae23b7e"),
+		Q:    hexInt("8000000000000000000000000000000150fe8a1892976154c59cfc193accf5b3"),
+		X:    hexInt("2"),
+		Y:    hexInt("08e2a8a0e65147d4bd6316030e16d19c85c97f0a9ca267122b96abbcea7e8fc8"),
+	}
+
+	// CurveCryptoProA - id-GostR3410-2001-CryptoPro-A-ParamSet
+	// (совпадает с id-GostR3410-2001-CryptoPro-XchA-ParamSet и id-tc26-gost-3410-2012-256-paramSetB).
+	CurveCryptoProA = &Curve{
+		Name: "id-GostR3410-2001-CryptoPro-A-ParamSet",
+		P:    hexInt("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97"),
+		A:    hexInt("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd94"),
+		B:    hexInt("a6"),
+		Q:    hexInt("ffffffffffffffffffffffffffffffff6c611070995ad10045841b09b761b893"),
+		X:    hexInt("1"),
+		Y:    hexInt("8d91e471e0989cda27df505a453f2b7635294f2ddf23e3b122acc99c9e9f1e14"),
+	}
+
+	// CurveCryptoProB - id-GostR3410-2001-CryptoPro-B-ParamSet
+	// (совпадает с id-tc26-gost-3410-2012-256-paramSetC).
+	CurveCryptoProB = &Curve{
+		Name: "id-GostR3410-2001-CryptoPro-B-ParamSet",
+		P:    hexInt("8000000000000000000000000000000000000000000000000000000000000c99"),
+		A:    hexInt("8000000000000000000000000000000000000000000000000000000000000c96"),
+		B:    hexInt("3e1af419a269a5f866a7d3c25c3df80ae979259373ff2b182f49d4ce7e1bbc8b"),
+		Q:    hexInt("800000000000000000000000000000015f700cfff1a624e5e497161bcc8a198f"),
+		X:    hexInt("1"),
+		Y:    hexInt("3fa8124359f96680b83d1c3eb2c070e5c545c9858d03ecfb744bf8d717717efc"),
+	}
+
+	// CurveCryptoProC - id-GostR3410-2001-CryptoPro-C-ParamSet
+	// (совпадает с id-GostR3410-2001-CryptoPro-XchB-ParamSet и id-tc26-gost-3410-2012-256-paramSetD).
+	CurveCryptoProC = &Curve{
+		Name: "id-GostR3410-2001-CryptoPro-C-ParamSet",
+		P:    hexInt("9b9f605f5a858107ab1ec85e6b41c8aacf846e86789051d37998f7b9022d759b"),
+		A:    hexInt("9b9f605f5a858107ab1ec85e6b41c8aacf846e86789051d37998f7b9022d7598"),
+		B:    hexInt("805a"),
+		Q:    hexInt("9b9f605f5a858107ab1ec85e6b41c8aa582ca3511eddfb74f02f3a6598980bb9"),
+		X:    hexInt("0"),
+		Y:    hexInt("41ece55743711a8c3cbf3783cd08c0ee4d4dc440d4641a8f366e550dfdb3bb67"),
+	}
+
+	// CurveTC26256A - id-tc26-gost-3410-2012-256-paramSetA
+	// (скрученная кривая Эдвардса в форме Вейерштрасса).
+	CurveTC26256A = &Curve{
+		Name: "id-tc26-gost-3410-2012-256-paramSetA",
+		P:    hexInt("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97"),
+		A:    hexInt("c2173f1513981673af4892c23035a27ce25e2013bf95aa33b22c656f277e7335"),
+		B:    hexInt("295f9bae7428ed9ccc20e7c359a9d41a22fccd9108e17bf7ba9337a6f8ae9513"),
+		Q:    hexInt("400000000000000000000000000000000fd8cddfc87b6635c115af556c360c67"),
+		X:    hexInt("91e38443a5e82c0d880923425712b2bb658b9196932e02c78b2582fe742daa28"),
+		Y:    hexInt("32879423ab1a0375895786c4bb46e9565fde0b5344766740af268adb32322e5c"),
+	}
+)
+
+// OID наборов параметров эллиптических кривых.
+var (
+	OIDCryptoProA     = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 1}
+	OIDCryptoProB     = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 2}
+	OIDCryptoProC     = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 3}
+	OIDCryptoProXchA  = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 36, 0}
+	OIDCryptoProXchB  = asn1.ObjectIdentifier{1, 2, 643, 2, 2, 36, 1}
+	OIDTC26256ParamsA = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 1}
+	OIDTC26256ParamsB = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 2}
+	OIDTC26256ParamsC = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 3}
+	OIDTC26256ParamsD = asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 4}
+)
+
+// CurveByOID - возвращает набор параметров эллиптической кривой по OID либо nil.
+func CurveByOID(oid asn1.ObjectIdentifier) *Curve {
+	switch {
+	case oid.Equal(OIDCryptoProA), oid.Equal(OIDCryptoProXchA), oid.Equal(OIDTC26256ParamsB):
+		return CurveCryptoProA
+	case oid.Equal(OIDCryptoProB), oid.Equal(OIDTC26256ParamsC):
+		return CurveCryptoProB
+	case oid.Equal(OIDCryptoProC), oid.Equal(OIDCryptoProXchB), oid.Equal(OIDTC26256ParamsD):
+		return CurveCryptoProC
+	case oid.Equal(OIDTC26256ParamsA):
+		return CurveTC26256A
+	}
+	return nil
+}
+
+// IsOnCurve - проверяет, что точка (x, y) лежит на кривой.
+func (c *Curve) IsOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || x.Cmp(c.P) >= 0 || y.Sign() < 0 || y.Cmp(c.P) >= 0 {
+		return false
+	}
+	left := new(big.Int).Mul(y, y)
+	left.Mod(left, c.P)
+	right := new(big.Int).Mul(x, x)
+	right.Add(right, c.A)
+	right.Mul(right, x)
+	right.Add(right, c.B)
+	right.Mod(right, c.P)
+	return left.Cmp(right) == 0
+}
+
+// point - точка кривой в якобиевых координатах (X/Z^2, Y/Z^3). Z = 0 - бесконечно удаленная точка.
+type point struct {
+	x, y, z *big.Int
+}
+
+func (c *Curve) newPoint(x, y *big.Int) *point {
+	return &point{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1)}
+}
+
+func (c *Curve) infinity() *point {
+	return &point{big.NewInt(1), big.NewInt(1), new(big.Int)}
+}
+
+// affine - возвращает аффинные координаты точки.
+func (c *Curve) affine(pt *point) (*big.Int, *big.Int) {
+	if pt.z.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	zInv := new(big.Int).ModInverse(pt.z, c.P)
+	zInv2 := new(big.Int).Mul(zInv, zInv)
+	x := new(big.Int).Mul(pt.x, zInv2)
+	x.Mod(x, c.P)
+	zInv2.Mul(zInv2, zInv)
+	y := new(big.Int).Mul(pt.y, zInv2)
+	y.Mod(y, c.P)
+	return x, y
+}
+
+// double - удвоение точки.
+func (c *Curve) double(pt *point) *point {
+	if pt.z.Sign() == 0 || pt.y.Sign() == 0 {
+		return c.infinity()
+	}
+	p := c.P
+	xx := new(big.Int).Mul(pt.x, pt.x)
+	yy := new(big.Int).Mul(pt.y, pt.y)
+	yy.Mod(yy, p)
+	yyyy := new(big.Int).Mul(yy, yy)
+	zz := new(big.Int).Mul(pt.z, pt.z)
+	zz.Mod(zz, p)
+
+	// s = 4*x*yy
+	s := new(big.Int).Mul(pt.x, yy)
+	s.Lsh(s, 2)
+	s.Mod(s, p)
+
+	// m = 3*xx + a*zz^2
+	m := new(big.Int).Mul(zz, zz)
+	m.Mul(m, c.A)
+	xx.Mul(xx, big.NewInt(3))
+	m.Add(m, xx)
+	m.Mod(m, p)
+
+	// x3 = m^2 - 2*s
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, s)
+	x3.Sub(x3, s)
+	x3.Mod(x3, p)
+
+	// y3 = m*(s - x3) - 8*yyyy
+	y3 := new(big.Int).Sub(s, x3)
+	y3.Mul(y3, m)
+	yyyy.Lsh(yyyy, 3)
+	y3.Sub(y3, yyyy)
+	y3.Mod(y3, p)
+
+	// z3 = 2*y*z
+	z3 := new(big.Int).Mul(pt.y, pt.z)
+	z3.Lsh(z3, 1)
+	z3.Mod(z3, p)
+
+	return &point{x3, y3, z3}
+}
+
+// add - сложение точек.
+func (c *Curve) add(p1, p2 *point) *point {
+	if p1.z.Sign() == 0 {
+		return &point{new(big.Int).Set(p2.x), new(big.Int).Set(p2.y), new(big.Int).Set(p2.z)}
+	}
+	if p2.z.Sign() == 0 {
+		return &point{new(big.Int).Set(p1.x), new(big.Int).Set(p1.y), new(big.Int).Set(p1.z)}
+	}
+	p := c.P
+	z1z1 := new(big.Int).Mul(p1.z, p1.z)
+	z1z1.Mod(z1z1, p)
+	z2z2 := new(big.Int).Mul(p2.z, p2.z)
+	z2z2.Mod(z2z2, p)
+
+	u1 := new(big.Int).Mul(p1.x, z2z2)
+	u1.Mod(u1, p)
+	u2 := new(big.Int).Mul(p2.x, z1z1)
+	u2.Mod(u2, p)
+
+	s1 := new(big.Int).Mul(p1.y, p2.z)
+	s1.Mul(s1, z2z2)
+	s1.Mod(s1, p)
+	s2 := new(big.Int).Mul(p2.y, p1.z)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, p)
+
+	if u1.Cmp(u2) == 0 {
+		if s1.Cmp(s2) == 0 {
+			return c.double(p1)
+		}
+		return c.infinity()
+	}
+
+	h := new(big.Int).Sub(u2, u1)
+	h.Mod(h, p)
+	r := new(big.Int).Sub(s2, s1)
+	r.Mod(r, p)
+
+	hh := new(big.Int).Mul(h, h)
+	hh.Mod(hh, p)
+	hhh := new(big.Int).Mul(hh, h)
+	hhh.Mod(hhh, p)
+	v := new(big.Int).Mul(u1, hh)
+	v.Mod(v, p)
+
+	// x3 = r^2 - hhh - 2*v
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, hhh)
+	x3.Sub(x3, v)
+	x3.Sub(x3, v)
+	x3.Mod(x3, p)
+
+	// y3 = r*(v - x3) - s1*hhh
+	y3 := new(big.Int).Sub(v, x3)
+	y3.Mul(y3, r)
+	s1.Mul(s1, hhh)
+	y3.Sub(y3, s1)
+	y3.Mod(y3, p)
+
+	// z3 = h*z1*z2
+	z3 := new(big.Int).Mul(p1.z, p2.z)
+	z3.Mul(z3, h)
+	z3.Mod(z3, p)
+
+	return &point{x3, y3, z3}
+}
+
+// scalarMult - умножение точки (x, y) на скаляр k по лестнице Монтгомери:
+// число операций не зависит от значения k.
+func (c *Curve) scalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	r0 := c.infinity()
+	r1 := c.newPoint(x, y)
+	for i := c.Q.BitLen() - 1; i >= 0; i-- {
+		if k.Bit(i) == 0 {
+			r1 = c.add(r0, r1)
+			r0 = c.double(r0)
+		} else {
+			r0 = c.add(r0, r1)
+			r1 = c.double(r1)
+		}
+	}
+	return c.affine(r0)
+}
+
+// scalarBaseMult - умножение базовой точки на скаляр k.
+func (c *Curve) scalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	return c.scalarMult(c.X, c.Y, k)
+}
+
+func hexInt(s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("gost3410: invalid hex constant " + s)
+	}
+	return i
+}
@@ -0,0 +1,196 @@
+package gost3410
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Ошибки пакета gost3410.
+var (
+	ErrKeySize      = errors.New("некорректная длина ключа")
+	ErrKeyValue     = errors.New("некорректное значение ключа")
+	ErrDigestSize   = errors.New("некорректная длина хэш-кода")
+	ErrSignature    = errors.New("некорректная подпись")
+	ErrRandomSource = errors.New("ошибка источника случайных чисел")
+)
+
+// PrivateKey - закрытый ключ подписи.
+type PrivateKey struct {
+	Curve *Curve
+	D     *big.Int
+}
+
+// PublicKey - открытый ключ проверки подписи.
+type PublicKey struct {
+	Curve *Curve
+	X, Y  *big.Int
+}
+
+// NewPrivateKey - создает закрытый ключ из raw, записанного начиная с младшего байта
+// (формат CryptoPro и PKCS#8 OpenSSL).
+func NewPrivateKey(curve *Curve, raw []byte) (*PrivateKey, error) {
+	if len(raw) != curve.PointSize() {
+		return nil, fmt.Errorf("%w: %d", ErrKeySize, len(raw))
+	}
+	return NewPrivateKeyInt(curve, new(big.Int).SetBytes(reverse(raw)))
+}
+
+// NewPrivateKeyInt - создает закрытый ключ из числа d.
+func NewPrivateKeyInt(curve *Curve, d *big.Int) (*PrivateKey, error) {
+	if d.Sign() <= 0 || d.Cmp(curve.Q) >= 0 {
+		return nil, ErrKeyValue
+	}
+	return &PrivateKey{Curve: curve, D: new(big.Int).Set(d)}, nil
+}
+
+// Raw - возвращает закрытый ключ, записанный начиная с младшего байта.
+func (k *PrivateKey) Raw() []byte {
+	return reverse(k.D.FillBytes(make([]byte, k.Curve.PointSize())))
+}
+
+// PublicKey - вычисляет открытый ключ.
+func (k *PrivateKey) PublicKey() *PublicKey {
+	x, y := k.Curve.scalarBaseMult(k.D)
+	return &PublicKey{Curve: k.Curve, X: x, Y: y}
+}
+
+// SignDigest - подписывает хэш-код digest (в порядке вывода байтов ГОСТ Р 34.11-2012).
+// Если rnd равен nil, используется [crypto/rand.Reader].
+// Возвращает подпись BE(s) || BE(r).
+func (k *PrivateKey) SignDigest(digest []byte, rnd io.Reader) ([]byte, error) {
+	if len(digest) != k.Curve.PointSize() {
+		return nil, fmt.Errorf("%w: %d", ErrDigestSize, len(digest))
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	e := digestInt(k.Curve, digest)
+	size := k.Curve.PointSize()
+	buf := make([]byte, size)
+	for {
+		if _, err := io.ReadFull(rnd, buf); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRandomSource, err)
+		}
+		rk := new(big.Int).SetBytes(buf)
+		rk.Mod(rk, k.Curve.Q)
+		if rk.Sign() == 0 {
+			continue
+		}
+		if sig := k.sign(e, rk); sig != nil {
+			return sig, nil
+		}
+	}
+}
+
+// sign - вычисляет подпись для числа e и случайного числа rk.
+// Возвращает nil, если rk не подходит и требуется новое значение.
+func (k *PrivateKey) sign(e, rk *big.Int) []byte {
+	q := k.Curve.Q
+	x, _ := k.Curve.scalarBaseMult(rk)
+	r := new(big.Int).Mod(x, q)
+	if r.Sign() == 0 {
+		return nil
+	}
+	// s = (r*d + k*e) mod q
+	s := new(big.Int).Mul(r, k.D)
+	s.Add(s, new(big.Int).Mul(rk, e))
+	s.Mod(s, q)
+	if s.Sign() == 0 {
+		return nil
+	}
+	size := k.Curve.PointSize()
+	sig := make([]byte, 2*size)
+	s.FillBytes(sig[:size])
+	r.FillBytes(sig[size:])
+	return sig
+}
+
+// NewPublicKey - создает открытый ключ из координат x и y.
+func NewPublicKey(curve *Curve, x, y *big.Int) (*PublicKey, error) {
+	if !curve.IsOnCurve(x, y) {
+		return nil, ErrKeyValue
+	}
+	return &PublicKey{Curve: curve, X: new(big.Int).Set(x), Y: new(big.Int).Set(y)}, nil
+}
+
+// NewPublicKeyRaw - создает открытый ключ из raw = LE(x) || LE(y) (формат сертификата X.509).
+func NewPublicKeyRaw(curve *Curve, raw []byte) (*PublicKey, error) {
+	size := curve.PointSize()
+	if len(raw) != 2*size {
+		return nil, fmt.Errorf("%w: %d", ErrKeySize, len(raw))
+	}
+	x := new(big.Int).SetBytes(reverse(raw[:size]))
+	y := new(big.Int).SetBytes(reverse(raw[size:]))
+	return NewPublicKey(curve, x, y)
+}
+
+// Raw - возвращает открытый ключ LE(x) || LE(y).
+func (pub *PublicKey) Raw() []byte {
+	size := pub.Curve.PointSize()
+	raw := make([]byte, 2*size)
+	pub.X.FillBytes(raw[:size])
+	pub.Y.FillBytes(raw[size:])
+	copy(raw[:size], reverse(raw[:size]))
+	copy(raw[size:], reverse(raw[size:]))
+	return raw
+}
+
+// VerifyDigest - проверяет подпись sig = BE(s) || BE(r) хэш-кода digest.
+func (pub *PublicKey) VerifyDigest(digest, sig []byte) error {
+	c := pub.Curve
+	size := c.PointSize()
+	if len(digest) != size {
+		return fmt.Errorf("%w: %d", ErrDigestSize, len(digest))
+	}
+	if len(sig) != 2*size {
+		return fmt.Errorf("%w: длина %d", ErrSignature, len(sig))
+	}
+	s := new(big.Int).SetBytes(sig[:size])
+	r := new(big.Int).SetBytes(sig[size:])
+	if r.Sign() <= 0 || r.Cmp(c.Q) >= 0 || s.Sign() <= 0 || s.Cmp(c.Q) >= 0 {
+		return ErrSignature
+	}
+
+	e := digestInt(c, digest)
+	v := new(big.Int).ModInverse(e, c.Q)
+	z1 := new(big.Int).Mul(s, v)
+	z1.Mod(z1, c.Q)
+	z2 := new(big.Int).Mul(r, v)
+	z2.Neg(z2)
+	z2.Mod(z2, c.Q)
+
+	x1, y1 := c.scalarBaseMult(z1)
+	x2, y2 := c.scalarMult(pub.X, pub.Y, z2)
+	pt := c.add(c.newPoint(x1, y1), c.newPoint(x2, y2))
+	if pt.z.Sign() == 0 {
+		return ErrSignature
+	}
+	x, _ := c.affine(pt)
+	x.Mod(x, c.Q)
+	if x.Cmp(r) != 0 {
+		return ErrSignature
+	}
+	return nil
+}
+
+// digestInt - число e, соответствующее хэш-коду: e = alpha mod q, e = 1 при e = 0.
+func digestInt(c *Curve, digest []byte) *big.Int {
+	e := new(big.Int).SetBytes(reverse(digest))
+	e.Mod(e, c.Q)
+	if e.Sign() == 0 {
+		e.SetInt64(1)
+	}
+	return e
+}
+
+// reverse - возвращает копию b в обратном порядке байтов.
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
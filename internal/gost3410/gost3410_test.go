@@ -0,0 +1,124 @@
+package gost3410
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTestGOST3410 struct {
+	suite.Suite
+}
+
+func TestGOST3410(t *testing.T) {
+	suite.Run(t, new(suiteTestGOST3410))
+}
+
+// Контрольный пример из ГОСТ Р 34.10-2012, Приложение А.1.
+const (
+	testD  = "7a929ade789bb9be10ed359dd39a72c11b60961f49397eee1d19ce9891ec3b28"
+	testE  = "2dfbc1b372d89a1188c09c52e0eec61fce52032ab1022e8e67ece6672b043ee5"
+	testK  = "77105c9b20bcd3122823c8cf6fcc7b956de33814e95b7fe64fed924594dceab3"
+	testR  = "41aa28d2f1ab148280cd9ed56feda41974053554a42767b83ad043fd39dc0493"
+	testS  = "01456c64ba4642a1653c235a98a60249bcd6d3f746b631df928014f6c5bf9c40"
+	testQx = "7f2b49e270db6d90d8595bec458b50c58585ba1d4e9b788f6689dbd8e56fd80b"
+	testQy = "26f1b489d6701dd185c8413a977b3cbbaf64d1c593d26627dffb101a87ff77da"
+)
+
+func (suite *suiteTestGOST3410) TestVector() {
+	key, err := NewPrivateKeyInt(CurveTest, hexInt(testD))
+	suite.Require().NoError(err)
+
+	pub := key.PublicKey()
+	suite.Equal(testQx, hex.EncodeToString(pub.X.FillBytes(make([]byte, 32))))
+	suite.Equal(testQy, hex.EncodeToString(pub.Y.FillBytes(make([]byte, 32))))
+
+	sig := key.sign(hexInt(testE), hexInt(testK))
+	suite.Require().NotNil(sig)
+	suite.Equal(testS+testR, hex.EncodeToString(sig))
+
+	digest := reverse(hexInt(testE).FillBytes(make([]byte, 32)))
+	suite.NoError(pub.VerifyDigest(digest, sig))
+}
+
+// Контрольный пример для id-GostR3410-2001-CryptoPro-A-ParamSet,
+// подпись проверена независимой реализацией (libgcrypt).
+func (suite *suiteTestGOST3410) TestVectorCryptoProA() {
+	raw, _ := hex.DecodeString("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+	digest, _ := hex.DecodeString("a0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebf")
+	const (
+		pubRaw = "d4572c4a208ac360480314e38f9e087904be0aa145c2e4f70f9fb47de60cecd342052b8dac9a81dd2fdfbf7cefebd0596f694e87e71861ff9560cf1709312bc6"
+		sigHex = "671e8d541c6052678fb8623aca3aa86821a5d7e82ffb766468f4211de5b5b476c35567805798ae580bd36ceb18253a08d8fea7c4c5cc59ae4c3c78605b3d6686"
+	)
+
+	key, err := NewPrivateKey(CurveCryptoProA, raw)
+	suite.Require().NoError(err)
+	suite.Equal(raw, key.Raw())
+	pub := key.PublicKey()
+	suite.Equal(pubRaw, hex.EncodeToString(pub.Raw()))
+
+	sig := key.sign(digestInt(CurveCryptoProA, digest), hexInt("1111111111111111111111111111111111111111111111111111111111111111"))
+	suite.Equal(sigHex, hex.EncodeToString(sig))
+
+	pubRawBytes, _ := hex.DecodeString(pubRaw)
+	pub2, err := NewPublicKeyRaw(CurveCryptoProA, pubRawBytes)
+	suite.Require().NoError(err)
+	suite.NoError(pub2.VerifyDigest(digest, sig))
+}
+
+func (suite *suiteTestGOST3410) TestCurves() {
+	for _, c := range []*Curve{CurveTest, CurveCryptoProA, CurveCryptoProB, CurveCryptoProC, CurveTC26256A} {
+		suite.Run(c.Name, func() {
+			suite.True(c.IsOnCurve(c.X, c.Y))
+			suite.Equal(32, c.PointSize())
+			x, y := c.scalarBaseMult(c.Q)
+			suite.Zero(x.Sign())
+			suite.Zero(y.Sign())
+		})
+	}
+}
+
+func (suite *suiteTestGOST3410) TestSignVerify() {
+	digest := make([]byte, 32)
+	for i := range digest {
+		digest[i] = byte(i)
+	}
+	for _, c := range []*Curve{CurveCryptoProA, CurveCryptoProB, CurveCryptoProC, CurveTC26256A} {
+		suite.Run(c.Name, func() {
+			raw := make([]byte, 32)
+			raw[0] = 42
+			key, err := NewPrivateKey(c, raw)
+			suite.Require().NoError(err)
+			pub := key.PublicKey()
+			suite.True(c.IsOnCurve(pub.X, pub.Y))
+
+			sig, err := key.SignDigest(digest, nil)
+			suite.Require().NoError(err)
+			suite.Len(sig, 64)
+			suite.NoError(pub.VerifyDigest(digest, sig))
+
+			sig[10] ^= 0xff
+			suite.ErrorIs(pub.VerifyDigest(digest, sig), ErrSignature)
+		})
+	}
+}
+
+func (suite *suiteTestGOST3410) TestErrors() {
+	_, err := NewPrivateKey(CurveCryptoProA, make([]byte, 31))
+	suite.ErrorIs(err, ErrKeySize)
+
+	_, err = NewPrivateKey(CurveCryptoProA, make([]byte, 32))
+	suite.ErrorIs(err, ErrKeyValue)
+
+	_, err = NewPublicKeyRaw(CurveCryptoProA, make([]byte, 64))
+	suite.ErrorIs(err, ErrKeyValue)
+
+	raw := make([]byte, 32)
+	raw[0] = 1
+	key, err := NewPrivateKey(CurveCryptoProA, raw)
+	suite.Require().NoError(err)
+	_, err = key.SignDigest(make([]byte, 64), nil)
+	suite.ErrorIs(err, ErrDigestSize)
+	suite.ErrorIs(key.PublicKey().VerifyDigest(make([]byte, 32), make([]byte, 10)), ErrSignature)
+}
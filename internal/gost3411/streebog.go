@@ -0,0 +1,183 @@
+// Package gost3411 - функция хэширования ГОСТ Р 34.11-2012 («Стрибог»).
+//
+// Порядок байтов результата соответствует принятому в CryptoPro, OpenSSL и ЕСИА:
+// хэш-код выводится начиная с младшего байта (обратно порядку записи векторов в тексте стандарта).
+package gost3411
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	BlockSize = 64 // Размер блока в байтах
+	Size256   = 32 // Размер хэш-кода длиной 256 бит в байтах
+	Size512   = 64 // Размер хэш-кода длиной 512 бит в байтах
+)
+
+// digest - состояние функции хэширования.
+type digest struct {
+	size  int
+	h     [8]uint64 // Промежуточное значение хэш-кода
+	n     [8]uint64 // Количество обработанных бит
+	sigma [8]uint64 // Контрольная сумма блоков
+	buf   [BlockSize]byte
+	nbuf  int
+}
+
+// New256 - возвращает [hash.Hash] для вычисления хэш-кода длиной 256 бит.
+func New256() hash.Hash {
+	d := &digest{size: Size256}
+	d.Reset()
+	return d
+}
+
+// New512 - возвращает [hash.Hash] для вычисления хэш-кода длиной 512 бит.
+func New512() hash.Hash {
+	d := &digest{size: Size512}
+	d.Reset()
+	return d
+}
+
+// Sum256 - возвращает хэш-код длиной 256 бит.
+func Sum256(data []byte) [Size256]byte {
+	var sum [Size256]byte
+	h := New256()
+	_, _ = h.Write(data)
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Sum512 - возвращает хэш-код длиной 512 бит.
+func Sum512(data []byte) [Size512]byte {
+	var sum [Size512]byte
+	h := New512()
+	_, _ = h.Write(data)
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+func (d *digest) Size() int      { return d.size }
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Reset() {
+	var iv uint64
+	if d.size == Size256 {
+		iv = 0x0101010101010101
+	}
+	for i := range d.h {
+		d.h[i] = iv
+		d.n[i] = 0
+		d.sigma[i] = 0
+	}
+	d.nbuf = 0
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := copy(d.buf[d.nbuf:], p)
+		d.nbuf += m
+		p = p[m:]
+		if d.nbuf == BlockSize {
+			d.block(d.buf[:], BlockSize*8)
+			d.nbuf = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	// Работаем с копией, чтобы вызывающая сторона могла продолжить запись
+	c := *d
+	var buf [BlockSize]byte
+	copy(buf[:], c.buf[:c.nbuf])
+	buf[c.nbuf] = 0x01
+	c.block(buf[:], uint64(c.nbuf)*8)
+
+	var zero [8]uint64
+	c.h = g(zero, c.h, c.n)
+	c.h = g(zero, c.h, c.sigma)
+
+	var out [Size512]byte
+	for i, w := range c.h {
+		binary.LittleEndian.PutUint64(out[i*8:], w)
+	}
+	return append(in, out[Size512-c.size:]...)
+}
+
+// block - обрабатывает блок данных, содержащий bits значащих бит.
+func (d *digest) block(data []byte, bits uint64) {
+	var m [8]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	d.h = g(d.n, d.h, m)
+	add512(&d.n, [8]uint64{bits})
+	add512(&d.sigma, m)
+}
+
+// g - функция сжатия g_N(h, m).
+func g(n, h, m [8]uint64) [8]uint64 {
+	k := lps(xor512(h, n))
+	t := m
+	for i := 0; i < 12; i++ {
+		t = lps(xor512(k, t))
+		k = lps(xor512(k, c[i]))
+	}
+	return xor512(xor512(xor512(t, k), h), m)
+}
+
+// lps - композиция преобразований L, P и S.
+func lps(x [8]uint64) [8]uint64 {
+	var r [8]uint64
+	for j := 0; j < 8; j++ {
+		shift := 8 * j
+		for i := 0; i < 8; i++ {
+			r[j] ^= lpsTable[i][byte(x[i]>>shift)]
+		}
+	}
+	return r
+}
+
+func xor512(x, y [8]uint64) [8]uint64 {
+	for i := range x {
+		x[i] ^= y[i]
+	}
+	return x
+}
+
+// add512 - сложение в кольце вычетов по модулю 2^512.
+func add512(x *[8]uint64, y [8]uint64) {
+	var carry uint64
+	for i := range x {
+		s := x[i] + y[i]
+		c1 := s < x[i]
+		s += carry
+		c2 := s < carry
+		x[i] = s
+		carry = 0
+		if c1 || c2 {
+			carry = 1
+		}
+	}
+}
+
+// lpsTable - предвычисленные значения композиции преобразований L, P и S
+// для каждого байта каждого слова состояния.
+var lpsTable [8][256]uint64
+
+func init() {
+	for i := 0; i < 8; i++ {
+		for b := 0; b < 256; b++ {
+			var v uint64
+			s := pi[b]
+			for t := 0; t < 8; t++ {
+				if s&(1<<t) != 0 {
+					v ^= a[(7-i)*8+(7-t)]
+				}
+			}
+			lpsTable[i][b] = v
+		}
+	}
+}
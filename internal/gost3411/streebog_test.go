@@ -0,0 +1,87 @@
+package gost3411
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTestStreebog struct {
+	suite.Suite
+}
+
+func TestStreebog(t *testing.T) {
+	suite.Run(t, new(suiteTestStreebog))
+}
+
+// Контрольные примеры из ГОСТ Р 34.11-2012, Приложение А (RFC 6986, раздел 10).
+// Хэш-коды записаны в порядке вывода байтов.
+const (
+	testM1 = "303132333435363738393031323334353637383930313233343536373839303132333435363738393031323334353637383930313233343536373839303132"
+	testM2 = "d1e520e2e5f2f0e82c20d1f2f0e8e1eee6e820e2edf3f6e82c20e2e5fef2fa20f120eceef0ff20f1f2f0e5ebe0ece820ede020f5f0e0e1f0fbff20efebfaeafb20c8e3eef0e5e2fb"
+
+	testM1Hash256 = "9d151eefd8590b89daa6ba6cb74af9275dd051026bb149a452fd84e5e57b5500"
+	testM1Hash512 = "1b54d01a4af5b9d5cc3d86d68d285462b19abc2475222f35c085122be4ba1ffa00ad30f8767b3a82384c6574f024c311e2a481332b08ef7f41797891c1646f48"
+	testM2Hash256 = "9dd2fe4e90409e5da87f53976d7405b0c0cac628fc669a741d50063c557e8f50"
+	testM2Hash512 = "1e88e62226bfca6f9994f1f2d51569e0daf8475a3b0fe61a5300eee46d961376035fe83549ada2b8620fcd7c496ce5b33f0cb9dddc2b6460143b03dabac9fb28"
+)
+
+func (suite *suiteTestStreebog) TestVectors() {
+	tests := []struct {
+		name    string
+		message string
+		hash256 string
+		hash512 string
+	}{
+		{"M1", testM1, testM1Hash256, testM1Hash512},
+		{"M2", testM2, testM2Hash256, testM2Hash512},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			message, err := hex.DecodeString(tt.message)
+			suite.Require().NoError(err)
+			sum256 := Sum256(message)
+			sum512 := Sum512(message)
+			suite.Equal(tt.hash256, hex.EncodeToString(sum256[:]))
+			suite.Equal(tt.hash512, hex.EncodeToString(sum512[:]))
+		})
+	}
+}
+
+func (suite *suiteTestStreebog) TestStream() {
+	message := make([]byte, 1000)
+	for i := range message {
+		message[i] = byte(i*7 + 3)
+	}
+	expected := Sum256(message)
+
+	for _, chunk := range []int{1, 7, 63, 64, 65, 999} {
+		h := New256()
+		for i := 0; i < len(message); i += chunk {
+			end := i + chunk
+			if end > len(message) {
+				end = len(message)
+			}
+			_, _ = h.Write(message[i:end])
+		}
+		suite.Equal(expected[:], h.Sum(nil), "chunk=%d", chunk)
+	}
+}
+
+func (suite *suiteTestStreebog) TestSumReset() {
+	message, err := hex.DecodeString(testM1)
+	suite.Require().NoError(err)
+
+	h := New512()
+	_, _ = h.Write(message[:10])
+	_ = h.Sum(nil)
+	_, _ = h.Write(message[10:])
+	suite.Equal(testM1Hash512, hex.EncodeToString(h.Sum(nil)))
+
+	h.Reset()
+	_, _ = h.Write(message)
+	suite.Equal(testM1Hash512, hex.EncodeToString(h.Sum([]byte{})))
+	suite.Equal(Size512, h.Size())
+	suite.Equal(BlockSize, h.BlockSize())
+}
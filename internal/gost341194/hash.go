@@ -0,0 +1,171 @@
+// Package gost341194 - функция хэширования ГОСТ Р 34.11-94.
+//
+// Используется только для совместимости: в частности, при выработке ключа
+// из PIN-кода контейнеров КриптоПро с ключами ГОСТ Р 34.10-2001.
+// Порядок байтов результата соответствует принятому в CryptoPro и OpenSSL.
+package gost341194
+
+import (
+	"encoding/binary"
+	"hash"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost28147"
+)
+
+const (
+	BlockSize = 32 // Размер блока в байтах
+	Size      = 32 // Размер хэш-кода в байтах
+)
+
+// c3 - константа C3 процедуры генерации ключей.
+var c3 = [32]byte{
+	0x00, 0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0xff,
+	0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0xff, 0x00,
+	0x00, 0xff, 0xff, 0x00, 0xff, 0x00, 0x00, 0xff,
+	0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0x00, 0xff,
+}
+
+type digest struct {
+	sbox *gost28147.Sbox
+	h    [32]byte
+	sum  [32]byte
+	bits uint64
+	buf  [BlockSize]byte
+	nbuf int
+}
+
+// New - возвращает [hash.Hash] ГОСТ Р 34.11-94 с таблицей замен sbox
+// (обычно [gost28147.SboxCryptoPro3411]).
+func New(sbox *gost28147.Sbox) hash.Hash {
+	return &digest{sbox: sbox}
+}
+
+// Sum - возвращает хэш-код ГОСТ Р 34.11-94 с таблицей замен sbox.
+func Sum(sbox *gost28147.Sbox, data []byte) [Size]byte {
+	var sum [Size]byte
+	h := New(sbox)
+	_, _ = h.Write(data)
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+func (d *digest) Size() int      { return Size }
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Reset() {
+	*d = digest{sbox: d.sbox}
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := copy(d.buf[d.nbuf:], p)
+		d.nbuf += m
+		p = p[m:]
+		if d.nbuf == BlockSize {
+			d.block(d.buf[:], BlockSize*8)
+			d.nbuf = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	c := *d
+	if c.nbuf > 0 {
+		var buf [BlockSize]byte
+		copy(buf[:], c.buf[:c.nbuf])
+		c.block(buf[:], uint64(c.nbuf)*8)
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], c.bits)
+	c.h = c.step(c.h, length)
+	c.h = c.step(c.h, c.sum)
+	return append(in, c.h[:]...)
+}
+
+// block - обрабатывает блок данных, содержащий bits значащих бит.
+func (d *digest) block(data []byte, bits uint64) {
+	var m [32]byte
+	copy(m[:], data)
+	d.h = d.step(d.h, m)
+	d.bits += bits
+	// sum = sum + m (mod 2^256)
+	var carry uint16
+	for i := range d.sum {
+		carry += uint16(d.sum[i]) + uint16(m[i])
+		d.sum[i] = byte(carry)
+		carry >>= 8
+	}
+}
+
+// step - шаговая функция хэширования.
+func (d *digest) step(h, m [32]byte) [32]byte {
+	// Генерация ключей
+	var keys [4][32]byte
+	u, v := h, m
+	keys[0] = p(xor(u, v))
+	for j := 1; j < 4; j++ {
+		u = a(u)
+		if j == 2 {
+			u = xor(u, c3)
+		}
+		v = a(a(v))
+		keys[j] = p(xor(u, v))
+	}
+
+	// Шифрующее преобразование
+	var s [32]byte
+	for i := 0; i < 4; i++ {
+		c, _ := gost28147.NewCipher(keys[i][:], d.sbox)
+		c.Encrypt(s[i*8:], h[i*8:])
+	}
+
+	// Перемешивающее преобразование
+	for i := 0; i < 12; i++ {
+		s = psi(s)
+	}
+	s = psi(xor(s, m))
+	s = xor(s, h)
+	for i := 0; i < 61; i++ {
+		s = psi(s)
+	}
+	return s
+}
+
+// a - преобразование A: (y4||y3||y2||y1) -> (y1^y2)||y4||y3||y2.
+func a(y [32]byte) [32]byte {
+	var r [32]byte
+	copy(r[0:24], y[8:32])
+	for i := 0; i < 8; i++ {
+		r[24+i] = y[i] ^ y[8+i]
+	}
+	return r
+}
+
+// p - перестановка байтов P: phi(i + 1 + 4(k-1)) = 8i + k.
+func p(y [32]byte) [32]byte {
+	var r [32]byte
+	for i := 0; i < 4; i++ {
+		for k := 0; k < 8; k++ {
+			r[i+4*k] = y[8*i+k]
+		}
+	}
+	return r
+}
+
+// psi - преобразование psi над 16-битными словами.
+func psi(y [32]byte) [32]byte {
+	var r [32]byte
+	copy(r[0:30], y[2:32])
+	r[30] = y[0] ^ y[2] ^ y[4] ^ y[6] ^ y[24] ^ y[30]
+	r[31] = y[1] ^ y[3] ^ y[5] ^ y[7] ^ y[25] ^ y[31]
+	return r
+}
+
+func xor(x, y [32]byte) [32]byte {
+	for i := range x {
+		x[i] ^= y[i]
+	}
+	return x
+}
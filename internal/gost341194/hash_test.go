@@ -0,0 +1,54 @@
+package gost341194
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/internal/gost28147"
+)
+
+type suiteTestGOST341194 struct {
+	suite.Suite
+}
+
+func TestGOST341194(t *testing.T) {
+	suite.Run(t, new(suiteTestGOST341194))
+}
+
+// Контрольные примеры ГОСТ Р 34.11-94 (RFC 5831) в порядке вывода байтов.
+func (suite *suiteTestGOST341194) TestVectors() {
+	tests := []struct {
+		name    string
+		sbox    *gost28147.Sbox
+		message string
+		hash    string
+	}{
+		{"test empty", gost28147.SboxTest3411, "", "ce85b99cc46752fffee35cab9a7b0278abb4c2d2055cff685af4912c49490f8d"},
+		{"test 32 bytes", gost28147.SboxTest3411, "This is message, length=32 bytes", "b1c466d37519b82e8319819ff32595e047a28cb6f83eff1c6916a815a637fffa"},
+		{"test 50 bytes", gost28147.SboxTest3411, "Suppose the original message has length = 50 bytes", "471aba57a60a770d3a76130635c1fbea4ef14de51f78b4ae57dd893b62f55208"},
+		{"cryptopro empty", gost28147.SboxCryptoPro3411, "", "981e5f3ca30c841487830f84fb433e13ac1101569b9c13584ac483234cd656c0"},
+		{"cryptopro abc", gost28147.SboxCryptoPro3411, "abc", "b285056dbf18d7392d7677369524dd14747459ed8143997e163b2986f92fd42c"},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			sum := Sum(tt.sbox, []byte(tt.message))
+			suite.Equal(tt.hash, hex.EncodeToString(sum[:]))
+		})
+	}
+}
+
+func (suite *suiteTestGOST341194) TestStream() {
+	message := []byte("Suppose the original message has length = 50 bytes")
+	h := New(gost28147.SboxTest3411)
+	for _, b := range message {
+		_, _ = h.Write([]byte{b})
+	}
+	suite.Equal("471aba57a60a770d3a76130635c1fbea4ef14de51f78b4ae57dd893b62f55208", hex.EncodeToString(h.Sum(nil)))
+
+	h.Reset()
+	suite.Equal("ce85b99cc46752fffee35cab9a7b0278abb4c2d2055cff685af4912c49490f8d", hex.EncodeToString(h.Sum(nil)))
+	suite.Equal(Size, h.Size())
+	suite.Equal(BlockSize, h.BlockSize())
+}
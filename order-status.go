@@ -0,0 +1,49 @@
+package apipgu
+
+// Результат поиска заявления в ответе методов [Client.OrdersStatus] и [Client.OrdersUpdatedAfter].
+const (
+	OrderSearchFound    = "FOUND"     // Заявление найдено
+	OrderSearchNotFound = "NOT_FOUND" // Заявление не найдено
+)
+
+// OrdersStatus - страница статусов заявлений методов [Client.OrdersStatus] и [Client.OrdersUpdatedAfter].
+//
+// Подробнее см "Спецификация API ЕПГУ версия 1.12",
+// раздел "2.3. Получение статусов заявлений".
+//
+// Пример:
+//
+//	{
+//	  "count": 1,
+//	  "totalCount": 1,
+//	  "content": [
+//	    {
+//	      "orderId": 1230254874,
+//	      "orderSearchStatus": "FOUND",
+//	      "status": {
+//	        "statusId": 2,
+//	        "statusName": "Заявление получено ведомством",
+//	        "updated": "2023-12-13T14:23:11.429"
+//	      }
+//	    }
+//	  ]
+//	}
+type OrdersStatus struct {
+	Count      int                 `json:"count"`      // Количество записей на странице
+	TotalCount int                 `json:"totalCount"` // Количество найденных записей
+	Content    []OrderStatusRecord `json:"content"`    // Записи
+}
+
+// OrderStatusRecord - статус заявления из структуры [OrdersStatus].
+type OrderStatusRecord struct {
+	OrderId           int                 `json:"orderId"`           // Номер заявления
+	OrderSearchStatus string              `json:"orderSearchStatus"` // Результат поиска: [OrderSearchFound] или [OrderSearchNotFound]
+	Status            *OrderCurrentStatus `json:"status"`            // Текущий статус; nil, если заявление не найдено или еще не отправлено
+}
+
+// OrderCurrentStatus - текущий статус заявления из структуры [OrderStatusRecord].
+type OrderCurrentStatus struct {
+	StatusId   int           `json:"statusId"`   // Код статуса заявления, см "Приложение 1. Коды статусов заявлений"
+	StatusName string        `json:"statusName"` // Наименование статуса
+	Updated    LocalDateTime `json:"updated"`    // Дата и время обновления статуса
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 func (c *Client) requestJSON(
+	op operation,
 	method,
 	endpoint,
 	contentType,
@@ -15,7 +17,7 @@ func (c *Client) requestJSON(
 	body io.Reader,
 	result any,
 ) error {
-	resBody, err := c.requestBody(method, endpoint, contentType, accessToken, body)
+	resBody, err := c.requestBody(op, method, endpoint, contentType, accessToken, body)
 	if err != nil {
 		return err
 	}
@@ -27,6 +29,7 @@ func (c *Client) requestJSON(
 }
 
 func (c *Client) requestBody(
+	op operation,
 	method,
 	endpoint,
 	contentType,
@@ -48,7 +51,9 @@ func (c *Client) requestBody(
 
 	c.logReq(req)
 
+	started := time.Now()
 	res, err := c.httpClient.Do(req)
+	c.harRecord(op, req, res, err, started)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrRequest, err)
 	}
@@ -0,0 +1,15 @@
+// Доверенные сертификаты НУЦ Минцифры России и настройки TLS для запросов к ЕПГУ и ЕСИА.
+//
+// Продуктовые среды ЕПГУ и ЕСИА используют сертификаты, выпущенные
+// Russian Trusted Root CA / Russian Trusted Sub CA. Этих сертификатов нет в системных хранилищах
+// большинства дистрибутивов Linux и в Go, поэтому без дополнительной настройки запросы завершаются
+// ошибкой "x509: certificate signed by unknown authority".
+//
+// [Config] возвращает [*tls.Config] или [*http.Client] с системными и встроенными сертификатами,
+// с проверкой открытого ключа сервера (pinning) и контролем срока действия встроенных сертификатов.
+//
+// Встроенные сертификаты размещаются в каталоге certs пакета, порядок обновления см. certs/README.md.
+// Если встроенных сертификатов нет, передайте их в [Config.WithRoots].
+//
+// Сертификаты НУЦ Минцифры России: https://www.gosuslugi.ru/crt
+package rootca
@@ -0,0 +1,12 @@
+package rootca
+
+import "errors"
+
+// Ошибки пакета rootca
+var (
+	ErrCertParse   = errors.New("ошибка разбора сертификата")
+	ErrCertExpired = errors.New("истек срок действия встроенного сертификата")
+	ErrNoCerts     = errors.New("нет сертификатов НУЦ Минцифры России")
+	ErrSystemRoots = errors.New("ошибка загрузки системных корневых сертификатов")
+	ErrPinMismatch = errors.New("открытый ключ сервера не совпадает с закрепленным")
+)
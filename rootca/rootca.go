@@ -0,0 +1,263 @@
+package rootca
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ofstudio/go-api-epgu/utils"
+)
+
+//go:embed certs
+var certsFS embed.FS
+
+// embedded - встроенные сертификаты; переменная используется в тестах.
+var embedded fs.FS = certsFS
+
+var nowFunc = time.Now
+
+// Certificates - возвращает встроенные сертификаты НУЦ Минцифры России
+// (Russian Trusted Root CA и Russian Trusted Sub CA).
+//
+// В случае ошибки возвращает цепочку из [ErrCertParse] и описания ошибки.
+func Certificates() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	err := fs.WalkDir(embedded, "certs", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := path.Ext(name); d.IsDir() || (ext != ".pem" && ext != ".crt") {
+			return nil
+		}
+		data, err := fs.ReadFile(embedded, name)
+		if err != nil {
+			return err
+		}
+		parsed, err := parsePEM(data)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", name, err)
+		}
+		certs = append(certs, parsed...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+	}
+	return certs, nil
+}
+
+// Pin - возвращает значение для [Config.WithPins]: SHA-256 от SubjectPublicKeyInfo сертификата в base64.
+// Совпадает со значением, которое принимает curl --pinnedpubkey (без префикса "sha256//").
+func Pin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Config - настройки TLS для запросов к ЕПГУ и ЕСИА.
+// Продуктовые среды ЕПГУ и ЕСИА используют сертификаты, выпущенные НУЦ Минцифры России,
+// которых нет в системных хранилищах большинства дистрибутивов Linux и в Go.
+//
+// По умолчанию к системным корневым сертификатам добавляется встроенный Russian Trusted Root CA, см [Config.CertPool].
+// Результат используется в [github.com/ofstudio/go-api-epgu.Client.WithHTTPClient]
+// и [github.com/ofstudio/go-api-epgu/esia/aas.Client.WithHTTPClient].
+//
+// Пример:
+//
+//	httpClient, err := rootca.NewConfig().HTTPClient()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client := apipgu.NewClient(baseURI).WithHTTPClient(httpClient)
+type Config struct {
+	systemRoots bool
+	roots       [][]byte
+	pins        map[string]struct{}
+	warnBefore  time.Duration
+	logger      utils.Logger
+}
+
+// NewConfig - конструктор [Config].
+func NewConfig() *Config {
+	return &Config{systemRoots: true}
+}
+
+// WithoutSystemRoots - не использовать системные корневые сертификаты:
+// доверенными считаются только встроенные и добавленные через [Config.WithRoots].
+func (c *Config) WithoutSystemRoots() *Config {
+	c.systemRoots = false
+	return c
+}
+
+// WithRoots - добавляет доверенные сертификаты в PEM-формате (например, более новые,
+// чем встроенные в текущую версию библиотеки, или сертификаты тестовой среды).
+// Доверенными считаются только корневые сертификаты, см [Config.CertPool].
+func (c *Config) WithRoots(certsPEM []byte) *Config {
+	c.roots = append(c.roots, certsPEM)
+	return c
+}
+
+// WithPins - включает проверку открытого ключа (pinning): цепочка сертификатов сервера
+// должна содержать сертификат с открытым ключом из списка pins, см [Pin].
+// Значения принимаются с префиксом "sha256//" или без него.
+func (c *Config) WithPins(pins ...string) *Config {
+	if c.pins == nil {
+		c.pins = make(map[string]struct{}, len(pins))
+	}
+	for _, pin := range pins {
+		c.pins[strings.TrimPrefix(pin, "sha256//")] = struct{}{}
+	}
+	return c
+}
+
+// WithExpiryWarning - включает предупреждение в logger, если срок действия
+// встроенного сертификата истекает менее чем через days дней.
+func (c *Config) WithExpiryWarning(days int, logger utils.Logger) *Config {
+	c.warnBefore = time.Duration(days) * 24 * time.Hour
+	c.logger = logger
+	return c
+}
+
+// CertPool - возвращает пул доверенных корневых сертификатов.
+// В пул добавляются только корневые (самоподписанные) сертификаты: встроенный Russian Trusted Root CA
+// и корневые сертификаты из [Config.WithRoots]. Промежуточные сертификаты, в том числе встроенный
+// Russian Trusted Sub CA, доверенными не считаются: сервер передает их в цепочке сертификатов.
+//
+// В случае ошибки возвращает цепочку из одной из ошибок и описания ошибки:
+//   - [ErrCertParse] - ошибка разбора встроенных или добавленных сертификатов
+//   - [ErrCertExpired] - истек срок действия встроенного сертификата: обновите библиотеку
+//     или передайте действующие сертификаты в [Config.WithRoots]
+//   - [ErrNoCerts] - нет встроенных и добавленных корневых сертификатов
+//   - [ErrSystemRoots] - ошибка загрузки системных корневых сертификатов
+func (c *Config) CertPool() (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if c.systemRoots {
+		system, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSystemRoots, err)
+		}
+		pool = system
+	}
+
+	certs, err := Certificates()
+	if err != nil {
+		return nil, err
+	}
+	now := nowFunc()
+	for _, cert := range certs {
+		if now.After(cert.NotAfter) {
+			return nil, fmt.Errorf(
+				"%w: '%s' (%s)", ErrCertExpired, cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly),
+			)
+		}
+		if c.logger != nil && cert.NotAfter.Sub(now) < c.warnBefore {
+			c.logger.Print(fmt.Sprintf(
+				"ВНИМАНИЕ: срок действия встроенного сертификата '%s' истекает %s",
+				cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly),
+			))
+		}
+	}
+
+	for _, data := range c.roots {
+		extra, err := parsePEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCertParse, err)
+		}
+		certs = append(certs, extra...)
+	}
+
+	roots := 0
+	for _, cert := range certs {
+		if isRoot(cert) {
+			pool.AddCert(cert)
+			roots++
+		}
+	}
+	if roots == 0 {
+		return nil, ErrNoCerts
+	}
+	return pool, nil
+}
+
+// TLSConfig - возвращает настройки TLS с пулом [Config.CertPool] и проверкой открытого ключа,
+// если она включена в [Config.WithPins].
+//
+// В случае ошибки возвращает ошибки [Config.CertPool].
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	pool, err := c.CertPool()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if len(c.pins) > 0 {
+		pins := c.pins
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPins(cs, pins)
+		}
+	}
+	return cfg, nil
+}
+
+// HTTPClient - возвращает http-клиент с настройками [Config.TLSConfig].
+// Остальные параметры транспорта совпадают с [http.DefaultTransport].
+//
+// В случае ошибки возвращает ошибки [Config.CertPool].
+func (c *Config) HTTPClient() (*http.Client, error) {
+	cfg, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	return &http.Client{Transport: transport}, nil
+}
+
+// isRoot - сертификат самоподписанный: издатель совпадает с субъектом и подпись проверяется ключом сертификата.
+func isRoot(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// verifyPins - проверяет, что проверенная цепочка сертификатов содержит открытый ключ из pins.
+func verifyPins(cs tls.ConnectionState, pins map[string]struct{}) error {
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			if _, ok := pins[Pin(cert)]; ok {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: '%s'", ErrPinMismatch, cs.ServerName)
+}
+
+// parsePEM - разбирает один или несколько сертификатов в PEM-формате.
+func parsePEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("нет PEM-блоков CERTIFICATE")
+	}
+	return certs, nil
+}
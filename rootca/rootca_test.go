@@ -0,0 +1,320 @@
+package rootca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// TestEmbedded - проверяет встроенные сертификаты по закрепленным отпечаткам certs/SHA256SUMS, см certs/README.md.
+func TestEmbedded(t *testing.T) {
+	certs, err := Certificates()
+	require.NoError(t, err)
+	require.NotEmpty(t, certs, "встроенные сертификаты отсутствуют, см certs/README.md")
+
+	sums := embeddedSums(t)
+	for _, cert := range certs {
+		sum := sha256.Sum256(cert.Raw)
+		require.Contains(t, sums, hex.EncodeToString(sum[:]),
+			"отпечаток '%s' не закреплен в certs/SHA256SUMS", cert.Subject.CommonName)
+	}
+	require.Len(t, certs, len(sums), "не все сертификаты из certs/SHA256SUMS встроены")
+
+	bySubject := map[string]*x509.Certificate{}
+	for _, cert := range certs {
+		require.True(t, cert.IsCA, cert.Subject.CommonName)
+		require.True(t, time.Now().Before(cert.NotAfter), "истек срок действия '%s'", cert.Subject.CommonName)
+		bySubject[cert.Subject.CommonName] = cert
+	}
+	root, ok := bySubject["Russian Trusted Root CA"]
+	require.True(t, ok, "нет сертификата Russian Trusted Root CA")
+	sub, ok := bySubject["Russian Trusted Sub CA"]
+	require.True(t, ok, "нет сертификата Russian Trusted Sub CA")
+	require.True(t, isRoot(root))
+	require.False(t, isRoot(sub))
+	require.NoError(t, sub.CheckSignatureFrom(root))
+
+	pool, err := NewConfig().WithoutSystemRoots().CertPool()
+	require.NoError(t, err)
+	chains, err := sub.Verify(x509.VerifyOptions{Roots: pool})
+	require.NoError(t, err)
+	require.Len(t, chains, 1)
+	require.Len(t, chains[0], 2, "Russian Trusted Sub CA не должен быть доверенным корневым сертификатом")
+}
+
+// embeddedSums - закрепленные отпечатки SHA-256 (DER) из certs/SHA256SUMS в формате sha256sum.
+func embeddedSums(t *testing.T) map[string]string {
+	data, err := fs.ReadFile(embedded, "certs/SHA256SUMS")
+	require.NoError(t, err, "нет закрепленных отпечатков certs/SHA256SUMS, см certs/README.md")
+	sums := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		require.Len(t, fields, 2, "certs/SHA256SUMS: '%s'", line)
+		sum := strings.ToLower(strings.ReplaceAll(fields[0], ":", ""))
+		require.Len(t, sum, sha256.Size*2, "certs/SHA256SUMS: '%s'", line)
+		sums[sum] = fields[1]
+	}
+	return sums
+}
+
+type suiteRootCA struct {
+	suite.Suite
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	caPEM  []byte
+	server *httptest.Server
+}
+
+func TestRootCA(t *testing.T) {
+	suite.Run(t, new(suiteRootCA))
+}
+
+func (suite *suiteRootCA) SetupTest() {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Trusted Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	suite.Require().NoError(err)
+	suite.caKey = caKey
+	suite.ca, err = x509.ParseCertificate(caDER)
+	suite.Require().NoError(err)
+	suite.caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	serverDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, suite.ca, &serverKey.PublicKey, caKey)
+	suite.Require().NoError(err)
+
+	suite.server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	suite.server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	suite.server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}}}
+	suite.server.StartTLS()
+
+	embedded = fstest.MapFS{"certs/test_root_ca.pem": {Data: suite.caPEM}, "certs/README.md": {Data: []byte("test")}}
+}
+
+func (suite *suiteRootCA) TearDownTest() {
+	suite.server.Close()
+	embedded = certsFS
+	nowFunc = time.Now
+}
+
+func (suite *suiteRootCA) TestHTTPClient() {
+	suite.Run("embedded", func() {
+		client, err := NewConfig().HTTPClient()
+		suite.Require().NoError(err)
+		suite.get(client)
+	})
+
+	suite.Run("without system roots", func() {
+		client, err := NewConfig().WithoutSystemRoots().HTTPClient()
+		suite.Require().NoError(err)
+		suite.get(client)
+	})
+
+	suite.Run("extra roots", func() {
+		embedded = fstest.MapFS{"certs/README.md": {Data: []byte("test")}}
+		client, err := NewConfig().WithoutSystemRoots().WithRoots(suite.caPEM).HTTPClient()
+		suite.Require().NoError(err)
+		suite.get(client)
+		embedded = fstest.MapFS{"certs/test_root_ca.pem": {Data: suite.caPEM}}
+	})
+
+	suite.Run("error unknown authority", func() {
+		_, err := http.Get(suite.server.URL)
+		suite.ErrorContains(err, "certificate")
+	})
+}
+
+func (suite *suiteRootCA) TestIntermediate() {
+	subKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	subDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber:          big.NewInt(3),
+		Subject:               pkix.Name{CommonName: "Test Trusted Sub CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, suite.ca, &subKey.PublicKey, suite.caKey)
+	suite.Require().NoError(err)
+	sub, err := x509.ParseCertificate(subDER)
+	suite.Require().NoError(err)
+	subPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: subDER})
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(4),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, sub, &leafKey.PublicKey, subKey)
+	suite.Require().NoError(err)
+
+	// server - TLS-сервер с цепочкой сертификатов chain.
+	server := func(chain ...[]byte) *httptest.Server {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+		server.Config.ErrorLog = log.New(io.Discard, "", 0)
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: chain, PrivateKey: leafKey}}}
+		server.StartTLS()
+		return server
+	}
+	withSub := server(leafDER, subDER)
+	defer withSub.Close()
+	leafOnly := server(leafDER)
+	defer leafOnly.Close()
+
+	suite.Run("sub ca is not a trust anchor", func() {
+		embedded = fstest.MapFS{"certs/root.pem": {Data: suite.caPEM}, "certs/sub.pem": {Data: subPEM}}
+		certs, err := Certificates()
+		suite.Require().NoError(err)
+		suite.Len(certs, 2)
+
+		client, err := NewConfig().WithoutSystemRoots().HTTPClient()
+		suite.Require().NoError(err)
+		res, err := client.Get(withSub.URL)
+		suite.Require().NoError(err)
+		suite.NoError(res.Body.Close())
+
+		_, err = client.Get(leafOnly.URL)
+		suite.ErrorContains(err, "certificate signed by unknown authority")
+	})
+
+	suite.Run("pin sub ca", func() {
+		embedded = fstest.MapFS{"certs/root.pem": {Data: suite.caPEM}, "certs/sub.pem": {Data: subPEM}}
+		client, err := NewConfig().WithoutSystemRoots().WithPins(Pin(sub)).HTTPClient()
+		suite.Require().NoError(err)
+		res, err := client.Get(withSub.URL)
+		suite.Require().NoError(err)
+		suite.NoError(res.Body.Close())
+	})
+
+	suite.Run("no root", func() {
+		embedded = fstest.MapFS{"certs/sub.pem": {Data: subPEM}}
+		_, err := NewConfig().WithoutSystemRoots().CertPool()
+		suite.ErrorIs(err, ErrNoCerts)
+
+		embedded = fstest.MapFS{"certs/README.md": {Data: []byte("test")}}
+		_, err = NewConfig().WithoutSystemRoots().WithRoots(subPEM).CertPool()
+		suite.ErrorIs(err, ErrNoCerts)
+	})
+}
+
+func (suite *suiteRootCA) TestPins() {
+	suite.Run("match", func() {
+		client, err := NewConfig().WithPins("sha256//other", "sha256//"+Pin(suite.ca)).HTTPClient()
+		suite.Require().NoError(err)
+		suite.get(client)
+	})
+
+	suite.Run("mismatch", func() {
+		client, err := NewConfig().WithPins("other").HTTPClient()
+		suite.Require().NoError(err)
+		_, err = client.Get(suite.server.URL)
+		suite.ErrorIs(err, ErrPinMismatch)
+	})
+}
+
+func (suite *suiteRootCA) TestExpiry() {
+	suite.Run("warning", func() {
+		logger := &testLogger{}
+		nowFunc = func() time.Time { return suite.ca.NotAfter.Add(-10 * 24 * time.Hour) }
+		_, err := NewConfig().WithExpiryWarning(30, logger).CertPool()
+		suite.Require().NoError(err)
+		suite.Require().Len(logger.messages, 1)
+		suite.Contains(logger.messages[0], "Test Trusted Root CA")
+	})
+
+	suite.Run("expired", func() {
+		nowFunc = func() time.Time { return suite.ca.NotAfter.Add(time.Hour) }
+		pool, err := NewConfig().CertPool()
+		suite.ErrorIs(err, ErrCertExpired)
+		suite.Nil(pool)
+		nowFunc = time.Now
+	})
+}
+
+func (suite *suiteRootCA) TestErrors() {
+	suite.Run("no certs", func() {
+		embedded = fstest.MapFS{"certs/README.md": {Data: []byte("test")}}
+		cfg, err := NewConfig().TLSConfig()
+		suite.ErrorIs(err, ErrNoCerts)
+		suite.Nil(cfg)
+	})
+
+	suite.Run("embedded parse", func() {
+		embedded = fstest.MapFS{"certs/bad.pem": {Data: []byte("test")}}
+		_, err := Certificates()
+		suite.ErrorIs(err, ErrCertParse)
+		suite.ErrorContains(err, "bad.pem")
+	})
+
+	suite.Run("extra roots parse", func() {
+		embedded = fstest.MapFS{"certs/README.md": {Data: []byte("test")}}
+		client, err := NewConfig().WithRoots([]byte("test")).HTTPClient()
+		suite.ErrorIs(err, ErrCertParse)
+		suite.Nil(client)
+	})
+}
+
+func (suite *suiteRootCA) get(client *http.Client) {
+	res, err := client.Get(suite.server.URL)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, res.StatusCode)
+	suite.NoError(res.Body.Close())
+}
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Print(v ...any) {
+	l.messages = append(l.messages, fmt.Sprint(v...))
+}
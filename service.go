@@ -0,0 +1,161 @@
+package apipgu
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Service - услуга API ЕПГУ: метаданные и архив заявления.
+// Позволяет создавать заявления по любой услуге без знания ее конкретного типа,
+// см [Client.OrderPushService] и [ServiceRegistry].
+//
+// Реализации находятся в пакетах услуг, например
+// [github.com/ofstudio/go-api-epgu/services/sfr/zdp-10000000109].
+type Service interface {
+	// ServiceCode - идентификатор формы заявления (serviceCode).
+	ServiceCode() string
+	// TargetCode - идентификатор цели обращения (targetCode).
+	TargetCode() string
+	// Meta - метаданные заявления для [Client.OrderCreate] и [Client.OrderPush].
+	Meta() OrderMeta
+	// EmbedsOrderId - возвращает true, если номер заявления должен быть указан в файлах архива.
+	// Тогда заявление создается до формирования архива, а архив загружается методом [Client.OrderPushChunked].
+	EmbedsOrderId() bool
+	// Archive - архив заявления с номером orderId. Если [Service.EmbedsOrderId] возвращает false,
+	// orderId может быть равен 0.
+	Archive(orderId int) (*Archive, error)
+}
+
+// ServiceFactory - создает услугу по данным заявления в формате JSON.
+type ServiceFactory func(data []byte) (Service, error)
+
+// ServiceEntry - запись реестра услуг [ServiceRegistry].
+type ServiceEntry struct {
+	ServiceCode string         // Идентификатор формы заявления
+	TargetCode  string         // Идентификатор цели обращения
+	Name        string         // Наименование услуги
+	New         ServiceFactory // Создание услуги по данным заявления в формате JSON
+	Data        any            // Пустое значение типа данных заявления для New: например, для описания схемы JSON
+}
+
+// ServiceRegistry - реестр услуг по кодам ServiceCode и TargetCode.
+// Безопасен для использования из нескольких горутин.
+//
+// Пакеты услуг регистрируют себя в реестре по умолчанию при импорте, см [RegisterService]:
+//
+//	import _ "github.com/ofstudio/go-api-epgu/services/sfr/zdp-10000000109"
+//
+//	svc, err := apipgu.NewService("10000000109", "-10000000109", data)
+//	orderId, err := client.OrderPushService(token, svc)
+type ServiceRegistry struct {
+	mu      sync.RWMutex
+	entries map[serviceKey]ServiceEntry
+}
+
+type serviceKey struct {
+	serviceCode string
+	targetCode  string
+}
+
+// NewServiceRegistry - конструктор [ServiceRegistry].
+func NewServiceRegistry() *ServiceRegistry {
+	return &ServiceRegistry{entries: map[serviceKey]ServiceEntry{}}
+}
+
+// Register - добавляет услугу в реестр.
+//
+// В случае ошибки возвращает цепочку из [ErrServiceRegistry] и описания ошибки:
+// не указаны коды услуги или New, либо услуга с такими кодами уже зарегистрирована.
+func (r *ServiceRegistry) Register(entry ServiceEntry) error {
+	if entry.ServiceCode == "" || entry.TargetCode == "" || entry.New == nil {
+		return fmt.Errorf("%w: не указаны ServiceCode, TargetCode или New", ErrServiceRegistry)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := serviceKey{entry.ServiceCode, entry.TargetCode}
+	if _, ok := r.entries[key]; ok {
+		return fmt.Errorf(
+			"%w: услуга уже зарегистрирована [serviceCode='%s', targetCode='%s']",
+			ErrServiceRegistry, entry.ServiceCode, entry.TargetCode,
+		)
+	}
+	r.entries[key] = entry
+	return nil
+}
+
+// Lookup - возвращает услугу по кодам serviceCode и targetCode.
+// Если targetCode пустой, возвращает единственную услугу с кодом serviceCode.
+func (r *ServiceRegistry) Lookup(serviceCode, targetCode string) (ServiceEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if targetCode != "" {
+		entry, ok := r.entries[serviceKey{serviceCode, targetCode}]
+		return entry, ok
+	}
+	var (
+		found ServiceEntry
+		n     int
+	)
+	for key, entry := range r.entries {
+		if key.serviceCode == serviceCode {
+			found = entry
+			n++
+		}
+	}
+	return found, n == 1
+}
+
+// NewService - создает услугу реестра с кодами serviceCode и targetCode по данным заявления data в формате JSON.
+// Пустой targetCode - см [ServiceRegistry.Lookup].
+//
+// В случае ошибки возвращает цепочку из [ErrServiceRegistry] и [ErrServiceNotFound]
+// либо ошибки [ServiceEntry.New].
+func (r *ServiceRegistry) NewService(serviceCode, targetCode string, data []byte) (Service, error) {
+	entry, ok := r.Lookup(serviceCode, targetCode)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: %w [serviceCode='%s', targetCode='%s']",
+			ErrServiceRegistry, ErrServiceNotFound, serviceCode, targetCode,
+		)
+	}
+	return entry.New(data)
+}
+
+// Entries - услуги реестра, упорядоченные по ServiceCode и TargetCode.
+func (r *ServiceRegistry) Entries() []ServiceEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]ServiceEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ServiceCode != entries[j].ServiceCode {
+			return entries[i].ServiceCode < entries[j].ServiceCode
+		}
+		return entries[i].TargetCode < entries[j].TargetCode
+	})
+	return entries
+}
+
+// DefaultServices - реестр услуг по умолчанию, в котором регистрируются пакеты услуг.
+var DefaultServices = NewServiceRegistry()
+
+// RegisterService - добавляет услугу в реестр [DefaultServices].
+// Вызывается из функции init пакета услуги; при ошибке регистрации завершается паникой.
+func RegisterService(entry ServiceEntry) {
+	if err := DefaultServices.Register(entry); err != nil {
+		panic(err)
+	}
+}
+
+// LookupService - возвращает услугу реестра [DefaultServices], см [ServiceRegistry.Lookup].
+func LookupService(serviceCode, targetCode string) (ServiceEntry, bool) {
+	return DefaultServices.Lookup(serviceCode, targetCode)
+}
+
+// NewService - создает услугу реестра [DefaultServices], см [ServiceRegistry.NewService].
+func NewService(serviceCode, targetCode string, data []byte) (Service, error) {
+	return DefaultServices.NewService(serviceCode, targetCode, data)
+}
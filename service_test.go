@@ -0,0 +1,230 @@
+package apipgu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestServiceRegistry(t *testing.T) {
+	suite.Run(t, new(suiteTestServiceRegistry))
+}
+
+type suiteTestServiceRegistry struct {
+	suite.Suite
+}
+
+// testService - услуга для тестов.
+type testService struct {
+	embeds     bool
+	archiveErr error
+	orderId    int // Номер заявления, переданный в Archive
+}
+
+func (s *testService) ServiceCode() string { return testMeta.ServiceCode }
+func (s *testService) TargetCode() string  { return testMeta.TargetCode }
+func (s *testService) Meta() OrderMeta     { return testMeta }
+func (s *testService) EmbedsOrderId() bool { return s.embeds }
+
+func (s *testService) Archive(orderId int) (*Archive, error) {
+	s.orderId = orderId
+	if s.archiveErr != nil {
+		return nil, s.archiveErr
+	}
+	return &Archive{Name: "test-archive", Data: []byte("test-data")}, nil
+}
+
+func testServiceEntry(serviceCode, targetCode string) ServiceEntry {
+	return ServiceEntry{
+		ServiceCode: serviceCode,
+		TargetCode:  targetCode,
+		Name:        "Тестовая услуга",
+		New: func(data []byte) (Service, error) {
+			svc := &testService{}
+			if err := json.Unmarshal(data, &struct{}{}); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrServiceData, err)
+			}
+			return svc, nil
+		},
+	}
+}
+
+func (suite *suiteTestServiceRegistry) TestRegister() {
+	suite.Run("success", func() {
+		r := NewServiceRegistry()
+		suite.NoError(r.Register(testServiceEntry("1", "-1")))
+		suite.NoError(r.Register(testServiceEntry("1", "-2")))
+		suite.NoError(r.Register(testServiceEntry("0", "-1")))
+
+		entries := r.Entries()
+		suite.Require().Len(entries, 3)
+		suite.Equal([]string{"0", "1", "1"}, []string{entries[0].ServiceCode, entries[1].ServiceCode, entries[2].ServiceCode})
+		suite.Equal([]string{"-1", "-1", "-2"}, []string{entries[0].TargetCode, entries[1].TargetCode, entries[2].TargetCode})
+	})
+
+	suite.Run("duplicate", func() {
+		r := NewServiceRegistry()
+		suite.NoError(r.Register(testServiceEntry("1", "-1")))
+		err := r.Register(testServiceEntry("1", "-1"))
+		suite.ErrorIs(err, ErrServiceRegistry)
+		suite.Equal("ошибка реестра услуг: услуга уже зарегистрирована [serviceCode='1', targetCode='-1']", err.Error())
+	})
+
+	suite.Run("empty fields", func() {
+		r := NewServiceRegistry()
+		suite.ErrorIs(r.Register(testServiceEntry("", "-1")), ErrServiceRegistry)
+		suite.ErrorIs(r.Register(testServiceEntry("1", "")), ErrServiceRegistry)
+		entry := testServiceEntry("1", "-1")
+		entry.New = nil
+		suite.ErrorIs(r.Register(entry), ErrServiceRegistry)
+		suite.Empty(r.Entries())
+	})
+}
+
+func (suite *suiteTestServiceRegistry) TestLookup() {
+	r := NewServiceRegistry()
+	suite.Require().NoError(r.Register(testServiceEntry("1", "-1")))
+	suite.Require().NoError(r.Register(testServiceEntry("2", "-1")))
+	suite.Require().NoError(r.Register(testServiceEntry("2", "-2")))
+
+	suite.Run("with targetCode", func() {
+		entry, ok := r.Lookup("2", "-2")
+		suite.True(ok)
+		suite.Equal("2", entry.ServiceCode)
+		suite.Equal("-2", entry.TargetCode)
+	})
+
+	suite.Run("empty targetCode with single target", func() {
+		entry, ok := r.Lookup("1", "")
+		suite.True(ok)
+		suite.Equal("-1", entry.TargetCode)
+	})
+
+	suite.Run("empty targetCode with multiple targets", func() {
+		_, ok := r.Lookup("2", "")
+		suite.False(ok)
+	})
+
+	suite.Run("not found", func() {
+		_, ok := r.Lookup("1", "-2")
+		suite.False(ok)
+		_, ok = r.Lookup("3", "")
+		suite.False(ok)
+	})
+}
+
+func (suite *suiteTestServiceRegistry) TestNewService() {
+	r := NewServiceRegistry()
+	suite.Require().NoError(r.Register(testServiceEntry("1", "-1")))
+
+	suite.Run("success", func() {
+		svc, err := r.NewService("1", "-1", []byte(`{}`))
+		suite.NoError(err)
+		suite.IsType(&testService{}, svc)
+	})
+
+	suite.Run("not found", func() {
+		svc, err := r.NewService("2", "", []byte(`{}`))
+		suite.Nil(svc)
+		suite.ErrorIs(err, ErrServiceRegistry)
+		suite.ErrorIs(err, ErrServiceNotFound)
+		suite.Equal("ошибка реестра услуг: услуга не найдена [serviceCode='2', targetCode='']", err.Error())
+	})
+
+	suite.Run("wrong data", func() {
+		svc, err := r.NewService("1", "-1", []byte(`malformed json{}`))
+		suite.Nil(svc)
+		suite.ErrorIs(err, ErrServiceData)
+	})
+}
+
+func (suite *suiteTestClient) TestOrderPushService() {
+
+	suite.Run("200 success with OrderPush", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			suite.Equal("/api/gusmev/push", r.URL.Path)
+			suite.NoError(r.ParseMultipartForm(0))
+			suite.JSONEq(`{"region":"test-region","serviceCode":"test-service","targetCode":"test-target"}`, r.FormValue("meta"))
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"orderId":123456}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		svc := &testService{}
+		orderId, err := client.OrderPushService(testToken, svc)
+		suite.NoError(err)
+		suite.Equal(123456, orderId)
+		suite.Equal(0, svc.orderId)
+	})
+
+	suite.Run("200 success with OrderCreate and OrderPushChunked", func() {
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			if r.URL.Path == "/api/gusmev/order" {
+				body, _ := io.ReadAll(r.Body)
+				suite.JSONEq(`{"region":"test-region","serviceCode":"test-service","targetCode":"test-target"}`, string(body))
+			} else {
+				suite.NoError(r.ParseMultipartForm(0))
+				suite.Equal("123456", r.FormValue("orderId"))
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"orderId":123456}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		svc := &testService{embeds: true}
+		orderId, err := client.OrderPushService(testToken, svc)
+		suite.NoError(err)
+		suite.Equal(123456, orderId)
+		suite.Equal(123456, svc.orderId)
+		suite.Equal([]string{"/api/gusmev/order", "/api/gusmev/push/chunked"}, paths)
+	})
+
+	suite.Run("archive error", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"orderId":123456}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		orderId, err := client.OrderPushService(testToken, &testService{archiveErr: ErrService})
+		suite.ErrorIs(err, ErrPushService)
+		suite.ErrorIs(err, ErrService)
+		suite.Equal(0, orderId)
+
+		orderId, err = client.OrderPushService(testToken, &testService{embeds: true, archiveErr: ErrService})
+		suite.ErrorIs(err, ErrPushService)
+		suite.ErrorIs(err, ErrService)
+		suite.Equal(123456, orderId)
+	})
+
+	suite.Run("OrderCreate error", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":"access_denied_service","message":"Доступ запрещен"}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		orderId, err := client.OrderPushService(testToken, &testService{embeds: true})
+		suite.ErrorIs(err, ErrPushService)
+		suite.ErrorIs(err, ErrOrderCreate)
+		suite.ErrorIs(err, ErrCodeAccessDeniedService)
+		suite.Equal(0, orderId)
+	})
+}
@@ -1,10 +1,16 @@
 package sfr
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"time"
 )
 
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05"
+)
+
 // Date - дата в формате YYYY-MM-DD
 type Date struct {
 	time.Time
@@ -18,7 +24,19 @@ func NewDate(year int, month time.Month, day int) Date {
 // MarshalXML - реализация интерфейса [xml.Marshaler].
 // Формат даты: YYYY-MM-DD.
 func (d Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	return e.EncodeElement(d.Format("2006-01-02"), start)
+	return e.EncodeElement(d.Format(dateLayout), start)
+}
+
+// MarshalJSON - реализация интерфейса [json.Marshaler].
+// Формат даты: "YYYY-MM-DD".
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(dateLayout))
+}
+
+// UnmarshalJSON - реализация интерфейса [json.Unmarshaler].
+// Формат даты: "YYYY-MM-DD".
+func (d *Date) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONTime(data, dateLayout, &d.Time)
 }
 
 // DateTime - дата и время в формате YYYY-MM-DDThh:mm:ss
@@ -34,5 +52,36 @@ func NewDateTime(year int, month time.Month, day, hour, min, sec int) DateTime {
 // MarshalXML - реализация интерфейса [xml.Marshaler].
 // Формат даты и времени: YYYY-MM-DDThh:mm:ss.
 func (d DateTime) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	return e.EncodeElement(d.Format("2006-01-02T15:04:05"), start)
+	return e.EncodeElement(d.Format(dateTimeLayout), start)
+}
+
+// MarshalJSON - реализация интерфейса [json.Marshaler].
+// Формат даты и времени: "YYYY-MM-DDThh:mm:ss".
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(dateTimeLayout))
+}
+
+// UnmarshalJSON - реализация интерфейса [json.Unmarshaler].
+// Формат даты и времени: "YYYY-MM-DDThh:mm:ss".
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONTime(data, dateTimeLayout, &d.Time)
+}
+
+// unmarshalJSONTime - читает из JSON-строки время в формате layout (UTC).
+// Пустая строка и null - нулевое время.
+func unmarshalJSONTime(data []byte, layout string, t *time.Time) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		*t = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(layout, *s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
 }
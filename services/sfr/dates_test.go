@@ -1,6 +1,7 @@
 package sfr
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 )
@@ -34,3 +35,23 @@ func ExampleNewDateTime() {
 
 	// Output: <Example><DateTime>2019-01-12T13:14:15</DateTime></Example>
 }
+
+func ExampleDate_MarshalJSON() {
+	type Example struct {
+		Date     Date     `json:"date"`
+		DateTime DateTime `json:"dateTime"`
+	}
+	doc := Example{}
+	err := json.Unmarshal([]byte(`{"date": "2019-01-12", "dateTime": "2019-01-12T13:14:15"}`), &doc)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(result))
+
+	// Output: {"date":"2019-01-12","dateTime":"2019-01-12T13:14:15"}
+}
@@ -1,6 +1,7 @@
 package sfr
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -65,8 +66,11 @@ func (s SNILS) Number() string {
 	return s.number
 }
 
-// String возвращает СНИЛС в формате "000-000-000 00".
+// String возвращает СНИЛС в формате "000-000-000 00" либо пустую строку для пустого СНИЛС.
 func (s SNILS) String() string {
+	if s.number == "" {
+		return ""
+	}
 	return fmt.Sprintf("%s-%s-%s %s", s.number[:3], s.number[3:6], s.number[6:9], s.number[9:])
 }
 
@@ -75,3 +79,31 @@ func (s SNILS) String() string {
 func (s SNILS) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return e.EncodeElement(s.String(), start)
 }
+
+// MarshalJSON реализует интерфейс [json.Marshaler] для типа [SNILS].
+// Формат СНИЛС: "000-000-000 00", пустой СНИЛС - null.
+func (s SNILS) MarshalJSON() ([]byte, error) {
+	if s.number == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON реализует интерфейс [json.Unmarshaler] для типа [SNILS].
+// Входной формат аналогичен [ParseSNILS]; null и пустая строка - пустой СНИЛС.
+func (s *SNILS) UnmarshalJSON(data []byte) error {
+	var number *string
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	if number == nil || *number == "" {
+		*s = SNILS{}
+		return nil
+	}
+	snils, err := ParseSNILS(*number)
+	if err != nil {
+		return err
+	}
+	*s = snils
+	return nil
+}
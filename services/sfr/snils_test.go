@@ -1,6 +1,7 @@
 package sfr
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -48,3 +49,45 @@ func TestParseSNILS(t *testing.T) {
 		})
 	}
 }
+
+func TestSNILS_UnmarshalJSON(t *testing.T) {
+	var snils SNILS
+	if err := json.Unmarshal([]byte(`"276 488 905 42"`), &snils); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	data, err := json.Marshal(snils)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if string(data) != `"276-488-905 42"` {
+		t.Errorf("MarshalJSON() got = %s", data)
+	}
+	if err = json.Unmarshal([]byte(`"200 746 095 00"`), &snils); !errors.Is(err, ErrSNILSCheck) {
+		t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, ErrSNILSCheck)
+	}
+}
+
+func TestSNILS_JSONZero(t *testing.T) {
+	type person struct {
+		SNILS SNILS `json:"snils"`
+	}
+	data, err := json.Marshal(person{})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if string(data) != `{"snils":null}` {
+		t.Errorf("MarshalJSON() got = %s", data)
+	}
+	for _, in := range []string{`{"snils":null}`, `{"snils":""}`} {
+		p := person{SNILS: MustParseSNILS("276 488 905 42")}
+		if err = json.Unmarshal([]byte(in), &p); err != nil {
+			t.Fatalf("UnmarshalJSON(%s) error = %v", in, err)
+		}
+		if p.SNILS != (SNILS{}) {
+			t.Errorf("UnmarshalJSON(%s) got = %v", in, p.SNILS)
+		}
+	}
+	if (SNILS{}).String() != "" {
+		t.Errorf("String() got = %s", SNILS{})
+	}
+}
@@ -1,6 +1,8 @@
 package zdp
 
 import (
+	"bytes"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"time"
@@ -21,9 +23,21 @@ var (
 	errService    = fmt.Errorf("%w: %s", apipgu.ErrService, "10000000109-sfr-zdp")
 	errXMLMarshal = fmt.Errorf("%w: %w", errService, apipgu.ErrXMLMarshal)
 	errGUID       = fmt.Errorf("%w: %w", errService, apipgu.ErrGUID)
+	errData       = fmt.Errorf("%w: %w", errService, apipgu.ErrServiceData)
 )
 
-// Service - Услуга "Доставка пенсии и социальных выплат ПФР"
+func init() {
+	apipgu.RegisterService(apipgu.ServiceEntry{
+		ServiceCode: ServiceCode,
+		TargetCode:  TargetCode,
+		Name:        "Доставка пенсии и социальных выплат СФР",
+		New:         NewServiceJSON,
+		Data:        Data{},
+	})
+}
+
+// Service - Услуга "Доставка пенсии и социальных выплат ПФР".
+// Реализует интерфейс [apipgu.Service].
 type Service struct {
 	EDPFR
 	Request
@@ -31,6 +45,8 @@ type Service struct {
 	logger utils.Logger
 }
 
+var _ apipgu.Service = (*Service)(nil)
+
 // NewService - конструктор [Service].
 // Принимает коды ОКАТО и ОКТМО заявителя, а также данные заявления.
 // В качестве ОКАТО и ОКТМО можно использовать коды региона заявителя. Напр.: "92000000000".
@@ -73,6 +89,36 @@ func NewService(okato, oktmo string, zdp ZDP) (*Service, error) {
 	}, nil
 }
 
+// Data - данные заявления в формате JSON для [NewServiceJSON] и реестра услуг [apipgu.DefaultServices].
+// Поля [ZDP] указываются по именам полей Go, даты - в формате "YYYY-MM-DD", СНИЛС - в формате [sfr.ParseSNILS].
+type Data struct {
+	OKATO string `json:"okato"` // Код ОКАТО заявителя или региона. Пример: "92000000000"
+	OKTMO string `json:"oktmo"` // Код ОКТМО заявителя или региона
+	ZDP   ZDP    `json:"zdp"`   // Данные заявления
+}
+
+// NewServiceJSON - создает [Service] по данным заявления [Data] в формате JSON, см [apipgu.ServiceFactory].
+// Неизвестные поля JSON считаются ошибкой.
+//
+// В случае ошибки возвращает цепочку из apipgu.ErrService и следующих возможных ошибок:
+//   - apipgu.ErrServiceData - ошибка чтения JSON, не указаны okato, oktmo или СНИЛС заявителя
+//   - apipgu.ErrGUID - ошибка генерации GUID
+func NewServiceJSON(data []byte) (apipgu.Service, error) {
+	d := Data{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("%w: %w", errData, err)
+	}
+	if d.OKATO == "" || d.OKTMO == "" {
+		return nil, fmt.Errorf("%w: не указаны okato и oktmo", errData)
+	}
+	if d.ZDP.Applicant.SNILS.Number() == "" {
+		return nil, fmt.Errorf("%w: не указан СНИЛС заявителя", errData)
+	}
+	return NewService(d.OKATO, d.OKTMO, d.ZDP)
+}
+
 // WithDebug - включает логирование создаваемых XML-файлов и метаданных услуги.
 // Формат лога:
 //
@@ -97,6 +143,22 @@ func (s *Service) Meta() apipgu.OrderMeta {
 	return meta
 }
 
+// ServiceCode - возвращает идентификатор формы заявления [ServiceCode].
+func (s *Service) ServiceCode() string {
+	return ServiceCode
+}
+
+// TargetCode - возвращает идентификатор цели [TargetCode].
+func (s *Service) TargetCode() string {
+	return TargetCode
+}
+
+// EmbedsOrderId - номер заявления указывается в файлах архива (ExternalRegistrationNumber),
+// поэтому заявление создается до формирования архива.
+func (s *Service) EmbedsOrderId() bool {
+	return true
+}
+
 // Archive - возвращает архив с файлом заявления и транспортным файлом.
 // В случае ошибки возвращает цепочку из apipgu.ErrService и следующих возможных ошибок:
 //   - apipgu.ErrXMLMarshal - ошибка создания XML
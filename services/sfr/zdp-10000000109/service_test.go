@@ -0,0 +1,198 @@
+package zdp
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	apipgu "github.com/ofstudio/go-api-epgu"
+)
+
+const testGUID = "8f8b7e4b-dec8-4dac-8a02-3dcde44d4fb2"
+
+var testNow = time.Date(2023, 4, 13, 14, 48, 3, 0, time.Local)
+
+// testData - данные заявления в формате [Data].
+const testData = `{
+	"okato": "92401379000",
+	"oktmo": "92701000001",
+	"zdp": {
+		"TOSFR": "Клиентская служба в Ново-Савиновском районе Казани",
+		"Applicant": {
+			"FIO": {"LastName": "ИВАНОВ", "FirstName": "ИВАН", "PatronymicName": "ИВАНОВИЧ"},
+			"Sex": "М",
+			"BirthDate": "1960-04-13",
+			"SNILS": "715-398-174 20",
+			"Phone": "89123456789"
+		},
+		"DeliveryInfo": {
+			"Location": 1,
+			"Method": 2,
+			"Recipient": 1,
+			"Organisation": "АО \"РОССЕЛЬХОЗБАНК\"",
+			"AccountNumber": "40817810000000000001"
+		},
+		"Confirmation": 1
+	}
+}`
+
+// stubFuncs - фиксирует время и GUID на время теста.
+func stubFuncs(t *testing.T) {
+	t.Helper()
+	now, guid := nowFunc, guidFunc
+	nowFunc = func() time.Time { return testNow }
+	guidFunc = func() (string, error) { return testGUID, nil }
+	t.Cleanup(func() { nowFunc, guidFunc = now, guid })
+}
+
+// newTestService - услуга из реестра [apipgu.DefaultServices] по данным data.
+func newTestService(t *testing.T, data string) *Service {
+	t.Helper()
+	svc, err := apipgu.NewService(ServiceCode, TargetCode, []byte(data))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	s, ok := svc.(*Service)
+	if !ok {
+		t.Fatalf("NewService() got type %T, want *Service", svc)
+	}
+	return s
+}
+
+func TestNewServiceJSON(t *testing.T) {
+	stubFuncs(t)
+	s := newTestService(t, testData)
+
+	if got := s.Request.SNILS; got != "71539817420" {
+		t.Errorf("Request.SNILS got = %s", got)
+	}
+	if s.Request.OKATO != "92401379000" || s.Request.OKTMO != "92701000001" {
+		t.Errorf("Request OKATO, OKTMO got = %s, %s", s.Request.OKATO, s.Request.OKTMO)
+	}
+	if got := s.Meta(); got != (apipgu.OrderMeta{Region: "92401379000", ServiceCode: ServiceCode, TargetCode: TargetCode}) {
+		t.Errorf("Meta() got = %+v", got)
+	}
+	zdp := s.EDPFR.ZDP
+	if zdp.Applicant.FIO.LastName != "ИВАНОВ" || zdp.Applicant.FIO.PatronymicName != "ИВАНОВИЧ" {
+		t.Errorf("Applicant.FIO got = %+v", zdp.Applicant.FIO)
+	}
+	if got := zdp.Applicant.BirthDate.Format(time.DateOnly); got != "1960-04-13" {
+		t.Errorf("Applicant.BirthDate got = %s", got)
+	}
+	if zdp.DeliveryInfo.Method != DeliveryBank || zdp.DeliveryInfo.Location != DeliveryBankOrHome || zdp.DeliveryInfo.Recipient != DeliveryMyself {
+		t.Errorf("DeliveryInfo got = %+v", zdp.DeliveryInfo)
+	}
+	if !zdp.FillingDate.Equal(testNow) || !zdp.DeliveryInfo.Date.Equal(testNow) {
+		t.Errorf("FillingDate, DeliveryInfo.Date got = %s, %s; want now", zdp.FillingDate, zdp.DeliveryInfo.Date)
+	}
+	if s.EDPFR.ServiceInfo.GUID != testGUID {
+		t.Errorf("ServiceInfo.GUID got = %s", s.EDPFR.ServiceInfo.GUID)
+	}
+	if !s.EmbedsOrderId() {
+		t.Errorf("EmbedsOrderId() got = false")
+	}
+}
+
+func TestNewServiceJSON_Errors(t *testing.T) {
+	stubFuncs(t)
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "неизвестное поле", data: strings.Replace(testData, `"okato"`, `"region": "92", "okato"`, 1)},
+		{name: "неизвестное поле ZDP", data: strings.Replace(testData, `"TOSFR"`, `"ТерОрган": "", "TOSFR"`, 1)},
+		{name: "ключ XML вместо поля Go", data: strings.Replace(testData, `"Applicant"`, `"Анкета"`, 1)},
+		{name: "нет okato", data: strings.Replace(testData, `"okato": "92401379000"`, `"okato": ""`, 1)},
+		{name: "нет oktmo", data: strings.Replace(testData, `"oktmo": "92701000001",`, ``, 1)},
+		{name: "нет СНИЛС", data: strings.Replace(testData, `"SNILS": "715-398-174 20",`, ``, 1)},
+		{name: "пустой СНИЛС", data: strings.Replace(testData, `"715-398-174 20"`, `""`, 1)},
+		{name: "ошибка контрольная сумма СНИЛС", data: strings.Replace(testData, `"715-398-174 20"`, `"000-666-666 99"`, 1)},
+		{name: "ошибка дата", data: strings.Replace(testData, `"1960-04-13"`, `"13.04.1960"`, 1)},
+		{name: "ошибка JSON", data: `{"okato": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := apipgu.NewService(ServiceCode, TargetCode, []byte(tt.data))
+			if svc != nil {
+				t.Errorf("NewService() got = %v, want nil", svc)
+			}
+			if !errors.Is(err, apipgu.ErrService) || !errors.Is(err, apipgu.ErrServiceData) {
+				t.Errorf("NewService() error = %v, want %v and %v", err, apipgu.ErrService, apipgu.ErrServiceData)
+			}
+		})
+	}
+}
+
+func TestService_Archive(t *testing.T) {
+	stubFuncs(t)
+	s := newTestService(t, testData)
+
+	archive, err := s.Archive(1230254874)
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if archive.Name != "1230254874-archive" {
+		t.Errorf("Archive() name = %s", archive.Name)
+	}
+
+	files := unzip(t, archive.Data)
+	if len(files) != 2 {
+		t.Fatalf("Archive() files = %d, want 2", len(files))
+	}
+	req, ok := files["req_"+testGUID+".xml"]
+	if !ok {
+		t.Fatalf("Archive() no req file")
+	}
+	trans, ok := files["trans_"+testGUID+".xml"]
+	if !ok {
+		t.Fatalf("Archive() no trans file")
+	}
+
+	for _, want := range []string{
+		"<НомерВнешний>1230254874</НомерВнешний>",
+		"<УТ:СтраховойНомер>715-398-174 20</УТ:СтраховойНомер>",
+		"<ВЗЛ:ДатаЗаполнения>2023-04-13</ВЗЛ:ДатаЗаполнения>",
+		"<АФ:GUID>" + testGUID + "</АФ:GUID>",
+	} {
+		if !strings.Contains(req, want) {
+			t.Errorf("req file does not contain %s:\n%s", want, req)
+		}
+	}
+	for _, want := range []string{
+		"<ExternalRegistrationNumber>1230254874</ExternalRegistrationNumber>",
+		"<SNILS>71539817420</SNILS>",
+		"<ApplicationFileName>req_" + testGUID + ".xml</ApplicationFileName>",
+		"<OKATO>92401379000</OKATO>",
+	} {
+		if !strings.Contains(trans, want) {
+			t.Errorf("trans file does not contain %s:\n%s", want, trans)
+		}
+	}
+}
+
+// unzip - файлы zip-архива по именам.
+func unzip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
@@ -0,0 +1,154 @@
+package apipgu
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+// SignatureVerifier - интерфейс проверки электронной подписи файлов заявления и ответа ведомства.
+//
+// Реализация на чистом Go: [signature.Verifier].
+//
+// [signature.Verifier]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/signature#Verifier
+type SignatureVerifier interface {
+	VerifyDetached(data, sig []byte) (*signature.SignerInfo, error)
+	VerifyAttached(sig []byte) ([]byte, *signature.SignerInfo, error)
+}
+
+// SignedFile - файл заявления или ответа ведомства и его электронная подпись.
+// Используется в методе [Client.AttachmentDownloadVerified].
+type SignedFile struct {
+	FileName      string // Имя файла
+	Link          string // Ссылка на файл в хранилище
+	SignatureLink string // Ссылка на файл отсоединенной подписи "<FileName>.sig" или "<FileName>.p7s", если он есть
+	Enveloped     bool   // Файл является присоединенной подписью CMS, содержащей подписанные данные
+}
+
+// Signed - возвращает true, если у файла есть электронная подпись.
+func (f SignedFile) Signed() bool {
+	return f.SignatureLink != "" || f.Enveloped
+}
+
+// SignedResponseFiles - сопоставляет файлам ответа ведомства [OrderDetails.OrderResponseFiles]
+// файлы отсоединенной подписи "<имя файла>.sig" и "<имя файла>.p7s".
+//
+// Файл ".p7m", файл ".sig" без парного файла, а также файл с признаком [OrderResponseFile.HasDigitalSignature]
+// без парного файла подписи считается присоединенной подписью ([SignedFile.Enveloped]).
+// Файл ".p7s" без парного файла возвращается без подписи: подписанных данных в нем нет.
+// Файлы без подписи также возвращаются: для них [SignedFile.Signed] возвращает false.
+func SignedResponseFiles(files []OrderResponseFile) []SignedFile {
+	entries := make([]signedFileEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, signedFileEntry{f.FileName, f.Link, f.HasDigitalSignature})
+	}
+	return signedFiles(entries)
+}
+
+// SignedAttachmentFiles - то же, что [SignedResponseFiles], для файлов заявления [OrderDetails.OrderAttachmentFiles].
+func SignedAttachmentFiles(files []OrderAttachmentFile) []SignedFile {
+	entries := make([]signedFileEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, signedFileEntry{f.FileName, f.Link, f.HasDigitalSignature})
+	}
+	return signedFiles(entries)
+}
+
+// AttachmentDownloadVerified - скачивание файла заявления или ответа ведомства
+// с проверкой его электронной подписи.
+//
+// Параметры:
+//   - token - маркер доступа ЕСИА
+//   - file - файл и его подпись, см [SignedResponseFiles] и [SignedAttachmentFiles]
+//   - verifier - проверка подписи, см [signature.Verifier]
+//
+// Для отсоединенной подписи скачиваются файл и файл подписи,
+// для присоединенной подписи подписанные данные извлекаются из файла.
+// Возвращает содержимое файла и сведения о подписанте (организация, ОГРН, время подписания).
+//
+// В случае ошибки возвращает цепочку из [ErrAttachmentVerify] и ошибок:
+//   - [ErrNoSignature] - у файла нет электронной подписи
+//   - ошибок [Client.AttachmentDownload]
+//   - ошибок проверки подписи (например, [signature.ErrSignatureInvalid], [signature.ErrCertUntrusted])
+//
+// [signature.Verifier]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/signature#Verifier
+// [signature.ErrSignatureInvalid]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/signature#ErrSignatureInvalid
+// [signature.ErrCertUntrusted]: https://pkg.go.dev/github.com/ofstudio/go-api-epgu/esia/signature#ErrCertUntrusted
+func (c *Client) AttachmentDownloadVerified(
+	token string,
+	file SignedFile,
+	verifier SignatureVerifier,
+) ([]byte, *signature.SignerInfo, error) {
+	if !file.Signed() {
+		return nil, nil, fmt.Errorf("%w: %w '%s'", ErrAttachmentVerify, ErrNoSignature, file.FileName)
+	}
+
+	data, err := c.AttachmentDownload(token, file.Link)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrAttachmentVerify, err)
+	}
+
+	if file.SignatureLink == "" {
+		content, info, err := verifier.VerifyAttached(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: файл '%s': %w", ErrAttachmentVerify, file.FileName, err)
+		}
+		return content, info, nil
+	}
+
+	sig, err := c.AttachmentDownload(token, file.SignatureLink)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrAttachmentVerify, err)
+	}
+	info, err := verifier.VerifyDetached(data, sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: файл '%s': %w", ErrAttachmentVerify, file.FileName, err)
+	}
+	return data, info, nil
+}
+
+// signedFileEntry - общие поля [OrderResponseFile] и [OrderAttachmentFile].
+type signedFileEntry struct {
+	name         string
+	link         string
+	hasSignature bool
+}
+
+// detachedExts - расширения файлов отсоединенной подписи в порядке предпочтения.
+var detachedExts = []string{SignatureExt, ".p7s"}
+
+// signedFiles - сопоставляет файлам файлы отсоединенной подписи.
+func signedFiles(entries []signedFileEntry) []SignedFile {
+	names := make(map[string]signedFileEntry, len(entries))
+	for _, e := range entries {
+		names[strings.ToLower(e.name)] = e
+	}
+
+	result := make([]SignedFile, 0, len(entries))
+	for _, e := range entries {
+		lower := strings.ToLower(e.name)
+		switch ext := path.Ext(lower); ext {
+		case SignatureExt, ".p7s":
+			// Файл отсоединенной подписи возвращается вместе с парным файлом
+			if _, ok := names[strings.TrimSuffix(lower, ext)]; ok {
+				continue
+			}
+			result = append(result, SignedFile{FileName: e.name, Link: e.link, Enveloped: ext == SignatureExt})
+			continue
+		case ".p7m":
+			result = append(result, SignedFile{FileName: e.name, Link: e.link, Enveloped: true})
+			continue
+		}
+		file := SignedFile{FileName: e.name, Link: e.link, Enveloped: e.hasSignature}
+		for _, ext := range detachedExts {
+			if sig, ok := names[lower+ext]; ok {
+				file.SignatureLink, file.Enveloped = sig.link, false
+				break
+			}
+		}
+		result = append(result, file)
+	}
+	return result
+}
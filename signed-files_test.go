@@ -0,0 +1,171 @@
+package apipgu
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ofstudio/go-api-epgu/esia/signature"
+)
+
+func TestSignedFiles(t *testing.T) {
+	suite.Run(t, new(suiteTestSignedFiles))
+}
+
+type suiteTestSignedFiles struct {
+	suite.Suite
+}
+
+func (suite *suiteTestSignedFiles) TestSignedResponseFiles() {
+	files := SignedResponseFiles([]OrderResponseFile{
+		{FileName: "resp.xml", Link: "terrabyte://00/1/resp.xml/2"},
+		{FileName: "resp.xml.sig", Link: "terrabyte://00/1/resp.xml.sig/2", HasDigitalSignature: true},
+		{FileName: "result.pdf.sig", Link: "terrabyte://00/1/result.pdf.sig/2"},
+		{FileName: "result.xml", Link: "terrabyte://00/1/result.xml/2", HasDigitalSignature: true},
+		{FileName: "info.txt", Link: "terrabyte://00/1/info.txt/2"},
+	})
+	suite.Equal([]SignedFile{
+		{FileName: "resp.xml", Link: "terrabyte://00/1/resp.xml/2", SignatureLink: "terrabyte://00/1/resp.xml.sig/2"},
+		{FileName: "result.pdf.sig", Link: "terrabyte://00/1/result.pdf.sig/2", Enveloped: true},
+		{FileName: "result.xml", Link: "terrabyte://00/1/result.xml/2", Enveloped: true},
+		{FileName: "info.txt", Link: "terrabyte://00/1/info.txt/2"},
+	}, files)
+	suite.True(files[0].Signed())
+	suite.False(files[3].Signed())
+
+	suite.Equal([]SignedFile{
+		{FileName: "req.xml", Link: "terrabyte://00/1/req.xml/2", SignatureLink: "terrabyte://00/1/req.xml.sig/2"},
+	}, SignedAttachmentFiles([]OrderAttachmentFile{
+		{FileName: "req.xml.sig", Link: "terrabyte://00/1/req.xml.sig/2"},
+		{FileName: "req.xml", Link: "terrabyte://00/1/req.xml/2"},
+	}))
+}
+
+func (suite *suiteTestSignedFiles) TestSignedResponseFilesP7() {
+	files := SignedResponseFiles([]OrderResponseFile{
+		{FileName: "report.pdf", Link: "terrabyte://00/1/report.pdf/2", HasDigitalSignature: true},
+		{FileName: "report.pdf.p7s", Link: "terrabyte://00/1/report.pdf.p7s/2"},
+		{FileName: "act.pdf", Link: "terrabyte://00/1/act.pdf/2"},
+		{FileName: "act.pdf.p7m", Link: "terrabyte://00/1/act.pdf.p7m/2"},
+		{FileName: "orphan.xml.P7S", Link: "terrabyte://00/1/orphan.xml.P7S/2"},
+	})
+	suite.Equal([]SignedFile{
+		{FileName: "report.pdf", Link: "terrabyte://00/1/report.pdf/2", SignatureLink: "terrabyte://00/1/report.pdf.p7s/2"},
+		{FileName: "act.pdf", Link: "terrabyte://00/1/act.pdf/2"},
+		{FileName: "act.pdf.p7m", Link: "terrabyte://00/1/act.pdf.p7m/2", Enveloped: true},
+		{FileName: "orphan.xml.P7S", Link: "terrabyte://00/1/orphan.xml.P7S/2"},
+	}, files)
+	suite.True(files[0].Signed())
+	suite.False(files[3].Signed())
+}
+
+func (suite *suiteTestSignedFiles) TestAttachmentDownloadVerified() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		switch r.URL.Path {
+		case "/api/storage/v2/files/1/2/download":
+			_, _ = w.Write([]byte("data:" + r.URL.Query().Get("mnemonic")))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+	verifier := &testVerifier{}
+
+	suite.Run("detached", func() {
+		data, info, err := client.AttachmentDownloadVerified(testToken, SignedFile{
+			FileName:      "resp.xml",
+			Link:          "terrabyte://00/1/resp.xml/2",
+			SignatureLink: "terrabyte://00/1/resp.xml.sig/2",
+		}, verifier)
+		suite.Require().NoError(err)
+		suite.Equal("data:resp.xml", string(data))
+		suite.Equal("data:resp.xml.sig", verifier.sig)
+		suite.Equal("1027700000000", info.OGRN)
+	})
+
+	suite.Run("enveloped", func() {
+		data, info, err := client.AttachmentDownloadVerified(testToken, SignedFile{
+			FileName:  "result.xml.sig",
+			Link:      "terrabyte://00/1/result.xml.sig/2",
+			Enveloped: true,
+		}, verifier)
+		suite.Require().NoError(err)
+		suite.Equal("content", string(data))
+		suite.Equal("data:result.xml.sig", verifier.sig)
+		suite.Equal("1027700000000", info.OGRN)
+	})
+
+	suite.Run("error no signature", func() {
+		data, info, err := client.AttachmentDownloadVerified(testToken, SignedFile{
+			FileName: "info.txt",
+			Link:     "terrabyte://00/1/info.txt/2",
+		}, verifier)
+		suite.ErrorIs(err, ErrAttachmentVerify)
+		suite.ErrorIs(err, ErrNoSignature)
+		suite.Nil(data)
+		suite.Nil(info)
+	})
+
+	suite.Run("error download", func() {
+		data, info, err := client.AttachmentDownloadVerified(testToken, SignedFile{
+			FileName:      "resp.xml",
+			Link:          "terrabyte://00/1/resp.xml/2",
+			SignatureLink: "terrabyte://00/3/resp.xml.sig/2",
+		}, verifier)
+		suite.ErrorIs(err, ErrAttachmentVerify)
+		suite.ErrorIs(err, ErrAttachmentDownload)
+		suite.ErrorIs(err, ErrStatusURLNotFound)
+		suite.Nil(data)
+		suite.Nil(info)
+	})
+
+	suite.Run("error verify", func() {
+		data, info, err := client.AttachmentDownloadVerified(testToken, SignedFile{
+			FileName:      "resp.xml",
+			Link:          "terrabyte://00/1/resp.xml/2",
+			SignatureLink: "terrabyte://00/1/resp.xml.sig/2",
+		}, &testVerifier{err: signature.ErrSignatureInvalid})
+		suite.ErrorIs(err, ErrAttachmentVerify)
+		suite.ErrorIs(err, signature.ErrSignatureInvalid)
+		suite.ErrorContains(err, "resp.xml")
+		suite.Nil(data)
+		suite.Nil(info)
+	})
+}
+
+// testVerifier - проверка подписи, принимающая подписи с префиксом "data:".
+type testVerifier struct {
+	sig string
+	err error
+}
+
+func (v *testVerifier) VerifyDetached(_, sig []byte) (*signature.SignerInfo, error) {
+	v.sig = string(sig)
+	return v.info(sig)
+}
+
+func (v *testVerifier) VerifyAttached(sig []byte) ([]byte, *signature.SignerInfo, error) {
+	v.sig = string(sig)
+	info, err := v.info(sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte("content"), info, nil
+}
+
+func (v *testVerifier) info(sig []byte) (*signature.SignerInfo, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	if !bytes.HasPrefix(sig, []byte("data:")) || !strings.HasSuffix(string(sig), ".sig") {
+		return nil, errors.New("invalid signature")
+	}
+	return &signature.SignerInfo{OGRN: "1027700000000"}, nil
+}
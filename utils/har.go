@@ -0,0 +1,373 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HARVersion - версия формата HTTP Archive.
+const HARVersion = "1.2"
+
+// HAR - журнал HTTP-запросов и ответов в формате HTTP Archive (HAR 1.2).
+// Файл журнала открывается в инструментах разработчика браузера и других программах просмотра HAR.
+//
+// Перед записью в журнал из запросов и ответов удаляются маркеры доступа и персональные данные
+// (заголовки Authorization и Cookie, поля [RedactFields], параметры форм [RedactFormFields]),
+// двоичное содержимое заменяется его размером, как в [LogReq].
+//
+// Пример:
+//
+//	har := utils.NewHAR()
+//	client := apipgu.NewClient(baseURI).WithHAR(har)
+//	...
+//	err = har.Save("session.har")
+type HAR struct {
+	mu      sync.Mutex
+	entries []HAREntry
+}
+
+// HARFile - файл HAR.
+type HARFile struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog - журнал HAR.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator - программа, создавшая журнал.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry - запрос и ответ.
+// Поле Comment содержит наименование операции клиента и номер заявления, например "OrderInfo orderId=1230254874".
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"` // Общее время в миллисекундах
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+// HARRequest - HTTP-запрос.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse - HTTP-ответ.
+// Если запрос завершился ошибкой транспорта, Status равен 0, а текст ошибки - в Comment.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+// HARNameValue - заголовок или параметр запроса.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData - тело запроса.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent - тело ответа.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARTimings - время этапов запроса в миллисекундах; -1 - этап не измерялся.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`    // Ожидание ответа: от отправки запроса до получения заголовков ответа
+	Receive float64 `json:"receive"` // Чтение тела ответа
+	SSL     float64 `json:"ssl"`
+}
+
+// NewHAR - конструктор [HAR].
+func NewHAR() *HAR {
+	return &HAR{}
+}
+
+// Record - добавляет в журнал запрос req и ответ res, полученный за время от started.
+// Тело ответа читается полностью и заменяется копией, поэтому после вызова его можно прочитать повторно.
+// Тело запроса читается с помощью [http.Request].GetBody.
+// Если res равен nil (ошибка транспорта), в журнал записывается ответ со статусом 0 и ошибкой err.
+// Параметр comment - наименование операции клиента, см [HAREntry].
+func (h *HAR) Record(req *http.Request, res *http.Response, err error, started time.Time, comment string) {
+	wait := time.Since(started)
+	entry := HAREntry{
+		StartedDateTime: started,
+		Request:         harRequest(req),
+		Comment:         comment,
+	}
+
+	var receive time.Duration
+	if res != nil {
+		receiveStarted := time.Now()
+		var body []byte
+		if res.Body != nil {
+			body, _ = io.ReadAll(res.Body)
+			_ = res.Body.Close()
+			res.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		receive = time.Since(receiveStarted)
+		entry.Response = harResponse(res, body)
+	} else {
+		entry.Response = HARResponse{
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARNameValue{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		if err != nil {
+			entry.Response.Comment = err.Error()
+		}
+	}
+
+	entry.Timings = HARTimings{
+		Blocked: -1, DNS: -1, Connect: -1, SSL: -1,
+		Wait:    ms(wait),
+		Receive: ms(receive),
+	}
+	entry.Time = entry.Timings.Wait + entry.Timings.Receive
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+}
+
+// Entries - возвращает записи журнала.
+func (h *HAR) Entries() []HAREntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]HAREntry{}, h.entries...)
+}
+
+// WriteTo - записывает журнал в w в формате HAR (JSON). Реализация [io.WriterTo].
+func (h *HAR) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(HARFile{Log: HARLog{
+		Version: HARVersion,
+		Creator: HARCreator{Name: "go-api-epgu", Version: HARVersion},
+		Entries: h.Entries(),
+	}}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Save - записывает журнал в файл path.
+func (h *HAR) Save(path string) error {
+	buf := &bytes.Buffer{}
+	if _, err := h.WriteTo(buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// HARComment - комментарий к записи журнала: наименование операции и номер заявления.
+// Номер заявления не указывается, если orderId равен 0.
+func HARComment(op string, orderId int) string {
+	if orderId == 0 {
+		return op
+	}
+	return fmt.Sprintf("%s orderId=%d", op, orderId)
+}
+
+func harRequest(req *http.Request) HARRequest {
+	r := HARRequest{
+		Method:      req.Method,
+		URL:         sanitizeURL(req.URL),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(req.Header),
+		QueryString: harValues(sanitizeValues(req.URL.Query())),
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if req.GetBody == nil {
+		return r
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return r
+	}
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	r.BodySize = len(data)
+	if len(data) > 0 {
+		contentType := req.Header.Get("Content-Type")
+		r.PostData = &HARPostData{MimeType: contentType, Text: sanitizeBody(contentType, data)}
+	}
+	return r
+}
+
+func harResponse(res *http.Response, body []byte) HARResponse {
+	contentType := res.Header.Get("Content-Type")
+	redirect := res.Header.Get("Location")
+	return HARResponse{
+		Status:      res.StatusCode,
+		StatusText:  http.StatusText(res.StatusCode),
+		HTTPVersion: res.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(res.Header),
+		Content: HARContent{
+			Size:     len(body),
+			MimeType: contentType,
+			Text:     sanitizeBody(contentType, body),
+		},
+		RedirectURL: redirect,
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+// harHeaders - заголовки в порядке имен; значение заголовка Authorization заменяется.
+func harHeaders(header http.Header) []HARNameValue {
+	res := []HARNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			if name == "Authorization" || name == "Cookie" || name == "Set-Cookie" {
+				value = harRedacted
+			}
+			res = append(res, HARNameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func harValues(values url.Values) []HARNameValue {
+	res := []HARNameValue{}
+	for name, vv := range values {
+		for _, v := range vv {
+			res = append(res, HARNameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// harRedacted - значение, которым заменяются маркеры доступа и персональные данные.
+const harRedacted = "***"
+
+// harSecretFields - поля форм и query-параметры, значения которых заменяются: [RedactFields] и [RedactFormFields].
+var harSecretFields = func() map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, fields := range [][]string{RedactFields, RedactFormFields} {
+		for _, f := range fields {
+			res[f] = struct{}{}
+		}
+	}
+	return res
+}()
+
+// reJSONSecret - поле JSON из [RedactFields] со строковым или числовым значением,
+// в том числе во вложенном JSON, переданном строкой (кавычки экранированы).
+var reJSONSecret = func() *regexp.Regexp {
+	names := make([]string, len(RedactFields))
+	for i, f := range RedactFields {
+		names[i] = regexp.QuoteMeta(f)
+	}
+	return regexp.MustCompile(`(\\?"(?:` + strings.Join(names, "|") + `)\\?"\s*:\s*)` +
+		`("(?:[^"\\]|\\.)*"|\\"[^"\\]*\\"|-?[0-9][0-9.eE+-]*)`)
+}()
+
+// sanitizeBody - удаляет маркеры доступа и персональные данные из тела запроса или ответа
+// и заменяет двоичное содержимое его размером.
+func sanitizeBody(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if v, err := url.ParseQuery(string(body)); err == nil {
+			return sanitizeValues(v).Encode()
+		}
+	case mediaType == "multipart/form-data":
+		return sanitize(string(body))
+	case !utf8.Valid(body):
+		return fmt.Sprintf("[ %d bytes of binary data... ]", len(body))
+	}
+	return sanitizeJSON(string(body))
+}
+
+// sanitizeJSON - заменяет значения полей [RedactFields] в JSON с сохранением типа:
+// числа заменяются на 0, строки - на harRedacted.
+func sanitizeJSON(body string) string {
+	return reJSONSecret.ReplaceAllStringFunc(body, func(s string) string {
+		m := reJSONSecret.FindStringSubmatch(s)
+		switch value := m[2]; {
+		case strings.HasPrefix(value, `\"`):
+			return m[1] + `\"` + harRedacted + `\"`
+		case strings.HasPrefix(value, `"`):
+			return m[1] + `"` + harRedacted + `"`
+		default:
+			return m[1] + "0"
+		}
+	})
+}
+
+func sanitizeValues(v url.Values) url.Values {
+	for name := range v {
+		if _, ok := harSecretFields[name]; ok {
+			v[name] = []string{harRedacted}
+		}
+	}
+	return v
+}
+
+func sanitizeURL(u *url.URL) string {
+	res := *u
+	if res.RawQuery != "" {
+		res.RawQuery = sanitizeValues(res.Query()).Encode()
+	}
+	return res.String()
+}
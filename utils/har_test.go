@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHAR_Record(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"secret-token","expires_in":3600}`))
+		case "/file":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	har := NewHAR()
+	do := func(req *http.Request, comment string) []byte {
+		started := time.Now()
+		res, err := http.DefaultClient.Do(req)
+		har.Record(req, res, err, started, comment)
+		if err != nil {
+			return nil
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return body
+	}
+
+	// форма с client_secret и ответ с маркером доступа
+	form := url.Values{"client_secret": {"signature"}, "scope": {"openid"}}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if body := do(req, "TokenExchange"); !bytes.Contains(body, []byte("secret-token")) {
+		t.Errorf("тело ответа должно остаться доступным после записи, получено %q", body)
+	}
+
+	// multipart с двоичным файлом
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	_ = w.WriteField("orderId", "1230254874")
+	fw, _ := w.CreateFormFile("file", "archive.zip")
+	_, _ = fw.Write([]byte("PK\x03\x04 binary"))
+	_ = w.Close()
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/push", buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer secret-token")
+	do(req, HARComment("OrderPushChunked", 1230254874))
+
+	// двоичный ответ
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/file?mnemonic=resp.pdf", nil)
+	do(req, HARComment("AttachmentDownload", 0))
+
+	// ошибка транспорта
+	req, _ = http.NewRequest(http.MethodGet, "http://127.0.0.1:1/", nil)
+	do(req, "OrderInfo")
+
+	entries := har.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("ожидается 4 записи, получено %d", len(entries))
+	}
+
+	if got := entries[0].Request.PostData.Text; got != "client_secret=%2A%2A%2A&scope=openid" {
+		t.Errorf("postData = %q", got)
+	}
+	if got := entries[0].Response.Content.Text; got != `{"access_token":"***","expires_in":3600}` {
+		t.Errorf("content = %q", got)
+	}
+	if entries[0].Comment != "TokenExchange" {
+		t.Errorf("comment = %q", entries[0].Comment)
+	}
+
+	if got := entries[1].Request.PostData.Text; !strings.Contains(got, "[ 11 bytes of binary data... ]") || strings.Contains(got, "PK") {
+		t.Errorf("postData = %q", got)
+	}
+	for _, h := range entries[1].Request.Headers {
+		if h.Name == "Authorization" && h.Value != "***" {
+			t.Errorf("Authorization = %q", h.Value)
+		}
+	}
+	if entries[1].Comment != "OrderPushChunked orderId=1230254874" {
+		t.Errorf("comment = %q", entries[1].Comment)
+	}
+	if entries[1].Response.Status != http.StatusNotFound {
+		t.Errorf("status = %d", entries[1].Response.Status)
+	}
+
+	if got := entries[2].Response.Content.Text; got != "[ 3 bytes of binary data... ]" {
+		t.Errorf("content = %q", got)
+	}
+	if got := entries[2].Request.QueryString; len(got) != 1 || got[0].Name != "mnemonic" {
+		t.Errorf("queryString = %v", got)
+	}
+
+	if entries[3].Response.Status != 0 || entries[3].Response.Comment == "" {
+		t.Errorf("response = %+v", entries[3].Response)
+	}
+	if entries[3].Timings.Wait < 0 || entries[3].Timings.DNS != -1 {
+		t.Errorf("timings = %+v", entries[3].Timings)
+	}
+}
+
+func TestHAR_RecordPersonalData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rs/prns/1000299353":
+			_, _ = w.Write([]byte(`{"oid":1000299353,"firstName":"Иван","lastName":"Иванов","middleName":"Иванович",` +
+				`"birthDate":"13.04.1960","snils":"715-398-174 20","inn":"500100732259","trusted":true}`))
+		case "/api/gusmev/order/1230254874":
+			_, _ = w.Write([]byte(`{"code":"OK","order":"{\"userId\":1000299353,\"email\":\"ivanov@example.com\",\"phone\":\"+7(912)3456789\"}"}`))
+		}
+	}))
+	defer server.Close()
+
+	har := NewHAR()
+	for _, path := range []string{"/rs/prns/1000299353", "/api/gusmev/order/1230254874"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path+"?oid=1000299353&code=auth-code", nil)
+		started := time.Now()
+		res, err := http.DefaultClient.Do(req)
+		har.Record(req, res, err, started, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+	}
+
+	entries := har.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("ожидается 2 записи, получено %d", len(entries))
+	}
+	for _, e := range entries {
+		for _, q := range e.Request.QueryString {
+			if q.Value != "***" {
+				t.Errorf("queryString %s = %q", q.Name, q.Value)
+			}
+		}
+		if strings.Contains(e.Request.URL, "1000299353&") || strings.Contains(e.Request.URL, "auth-code") {
+			t.Errorf("url = %q", e.Request.URL)
+		}
+	}
+	if got := entries[0].Response.Content.Text; got != `{"oid":0,"firstName":"***","lastName":"***","middleName":"***",`+
+		`"birthDate":"***","snils":"***","inn":"***","trusted":true}` {
+		t.Errorf("content = %q", got)
+	}
+	if got := entries[1].Response.Content.Text; got != `{"code":"OK","order":"{\"userId\":0,\"email\":\"***\",\"phone\":\"***\"}"}` {
+		t.Errorf("content = %q", got)
+	}
+}
+
+func TestHAR_WriteTo(t *testing.T) {
+	har := NewHAR()
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	har.Record(req, nil, errors.New("test"), time.Now(), "Dict")
+
+	buf := &bytes.Buffer{}
+	if _, err := har.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	file := HARFile{}
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Log.Version != HARVersion || len(file.Log.Entries) != 1 {
+		t.Errorf("log = %+v", file.Log)
+	}
+}
@@ -0,0 +1,15 @@
+package utils
+
+// RedactFields - поля JSON, параметры форм и query-параметры, значения которых не должны попадать
+// в журналы и записи запросов: маркеры доступа, подписи и персональные данные.
+// Общий список для [HAR] и пакета cassette.
+var RedactFields = []string{
+	"access_token", "id_token", "refresh_token", "client_secret",
+	"userId", "oid", "urn:esia:sbj_id",
+	"snils", "inn", "firstName", "lastName", "middleName", "birthDate", "mobile", "phone", "email",
+}
+
+// RedactFormFields - параметры форм и query-параметры, значения которых заменяются
+// только в формах и query: код авторизации ЕСИА. Поле code в JSON - код ошибки или результата ЕПГУ,
+// поэтому в JSON оно не заменяется.
+var RedactFormFields = []string{"code"}